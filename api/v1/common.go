@@ -20,3 +20,55 @@ import (
 
 // testTime is only in unit tests to pin the time to a fixed value
 var testTime *time.Time
+
+// VersionSkewPolicy defines how the operator handles an unsupported version skew between a data plane
+// component (IstioCNI, ZTunnel) and an in-use IstioRevision.
+// +kubebuilder:validation:Enum=Warn;Block
+type VersionSkewPolicy string
+
+const (
+	// VersionSkewPolicyWarn reports the unsupported version skew in the status, but installs the component anyway.
+	VersionSkewPolicyWarn VersionSkewPolicy = "Warn"
+
+	// VersionSkewPolicyBlock refuses to install or upgrade the component to a version with an unsupported version skew.
+	// An existing installation keeps running the previously installed version.
+	VersionSkewPolicyBlock VersionSkewPolicy = "Block"
+)
+
+// VersionSource references the resource from which a data plane component takes its version.
+type VersionSource struct {
+	// Name of the Istio resource whose version the component tracks. The component is upgraded
+	// only after the Istio's active revision has been upgraded and is ready.
+	// +kubebuilder:validation:MinLength=1
+	Istio string `json:"istio"`
+}
+
+// DeletionPolicy defines what happens to the components installed for a resource when the resource is deleted.
+// +kubebuilder:validation:Enum=Delete;Orphan
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete uninstalls the components when the resource is deleted.
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+
+	// DeletionPolicyOrphan leaves the components running when the resource is deleted, but removes the owner
+	// references and Helm release records through which the operator manages them. A resource of the same kind
+	// with the same name and namespace adopts the components again.
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+)
+
+// NamespaceCreation configures the namespace that the operator creates for a component when it doesn't exist.
+type NamespaceCreation struct {
+	// Labels to set on the namespace, e.g. pod-security.kubernetes.io/enforce or topology.istio.io/network.
+	// The operator keeps these labels up to date, but doesn't remove labels that are no longer listed.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations to set on the namespace. The operator keeps these annotations up to date, but doesn't
+	// remove annotations that are no longer listed.
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Defines whether the namespace is deleted together with the resource. The operator only deletes the
+	// namespace if it created it, and never deletes a namespace that contains workloads that weren't
+	// installed by the operator.
+	Owned bool `json:"owned,omitempty"`
+}
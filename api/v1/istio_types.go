@@ -32,6 +32,20 @@ const (
 	MinRevisionDeletionGracePeriodSeconds     = 0
 )
 
+// DiscoverySelectorPolicy defines how the discovery selectors of a control plane are determined.
+// +kubebuilder:validation:Enum=Manual;Automatic
+type DiscoverySelectorPolicy string
+
+const (
+	// DiscoverySelectorPolicyManual leaves the discovery selectors to the user, who can set them in
+	// spec.values.meshConfig.discoverySelectors.
+	DiscoverySelectorPolicyManual DiscoverySelectorPolicy = "Manual"
+
+	// DiscoverySelectorPolicyAutomatic makes the operator compute the discovery selectors from the
+	// namespaces that reference the Istio's revisions or the revision tags that point to them.
+	DiscoverySelectorPolicyAutomatic DiscoverySelectorPolicy = "Automatic"
+)
+
 // IstioSpec defines the desired state of Istio
 // +kubebuilder:validation:XValidation:rule="!has(self.values) || !has(self.values.global) || !has(self.values.global.istioNamespace) || self.values.global.istioNamespace == self.__namespace__",message="spec.values.global.istioNamespace must match spec.namespace"
 type IstioSpec struct {
@@ -63,9 +77,76 @@ type IstioSpec struct {
 	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"
 	Namespace string `json:"namespace"`
 
+	// Makes the operator create the namespace specified in spec.namespace if it doesn't exist, instead of
+	// waiting for it to be created.
+	CreateNamespace *NamespaceCreation `json:"createNamespace,omitempty"`
+
+	// Defines what happens to the control plane when the Istio is deleted. When set to Delete, the control
+	// plane is uninstalled. When set to Orphan, istiod, its webhooks and the components created from
+	// spec.components are left running, but the operator stops managing them until an Istio with the same
+	// name and namespace is created. The policy is applied to the IstioRevisions and components owned by
+	// the Istio.
+	// +kubebuilder:default=Delete
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
 	// Defines the values to be passed to the Helm charts when installing Istio.
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Helm Values"
 	Values *Values `json:"values,omitempty"`
+
+	// Defines the data plane components that are created and managed together with this Istio.
+	// Each component is installed with the same version and profile as the control plane and
+	// is removed when it is removed from this field or when the Istio is deleted.
+	Components *IstioComponents `json:"components,omitempty"`
+
+	// Defines how the discovery selectors of the control plane are determined. When set to Manual, the
+	// discovery selectors are taken from spec.values.meshConfig.discoverySelectors. When set to Automatic,
+	// the control plane only discovers its own namespace and the namespaces whose labels or pods reference
+	// one of its revisions or a revision tag pointing to them, as well as namespaces that were added to
+	// the mesh with a MeshMember. In that case, spec.values.meshConfig.discoverySelectors must not be set.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Discovery Selector Policy"
+	// +kubebuilder:default=Manual
+	DiscoverySelectorPolicy DiscoverySelectorPolicy `json:"discoverySelectorPolicy,omitempty"`
+
+	// Restricts which namespaces can join this control plane with a MeshMember. A MeshMember that references this
+	// Istio is only applied if the labels of its namespace match the selector. If not set, MeshMembers in any
+	// namespace can join. Namespaces labeled directly by a user with permission to modify them aren't affected.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Member Selector"
+	MemberSelector *metav1.LabelSelector `json:"memberSelector,omitempty"`
+}
+
+// IstioComponents defines the components that the Istio resource creates and owns.
+type IstioComponents struct {
+	// Configures the IstioCNI resource that is created for this Istio. The IstioCNI
+	// is only created when this field is set.
+	CNI *IstioCNIComponent `json:"cni,omitempty"`
+
+	// Configures the ZTunnel resource that is created for this Istio. The ZTunnel
+	// is only created when this field is set.
+	ZTunnel *ZTunnelComponent `json:"ztunnel,omitempty"`
+}
+
+// IstioCNIComponent defines the configuration of the IstioCNI created by an Istio resource.
+type IstioCNIComponent struct {
+	// Namespace to which the Istio CNI component should be installed.
+	// +kubebuilder:default=istio-cni
+	Namespace string `json:"namespace"`
+
+	// Defines the values to be passed to the Helm charts when installing Istio CNI.
+	Values *CNIValues `json:"values,omitempty"`
+	// Makes the operator create the component's namespace if it doesn't exist.
+	CreateNamespace *NamespaceCreation `json:"createNamespace,omitempty"`
+}
+
+// ZTunnelComponent defines the configuration of the ZTunnel created by an Istio resource.
+type ZTunnelComponent struct {
+	// Namespace to which the Istio ztunnel component should be installed.
+	// +kubebuilder:default=ztunnel
+	Namespace string `json:"namespace"`
+
+	// Defines the values to be passed to the Helm charts when installing Istio ztunnel.
+	Values *ZTunnelValues `json:"values,omitempty"`
+	// Makes the operator create the component's namespace if it doesn't exist.
+	CreateNamespace *NamespaceCreation `json:"createNamespace,omitempty"`
 }
 
 // IstioUpdateStrategy defines how the control plane should be updated when the version in
@@ -120,6 +201,42 @@ type IstioStatus struct {
 
 	// Reports information about the underlying IstioRevisions.
 	Revisions RevisionSummary `json:"revisions,omitempty"`
+
+	// Reports the readiness of the components created from spec.components.
+	Components []IstioComponentStatus `json:"components,omitempty"`
+
+	// The namespaces that the control plane discovers. Only set when spec.discoverySelectorPolicy is Automatic.
+	DiscoveredNamespaces []string `json:"discoveredNamespaces,omitempty"`
+
+	// Lists the discovered namespaces that are also claimed by another Istio.
+	NamespaceConflicts []IstioNamespaceConflict `json:"namespaceConflicts,omitempty"`
+}
+
+// IstioNamespaceConflict describes a namespace that references the revisions of more than one Istio.
+type IstioNamespaceConflict struct {
+	// The name of the namespace.
+	Namespace string `json:"namespace"`
+
+	// The names of the other Istio resources that claim the namespace.
+	Istios []string `json:"istios"`
+}
+
+// IstioComponentStatus reports the state of a component that is owned by an Istio resource.
+type IstioComponentStatus struct {
+	// Kind of the component resource, e.g. IstioCNI or ZTunnel.
+	Kind string `json:"kind"`
+
+	// Name of the component resource.
+	Name string `json:"name"`
+
+	// Whether the component is ready. Mirrors the status of the component's Ready condition.
+	Ready metav1.ConditionStatus `json:"ready"`
+
+	// The version of the component that is currently installed.
+	Version string `json:"version,omitempty"`
+
+	// Human-readable message explaining why the component is not ready.
+	Message string `json:"message,omitempty"`
 }
 
 // RevisionSummary contains information on the number of IstioRevisions associated with this Istio.
@@ -211,6 +328,10 @@ const (
 
 	// IstioReasonReconcileError indicates that the reconciliation of the resource has failed, but will be retried.
 	IstioReasonReconcileError IstioConditionReason = "ReconcileError"
+
+	// IstioReasonImageVerificationFailed indicates that an image was rejected by the image verification policy of
+	// the operator, so the component wasn't installed or upgraded. The condition message names the rejected image.
+	IstioReasonImageVerificationFailed IstioConditionReason = "ImageVerificationFailed"
 )
 
 const (
@@ -230,10 +351,32 @@ const (
 	// IstioReasonRemoteIstiodNotReady indicates that the control plane is fully reconciled, but the remote istiod is not ready.
 	IstioReasonRemoteIstiodNotReady IstioConditionReason = "RemoteIstiodNotReady"
 
+	// IstioReasonComponentsNotReady indicates that the control plane is ready, but one or more of the
+	// components defined in spec.components are not.
+	IstioReasonComponentsNotReady IstioConditionReason = "ComponentsNotReady"
+
 	// IstioReasonReadinessCheckFailed indicates that readiness could not be ascertained.
 	IstioReasonReadinessCheckFailed IstioConditionReason = "ReadinessCheckFailed"
 )
 
+const (
+	// IstioConditionExclusiveNamespaces signifies whether the namespaces discovered by the control plane
+	// aren't claimed by any other Istio. This condition is only set when spec.discoverySelectorPolicy is Automatic.
+	IstioConditionExclusiveNamespaces IstioConditionType = "ExclusiveNamespaces"
+
+	// IstioReasonNamespaceConflict indicates that a namespace references the revisions of more than one Istio.
+	IstioReasonNamespaceConflict IstioConditionReason = "NamespaceConflict"
+)
+
+const (
+	// IstioConditionDeletionBlocked signifies whether the deletion of the Istio resource is blocked, because workloads
+	// still use its revisions. This condition is only set while the resource is being deleted.
+	IstioConditionDeletionBlocked IstioConditionType = "DeletionBlocked"
+
+	// IstioReasonRevisionsInUse indicates that the deletion is blocked, because at least one of the IstioRevisions is in use.
+	IstioReasonRevisionsInUse IstioConditionReason = "RevisionsInUse"
+)
+
 const (
 	// IstioReasonHealthy indicates that the control plane is fully reconciled and that all components are ready.
 	IstioReasonHealthy IstioConditionReason = "Healthy"
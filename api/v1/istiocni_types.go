@@ -48,9 +48,29 @@ type IstioCNISpec struct {
 	// +kubebuilder:default=istio-cni
 	Namespace string `json:"namespace"`
 
+	// Makes the operator create the namespace specified in spec.namespace if it doesn't exist, instead of
+	// waiting for it to be created.
+	CreateNamespace *NamespaceCreation `json:"createNamespace,omitempty"`
+
+	// Defines what happens to the CNI plugin when the IstioCNI is deleted. When set to Delete, the plugin
+	// is uninstalled. When set to Orphan, the istio-cni-node DaemonSet is left running, but the operator
+	// stops managing it until an IstioCNI with the same namespace is created.
+	// +kubebuilder:default=Delete
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
 	// Defines the values to be passed to the Helm charts when installing Istio CNI.
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Helm Values"
 	Values *CNIValues `json:"values,omitempty"`
+
+	// Defines how the operator handles a version that is too far apart from the version of an in-use IstioRevision.
+	// Warn only reports the skew in the SupportedVersionSkew condition, whereas Block also refuses to install or
+	// upgrade to such a version.
+	// +kubebuilder:default=Warn
+	VersionSkewPolicy VersionSkewPolicy `json:"versionSkewPolicy,omitempty"`
+
+	// Makes the component track the version of the referenced Istio resource instead of using spec.version.
+	// When the Istio's version changes, the component is upgraded after the control plane has been upgraded.
+	VersionFrom *VersionSource `json:"versionFrom,omitempty"`
 }
 
 // IstioCNIStatus defines the observed state of IstioCNI
@@ -66,8 +86,69 @@ type IstioCNIStatus struct {
 
 	// Reports the current state of the object.
 	State IstioCNIConditionReason `json:"state,omitempty"`
+
+	// The Istio version of the installed Istio CNI component. When spec.versionFrom is set, this may lag behind the version
+	// of the referenced Istio until its control plane has been upgraded.
+	Version string `json:"version,omitempty"`
+
+	// Reports the rollout of the istio-cni-node DaemonSet and the nodes that don't run a ready istio-cni-node pod.
+	DaemonSet *IstioCNIDaemonSetStatus `json:"daemonSet,omitempty"`
 }
 
+// IstioCNIDaemonSetStatus reports the rollout of the istio-cni-node DaemonSet.
+type IstioCNIDaemonSetStatus struct {
+	// The number of nodes that should be running the istio-cni-node pod.
+	DesiredNumberScheduled int32 `json:"desiredNumberScheduled"`
+
+	// The number of nodes that are running the updated istio-cni-node pod.
+	UpdatedNumberScheduled int32 `json:"updatedNumberScheduled"`
+
+	// The number of nodes that are running a ready istio-cni-node pod.
+	NumberReady int32 `json:"numberReady"`
+
+	// The number of nodes that should be running the istio-cni-node pod, but don't have an available one.
+	NumberUnavailable int32 `json:"numberUnavailable"`
+
+	// The Istio versions of the running istio-cni-node pods. During an upgrade, both the old and the new version are listed.
+	Versions []string `json:"versions,omitempty"`
+
+	// Lists the nodes that don't run a ready istio-cni-node pod, including the nodes that the DaemonSet doesn't
+	// schedule pods to because of their labels or taints. At most 100 nodes are listed.
+	NodeGaps []IstioCNINodeGap `json:"nodeGaps,omitempty"`
+}
+
+// IstioCNINodeGap describes a node that doesn't run a ready istio-cni-node pod.
+type IstioCNINodeGap struct {
+	// The name of the node.
+	Node string `json:"node"`
+
+	// Why the node doesn't run a ready istio-cni-node pod.
+	Reason IstioCNINodeGapReason `json:"reason"`
+
+	// Human-readable message with details about the gap.
+	Message string `json:"message,omitempty"`
+}
+
+// IstioCNINodeGapReason describes why a node doesn't run a ready istio-cni-node pod.
+type IstioCNINodeGapReason string
+
+const (
+	// IstioCNINodeGapNodeSelectorMismatch indicates that the node's labels don't match the DaemonSet's nodeSelector.
+	IstioCNINodeGapNodeSelectorMismatch IstioCNINodeGapReason = "NodeSelectorMismatch"
+
+	// IstioCNINodeGapNodeAffinityMismatch indicates that the node doesn't match the DaemonSet's required node affinity.
+	IstioCNINodeGapNodeAffinityMismatch IstioCNINodeGapReason = "NodeAffinityMismatch"
+
+	// IstioCNINodeGapTaintNotTolerated indicates that the node has a taint that the DaemonSet doesn't tolerate.
+	IstioCNINodeGapTaintNotTolerated IstioCNINodeGapReason = "TaintNotTolerated"
+
+	// IstioCNINodeGapPodMissing indicates that the DaemonSet should run a pod on the node, but there is none.
+	IstioCNINodeGapPodMissing IstioCNINodeGapReason = "PodMissing"
+
+	// IstioCNINodeGapPodNotReady indicates that the istio-cni-node pod on the node is not ready.
+	IstioCNINodeGapPodNotReady IstioCNINodeGapReason = "PodNotReady"
+)
+
 // GetCondition returns the condition of the specified type
 func (s *IstioCNIStatus) GetCondition(conditionType IstioCNIConditionType) IstioCNICondition {
 	if s != nil {
@@ -131,7 +212,7 @@ type IstioCNICondition struct {
 }
 
 // IstioCNIConditionType represents the type of the condition.  Condition stages are:
-// Installed, Reconciled, Ready
+// Installed, Reconciled, Ready, SupportedVersionSkew
 type IstioCNIConditionType string
 
 // IstioCNIConditionReason represents a short message indicating how the condition came
@@ -145,6 +226,18 @@ const (
 
 	// IstioCNIReasonReconcileError indicates that the reconciliation of the resource has failed, but will be retried.
 	IstioCNIReasonReconcileError IstioCNIConditionReason = "ReconcileError"
+
+	// IstioCNIReasonWaitingForControlPlane indicates that the component tracks the version of an Istio resource
+	// and waits for its control plane to be upgraded before being upgraded itself.
+	IstioCNIReasonWaitingForControlPlane IstioCNIConditionReason = "WaitingForControlPlane"
+
+	// IstioCNIReasonUnsupportedVersionSkew indicates that the component wasn't installed or upgraded, because its
+	// version is too far apart from the version of an in-use IstioRevision and spec.versionSkewPolicy is Block.
+	IstioCNIReasonUnsupportedVersionSkew IstioCNIConditionReason = "UnsupportedVersionSkew"
+
+	// IstioCNIReasonImageVerificationFailed indicates that an image was rejected by the image verification policy of
+	// the operator, so the component wasn't installed or upgraded. The condition message names the rejected image.
+	IstioCNIReasonImageVerificationFailed IstioCNIConditionReason = "ImageVerificationFailed"
 )
 
 const (
@@ -156,6 +249,26 @@ const (
 
 	// IstioCNIReasonReadinessCheckFailed indicates that the DaemonSet readiness status could not be ascertained.
 	IstioCNIReasonReadinessCheckFailed IstioCNIConditionReason = "ReadinessCheckFailed"
+
+	// IstioCNIReasonUpgrading indicates that the istio-cni-node DaemonSet is being rolled out and that some nodes
+	// still run an outdated istio-cni-node pod.
+	IstioCNIReasonUpgrading IstioCNIConditionReason = "Upgrading"
+)
+
+const (
+	// IstioCNIConditionSupportedVersionSkew signifies whether the version of the component is supported by
+	// all in-use IstioRevisions.
+	IstioCNIConditionSupportedVersionSkew IstioCNIConditionType = "SupportedVersionSkew"
+)
+
+const (
+	// IstioCNIConditionDeletionBlocked signifies whether the deletion of the IstioCNI resource is blocked, because
+	// workloads still rely on the CNI plugin. This condition is only set while the resource is being deleted.
+	IstioCNIConditionDeletionBlocked IstioCNIConditionType = "DeletionBlocked"
+
+	// IstioCNIReasonReferencedByWorkloads indicates that the deletion is blocked, because ambient or sidecar pods
+	// rely on the CNI plugin.
+	IstioCNIReasonReferencedByWorkloads IstioCNIConditionReason = "ReferencedByWorkloads"
 )
 
 const (
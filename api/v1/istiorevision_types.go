@@ -43,6 +43,12 @@ type IstioRevisionSpec struct {
 	// Defines the values to be passed to the Helm charts when installing Istio.
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Helm Values"
 	Values *Values `json:"values,omitempty"`
+
+	// Defines what happens to istiod when the IstioRevision is deleted. When set to Delete, the revision is
+	// uninstalled. When set to Orphan, istiod and its webhooks are left running, but the operator stops
+	// managing them until an IstioRevision with the same name and namespace is created.
+	// +kubebuilder:default=Delete
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
 }
 
 // IstioRevisionStatus defines the observed state of IstioRevision
@@ -58,8 +64,98 @@ type IstioRevisionStatus struct {
 
 	// Reports the current state of the object.
 	State IstioRevisionConditionReason `json:"state,omitempty"`
+
+	// Reports how the control plane is deployed, as derived from the fully evaluated Helm values
+	// (e.g. values.istiodRemote.enabled and values.global.externalIstiod).
+	Mode IstioRevisionMode `json:"mode,omitempty"`
+
+	// Reports the results of the readiness probes that the operator sends to the remote istiod
+	// through the revision's webhooks. Only set when the mode is Remote.
+	RemoteIstiod *RemoteIstiodStatus `json:"remoteIstiod,omitempty"`
+}
+
+// RemoteIstiodStatus reports the readiness of a remote istiod, as observed by probing the
+// webhooks in the revision's MutatingWebhookConfigurations.
+type RemoteIstiodStatus struct {
+	// The results of the latest probes, for each MutatingWebhookConfiguration of the revision
+	// whose webhooks were probed.
+	// +listType=map
+	// +listMapKey=name
+	WebhookConfigurations []WebhookConfigurationProbeStatus `json:"webhookConfigurations,omitempty"`
+}
+
+// GetWebhookConfiguration returns the probe results of the MutatingWebhookConfiguration with the given
+// name, or nil if its webhooks haven't been probed.
+func (s *RemoteIstiodStatus) GetWebhookConfiguration(name string) *WebhookConfigurationProbeStatus {
+	if s == nil {
+		return nil
+	}
+	for i := range s.WebhookConfigurations {
+		if s.WebhookConfigurations[i].Name == name {
+			return &s.WebhookConfigurations[i]
+		}
+	}
+	return nil
+}
+
+// WebhookConfigurationProbeStatus reports the results of probing the remote istiod through the
+// webhooks of a single MutatingWebhookConfiguration.
+type WebhookConfigurationProbeStatus struct {
+	// The name of the MutatingWebhookConfiguration whose webhooks were probed.
+	Name string `json:"name"`
+
+	// The results of the latest probe of each webhook in the MutatingWebhookConfiguration.
+	Webhooks []WebhookProbeResult `json:"webhooks,omitempty"`
+}
+
+// IsReady returns true if the remote istiod passed the readiness probes of all webhooks
+func (s *WebhookConfigurationProbeStatus) IsReady() bool {
+	if s == nil || len(s.Webhooks) == 0 {
+		return false
+	}
+	for _, webhook := range s.Webhooks {
+		if !webhook.Ready {
+			return false
+		}
+	}
+	return true
 }
 
+// WebhookProbeResult is the result of probing the remote istiod through a single webhook.
+type WebhookProbeResult struct {
+	// The name of the webhook.
+	Name string `json:"name"`
+
+	// The URL of the readiness endpoint that was probed.
+	URL string `json:"url,omitempty"`
+
+	// Whether the remote istiod responded successfully to the readiness probe.
+	Ready bool `json:"ready"`
+
+	// Human-readable message indicating why the probe failed.
+	Message string `json:"message,omitempty"`
+
+	// Last time the result of the probe changed.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// IstioRevisionMode describes how the control plane of an IstioRevision is deployed.
+// +kubebuilder:validation:Enum=Local;Remote;External
+type IstioRevisionMode string
+
+const (
+	// IstioRevisionModeLocal indicates that istiod is deployed in this cluster and serves the workloads in this cluster.
+	IstioRevisionModeLocal IstioRevisionMode = "Local"
+
+	// IstioRevisionModeRemote indicates that istiod is not deployed in this cluster. Instead, the workloads
+	// in this cluster are served by a control plane running elsewhere (values.istiodRemote.enabled is true).
+	IstioRevisionModeRemote IstioRevisionMode = "Remote"
+
+	// IstioRevisionModeExternal indicates that istiod is deployed in this cluster, but serves the workloads
+	// in an external cluster (values.global.externalIstiod is true).
+	IstioRevisionModeExternal IstioRevisionMode = "External"
+)
+
 // GetCondition returns the condition of the specified type
 func (s *IstioRevisionStatus) GetCondition(conditionType IstioRevisionConditionType) IstioRevisionCondition {
 	if s != nil {
@@ -137,6 +233,10 @@ const (
 
 	// IstioRevisionReasonReconcileError indicates that the reconciliation of the resource has failed, but will be retried.
 	IstioRevisionReasonReconcileError IstioRevisionConditionReason = "ReconcileError"
+
+	// IstioRevisionReasonImageVerificationFailed indicates that an image was rejected by the image verification policy of
+	// the operator, so the component wasn't installed or upgraded. The condition message names the rejected image.
+	IstioRevisionReasonImageVerificationFailed IstioRevisionConditionReason = "ImageVerificationFailed"
 )
 
 const (
@@ -168,6 +268,12 @@ const (
 	IstioRevisionReasonUsageCheckFailed IstioRevisionConditionReason = "UsageCheckFailed"
 )
 
+const (
+	// IstioRevisionConditionDeletionBlocked signifies whether the deletion of the IstioRevision is blocked, because
+	// workloads still use it. This condition is only set while the resource is being deleted.
+	IstioRevisionConditionDeletionBlocked IstioRevisionConditionType = "DeletionBlocked"
+)
+
 const (
 	// IstioRevisionReasonHealthy indicates that the control plane is fully reconciled and that all components are ready.
 	IstioRevisionReasonHealthy IstioRevisionConditionReason = "Healthy"
@@ -176,7 +282,7 @@ const (
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:scope=Cluster,shortName=istiorev,categories=istio-io
 // +kubebuilder:subresource:status
-// +kubebuilder:printcolumn:name="Type",type="string",JSONPath=".spec.type",description="Whether the control plane is installed locally or in a remote cluster."
+// +kubebuilder:printcolumn:name="Mode",type="string",JSONPath=".status.mode",description="Whether the control plane is installed locally or in a remote cluster."
 // +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="Whether the control plane installation is ready to handle requests."
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.state",description="The current state of this object."
 // +kubebuilder:printcolumn:name="In use",type="string",JSONPath=".status.conditions[?(@.type==\"InUse\")].status",description="Whether the revision is being used by workloads."
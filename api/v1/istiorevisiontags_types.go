@@ -29,9 +29,64 @@ const (
 type IstioRevisionTagSpec struct {
 	// +kubebuilder:validation:Required
 	TargetRef IstioRevisionTagTargetReference `json:"targetRef"`
+
+	// Defines when the tag is moved from the IstioRevision it currently points to to a new IstioRevision,
+	// for example when the active revision of the referenced Istio changes. If not set, the tag is moved
+	// immediately.
+	PromotionPolicy *IstioRevisionTagPromotionPolicy `json:"promotionPolicy,omitempty"`
+
+	// Splits the injection of new pods between the IstioRevision referenced by targetRef and a canary
+	// IstioRevision. If not set, all pods that use the tag are injected by the IstioRevision referenced by targetRef.
+	Canary *IstioRevisionTagCanary `json:"canary,omitempty"`
+
+	// Defines what happens to the tag's webhooks when the IstioRevisionTag is deleted. When set to Delete,
+	// they're removed. When set to Orphan, they're left in place, but the operator stops managing them until
+	// an IstioRevisionTag with the same name is created.
+	// +kubebuilder:default=Delete
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// Restricts which namespaces can use this tag through a MeshMember. A MeshMember that references this tag is
+	// only applied if the labels of its namespace match the selector. If not set, MeshMembers in any namespace can
+	// use the tag.
+	MemberSelector *metav1.LabelSelector `json:"memberSelector,omitempty"`
+}
+
+// IstioRevisionTagCanary defines a canary IstioRevision that injects a share of the pods that use the tag.
+// The share is assigned per namespace: each namespace is placed in a bucket between 0 and 99 based on a hash
+// of its name, and the namespaces whose bucket is lower than the weight are injected by the canary revision.
+// The operator records the bucket in the sailoperator.io/canary-bucket label of each namespace, which the
+// webhooks select on. A namespace created while the tag has a canary is injected by the primary revision until
+// the operator has labeled it. Increasing the weight only ever moves labeled namespaces from the primary to the
+// canary revision.
+type IstioRevisionTagCanary struct {
+	// +kubebuilder:validation:Required
+	TargetRef IstioRevisionTagTargetReference `json:"targetRef"`
+
+	// Defines the percentage of namespaces whose pods are injected by the canary revision.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	Weight int32 `json:"weight"`
+}
+
+// IstioRevisionTagPromotionPolicy defines the conditions that must be met before a tag is moved to a new IstioRevision.
+// The policy doesn't apply when the tag is created or when the IstioRevision it currently points to no longer exists.
+type IstioRevisionTagPromotionPolicy struct {
+	// Defines how many seconds the new IstioRevision must be Ready before the tag is moved to it.
+	// The tag is never moved to a revision that isn't Ready, even if this field is not set.
+	// +kubebuilder:validation:Minimum=0
+	SoakPeriodSeconds *int64 `json:"soakPeriodSeconds,omitempty"`
+
+	// Defines whether the tag is only moved after the promotion has been approved manually. A promotion is approved
+	// by setting the sailoperator.io/approved-revision annotation on the IstioRevisionTag to the name of the
+	// pending IstioRevision.
+	RequireApproval bool `json:"requireApproval,omitempty"`
 }
 
 // IstioRevisionTagTargetReference can reference either Istio or IstioRevision objects in the cluster. In the case of referencing an Istio object, the Sail Operator will automatically update the reference to the Istio object's Active Revision.
+// Instead of referencing an IstioRevision by name, the reference can select IstioRevisions by their labels and/or version;
+// the tag then points to the newest Ready IstioRevision that matches.
+// +kubebuilder:validation:XValidation:rule="has(self.name) != (has(self.selector) || has(self.versionConstraint))",message="exactly one of name or selector/versionConstraint must be set"
+// +kubebuilder:validation:XValidation:rule="!(has(self.selector) || has(self.versionConstraint)) || self.kind == 'IstioRevision'",message="selector and versionConstraint can only be used with kind IstioRevision"
 type IstioRevisionTagTargetReference struct {
 	// Kind is the kind of the target resource.
 	//
@@ -40,12 +95,20 @@ type IstioRevisionTagTargetReference struct {
 	// +kubebuilder:validation:Required
 	Kind string `json:"kind"`
 
-	// Name is the name of the target resource.
+	// Name is the name of the target resource. Must not be set if selector or versionConstraint is set.
 	//
 	// +kubebuilder:validation:MinLength=1
 	// +kubebuilder:validation:MaxLength=253
-	// +kubebuilder:validation:Required
-	Name string `json:"name"`
+	Name string `json:"name,omitempty"`
+
+	// Selects the IstioRevisions the tag can point to by their labels. The labels of an IstioRevision that is owned by
+	// an Istio resource include the labels of the Istio resource. Can only be used with kind IstioRevision.
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// Selects the IstioRevisions the tag can point to by their version, using a semantic version constraint
+	// (e.g. ">=1.24, <1.25"). IstioRevisions whose version is not a semantic version (e.g. "latest") never match.
+	// Can only be used with kind IstioRevision.
+	VersionConstraint string `json:"versionConstraint,omitempty"`
 }
 
 // IstioRevisionStatus defines the observed state of IstioRevision
@@ -67,6 +130,38 @@ type IstioRevisionTagStatus struct {
 
 	// IstioRevision stores the name of the referenced IstioRevision
 	IstioRevision string `json:"istioRevision"`
+
+	// PreviousIstioRevision stores the name of the IstioRevision the tag pointed to before it was last moved
+	PreviousIstioRevision string `json:"previousIstioRevision,omitempty"`
+
+	// PendingIstioRevision stores the name of the IstioRevision the tag will be moved to once the promotion policy allows it
+	PendingIstioRevision string `json:"pendingIstioRevision,omitempty"`
+
+	// LastPromotionTime is the time at which the tag was last moved to a different IstioRevision
+	LastPromotionTime *metav1.Time `json:"lastPromotionTime,omitempty"`
+
+	// Split reports how the injection of pods that use the tag is split between the primary and the canary
+	// IstioRevision. Only set when spec.canary is set. The first entry describes the primary IstioRevision,
+	// the second one the canary IstioRevision.
+	Split []IstioRevisionTagSplitStatus `json:"split,omitempty"`
+}
+
+// IstioRevisionTagSplitStatus reports the share of the tag's namespaces and pods that are assigned to an IstioRevision.
+type IstioRevisionTagSplitStatus struct {
+	// The name of the IstioRevision.
+	IstioRevision string `json:"istioRevision"`
+
+	// The namespace of the IstioRevision's istiod.
+	IstiodNamespace string `json:"istiodNamespace"`
+
+	// The desired percentage of namespaces assigned to the IstioRevision.
+	Weight int32 `json:"weight"`
+
+	// The number of namespaces using the tag that are assigned to the IstioRevision.
+	Namespaces int32 `json:"namespaces"`
+
+	// The number of pods using the tag that were injected by the IstioRevision.
+	Pods int32 `json:"pods"`
 }
 
 // GetCondition returns the condition of the specified type
@@ -132,7 +227,7 @@ type IstioRevisionTagCondition struct {
 }
 
 // IstioRevisionConditionType represents the type of the condition.  Condition stages are:
-// Installed, Reconciled, Ready
+// Installed, Reconciled, Ready, Promoted
 type IstioRevisionTagConditionType string
 
 // IstioRevisionConditionReason represents a short message indicating how the condition came
@@ -150,6 +245,11 @@ const (
 	// IstioRevisionTagReasonReferenceNotFound indicates that the resource referenced by the tag's TargetRef was not found
 	IstioRevisionTagReasonReferenceNotFound IstioRevisionTagConditionReason = "RefNotFound"
 
+	// IstioRevisionTagReasonUnmanagedWebhookExists indicates that a MutatingWebhookConfiguration for the tag already exists,
+	// but wasn't created by the operator (e.g. it was created by istioctl). The operator only takes ownership of the
+	// webhook configuration if the sailoperator.io/adopt-webhook annotation is set to "true" on the IstioRevisionTag.
+	IstioRevisionTagReasonUnmanagedWebhookExists IstioRevisionTagConditionReason = "UnmanagedWebhookExists"
+
 	// IstioRevisionReasonReconcileError indicates that the reconciliation of the resource has failed, but will be retried.
 	IstioRevisionTagReasonReconcileError IstioRevisionTagConditionReason = "ReconcileError"
 )
@@ -168,6 +268,19 @@ const (
 	IstioRevisionTagReasonUsageCheckFailed IstioRevisionTagConditionReason = "UsageCheckFailed"
 )
 
+const (
+	// IstioRevisionTagConditionPromoted signifies whether the tag points to the IstioRevision referenced by its
+	// TargetRef, or whether moving the tag to that revision is held back by the tag's promotion policy.
+	IstioRevisionTagConditionPromoted IstioRevisionTagConditionType = "Promoted"
+
+	// IstioRevisionTagReasonPromotionPending indicates that the tag will be moved once the new revision has been Ready
+	// for the duration required by the promotion policy.
+	IstioRevisionTagReasonPromotionPending IstioRevisionTagConditionReason = "PromotionPending"
+
+	// IstioRevisionTagReasonAwaitingApproval indicates that the tag will be moved once the promotion has been approved.
+	IstioRevisionTagReasonAwaitingApproval IstioRevisionTagConditionReason = "AwaitingApproval"
+)
+
 const (
 	// IstioRevisionTagReasonHealthy indicates that the revision tag has been successfully reconciled and is in use.
 	IstioRevisionTagReasonHealthy IstioRevisionTagConditionReason = "Healthy"
@@ -179,6 +292,7 @@ const (
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.state",description="The current state of this object."
 // +kubebuilder:printcolumn:name="In use",type="string",JSONPath=".status.conditions[?(@.type==\"InUse\")].status",description="Whether the tag is being used by workloads."
 // +kubebuilder:printcolumn:name="Revision",type="string",JSONPath=".status.istioRevision",description="The IstioRevision this object is referencing."
+// +kubebuilder:printcolumn:name="Pending Revision",type="string",JSONPath=".status.pendingIstioRevision",description="The IstioRevision this object will reference once the promotion policy allows it."
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="The age of the object"
 
 // IstioRevisionTag references an Istio or IstioRevision object and serves as an alias for sidecar injection. It can be used to manage stable revision tags without having to use istioctl or helm directly. See https://istio.io/latest/docs/setup/upgrade/canary/#stable-revision-labels for more information on the concept.
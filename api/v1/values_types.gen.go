@@ -1022,6 +1022,111 @@ type CNIGlobalConfig struct { // Default k8s resources settings for all Istio co
 	Platform *string `json:"platform,omitempty"`
 }
 
+// ZTunnelGlobalConfig is a subset of the Global Configuration used in the Istio ztunnel chart.
+type ZTunnelGlobalConfig struct { // Default k8s resources settings for all Istio control plane components.
+	//
+	// See https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/#resource-requests-and-limits-of-pod-and-container
+	//
+	// Deprecated: Marked as deprecated in pkg/apis/values_types.proto.
+	DefaultResources *k8sv1.ResourceRequirements `json:"defaultResources,omitempty"`
+
+	// Specifies the docker hub for Istio images.
+	Hub *string `json:"hub,omitempty"`
+	// Specifies the image pull policy for the Istio images. one of Always, Never, IfNotPresent.
+	// Defaults to Always if :latest tag is specified, or IfNotPresent otherwise. Cannot be updated.
+	//
+	// More info: https://kubernetes.io/docs/concepts/containers/images#updating-images
+	// +kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	ImagePullPolicy *k8sv1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// ImagePullSecrets for the control plane ServiceAccount, list of secrets in the same namespace
+	// to use for pulling any images in pods that reference this ServiceAccount.
+	// Must be set for any cluster configured with private docker registry.
+	ImagePullSecrets []string `json:"imagePullSecrets,omitempty"`
+
+	// Specifies whether istio components should output logs in json format by adding --log_as_json argument to each container.
+	LogAsJson *bool `json:"logAsJson,omitempty"`
+	// Specifies the global logging level settings for the Istio control plane components.
+	Logging *GlobalLoggingConfig `json:"logging,omitempty"`
+
+	// Specifies the tag for the Istio docker images.
+	Tag *string `json:"tag,omitempty"`
+	// The variant of the Istio container images to use. Options are "debug" or "distroless". Unset will use the default for the given version.
+	Variant *string `json:"variant,omitempty"`
+
+	// Platform in which Istio is deployed. Possible values are: "openshift" and "gcp"
+	// An empty value means it is a vanilla Kubernetes distribution, therefore no special
+	// treatment will be considered.
+	Platform *string `json:"platform,omitempty"`
+}
+
+// Configuration for ztunnel.
+type ZTunnelConfig struct {
+	// Hub to pull from. Image will be `Hub/Image:Tag-Variant`
+	Hub *string `json:"hub,omitempty"`
+	// Tag to pull from. Image will be `Hub/Image:Tag-Variant`
+	Tag *string `json:"tag,omitempty"`
+	// Variant to pull. Options are "debug" or "distroless". Unset will use the default for the given version.
+	Variant *string `json:"variant,omitempty"`
+	// Image name to pull from. Image will be `Hub/Image:Tag-Variant`
+	// If Image contains a "/", it will replace the entire `image` in the pod.
+	Image *string `json:"image,omitempty"`
+	// resourceName, if set, will override the naming of resources. If not set, will default to the release name.
+	// It is recommended to not set this; this is primarily for backwards compatibility.
+	ResourceName *string `json:"resourceName,omitempty"`
+	// Labels to apply to all top level resources
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations to apply to all top level resources
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Additional volumeMounts to the ztunnel container
+	VolumeMounts []k8sv1.VolumeMount `json:"volumeMounts,omitempty"`
+	// Additional volumes to the ztunnel pod
+	Volumes []k8sv1.Volume `json:"volumes,omitempty"`
+	// Annotations added to each pod. The default annotations are required for scraping prometheus (in most environments).
+	PodAnnotations map[string]string `json:"podAnnotations,omitempty"`
+	// Additional labels to apply on the pod level
+	PodLabels map[string]string `json:"podLabels,omitempty"`
+	// Pod resource configuration
+	Resources *k8sv1.ResourceRequirements `json:"resources,omitempty"`
+	// List of secret names to add to the service account as image pull secrets
+	ImagePullSecrets []string `json:"imagePullSecrets,omitempty"`
+	// A `key: value` mapping of environment variables to add to the pod
+	Env map[string]string `json:"env,omitempty"`
+	// Override for the pod imagePullPolicy
+	// +kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	ImagePullPolicy *k8sv1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// Settings for multicluster
+	MultiCluster *MultiClusterConfig `json:"multiCluster,omitempty"`
+	// meshConfig defines runtime configuration of components.
+	// For ztunnel, only defaultConfig is used, but this is nested under `meshConfig` for consistency with other
+	// components.
+	// TODO: https://github.com/istio/istio/issues/43248
+	MeshConfig *MeshConfig `json:"meshConfig,omitempty"`
+	// This value defines:
+	// 1. how many seconds kube waits for ztunnel pod to gracefully exit before forcibly terminating it (this value)
+	// 2. how many seconds ztunnel waits to drain its own connections (this value - 1 sec)
+	// Default K8S value is 30 seconds
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+	// Revision is set as 'version' label and part of the resource names when installing multiple control planes.
+	// Used to locate the XDS and CA, if caAddress or xdsAddress are not set explicitly.
+	Revision *string `json:"revision,omitempty"`
+	// The customized CA address to retrieve certificates for the pods in the cluster.
+	// CSR clients such as the Istio Agent and ingress gateways can use this to specify the CA endpoint.
+	CaAddress *string `json:"caAddress,omitempty"`
+	// The customized XDS address to retrieve configuration.
+	// This should include the port - 15012 for Istiod. TLS will be used with the certificates in "istiod-ca-cert" secret.
+	// By default, it is istiod.istio-system.svc:15012 if revision is not set, or istiod-<revision>.<istioNamespace>.svc:15012
+	XdsAddress *string `json:"xdsAddress,omitempty"`
+	// Used to locate the XDS and CA, if caAddress or xdsAddress are not set.
+	IstioNamespace *string `json:"istioNamespace,omitempty"`
+	// Configuration log level of ztunnel binary, default is info.
+	// Valid values are: trace, debug, info, warn, error
+	LogLevel *string `json:"logLevel,omitempty"`
+	// To output all logs in json format
+	LogAsJson *bool `json:"logAsJson,omitempty"`
+	// Set to `type: RuntimeDefault` to use the default profile if available.
+	SeLinuxOptions *k8sv1.SELinuxOptions `json:"seLinuxOptions,omitempty"`
+}
+
 // Resource describes the source of configuration
 // +kubebuilder:validation:Enum=SERVICE_REGISTRY
 type Resource string
@@ -959,6 +959,31 @@ func (in *IstioCNI) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IstioCNIComponent) DeepCopyInto(out *IstioCNIComponent) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = new(CNIValues)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CreateNamespace != nil {
+		in, out := &in.CreateNamespace, &out.CreateNamespace
+		*out = new(NamespaceCreation)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioCNIComponent.
+func (in *IstioCNIComponent) DeepCopy() *IstioCNIComponent {
+	if in == nil {
+		return nil
+	}
+	out := new(IstioCNIComponent)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IstioCNICondition) DeepCopyInto(out *IstioCNICondition) {
 	*out = *in
@@ -975,6 +1000,31 @@ func (in *IstioCNICondition) DeepCopy() *IstioCNICondition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IstioCNIDaemonSetStatus) DeepCopyInto(out *IstioCNIDaemonSetStatus) {
+	*out = *in
+	if in.Versions != nil {
+		in, out := &in.Versions, &out.Versions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeGaps != nil {
+		in, out := &in.NodeGaps, &out.NodeGaps
+		*out = make([]IstioCNINodeGap, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioCNIDaemonSetStatus.
+func (in *IstioCNIDaemonSetStatus) DeepCopy() *IstioCNIDaemonSetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IstioCNIDaemonSetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IstioCNIList) DeepCopyInto(out *IstioCNIList) {
 	*out = *in
@@ -1007,14 +1057,39 @@ func (in *IstioCNIList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IstioCNINodeGap) DeepCopyInto(out *IstioCNINodeGap) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioCNINodeGap.
+func (in *IstioCNINodeGap) DeepCopy() *IstioCNINodeGap {
+	if in == nil {
+		return nil
+	}
+	out := new(IstioCNINodeGap)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IstioCNISpec) DeepCopyInto(out *IstioCNISpec) {
 	*out = *in
+	if in.CreateNamespace != nil {
+		in, out := &in.CreateNamespace, &out.CreateNamespace
+		*out = new(NamespaceCreation)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Values != nil {
 		in, out := &in.Values, &out.Values
 		*out = new(CNIValues)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.VersionFrom != nil {
+		in, out := &in.VersionFrom, &out.VersionFrom
+		*out = new(VersionSource)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioCNISpec.
@@ -1037,6 +1112,11 @@ func (in *IstioCNIStatus) DeepCopyInto(out *IstioCNIStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.DaemonSet != nil {
+		in, out := &in.DaemonSet, &out.DaemonSet
+		*out = new(IstioCNIDaemonSetStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioCNIStatus.
@@ -1049,6 +1129,46 @@ func (in *IstioCNIStatus) DeepCopy() *IstioCNIStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IstioComponentStatus) DeepCopyInto(out *IstioComponentStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioComponentStatus.
+func (in *IstioComponentStatus) DeepCopy() *IstioComponentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IstioComponentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IstioComponents) DeepCopyInto(out *IstioComponents) {
+	*out = *in
+	if in.CNI != nil {
+		in, out := &in.CNI, &out.CNI
+		*out = new(IstioCNIComponent)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ZTunnel != nil {
+		in, out := &in.ZTunnel, &out.ZTunnel
+		*out = new(ZTunnelComponent)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioComponents.
+func (in *IstioComponents) DeepCopy() *IstioComponents {
+	if in == nil {
+		return nil
+	}
+	out := new(IstioComponents)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IstioCondition) DeepCopyInto(out *IstioCondition) {
 	*out = *in
@@ -1097,6 +1217,26 @@ func (in *IstioList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IstioNamespaceConflict) DeepCopyInto(out *IstioNamespaceConflict) {
+	*out = *in
+	if in.Istios != nil {
+		in, out := &in.Istios, &out.Istios
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioNamespaceConflict.
+func (in *IstioNamespaceConflict) DeepCopy() *IstioNamespaceConflict {
+	if in == nil {
+		return nil
+	}
+	out := new(IstioNamespaceConflict)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IstioRevision) DeepCopyInto(out *IstioRevision) {
 	*out = *in
@@ -1202,6 +1342,11 @@ func (in *IstioRevisionStatus) DeepCopyInto(out *IstioRevisionStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.RemoteIstiod != nil {
+		in, out := &in.RemoteIstiod, &out.RemoteIstiod
+		*out = new(RemoteIstiodStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioRevisionStatus.
@@ -1219,7 +1364,7 @@ func (in *IstioRevisionTag) DeepCopyInto(out *IstioRevisionTag) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -1241,6 +1386,22 @@ func (in *IstioRevisionTag) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IstioRevisionTagCanary) DeepCopyInto(out *IstioRevisionTagCanary) {
+	*out = *in
+	in.TargetRef.DeepCopyInto(&out.TargetRef)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioRevisionTagCanary.
+func (in *IstioRevisionTagCanary) DeepCopy() *IstioRevisionTagCanary {
+	if in == nil {
+		return nil
+	}
+	out := new(IstioRevisionTagCanary)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IstioRevisionTagCondition) DeepCopyInto(out *IstioRevisionTagCondition) {
 	*out = *in
@@ -1289,10 +1450,45 @@ func (in *IstioRevisionTagList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IstioRevisionTagPromotionPolicy) DeepCopyInto(out *IstioRevisionTagPromotionPolicy) {
+	*out = *in
+	if in.SoakPeriodSeconds != nil {
+		in, out := &in.SoakPeriodSeconds, &out.SoakPeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioRevisionTagPromotionPolicy.
+func (in *IstioRevisionTagPromotionPolicy) DeepCopy() *IstioRevisionTagPromotionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(IstioRevisionTagPromotionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IstioRevisionTagSpec) DeepCopyInto(out *IstioRevisionTagSpec) {
 	*out = *in
-	out.TargetRef = in.TargetRef
+	in.TargetRef.DeepCopyInto(&out.TargetRef)
+	if in.PromotionPolicy != nil {
+		in, out := &in.PromotionPolicy, &out.PromotionPolicy
+		*out = new(IstioRevisionTagPromotionPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(IstioRevisionTagCanary)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MemberSelector != nil {
+		in, out := &in.MemberSelector, &out.MemberSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioRevisionTagSpec.
@@ -1305,6 +1501,21 @@ func (in *IstioRevisionTagSpec) DeepCopy() *IstioRevisionTagSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IstioRevisionTagSplitStatus) DeepCopyInto(out *IstioRevisionTagSplitStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioRevisionTagSplitStatus.
+func (in *IstioRevisionTagSplitStatus) DeepCopy() *IstioRevisionTagSplitStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IstioRevisionTagSplitStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IstioRevisionTagStatus) DeepCopyInto(out *IstioRevisionTagStatus) {
 	*out = *in
@@ -1315,6 +1526,15 @@ func (in *IstioRevisionTagStatus) DeepCopyInto(out *IstioRevisionTagStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.LastPromotionTime != nil {
+		in, out := &in.LastPromotionTime, &out.LastPromotionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Split != nil {
+		in, out := &in.Split, &out.Split
+		*out = make([]IstioRevisionTagSplitStatus, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioRevisionTagStatus.
@@ -1330,6 +1550,11 @@ func (in *IstioRevisionTagStatus) DeepCopy() *IstioRevisionTagStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IstioRevisionTagTargetReference) DeepCopyInto(out *IstioRevisionTagTargetReference) {
 	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioRevisionTagTargetReference.
@@ -1350,11 +1575,26 @@ func (in *IstioSpec) DeepCopyInto(out *IstioSpec) {
 		*out = new(IstioUpdateStrategy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.CreateNamespace != nil {
+		in, out := &in.CreateNamespace, &out.CreateNamespace
+		*out = new(NamespaceCreation)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Values != nil {
 		in, out := &in.Values, &out.Values
 		*out = new(Values)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = new(IstioComponents)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MemberSelector != nil {
+		in, out := &in.MemberSelector, &out.MemberSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioSpec.
@@ -1378,6 +1618,23 @@ func (in *IstioStatus) DeepCopyInto(out *IstioStatus) {
 		}
 	}
 	out.Revisions = in.Revisions
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]IstioComponentStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.DiscoveredNamespaces != nil {
+		in, out := &in.DiscoveredNamespaces, &out.DiscoveredNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceConflicts != nil {
+		in, out := &in.NamespaceConflicts, &out.NamespaceConflicts
+		*out = make([]IstioNamespaceConflict, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioStatus.
@@ -3260,6 +3517,35 @@ func (in *MultiClusterConfig) DeepCopy() *MultiClusterConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceCreation) DeepCopyInto(out *NamespaceCreation) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceCreation.
+func (in *NamespaceCreation) DeepCopy() *NamespaceCreation {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceCreation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Network) DeepCopyInto(out *Network) {
 	*out = *in
@@ -4209,6 +4495,28 @@ func (in *ProxyInitConfig) DeepCopy() *ProxyInitConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteIstiodStatus) DeepCopyInto(out *RemoteIstiodStatus) {
+	*out = *in
+	if in.WebhookConfigurations != nil {
+		in, out := &in.WebhookConfigurations, &out.WebhookConfigurations
+		*out = make([]WebhookConfigurationProbeStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteIstiodStatus.
+func (in *RemoteIstiodStatus) DeepCopy() *RemoteIstiodStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteIstiodStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RemoteService) DeepCopyInto(out *RemoteService) {
 	*out = *in
@@ -5173,6 +5481,21 @@ func (in *Values) DeepCopy() *Values {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VersionSource) DeepCopyInto(out *VersionSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VersionSource.
+func (in *VersionSource) DeepCopy() *VersionSource {
+	if in == nil {
+		return nil
+	}
+	out := new(VersionSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WaypointConfig) DeepCopyInto(out *WaypointConfig) {
 	*out = *in
@@ -5225,6 +5548,44 @@ func (in *WaypointConfig) DeepCopy() *WaypointConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookConfigurationProbeStatus) DeepCopyInto(out *WebhookConfigurationProbeStatus) {
+	*out = *in
+	if in.Webhooks != nil {
+		in, out := &in.Webhooks, &out.Webhooks
+		*out = make([]WebhookProbeResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookConfigurationProbeStatus.
+func (in *WebhookConfigurationProbeStatus) DeepCopy() *WebhookConfigurationProbeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookConfigurationProbeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookProbeResult) DeepCopyInto(out *WebhookProbeResult) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookProbeResult.
+func (in *WebhookProbeResult) DeepCopy() *WebhookProbeResult {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookProbeResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkloadSelector) DeepCopyInto(out *WorkloadSelector) {
 	*out = *in
@@ -5247,6 +5608,74 @@ func (in *WorkloadSelector) DeepCopy() *WorkloadSelector {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZTunnel) DeepCopyInto(out *ZTunnel) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZTunnel.
+func (in *ZTunnel) DeepCopy() *ZTunnel {
+	if in == nil {
+		return nil
+	}
+	out := new(ZTunnel)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ZTunnel) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZTunnelComponent) DeepCopyInto(out *ZTunnelComponent) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = new(ZTunnelValues)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CreateNamespace != nil {
+		in, out := &in.CreateNamespace, &out.CreateNamespace
+		*out = new(NamespaceCreation)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZTunnelComponent.
+func (in *ZTunnelComponent) DeepCopy() *ZTunnelComponent {
+	if in == nil {
+		return nil
+	}
+	out := new(ZTunnelComponent)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZTunnelCondition) DeepCopyInto(out *ZTunnelCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZTunnelCondition.
+func (in *ZTunnelCondition) DeepCopy() *ZTunnelCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(ZTunnelCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ZTunnelConfig) DeepCopyInto(out *ZTunnelConfig) {
 	*out = *in
@@ -5270,15 +5699,20 @@ func (in *ZTunnelConfig) DeepCopyInto(out *ZTunnelConfig) {
 		*out = new(string)
 		**out = **in
 	}
-	if in.Annotations != nil {
-		in, out := &in.Annotations, &out.Annotations
+	if in.ResourceName != nil {
+		in, out := &in.ResourceName, &out.ResourceName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
 		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
 		}
 	}
-	if in.Labels != nil {
-		in, out := &in.Labels, &out.Labels
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
 		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
@@ -5344,6 +5778,11 @@ func (in *ZTunnelConfig) DeepCopyInto(out *ZTunnelConfig) {
 		*out = new(MeshConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.TerminationGracePeriodSeconds != nil {
+		in, out := &in.TerminationGracePeriodSeconds, &out.TerminationGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
 	if in.Revision != nil {
 		in, out := &in.Revision, &out.Revision
 		*out = new(string)
@@ -5364,16 +5803,21 @@ func (in *ZTunnelConfig) DeepCopyInto(out *ZTunnelConfig) {
 		*out = new(string)
 		**out = **in
 	}
-	if in.Logging != nil {
-		in, out := &in.Logging, &out.Logging
-		*out = new(GlobalLoggingConfig)
-		(*in).DeepCopyInto(*out)
+	if in.LogLevel != nil {
+		in, out := &in.LogLevel, &out.LogLevel
+		*out = new(string)
+		**out = **in
 	}
-	if in.LogAsJSON != nil {
-		in, out := &in.LogAsJSON, &out.LogAsJSON
+	if in.LogAsJson != nil {
+		in, out := &in.LogAsJson, &out.LogAsJson
 		*out = new(bool)
 		**out = **in
 	}
+	if in.SeLinuxOptions != nil {
+		in, out := &in.SeLinuxOptions, &out.SeLinuxOptions
+		*out = new(corev1.SELinuxOptions)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZTunnelConfig.
@@ -5386,6 +5830,26 @@ func (in *ZTunnelConfig) DeepCopy() *ZTunnelConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZTunnelDaemonSetStatus) DeepCopyInto(out *ZTunnelDaemonSetStatus) {
+	*out = *in
+	if in.Versions != nil {
+		in, out := &in.Versions, &out.Versions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZTunnelDaemonSetStatus.
+func (in *ZTunnelDaemonSetStatus) DeepCopy() *ZTunnelDaemonSetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ZTunnelDaemonSetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ZTunnelGlobalConfig) DeepCopyInto(out *ZTunnelGlobalConfig) {
 	*out = *in
@@ -5409,8 +5873,8 @@ func (in *ZTunnelGlobalConfig) DeepCopyInto(out *ZTunnelGlobalConfig) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.LogAsJSON != nil {
-		in, out := &in.LogAsJSON, &out.LogAsJSON
+	if in.LogAsJson != nil {
+		in, out := &in.LogAsJson, &out.LogAsJson
 		*out = new(bool)
 		**out = **in
 	}
@@ -5446,6 +5910,115 @@ func (in *ZTunnelGlobalConfig) DeepCopy() *ZTunnelGlobalConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZTunnelList) DeepCopyInto(out *ZTunnelList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ZTunnel, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZTunnelList.
+func (in *ZTunnelList) DeepCopy() *ZTunnelList {
+	if in == nil {
+		return nil
+	}
+	out := new(ZTunnelList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ZTunnelList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZTunnelSpec) DeepCopyInto(out *ZTunnelSpec) {
+	*out = *in
+	if in.CreateNamespace != nil {
+		in, out := &in.CreateNamespace, &out.CreateNamespace
+		*out = new(NamespaceCreation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TargetRef != nil {
+		in, out := &in.TargetRef, &out.TargetRef
+		*out = new(ZTunnelTargetReference)
+		**out = **in
+	}
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = new(ZTunnelValues)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VersionFrom != nil {
+		in, out := &in.VersionFrom, &out.VersionFrom
+		*out = new(VersionSource)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZTunnelSpec.
+func (in *ZTunnelSpec) DeepCopy() *ZTunnelSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ZTunnelSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZTunnelStatus) DeepCopyInto(out *ZTunnelStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ZTunnelCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DaemonSet != nil {
+		in, out := &in.DaemonSet, &out.DaemonSet
+		*out = new(ZTunnelDaemonSetStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZTunnelStatus.
+func (in *ZTunnelStatus) DeepCopy() *ZTunnelStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ZTunnelStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZTunnelTargetReference) DeepCopyInto(out *ZTunnelTargetReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZTunnelTargetReference.
+func (in *ZTunnelTargetReference) DeepCopy() *ZTunnelTargetReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ZTunnelTargetReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ZTunnelValues) DeepCopyInto(out *ZTunnelValues) {
 	*out = *in
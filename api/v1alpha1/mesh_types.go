@@ -0,0 +1,287 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	MeshKind = "Mesh"
+)
+
+// MeshSpec defines the desired state of Mesh. The Mesh resource is a read-only summary,
+// so the spec contains no fields.
+type MeshSpec struct{}
+
+// MeshStatus defines the observed state of Mesh
+type MeshStatus struct {
+	// ObservedGeneration is the most recent generation observed for this
+	// Mesh object. It corresponds to the object's generation, which is
+	// updated on mutation by the API Server. The information in the status
+	// pertains to this particular generation of the object.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Represents the latest available observations of the object's current state.
+	Conditions []MeshCondition `json:"conditions,omitempty"`
+
+	// Reports the current state of the object.
+	State MeshConditionReason `json:"state,omitempty"`
+
+	// The Istio versions used by the control plane and data plane components in the cluster.
+	Versions []string `json:"versions,omitempty"`
+
+	// Reports the IstioRevisions in the cluster.
+	Revisions []MeshRevisionStatus `json:"revisions,omitempty"`
+
+	// Reports the IstioRevisionTags in the cluster and the revisions they point to.
+	Tags []MeshTagStatus `json:"tags,omitempty"`
+
+	// Reports the IstioCNI in the cluster, if any.
+	CNI *MeshDaemonSetComponentStatus `json:"cni,omitempty"`
+
+	// Reports the ZTunnel in the cluster, if any.
+	ZTunnel *MeshDaemonSetComponentStatus `json:"ztunnel,omitempty"`
+
+	// Lists the data plane components whose version is not supported by an IstioRevision in the cluster.
+	VersionSkew []MeshVersionSkew `json:"versionSkew,omitempty"`
+}
+
+// MeshRevisionStatus summarizes the status of an IstioRevision.
+type MeshRevisionStatus struct {
+	// The name of the IstioRevision.
+	Name string `json:"name"`
+
+	// The name of the Istio resource that owns the revision, if any.
+	Istio string `json:"istio,omitempty"`
+
+	// The Istio version of the revision.
+	Version string `json:"version,omitempty"`
+
+	// The namespace of the revision's control plane.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Whether the revision is ready.
+	Ready metav1.ConditionStatus `json:"ready,omitempty"`
+
+	// Whether the revision is in use.
+	InUse metav1.ConditionStatus `json:"inUse,omitempty"`
+
+	// The state of the revision.
+	State string `json:"state,omitempty"`
+}
+
+// MeshTagStatus summarizes the status of an IstioRevisionTag.
+type MeshTagStatus struct {
+	// The name of the IstioRevisionTag.
+	Name string `json:"name"`
+
+	// The kind of the resource referenced by the tag's targetRef.
+	TargetKind string `json:"targetKind,omitempty"`
+
+	// The name of the resource referenced by the tag's targetRef.
+	TargetName string `json:"targetName,omitempty"`
+
+	// The name of the IstioRevision the tag currently points to.
+	Revision string `json:"revision,omitempty"`
+
+	// Whether the tag is in use.
+	InUse metav1.ConditionStatus `json:"inUse,omitempty"`
+
+	// The state of the tag.
+	State string `json:"state,omitempty"`
+}
+
+// MeshDaemonSetComponentStatus summarizes the status of a data plane component that is deployed as a DaemonSet.
+type MeshDaemonSetComponentStatus struct {
+	// The name of the resource that deploys the component.
+	Name string `json:"name"`
+
+	// The Istio version of the component.
+	Version string `json:"version,omitempty"`
+
+	// The namespace the component is deployed in.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Whether the component is ready.
+	Ready metav1.ConditionStatus `json:"ready,omitempty"`
+
+	// The state of the component.
+	State string `json:"state,omitempty"`
+
+	// The number of nodes that should be running the component's pod.
+	DesiredNumberScheduled int32 `json:"desiredNumberScheduled"`
+
+	// The number of nodes that are running the updated component's pod.
+	UpdatedNumberScheduled int32 `json:"updatedNumberScheduled"`
+
+	// The number of nodes that are running a ready component's pod.
+	NumberReady int32 `json:"numberReady"`
+
+	// The number of nodes that are running an available component's pod.
+	NumberAvailable int32 `json:"numberAvailable"`
+}
+
+// MeshVersionSkew describes an unsupported version skew between a data plane component and an IstioRevision.
+type MeshVersionSkew struct {
+	// The kind of the data plane component (IstioCNI or ZTunnel).
+	Component string `json:"component"`
+
+	// The Istio version of the data plane component.
+	Version string `json:"version"`
+
+	// The name of the IstioRevision.
+	Revision string `json:"revision"`
+
+	// The Istio version of the IstioRevision.
+	RevisionVersion string `json:"revisionVersion"`
+}
+
+// GetCondition returns the condition of the specified type
+func (s *MeshStatus) GetCondition(conditionType MeshConditionType) MeshCondition {
+	if s != nil {
+		for i := range s.Conditions {
+			if s.Conditions[i].Type == conditionType {
+				return s.Conditions[i]
+			}
+		}
+	}
+	return MeshCondition{Type: conditionType, Status: metav1.ConditionUnknown}
+}
+
+// SetCondition sets a specific condition in the list of conditions
+func (s *MeshStatus) SetCondition(condition MeshCondition) {
+	var now time.Time
+	if testTime == nil {
+		now = time.Now()
+	} else {
+		now = *testTime
+	}
+
+	// The lastTransitionTime only gets serialized out to the second.  This can
+	// break update skipping, as the time in the resource returned from the client
+	// may not match the time in our cached status during a reconcile.  We truncate
+	// here to save any problems down the line.
+	lastTransitionTime := metav1.NewTime(now.Truncate(time.Second))
+
+	for i, prevCondition := range s.Conditions {
+		if prevCondition.Type == condition.Type {
+			if prevCondition.Status != condition.Status {
+				condition.LastTransitionTime = lastTransitionTime
+			} else {
+				condition.LastTransitionTime = prevCondition.LastTransitionTime
+			}
+			s.Conditions[i] = condition
+			return
+		}
+	}
+
+	// If the condition does not exist, initialize the lastTransitionTime
+	condition.LastTransitionTime = lastTransitionTime
+	s.Conditions = append(s.Conditions, condition)
+}
+
+// MeshCondition represents a specific observation of the Mesh object's state.
+type MeshCondition struct {
+	// The type of this condition.
+	Type MeshConditionType `json:"type,omitempty"`
+
+	// The status of this condition. Can be True, False or Unknown.
+	Status metav1.ConditionStatus `json:"status,omitempty"`
+
+	// Unique, single-word, CamelCase reason for the condition's last transition.
+	Reason MeshConditionReason `json:"reason,omitempty"`
+
+	// Human-readable message indicating details about the last transition.
+	Message string `json:"message,omitempty"`
+
+	// Last time the condition transitioned from one status to another.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// MeshConditionType represents the type of the condition.  Condition stages are:
+// Reconciled, Ready, SupportedVersionSkew
+type MeshConditionType string
+
+// MeshConditionReason represents a short message indicating how the condition came
+// to be in its present state.
+type MeshConditionReason string
+
+const (
+	// MeshConditionReconciled signifies whether the controller has
+	// successfully collected the status of all components.
+	MeshConditionReconciled MeshConditionType = "Reconciled"
+
+	// MeshReasonReconcileError indicates that the reconciliation of the resource has failed, but will be retried.
+	MeshReasonReconcileError MeshConditionReason = "ReconcileError"
+)
+
+const (
+	// MeshConditionReady signifies whether all IstioRevisions, the IstioCNI and the ZTunnel are ready.
+	MeshConditionReady MeshConditionType = "Ready"
+
+	// MeshReasonComponentsNotReady indicates that one or more components are not ready.
+	MeshReasonComponentsNotReady MeshConditionReason = "ComponentsNotReady"
+)
+
+const (
+	// MeshConditionSupportedVersionSkew signifies whether the versions of the data plane components
+	// are supported by all IstioRevisions in the cluster.
+	MeshConditionSupportedVersionSkew MeshConditionType = "SupportedVersionSkew"
+
+	// MeshReasonUnsupportedVersionSkew indicates that the version of a data plane component is too far apart
+	// from the version of an IstioRevision.
+	MeshReasonUnsupportedVersionSkew MeshConditionReason = "UnsupportedVersionSkew"
+)
+
+const (
+	// MeshReasonHealthy indicates that all components are ready and that their versions are compatible.
+	MeshReasonHealthy MeshConditionReason = "Healthy"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,categories=istio-io
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="Whether all components of the mesh are ready."
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.state",description="The current state of this object."
+// +kubebuilder:printcolumn:name="Versions",type="string",JSONPath=".status.versions",description="The Istio versions in use."
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="The age of the object"
+// +kubebuilder:validation:XValidation:rule="self.metadata.name == 'default'",message="metadata.name must be 'default'"
+
+// Mesh is a read-only summary of the state of all Istio, IstioRevision, IstioRevisionTag,
+// IstioCNI and ZTunnel resources in the cluster.
+type Mesh struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec MeshSpec `json:"spec,omitempty"`
+
+	Status MeshStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// MeshList contains a list of Mesh
+type MeshList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Mesh `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Mesh{}, &MeshList{})
+}
@@ -0,0 +1,232 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	MeshMemberKind = "MeshMember"
+
+	// MeshMemberName is the only name allowed for MeshMember objects. A namespace can only be a member of one mesh.
+	MeshMemberName = "default"
+)
+
+// DataplaneMode specifies how the workloads in a member namespace are added to the mesh.
+// +kubebuilder:validation:Enum=Sidecar;Ambient
+type DataplaneMode string
+
+const (
+	// DataplaneModeSidecar adds the workloads to the mesh by injecting a sidecar proxy into their pods.
+	DataplaneModeSidecar DataplaneMode = "Sidecar"
+
+	// DataplaneModeAmbient adds the workloads to the ambient mesh.
+	DataplaneModeAmbient DataplaneMode = "Ambient"
+)
+
+// MeshMemberSpec defines the mesh that the namespace of the MeshMember should join.
+type MeshMemberSpec struct {
+	// The Istio control plane or IstioRevisionTag that the namespace's workloads should use.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,order=1,displayName="Target"
+	// +kubebuilder:default={kind: "Istio", name: "default"}
+	Target MeshMemberTargetReference `json:"target"`
+
+	// Defines how the namespace's workloads are added to the mesh. In Sidecar mode, the operator sets the
+	// istio.io/rev label on the namespace, so that a sidecar is injected into new pods. In Ambient mode, the operator
+	// also sets the istio.io/dataplane-mode=ambient label, so that the workloads are captured by ztunnel, while
+	// waypoints in the namespace still use the referenced revision.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,order=2,displayName="Data Plane Mode"
+	// +kubebuilder:default=Sidecar
+	DataplaneMode DataplaneMode `json:"dataplaneMode,omitempty"`
+}
+
+// MeshMemberTargetReference references the control plane that a member namespace should use.
+type MeshMemberTargetReference struct {
+	// Kind is the kind of the target resource.
+	// +kubebuilder:validation:Enum=Istio;IstioRevisionTag
+	// +kubebuilder:default=Istio
+	Kind string `json:"kind"`
+
+	// Name is the name of the target resource.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// MeshMemberStatus defines the observed state of MeshMember
+type MeshMemberStatus struct {
+	// ObservedGeneration is the most recent generation observed for this
+	// MeshMember object. It corresponds to the object's generation, which is
+	// updated on mutation by the API Server. The information in the status
+	// pertains to this particular generation of the object.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Represents the latest available observations of the object's current state.
+	Conditions []MeshMemberCondition `json:"conditions,omitempty"`
+
+	// Reports the current state of the object.
+	State MeshMemberConditionReason `json:"state,omitempty"`
+
+	// The IstioRevision that the namespace's workloads are currently assigned to.
+	IstioRevision string `json:"istioRevision,omitempty"`
+}
+
+// GetCondition returns the condition of the specified type
+func (s *MeshMemberStatus) GetCondition(conditionType MeshMemberConditionType) MeshMemberCondition {
+	if s != nil {
+		for i := range s.Conditions {
+			if s.Conditions[i].Type == conditionType {
+				return s.Conditions[i]
+			}
+		}
+	}
+	return MeshMemberCondition{Type: conditionType, Status: metav1.ConditionUnknown}
+}
+
+// SetCondition sets a specific condition in the list of conditions
+func (s *MeshMemberStatus) SetCondition(condition MeshMemberCondition) {
+	var now time.Time
+	if testTime == nil {
+		now = time.Now()
+	} else {
+		now = *testTime
+	}
+
+	// The lastTransitionTime only gets serialized out to the second.  This can
+	// break update skipping, as the time in the resource returned from the client
+	// may not match the time in our cached status during a reconcile.  We truncate
+	// here to save any problems down the line.
+	lastTransitionTime := metav1.NewTime(now.Truncate(time.Second))
+
+	for i, prevCondition := range s.Conditions {
+		if prevCondition.Type == condition.Type {
+			if prevCondition.Status != condition.Status {
+				condition.LastTransitionTime = lastTransitionTime
+			} else {
+				condition.LastTransitionTime = prevCondition.LastTransitionTime
+			}
+			s.Conditions[i] = condition
+			return
+		}
+	}
+
+	// If the condition does not exist, initialize the lastTransitionTime
+	condition.LastTransitionTime = lastTransitionTime
+	s.Conditions = append(s.Conditions, condition)
+}
+
+// MeshMemberCondition represents a specific observation of the MeshMember object's state.
+type MeshMemberCondition struct {
+	// The type of this condition.
+	Type MeshMemberConditionType `json:"type,omitempty"`
+
+	// The status of this condition. Can be True, False or Unknown.
+	Status metav1.ConditionStatus `json:"status,omitempty"`
+
+	// Unique, single-word, CamelCase reason for the condition's last transition.
+	Reason MeshMemberConditionReason `json:"reason,omitempty"`
+
+	// Human-readable message indicating details about the last transition.
+	Message string `json:"message,omitempty"`
+
+	// Last time the condition transitioned from one status to another.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// MeshMemberConditionType represents the type of the condition.  Condition stages are:
+// Reconciled, Ready
+type MeshMemberConditionType string
+
+// MeshMemberConditionReason represents a short message indicating how the condition came
+// to be in its present state.
+type MeshMemberConditionReason string
+
+const (
+	// MeshMemberConditionReconciled signifies whether the controller has
+	// successfully applied the mesh membership labels to the namespace.
+	MeshMemberConditionReconciled MeshMemberConditionType = "Reconciled"
+
+	// MeshMemberReasonReconcileError indicates that the reconciliation of the resource has failed, but will be retried.
+	MeshMemberReasonReconcileError MeshMemberConditionReason = "ReconcileError"
+
+	// MeshMemberReasonTargetNotFound indicates that the Istio or IstioRevisionTag referenced by the MeshMember doesn't exist
+	// or doesn't reference an IstioRevision yet.
+	MeshMemberReasonTargetNotFound MeshMemberConditionReason = "TargetNotFound"
+
+	// MeshMemberReasonNamespaceIgnored indicates that the namespace is labeled with sailoperator.io/ignore-namespace
+	// and can't be added to the mesh.
+	MeshMemberReasonNamespaceIgnored MeshMemberConditionReason = "NamespaceIgnored"
+
+	// MeshMemberReasonNamespaceNotAllowed indicates that the namespace doesn't match the memberSelector of the
+	// Istio or IstioRevisionTag referenced by the MeshMember.
+	MeshMemberReasonNamespaceNotAllowed MeshMemberConditionReason = "NamespaceNotAllowed"
+)
+
+const (
+	// MeshMemberConditionReady signifies whether the control plane that the namespace's workloads use is ready.
+	MeshMemberConditionReady MeshMemberConditionType = "Ready"
+
+	// MeshMemberReasonIstioRevisionNotReady indicates that the IstioRevision that the namespace is assigned to isn't ready.
+	MeshMemberReasonIstioRevisionNotReady MeshMemberConditionReason = "IstioRevisionNotReady"
+
+	// MeshMemberReasonReadinessCheckFailed indicates that readiness could not be ascertained.
+	MeshMemberReasonReadinessCheckFailed MeshMemberConditionReason = "ReadinessCheckFailed"
+)
+
+const (
+	// MeshMemberReasonHealthy indicates that the namespace is a member of the mesh and the control plane is ready.
+	MeshMemberReasonHealthy MeshMemberConditionReason = "Healthy"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,categories=istio-io
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Target Kind",type="string",JSONPath=".spec.target.kind",description="The kind of the target resource."
+// +kubebuilder:printcolumn:name="Target Name",type="string",JSONPath=".spec.target.name",description="The name of the target resource."
+// +kubebuilder:printcolumn:name="Mode",type="string",JSONPath=".spec.dataplaneMode",description="The data plane mode."
+// +kubebuilder:printcolumn:name="Revision",type="string",JSONPath=".status.istioRevision",description="The IstioRevision the namespace is assigned to."
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="Whether the control plane is ready."
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.state",description="The current state of this object."
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="The age of the object"
+// +kubebuilder:validation:XValidation:rule="self.metadata.name == 'default'",message="metadata.name must be 'default'"
+
+// MeshMember requests that the namespace it is created in is added to a mesh. The operator sets the labels
+// that assign the namespace's workloads to the referenced control plane and removes them when the MeshMember
+// is deleted. Because MeshMember is a namespaced resource, namespace administrators can add their namespace to a
+// mesh without permission to modify the Namespace object itself.
+type MeshMember struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +kubebuilder:default={target: {kind: "Istio", name: "default"}, dataplaneMode: "Sidecar"}
+	Spec MeshMemberSpec `json:"spec,omitempty"`
+
+	Status MeshMemberStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// MeshMemberList contains a list of MeshMember
+type MeshMemberList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MeshMember `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MeshMember{}, &MeshMemberList{})
+}
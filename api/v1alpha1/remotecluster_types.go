@@ -0,0 +1,257 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	RemoteClusterKind = "RemoteCluster"
+)
+
+// RemoteClusterSpec defines the desired state of RemoteCluster
+type RemoteClusterSpec struct {
+	// The name of the remote cluster. It must match the value of `values.global.multiCluster.clusterName`
+	// configured for the control plane or workloads in the remote cluster. Defaults to the name of the
+	// RemoteCluster object.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,order=1,displayName="Cluster Name"
+	// +kubebuilder:validation:MaxLength=253
+	ClusterName string `json:"clusterName,omitempty"`
+
+	// The name of the Istio resource whose control plane should discover the services and
+	// endpoints in the remote cluster. The operator creates the remote secret in the namespace
+	// of this control plane.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,order=2,displayName="Istio"
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:default=default
+	Istio string `json:"istio"`
+
+	// Reference to the Secret containing the kubeconfig that the operator uses to connect to the
+	// remote cluster. The kubeconfig must be self-contained: certificates and tokens must be embedded
+	// instead of referenced as files, and exec and auth-provider credential plugins aren't supported.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,order=3,displayName="Kubeconfig"
+	Kubeconfig KubeconfigSecretReference `json:"kubeconfig"`
+
+	// The ServiceAccount in the remote cluster whose credentials the control plane uses to access the
+	// remote cluster. When set, the operator uses the kubeconfig only to request a token for this
+	// ServiceAccount and writes a kubeconfig containing this token into the remote secret. The token is
+	// rotated before it expires. When not set, the current context of the kubeconfig is copied into the
+	// remote secret.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,order=4,displayName="Service Account"
+	ServiceAccount *RemoteServiceAccount `json:"serviceAccount,omitempty"`
+}
+
+// KubeconfigSecretReference references the key of a Secret that contains a kubeconfig.
+type KubeconfigSecretReference struct {
+	// Name of the Secret.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace of the Secret.
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+
+	// The key in the Secret's data that contains the kubeconfig.
+	// +kubebuilder:default=kubeconfig
+	Key string `json:"key,omitempty"`
+}
+
+// RemoteServiceAccount identifies a ServiceAccount in the remote cluster.
+type RemoteServiceAccount struct {
+	// Name of the ServiceAccount.
+	// +kubebuilder:default=istio-reader-service-account
+	Name string `json:"name,omitempty"`
+
+	// Namespace of the ServiceAccount.
+	// +kubebuilder:default=istio-system
+	Namespace string `json:"namespace,omitempty"`
+
+	// The requested lifetime of the ServiceAccount token. The operator requests a new
+	// token when 80% of this duration has elapsed.
+	// +kubebuilder:validation:Minimum=600
+	// +kubebuilder:default=86400
+	TokenExpirationSeconds *int64 `json:"tokenExpirationSeconds,omitempty"`
+}
+
+// RemoteClusterStatus defines the observed state of RemoteCluster
+type RemoteClusterStatus struct {
+	// ObservedGeneration is the most recent generation observed for this
+	// RemoteCluster object. It corresponds to the object's generation, which is
+	// updated on mutation by the API Server. The information in the status
+	// pertains to this particular generation of the object.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Represents the latest available observations of the object's current state.
+	Conditions []RemoteClusterCondition `json:"conditions,omitempty"`
+
+	// Reports the current state of the object.
+	State RemoteClusterConditionReason `json:"state,omitempty"`
+
+	// The name of the remote secret created by the operator.
+	SecretName string `json:"secretName,omitempty"`
+
+	// The namespace of the remote secret created by the operator.
+	SecretNamespace string `json:"secretNamespace,omitempty"`
+
+	// The Kubernetes version reported by the remote cluster's API server.
+	ServerVersion string `json:"serverVersion,omitempty"`
+
+	// The time at which the ServiceAccount token in the remote secret expires.
+	TokenExpirationTime *metav1.Time `json:"tokenExpirationTime,omitempty"`
+
+	// The time at which the operator last checked whether the remote cluster is reachable.
+	LastProbeTime *metav1.Time `json:"lastProbeTime,omitempty"`
+
+	// The resourceVersion of the remote secret whose credentials were used in the last reachability check. The
+	// check is repeated when the remote secret changes, instead of waiting for the next periodic check.
+	ProbedSecretResourceVersion string `json:"probedSecretResourceVersion,omitempty"`
+}
+
+// GetCondition returns the condition of the specified type
+func (s *RemoteClusterStatus) GetCondition(conditionType RemoteClusterConditionType) RemoteClusterCondition {
+	if s != nil {
+		for i := range s.Conditions {
+			if s.Conditions[i].Type == conditionType {
+				return s.Conditions[i]
+			}
+		}
+	}
+	return RemoteClusterCondition{Type: conditionType, Status: metav1.ConditionUnknown}
+}
+
+// SetCondition sets a specific condition in the list of conditions
+func (s *RemoteClusterStatus) SetCondition(condition RemoteClusterCondition) {
+	var now time.Time
+	if testTime == nil {
+		now = time.Now()
+	} else {
+		now = *testTime
+	}
+
+	// The lastTransitionTime only gets serialized out to the second.  This can
+	// break update skipping, as the time in the resource returned from the client
+	// may not match the time in our cached status during a reconcile.  We truncate
+	// here to save any problems down the line.
+	lastTransitionTime := metav1.NewTime(now.Truncate(time.Second))
+
+	for i, prevCondition := range s.Conditions {
+		if prevCondition.Type == condition.Type {
+			if prevCondition.Status != condition.Status {
+				condition.LastTransitionTime = lastTransitionTime
+			} else {
+				condition.LastTransitionTime = prevCondition.LastTransitionTime
+			}
+			s.Conditions[i] = condition
+			return
+		}
+	}
+
+	// If the condition does not exist, initialize the lastTransitionTime
+	condition.LastTransitionTime = lastTransitionTime
+	s.Conditions = append(s.Conditions, condition)
+}
+
+// RemoteClusterCondition represents a specific observation of the RemoteCluster object's state.
+type RemoteClusterCondition struct {
+	// The type of this condition.
+	Type RemoteClusterConditionType `json:"type,omitempty"`
+
+	// The status of this condition. Can be True, False or Unknown.
+	Status metav1.ConditionStatus `json:"status,omitempty"`
+
+	// Unique, single-word, CamelCase reason for the condition's last transition.
+	Reason RemoteClusterConditionReason `json:"reason,omitempty"`
+
+	// Human-readable message indicating details about the last transition.
+	Message string `json:"message,omitempty"`
+
+	// Last time the condition transitioned from one status to another.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// RemoteClusterConditionType represents the type of the condition.  Condition stages are:
+// Reconciled, Ready
+type RemoteClusterConditionType string
+
+// RemoteClusterConditionReason represents a short message indicating how the condition came
+// to be in its present state.
+type RemoteClusterConditionReason string
+
+const (
+	// RemoteClusterConditionReconciled signifies whether the controller has
+	// successfully reconciled the remote secret.
+	RemoteClusterConditionReconciled RemoteClusterConditionType = "Reconciled"
+
+	// RemoteClusterReasonReconcileError indicates that the reconciliation of the resource has failed, but will be retried.
+	RemoteClusterReasonReconcileError RemoteClusterConditionReason = "ReconcileError"
+)
+
+const (
+	// RemoteClusterConditionReady signifies whether the remote cluster's API server is reachable
+	// using the credentials in the remote secret.
+	RemoteClusterConditionReady RemoteClusterConditionType = "Ready"
+
+	// RemoteClusterReasonSecretNotFound indicates that the remote secret hasn't been created yet.
+	RemoteClusterReasonSecretNotFound RemoteClusterConditionReason = "SecretNotFound"
+
+	// RemoteClusterReasonClusterUnreachable indicates that the remote cluster's API server
+	// could not be reached using the credentials in the remote secret.
+	RemoteClusterReasonClusterUnreachable RemoteClusterConditionReason = "ClusterUnreachable"
+
+	// RemoteClusterReasonReadinessCheckFailed indicates that the connectivity to the remote cluster could not be ascertained.
+	RemoteClusterReasonReadinessCheckFailed RemoteClusterConditionReason = "ReadinessCheckFailed"
+)
+
+const (
+	// RemoteClusterReasonHealthy indicates that the remote secret is up to date and that the remote cluster is reachable.
+	RemoteClusterReasonHealthy RemoteClusterConditionReason = "Healthy"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,categories=istio-io
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".spec.clusterName",description="The name of the remote cluster."
+// +kubebuilder:printcolumn:name="Istio",type="string",JSONPath=".spec.istio",description="The Istio control plane that discovers the remote cluster."
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="Whether the remote cluster is reachable."
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.state",description="The current state of this object."
+// +kubebuilder:printcolumn:name="Server Version",type="string",JSONPath=".status.serverVersion",description="The Kubernetes version of the remote cluster."
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="The age of the object"
+
+// RemoteCluster represents a remote cluster whose services and endpoints are discovered by an Istio control plane.
+// The operator creates the remote secret that the control plane uses to access the remote cluster.
+type RemoteCluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec RemoteClusterSpec `json:"spec,omitempty"`
+
+	Status RemoteClusterStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RemoteClusterList contains a list of RemoteCluster
+type RemoteClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RemoteCluster `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RemoteCluster{}, &RemoteClusterList{})
+}
@@ -0,0 +1,287 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	SailOperatorConfigKind = "SailOperatorConfig"
+
+	// SailOperatorConfigName is the only name allowed for SailOperatorConfig objects, since the operator has a single configuration.
+	SailOperatorConfigName = "default"
+)
+
+// LogLevel is the minimum severity of the messages that the operator logs.
+// +kubebuilder:validation:Enum=debug;info;error
+type LogLevel string
+
+const (
+	LogLevelDebug LogLevel = "debug"
+	LogLevelInfo  LogLevel = "info"
+	LogLevelError LogLevel = "error"
+)
+
+// SailOperatorConfigSpec defines the configuration of the operator. Fields that aren't set keep the values the
+// operator was started with, which come from its properties file and command-line flags.
+type SailOperatorConfigSpec struct {
+	// The images to deploy for each Istio version, keyed by the version (e.g. v1.24.2). The images are used for
+	// components whose values specify neither the hub, the tag nor the image. A version listed here replaces the
+	// images the operator's properties file defines for that version.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,order=1,displayName="Image Digests"
+	ImageDigests map[string]ImageDigests `json:"imageDigests,omitempty"`
+
+	// The profile that is applied before the profile selected in a resource. If not set, the operator uses the
+	// openshift profile on OpenShift and the default profile on other platforms.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,order=2,displayName="Default Profile"
+	DefaultProfile string `json:"defaultProfile,omitempty"`
+
+	// Overrides the platform that the operator detected at startup or that was set with the --platform flag.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,order=3,displayName="Platform"
+	// +kubebuilder:validation:Enum=kubernetes;openshift;gke;eks;k3d;k3s;minikube;microk8s;kind
+	Platform string `json:"platform,omitempty"`
+
+	// Configures the operator's logging.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,order=4,displayName="Logging"
+	Logging *OperatorLogging `json:"logging,omitempty"`
+
+	// Defaults for the readiness probe of the sidecar proxies, used by every Istio whose values don't set them.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,order=5,displayName="Probe Defaults"
+	ProbeDefaults *ProbeDefaults `json:"probeDefaults,omitempty"`
+
+	// The namespaces into which the operator installs control planes and data plane components. IstioRevisions,
+	// IstioCNIs and ZTunnels targeting other namespaces aren't reconciled. If empty, all namespaces are allowed.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,order=6,displayName="Watched Namespaces"
+	WatchedNamespaces []string `json:"watchedNamespaces,omitempty"`
+
+	// Enables or disables optional features of the operator. Supported feature gates: HelmReleaseAdoption
+	// (enabled by default).
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,order=7,displayName="Feature Gates"
+	// +kubebuilder:validation:XValidation:rule="self.all(k, k in ['HelmReleaseAdoption'])",message="unknown feature gate"
+	FeatureGates map[string]bool `json:"featureGates,omitempty"`
+
+	// Rules that rewrite the registry of the images deployed by the operator, e.g. to pull them from a mirror in
+	// an air-gapped cluster. The rules apply to all images in the rendered manifests, including the images
+	// defined by image digests, and to the images that istiod injects into sidecars and gateways. The first
+	// rule whose source matches an image is applied.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,order=8,displayName="Registry Rewrites"
+	// +listType=atomic
+	RegistryRewrites []RegistryRewrite `json:"registryRewrites,omitempty"`
+
+	// Verifies the images in the rendered charts before the operator installs or upgrades them. Each image is
+	// resolved to its digest, which must either be listed in allowedDigests or have a cosign signature that can be
+	// verified with publicKey. If an image fails verification, the chart isn't installed and the resource's
+	// Reconciled condition names the image.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,order=9,displayName="Image Verification"
+	ImageVerification *ImageVerification `json:"imageVerification,omitempty"`
+}
+
+// ImageVerification defines the images that the operator may deploy.
+// +kubebuilder:validation:XValidation:rule="has(self.allowedDigests) || has(self.publicKey)",message="either allowedDigests or publicKey must be set"
+type ImageVerification struct {
+	// The digests of the images that may be deployed, e.g. sha256:0123...
+	// +kubebuilder:validation:items:Pattern=`^sha256:[a-f0-9]{64}$`
+	AllowedDigests []string `json:"allowedDigests,omitempty"`
+
+	// The PEM-encoded public key that verifies the cosign signatures of the images. Images signed with the
+	// corresponding private key may be deployed even if their digests aren't listed in allowedDigests.
+	PublicKey string `json:"publicKey,omitempty"`
+}
+
+// RegistryRewrite replaces the source prefix of an image with the mirror prefix. The rest of the image reference,
+// including its tag or digest, is preserved.
+type RegistryRewrite struct {
+	// The prefix of the images to rewrite, e.g. docker.io/istio. A prefix matches an image whose repository
+	// equals the prefix or continues with a slash after it.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Pattern=`^[^/@:]+(:[0-9]+)?(/[^/@:]+)*$`
+	Source string `json:"source"`
+
+	// The prefix that replaces the source prefix, e.g. mirror.example.com/istio.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Pattern=`^[^/@:]+(:[0-9]+)?(/[^/@:]+)*$`
+	Mirror string `json:"mirror"`
+}
+
+// ImageDigests defines the images of the Istio components of a single version.
+type ImageDigests struct {
+	// The istiod image.
+	Istiod string `json:"istiod,omitempty"`
+
+	// The proxy image, used for the sidecars, the gateways and the init containers.
+	Proxy string `json:"proxy,omitempty"`
+
+	// The Istio CNI image.
+	CNI string `json:"cni,omitempty"`
+
+	// The ztunnel image.
+	ZTunnel string `json:"ztunnel,omitempty"`
+}
+
+// OperatorLogging defines what the operator logs.
+type OperatorLogging struct {
+	// The minimum severity of the logged messages. Overrides the --zap-log-level flag.
+	Level LogLevel `json:"level,omitempty"`
+
+	// Whether to log the events that cause an object to be enqueued for reconciliation. Overrides the
+	// --log-enqueue-events flag.
+	EnqueueEvents *bool `json:"enqueueEvents,omitempty"`
+
+	// Whether to log each request sent to the Kubernetes API server. Overrides the --log-api-requests flag.
+	APIRequests *bool `json:"apiRequests,omitempty"`
+}
+
+// ProbeDefaults defines the default readiness probe settings of the sidecar proxies.
+type ProbeDefaults struct {
+	// The initial delay for the readiness probe in seconds.
+	ReadinessInitialDelaySeconds *uint32 `json:"readinessInitialDelaySeconds,omitempty"`
+
+	// The period between readiness probes in seconds.
+	ReadinessPeriodSeconds *uint32 `json:"readinessPeriodSeconds,omitempty"`
+
+	// The number of successive failed probes before indicating readiness failure.
+	ReadinessFailureThreshold *uint32 `json:"readinessFailureThreshold,omitempty"`
+}
+
+// SailOperatorConfigStatus defines the observed state of SailOperatorConfig
+type SailOperatorConfigStatus struct {
+	// ObservedGeneration is the most recent generation observed for this
+	// SailOperatorConfig object. It corresponds to the object's generation, which is
+	// updated on mutation by the API Server. The information in the status
+	// pertains to this particular generation of the object.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Represents the latest available observations of the object's current state.
+	Conditions []SailOperatorConfigCondition `json:"conditions,omitempty"`
+
+	// Reports the current state of the object.
+	State SailOperatorConfigConditionReason `json:"state,omitempty"`
+}
+
+// GetCondition returns the condition of the specified type
+func (s *SailOperatorConfigStatus) GetCondition(conditionType SailOperatorConfigConditionType) SailOperatorConfigCondition {
+	if s != nil {
+		for i := range s.Conditions {
+			if s.Conditions[i].Type == conditionType {
+				return s.Conditions[i]
+			}
+		}
+	}
+	return SailOperatorConfigCondition{Type: conditionType, Status: metav1.ConditionUnknown}
+}
+
+// SetCondition sets a specific condition in the list of conditions
+func (s *SailOperatorConfigStatus) SetCondition(condition SailOperatorConfigCondition) {
+	var now time.Time
+	if testTime == nil {
+		now = time.Now()
+	} else {
+		now = *testTime
+	}
+
+	// The lastTransitionTime only gets serialized out to the second.  This can
+	// break update skipping, as the time in the resource returned from the client
+	// may not match the time in our cached status during a reconcile.  We truncate
+	// here to save any problems down the line.
+	lastTransitionTime := metav1.NewTime(now.Truncate(time.Second))
+
+	for i, prevCondition := range s.Conditions {
+		if prevCondition.Type == condition.Type {
+			if prevCondition.Status != condition.Status {
+				condition.LastTransitionTime = lastTransitionTime
+			} else {
+				condition.LastTransitionTime = prevCondition.LastTransitionTime
+			}
+			s.Conditions[i] = condition
+			return
+		}
+	}
+
+	// If the condition does not exist, initialize the lastTransitionTime
+	condition.LastTransitionTime = lastTransitionTime
+	s.Conditions = append(s.Conditions, condition)
+}
+
+// SailOperatorConfigCondition represents a specific observation of the SailOperatorConfig object's state.
+type SailOperatorConfigCondition struct {
+	// The type of this condition.
+	Type SailOperatorConfigConditionType `json:"type,omitempty"`
+
+	// The status of this condition. Can be True, False or Unknown.
+	Status metav1.ConditionStatus `json:"status,omitempty"`
+
+	// Unique, single-word, CamelCase reason for the condition's last transition.
+	Reason SailOperatorConfigConditionReason `json:"reason,omitempty"`
+
+	// Human-readable message indicating details about the last transition.
+	Message string `json:"message,omitempty"`
+
+	// Last time the condition transitioned from one status to another.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// SailOperatorConfigConditionType represents the type of the condition.  Condition stages are:
+// Reconciled
+type SailOperatorConfigConditionType string
+
+// SailOperatorConfigConditionReason represents a short message indicating how the condition came
+// to be in its present state.
+type SailOperatorConfigConditionReason string
+
+const (
+	// SailOperatorConfigConditionReconciled signifies whether the operator has applied the configuration.
+	SailOperatorConfigConditionReconciled SailOperatorConfigConditionType = "Reconciled"
+
+	// SailOperatorConfigReasonReconcileError indicates that the configuration couldn't be applied.
+	SailOperatorConfigReasonReconcileError SailOperatorConfigConditionReason = "ReconcileError"
+
+	// SailOperatorConfigReasonApplied indicates that the operator is running with the configuration.
+	SailOperatorConfigReasonApplied SailOperatorConfigConditionReason = "Applied"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,categories=istio-io
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.state",description="The current state of this object."
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="The age of the object"
+// +kubebuilder:validation:XValidation:rule="self.metadata.name == 'default'",message="metadata.name must be 'default'"
+
+// SailOperatorConfig holds the configuration of the Sail Operator. The operator applies changes to it while it's
+// running and reconciles the Istio, IstioRevision, IstioCNI and ZTunnel resources again, so that they pick up the
+// new configuration. The properties file and command-line flags of the operator only provide the defaults.
+type SailOperatorConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec SailOperatorConfigSpec `json:"spec,omitempty"`
+
+	Status SailOperatorConfigStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SailOperatorConfigList contains a list of SailOperatorConfig
+type SailOperatorConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SailOperatorConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SailOperatorConfig{}, &SailOperatorConfigList{})
+}
@@ -0,0 +1,119 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// ZTunnelTargetRefAnnotation preserves spec.targetRef of a v1 ZTunnel, which v1alpha1 has no field for,
+// so that it isn't lost when the object is read and written back through the v1alpha1 API.
+const ZTunnelTargetRefAnnotation = "sailoperator.io/v1-target-ref"
+
+// ConvertTo converts this ZTunnel to the hub version (v1).
+func (src *ZTunnel) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1.ZTunnel)
+	if !ok {
+		return fmt.Errorf("unsupported conversion target %T", dstRaw)
+	}
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Spec = v1.ZTunnelSpec{
+		Version:           src.Spec.Version,
+		Profile:           src.Spec.Profile,
+		Namespace:         src.Spec.Namespace,
+		Values:            src.Spec.Values.DeepCopy(),
+		VersionSkewPolicy: src.Spec.VersionSkewPolicy,
+		VersionFrom:       src.Spec.VersionFrom.DeepCopy(),
+		CreateNamespace:   src.Spec.CreateNamespace.DeepCopy(),
+		DeletionPolicy:    src.Spec.DeletionPolicy,
+	}
+	if targetRef, found := dst.Annotations[ZTunnelTargetRefAnnotation]; found {
+		dst.Spec.TargetRef = &v1.ZTunnelTargetReference{}
+		if err := json.Unmarshal([]byte(targetRef), dst.Spec.TargetRef); err != nil {
+			return fmt.Errorf("invalid %s annotation: %w", ZTunnelTargetRefAnnotation, err)
+		}
+		delete(dst.Annotations, ZTunnelTargetRefAnnotation)
+		if len(dst.Annotations) == 0 {
+			dst.Annotations = nil
+		}
+	}
+
+	dst.Status = v1.ZTunnelStatus{
+		ObservedGeneration: src.Status.ObservedGeneration,
+		State:              v1.ZTunnelConditionReason(src.Status.State),
+		Version:            src.Status.Version,
+	}
+	for _, c := range src.Status.Conditions {
+		dst.Status.Conditions = append(dst.Status.Conditions, v1.ZTunnelCondition{
+			Type:               v1.ZTunnelConditionType(c.Type),
+			Status:             c.Status,
+			Reason:             v1.ZTunnelConditionReason(c.Reason),
+			Message:            c.Message,
+			LastTransitionTime: c.LastTransitionTime,
+		})
+	}
+	return nil
+}
+
+// ConvertFrom converts the hub version (v1) to this ZTunnel.
+func (dst *ZTunnel) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1.ZTunnel)
+	if !ok {
+		return fmt.Errorf("unsupported conversion source %T", srcRaw)
+	}
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Spec = ZTunnelSpec{
+		Version:           src.Spec.Version,
+		Profile:           src.Spec.Profile,
+		Namespace:         src.Spec.Namespace,
+		Values:            src.Spec.Values.DeepCopy(),
+		VersionSkewPolicy: src.Spec.VersionSkewPolicy,
+		VersionFrom:       src.Spec.VersionFrom.DeepCopy(),
+		CreateNamespace:   src.Spec.CreateNamespace.DeepCopy(),
+		DeletionPolicy:    src.Spec.DeletionPolicy,
+	}
+	if src.Spec.TargetRef != nil {
+		targetRef, err := json.Marshal(src.Spec.TargetRef)
+		if err != nil {
+			return fmt.Errorf("failed to marshal spec.targetRef: %w", err)
+		}
+		if dst.Annotations == nil {
+			dst.Annotations = map[string]string{}
+		}
+		dst.Annotations[ZTunnelTargetRefAnnotation] = string(targetRef)
+	}
+
+	dst.Status = ZTunnelStatus{
+		ObservedGeneration: src.Status.ObservedGeneration,
+		State:              ZTunnelConditionReason(src.Status.State),
+		Version:            src.Status.Version,
+	}
+	for _, c := range src.Status.Conditions {
+		dst.Status.Conditions = append(dst.Status.Conditions, ZTunnelCondition{
+			Type:               ZTunnelConditionType(c.Type),
+			Status:             c.Status,
+			Reason:             ZTunnelConditionReason(c.Reason),
+			Message:            c.Message,
+			LastTransitionTime: c.LastTransitionTime,
+		})
+	}
+	return nil
+}
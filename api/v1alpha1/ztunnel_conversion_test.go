@@ -0,0 +1,83 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/istio/pkg/ptr"
+)
+
+func TestZTunnelConversion(t *testing.T) {
+	hub := &v1.ZTunnel{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "default",
+			Annotations: map[string]string{"foo": "bar"},
+		},
+		Spec: v1.ZTunnelSpec{
+			Version:           "v1.24.2",
+			Profile:           "ambient",
+			Namespace:         "ztunnel",
+			TargetRef:         &v1.ZTunnelTargetReference{Kind: v1.IstioKind, Name: "default"},
+			Values:            &v1.ZTunnelValues{ZTunnel: &v1.ZTunnelConfig{LogAsJson: ptr.Of(true)}},
+			VersionSkewPolicy: v1.VersionSkewPolicyBlock,
+			CreateNamespace:   &v1.NamespaceCreation{Labels: map[string]string{"pod-security.kubernetes.io/enforce": "privileged"}, Owned: true},
+			DeletionPolicy:    v1.DeletionPolicyOrphan,
+		},
+		Status: v1.ZTunnelStatus{
+			ObservedGeneration: 3,
+			State:              v1.ZTunnelReasonHealthy,
+			Version:            "v1.24.2",
+			Conditions: []v1.ZTunnelCondition{
+				{Type: v1.ZTunnelConditionReady, Status: metav1.ConditionTrue},
+			},
+		},
+	}
+
+	spoke := &ZTunnel{}
+	if err := spoke.ConvertFrom(hub); err != nil {
+		t.Fatalf("ConvertFrom failed: %v", err)
+	}
+	if spoke.Annotations[ZTunnelTargetRefAnnotation] != `{"kind":"Istio","name":"default"}` {
+		t.Errorf("expected targetRef to be preserved in annotation, got %v", spoke.Annotations)
+	}
+	if spoke.Spec.Version != hub.Spec.Version || spoke.Status.State != ZTunnelReasonHealthy {
+		t.Errorf("unexpected conversion result: %+v", spoke)
+	}
+
+	result := &v1.ZTunnel{}
+	if err := spoke.ConvertTo(result); err != nil {
+		t.Fatalf("ConvertTo failed: %v", err)
+	}
+	if !reflect.DeepEqual(hub, result) {
+		t.Errorf("round trip conversion changed object:\nexpected: %+v\nactual:   %+v", hub, result)
+	}
+}
+
+func TestZTunnelConversionInvalidAnnotation(t *testing.T) {
+	spoke := &ZTunnel{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "default",
+			Annotations: map[string]string{ZTunnelTargetRefAnnotation: "{"},
+		},
+	}
+	if err := spoke.ConvertTo(&v1.ZTunnel{}); err == nil {
+		t.Error("expected error for invalid annotation")
+	}
+}
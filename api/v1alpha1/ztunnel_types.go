@@ -50,9 +50,29 @@ type ZTunnelSpec struct {
 	// +kubebuilder:default=ztunnel
 	Namespace string `json:"namespace"`
 
+	// Makes the operator create the namespace specified in spec.namespace if it doesn't exist, instead of
+	// waiting for it to be created.
+	CreateNamespace *v1.NamespaceCreation `json:"createNamespace,omitempty"`
+
+	// Defines what happens to ztunnel when the ZTunnel resource is deleted. When set to Delete, ztunnel is
+	// uninstalled. When set to Orphan, the ztunnel DaemonSet is left running, but the operator stops managing
+	// it until a ZTunnel with the same namespace is created.
+	// +kubebuilder:default=Delete
+	DeletionPolicy v1.DeletionPolicy `json:"deletionPolicy,omitempty"`
+
 	// Defines the values to be passed to the Helm charts when installing Istio ztunnel.
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Helm Values"
 	Values *v1.ZTunnelValues `json:"values,omitempty"`
+
+	// Defines how the operator handles a version that is too far apart from the version of an in-use IstioRevision.
+	// Warn only reports the skew in the SupportedVersionSkew condition, whereas Block also refuses to install or
+	// upgrade to such a version.
+	// +kubebuilder:default=Warn
+	VersionSkewPolicy v1.VersionSkewPolicy `json:"versionSkewPolicy,omitempty"`
+
+	// Makes the component track the version of the referenced Istio resource instead of using spec.version.
+	// When the Istio's version changes, the component is upgraded after the control plane has been upgraded.
+	VersionFrom *v1.VersionSource `json:"versionFrom,omitempty"`
 }
 
 // ZTunnelStatus defines the observed state of ZTunnel
@@ -68,6 +88,10 @@ type ZTunnelStatus struct {
 
 	// Reports the current state of the object.
 	State ZTunnelConditionReason `json:"state,omitempty"`
+
+	// The Istio version of the installed ztunnel component. When spec.versionFrom is set, this may lag behind the version
+	// of the referenced Istio until its control plane has been upgraded.
+	Version string `json:"version,omitempty"`
 }
 
 // GetCondition returns the condition of the specified type
@@ -133,7 +157,7 @@ type ZTunnelCondition struct {
 }
 
 // ZTunnelConditionType represents the type of the condition.  Condition stages are:
-// Installed, Reconciled, Ready
+// Installed, Reconciled, Ready, SupportedVersionSkew
 type ZTunnelConditionType string
 
 // ZTunnelConditionReason represents a short message indicating how the condition came
@@ -147,6 +171,14 @@ const (
 
 	// ZTunnelReasonReconcileError indicates that the reconciliation of the resource has failed, but will be retried.
 	ZTunnelReasonReconcileError ZTunnelConditionReason = "ReconcileError"
+
+	// ZTunnelReasonWaitingForControlPlane indicates that the component tracks the version of an Istio resource
+	// and waits for its control plane to be upgraded before being upgraded itself.
+	ZTunnelReasonWaitingForControlPlane ZTunnelConditionReason = "WaitingForControlPlane"
+
+	// ZTunnelReasonUnsupportedVersionSkew indicates that the component wasn't installed or upgraded, because its
+	// version is too far apart from the version of an in-use IstioRevision and spec.versionSkewPolicy is Block.
+	ZTunnelReasonUnsupportedVersionSkew ZTunnelConditionReason = "UnsupportedVersionSkew"
 )
 
 const (
@@ -160,6 +192,12 @@ const (
 	ZTunnelReasonReadinessCheckFailed ZTunnelConditionReason = "ReadinessCheckFailed"
 )
 
+const (
+	// ZTunnelConditionSupportedVersionSkew signifies whether the version of the component is supported by
+	// all in-use IstioRevisions.
+	ZTunnelConditionSupportedVersionSkew ZTunnelConditionType = "SupportedVersionSkew"
+)
+
 const (
 	// ZTunnelReasonHealthy indicates that the control plane is fully reconciled and that all components are ready.
 	ZTunnelReasonHealthy ZTunnelConditionReason = "Healthy"
@@ -168,6 +206,7 @@ const (
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:scope=Cluster,categories=istio-io
 // +kubebuilder:subresource:status
+// +kubebuilder:deprecatedversion:warning="sailoperator.io/v1alpha1 ZTunnel is deprecated; use sailoperator.io/v1 ZTunnel"
 // +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="Whether the Istio ztunnel installation is ready to handle requests."
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.state",description="The current state of this object."
 // +kubebuilder:printcolumn:name="Version",type="string",JSONPath=".spec.version",description="The version of the Istio ztunnel installation."
@@ -175,6 +214,8 @@ const (
 // +kubebuilder:validation:XValidation:rule="self.metadata.name == 'default'",message="metadata.name must be 'default'"
 
 // ZTunnel represents a deployment of the Istio ztunnel component.
+//
+// Deprecated: use the v1 ZTunnel instead.
 type ZTunnel struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
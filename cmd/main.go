@@ -15,30 +15,46 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
 	"fmt"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
 
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
 	"github.com/istio-ecosystem/sail-operator/controllers/istio"
 	"github.com/istio-ecosystem/sail-operator/controllers/istiocni"
 	"github.com/istio-ecosystem/sail-operator/controllers/istiorevision"
 	"github.com/istio-ecosystem/sail-operator/controllers/istiorevisiontag"
+	"github.com/istio-ecosystem/sail-operator/controllers/mesh"
+	"github.com/istio-ecosystem/sail-operator/controllers/meshmember"
+	"github.com/istio-ecosystem/sail-operator/controllers/remotecluster"
+	"github.com/istio-ecosystem/sail-operator/controllers/sailoperatorconfig"
+	"github.com/istio-ecosystem/sail-operator/controllers/waypoint"
 	"github.com/istio-ecosystem/sail-operator/controllers/webhook"
 	"github.com/istio-ecosystem/sail-operator/controllers/ztunnel"
 	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/conversion"
 	"github.com/istio-ecosystem/sail-operator/pkg/enqueuelogger"
 	"github.com/istio-ecosystem/sail-operator/pkg/helm"
 	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
 	"github.com/istio-ecosystem/sail-operator/pkg/version"
+	uberzap "go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	ctrlwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
 )
 
 var setupLog = ctrl.Log.WithName("setup")
@@ -47,21 +63,35 @@ func main() {
 	var metricsAddr string
 	var probeAddr string
 	var configFile string
+	var webhookCertDir string
+	var conversionWebhookService string
 	var logAPIRequests bool
 	var printVersion bool
 	var leaderElectionEnabled bool
+	var platform string
 	var reconcilerCfg config.ReconcilerConfig
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8443", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.StringVar(&configFile, "config-file", "/etc/sail-operator/config.properties", "Location of the config file, propagated by k8s downward APIs")
+	flag.StringVar(&webhookCertDir, "webhook-cert-dir", "/tmp/k8s-webhook-server/serving-certs",
+		"Directory containing the serving certificate (tls.crt, tls.key) for the conversion webhook")
+	flag.StringVar(&conversionWebhookService, "conversion-webhook-service", "",
+		"Service (namespace/name) in front of the conversion webhook; if set, the operator configures the ZTunnel CRD to use "+
+			"it, with the CA bundle from ca.crt in the webhook cert dir. Leave empty when OLM manages the conversion webhook")
 	flag.StringVar(&reconcilerCfg.ResourceDirectory, "resource-directory", "/var/lib/sail-operator/resources", "Where to find resources (e.g. charts)")
+	flag.StringVar(&platform, "platform", "",
+		fmt.Sprintf("The platform of the cluster, one of %v. If not set, the platform is detected automatically", config.KnownPlatforms()))
 	flag.BoolVar(&logAPIRequests, "log-api-requests", false, "Whether to log each request sent to the Kubernetes API server")
 	flag.BoolVar(&printVersion, "version", printVersion, "Prints version information and exits")
 	flag.BoolVar(&leaderElectionEnabled, "leader-elect", true,
 		"Enable leader election for this operator. Enabling this will ensure there is only one active controller manager.")
 
-	flag.BoolVar(&enqueuelogger.LogEnqueueEvents, "log-enqueue-events", false, "Whether to log events that cause an object to be enqueued for reconciliation")
+	flag.BoolFunc("log-enqueue-events", "Whether to log events that cause an object to be enqueued for reconciliation", func(value string) error {
+		enabled, err := strconv.ParseBool(value)
+		enqueuelogger.LogEnqueueEvents.Store(enabled)
+		return err
+	})
 
 	opts := zap.Options{
 		Development: true,
@@ -74,6 +104,12 @@ func main() {
 		os.Exit(0)
 	}
 
+	// the level is kept in an AtomicLevel, so that the SailOperatorConfig can change it at runtime
+	logLevel := uberzap.NewAtomicLevelAt(uberzap.DebugLevel)
+	if level, ok := opts.Level.(uberzap.AtomicLevel); ok {
+		logLevel = level
+	}
+	opts.Level = logLevel
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
 	operatorNamespace := os.Getenv("POD_NAMESPACE")
@@ -88,19 +124,20 @@ func main() {
 
 	setupLog.Info(version.Info.String())
 	setupLog.Info("reading config")
-	err := config.Read(configFile)
+	fileConfig, err := config.Read(configFile)
 	if err != nil {
 		setupLog.Error(err, "unable to read config file at "+configFile)
 		os.Exit(1)
 	}
-	setupLog.Info("config loaded", "config", config.Config)
+	setupLog.Info("config loaded", "config", fileConfig)
+	reconcilerCfg.OperatorConfig = config.NewProvider(fileConfig)
 
+	var apiRequestLogging atomic.Bool
+	apiRequestLogging.Store(logAPIRequests)
 	cfg := ctrl.GetConfigOrDie()
-	if logAPIRequests {
-		cfg.Wrap(func(rt http.RoundTripper) http.RoundTripper {
-			return requestLogger{rt: rt}
-		})
-	}
+	cfg.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return requestLogger{rt: rt, enabled: &apiRequestLogging}
+	})
 
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
@@ -126,9 +163,16 @@ func main() {
 	}
 
 	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
-		Scheme:                  scheme.Scheme,
-		Metrics:                 metricsServerOptions,
+		Scheme:  scheme.Scheme,
+		Metrics: metricsServerOptions,
+		Cache: cache.Options{
+			ByObject: map[client.Object]cache.ByObject{
+				// only the remote secrets and the kubeconfig secrets referenced by RemoteClusters are cached
+				&corev1.Secret{}: {Label: remotecluster.SecretCacheSelector},
+			},
+		},
 		HealthProbeBindAddress:  probeAddr,
+		WebhookServer:           ctrlwebhook.NewServer(ctrlwebhook.Options{CertDir: webhookCertDir, TLSOpts: tlsOpts}),
 		LeaderElection:          leaderElectionEnabled,
 		LeaderElectionID:        "sail-operator-lock",
 		LeaderElectionNamespace: operatorNamespace,
@@ -151,19 +195,38 @@ func main() {
 
 	chartManager := helm.NewChartManager(mgr.GetConfig(), os.Getenv("HELM_DRIVER"))
 
-	reconcilerCfg.Platform, err = config.DetectPlatform(mgr.GetConfig())
-	if err != nil {
-		setupLog.Error(err, "unable to detect platform")
+	if platform != "" {
+		reconcilerCfg.Platform, err = config.ParsePlatform(platform)
+		if err != nil {
+			setupLog.Error(err, "invalid --platform flag")
+			os.Exit(1)
+		}
+	} else {
+		reconcilerCfg.Platform, err = config.DetectPlatform(context.Background(), mgr.GetConfig())
+		if err != nil {
+			setupLog.Error(err, "unable to detect platform")
+			os.Exit(1)
+		}
+	}
+	setupLog.Info("Using platform", "platform", reconcilerCfg.Platform)
+
+	reconcilerCfg.DefaultProfile = config.DefaultProfileFor(reconcilerCfg.Platform)
+
+	// reload the image digests when the mounted config file changes
+	if err := mgr.Add(config.NewFileWatcher(configFile, reconcilerCfg.OperatorConfig)); err != nil {
+		setupLog.Error(err, "unable to set up config file watcher")
 		os.Exit(1)
 	}
 
-	if reconcilerCfg.Platform == config.PlatformOpenShift {
-		reconcilerCfg.DefaultProfile = "openshift"
-	} else {
-		reconcilerCfg.DefaultProfile = "default"
+	err = sailoperatorconfig.NewReconciler(mgr.GetClient(), mgr.GetScheme(), reconcilerCfg.OperatorConfig,
+		sailoperatorconfig.Logging{Level: logLevel, APIRequests: &apiRequestLogging}).
+		SetupWithManager(mgr)
+	if err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SailOperatorConfig")
+		os.Exit(1)
 	}
 
-	err = istio.NewReconciler(reconcilerCfg, mgr.GetClient(), mgr.GetScheme()).
+	err = istio.NewReconciler(reconcilerCfg, mgr.GetClient(), mgr.GetScheme(), chartManager).
 		SetupWithManager(mgr)
 	if err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Istio")
@@ -198,12 +261,69 @@ func main() {
 		os.Exit(1)
 	}
 
+	err = remotecluster.NewReconciler(mgr.GetClient(), mgr.GetAPIReader(), mgr.GetScheme()).
+		SetupWithManager(mgr)
+	if err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RemoteCluster")
+		os.Exit(1)
+	}
+
+	err = mesh.NewReconciler(mgr.GetClient(), mgr.GetScheme()).
+		SetupWithManager(mgr)
+	if err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Mesh")
+		os.Exit(1)
+	}
+
+	err = meshmember.NewReconciler(mgr.GetClient(), mgr.GetScheme()).
+		SetupWithManager(mgr)
+	if err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "MeshMember")
+		os.Exit(1)
+	}
+
+	waypointClient, err := waypoint.NewClient(mgr)
+	if err != nil {
+		setupLog.Error(err, "unable to create client", "controller", "Waypoint")
+		os.Exit(1)
+	}
+	err = waypoint.NewReconciler(waypointClient, mgr.GetScheme()).
+		SetupWithManager(mgr)
+	if err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Waypoint")
+		os.Exit(1)
+	}
+
 	err = webhook.NewReconciler(mgr.GetClient(), mgr.GetScheme()).
 		SetupWithManager(mgr)
 	if err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Endpoints")
 		os.Exit(1)
 	}
+
+	// The ZTunnel conversion webhook is only served when a serving certificate is provided, either by OLM or by the
+	// Helm chart. Without it, the CRD uses the None conversion strategy and the API server only changes the apiVersion
+	// of the object.
+	if _, err := os.Stat(filepath.Join(webhookCertDir, "tls.crt")); err == nil {
+		if err := ctrl.NewWebhookManagedBy(mgr).For(&v1.ZTunnel{}).Complete(); err != nil {
+			setupLog.Error(err, "unable to create conversion webhook", "webhook", "ZTunnel")
+			os.Exit(1)
+		}
+		// OLM configures the CRD conversion from the CSV; for Helm installs, the operator does it itself
+		if conversionWebhookService != "" {
+			service, err := conversion.ParseService(conversionWebhookService)
+			if err != nil {
+				setupLog.Error(err, "invalid --conversion-webhook-service flag")
+				os.Exit(1)
+			}
+			if err := mgr.Add(conversion.NewCRDPatcher(mgr.GetClient(), service, webhookCertDir, "ztunnels.sailoperator.io")); err != nil {
+				setupLog.Error(err, "unable to set up CRD conversion patcher")
+				os.Exit(1)
+			}
+		}
+	} else {
+		setupLog.Info("no webhook serving certificate found; ZTunnel conversion webhook disabled", "certDir", webhookCertDir)
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -223,12 +343,15 @@ func main() {
 }
 
 type requestLogger struct {
-	rt http.RoundTripper
+	rt      http.RoundTripper
+	enabled *atomic.Bool
 }
 
 func (rl requestLogger) RoundTrip(req *http.Request) (*http.Response, error) {
-	log := logf.FromContext(req.Context())
-	log.Info("Performing API request", "method", req.Method, "URL", req.URL)
+	if rl.enabled.Load() {
+		log := logf.FromContext(req.Context())
+		log.Info("Performing API request", "method", req.Method, "URL", req.URL)
+	}
 	return rl.rt.RoundTrip(req)
 }
 
@@ -0,0 +1,98 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package istio
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	"github.com/istio-ecosystem/sail-operator/pkg/helm"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	"helm.sh/helm/v3/pkg/release"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// adoptHelmRelease takes over the istiod release named in the sailoperator.io/adopt-helm-release annotation. The
+// version and values of the release are copied into the spec and the release is renamed to the release of the
+// active IstioRevision, which then upgrades it in place. The releases of the base chart in the same namespace are
+// detached, since the operator doesn't install that chart: the CRDs and cluster-wide resources it installed are
+// kept, but no longer belong to a Helm release, so that uninstalling it can't delete them.
+func (r *Reconciler) adoptHelmRelease(ctx context.Context, istio *v1.Istio) error {
+	revName := getActiveRevisionName(istio)
+	return r.ChartManager.AdoptRelease(ctx, r.Client, r.Config.OperatorConfig.Get(), istio, istio.Spec.Namespace, revName+"-"+constants.IstiodChartName,
+		func(rel *release.Release) error {
+			spec, err := istioSpecFromRelease(istio.Spec, rel)
+			if err != nil {
+				return err
+			}
+			chartDir := path.Join(r.Config.ResourceDirectory, spec.Version, "charts", constants.IstiodChartName)
+			if _, err := os.Stat(chartDir); err != nil {
+				return reconciler.NewValidationError(fmt.Sprintf("Helm release %q uses Istio version %s, which is not supported", rel.Name, spec.Version))
+			}
+
+			adopted := istio.DeepCopy()
+			adopted.Spec = spec
+			if getActiveRevisionName(adopted) != revName {
+				return reconciler.NewValidationError(fmt.Sprintf("spec.version must be set to %s, the version of Helm release %q", spec.Version, rel.Name))
+			}
+			if releaseRevision := getReleaseRevision(rel); releaseRevision != revName {
+				return reconciler.NewValidationError(fmt.Sprintf("Helm release %q installs revision %q, but the Istio installs revision %q",
+					rel.Name, releaseRevision, revName))
+			}
+
+			if _, err := r.ChartManager.DetachReleases(ctx, constants.BaseChartName, istio.Spec.Namespace); err != nil {
+				return fmt.Errorf("failed to detach %s Helm releases: %w", constants.BaseChartName, err)
+			}
+
+			patch := client.MergeFrom(istio.DeepCopy())
+			istio.Spec = spec
+			if err := r.Client.Patch(ctx, istio, patch); err != nil {
+				return fmt.Errorf("failed to copy the values of Helm release %q into the Istio spec: %w", rel.Name, err)
+			}
+			return nil
+		})
+}
+
+// istioSpecFromRelease returns the spec with the version set to the one of the release and, unless the spec already
+// contains values, the values set to the ones the release was installed with.
+func istioSpecFromRelease(spec v1.IstioSpec, rel *release.Release) (v1.IstioSpec, error) {
+	version := helm.ReleaseVersion(rel)
+	if version == "" {
+		return spec, reconciler.NewValidationError(fmt.Sprintf("cannot determine Istio version of Helm release %q", rel.Name))
+	}
+	spec.Version = version
+
+	if spec.Values == nil && len(rel.Config) > 0 {
+		values, err := helm.ToValues(helm.Values(rel.Config), &v1.Values{})
+		if err != nil {
+			return spec, fmt.Errorf("failed to convert values of Helm release %q: %w", rel.Name, err)
+		}
+		spec.Values = values
+	}
+	return spec, nil
+}
+
+// getReleaseRevision returns the name of the revision that an istiod release installs. Releases installed without
+// the revision value install the default revision.
+func getReleaseRevision(rel *release.Release) string {
+	if revision, ok := rel.Config["revision"].(string); ok && revision != "" {
+		return revision
+	}
+	return v1.DefaultRevision
+}
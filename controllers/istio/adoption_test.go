@@ -0,0 +1,78 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package istio
+
+import (
+	"testing"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	. "github.com/onsi/gomega"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+
+	"istio.io/istio/pkg/ptr"
+)
+
+func newIstiodRelease(config map[string]any) *release.Release {
+	return &release.Release{
+		Name:   "istiod",
+		Chart:  &chart.Chart{Metadata: &chart.Metadata{Name: "istiod", Version: "1.24.1"}},
+		Config: config,
+	}
+}
+
+func TestIstioSpecFromRelease(t *testing.T) {
+	rel := newIstiodRelease(map[string]any{
+		"pilot": map[string]any{"autoscaleEnabled": false},
+		"meshConfig": map[string]any{
+			"accessLogFile": "/dev/stdout",
+		},
+	})
+
+	t.Run("copies version and values", func(t *testing.T) {
+		g := NewWithT(t)
+		spec, err := istioSpecFromRelease(v1.IstioSpec{Version: "v1.24.2", Namespace: istioNamespace}, rel)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(spec.Version).To(Equal("v1.24.1"))
+		g.Expect(spec.Namespace).To(Equal(istioNamespace))
+		g.Expect(spec.Values).To(Equal(&v1.Values{
+			Pilot:      &v1.PilotConfig{AutoscaleEnabled: ptr.Of(false)},
+			MeshConfig: &v1.MeshConfig{AccessLogFile: ptr.Of("/dev/stdout")},
+		}))
+	})
+
+	t.Run("keeps values in spec", func(t *testing.T) {
+		g := NewWithT(t)
+		values := &v1.Values{Pilot: &v1.PilotConfig{Hub: ptr.Of("quay.io/custom")}}
+		spec, err := istioSpecFromRelease(v1.IstioSpec{Version: "v1.24.2", Values: values}, rel)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(spec.Version).To(Equal("v1.24.1"))
+		g.Expect(spec.Values).To(Equal(values))
+	})
+
+	t.Run("no chart version", func(t *testing.T) {
+		g := NewWithT(t)
+		_, err := istioSpecFromRelease(v1.IstioSpec{}, &release.Release{Name: "istiod"})
+		g.Expect(reconciler.IsValidationError(err)).To(BeTrue())
+	})
+}
+
+func TestGetReleaseRevision(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(getReleaseRevision(newIstiodRelease(nil))).To(Equal(v1.DefaultRevision))
+	g.Expect(getReleaseRevision(newIstiodRelease(map[string]any{"revision": ""}))).To(Equal(v1.DefaultRevision))
+	g.Expect(getReleaseRevision(newIstiodRelease(map[string]any{"revision": "canary"}))).To(Equal("canary"))
+}
@@ -0,0 +1,201 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package istio
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/errlist"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// componentName is the name of the IstioCNI and ZTunnel resources created from spec.components.
+// Both resources are singletons, so they must always be named "default".
+const componentName = "default"
+
+// reconcileComponents creates, updates or deletes the IstioCNI and ZTunnel resources owned by
+// the Istio, so that they match spec.components.
+func (r *Reconciler) reconcileComponents(ctx context.Context, istio *v1.Istio) error {
+	components := istio.Spec.Components
+	if components == nil {
+		components = &v1.IstioComponents{}
+	}
+
+	var errs errlist.Builder
+	errs.Add(r.reconcileComponent(ctx, istio, &v1.IstioCNI{ObjectMeta: metav1.ObjectMeta{Name: componentName}}, components.CNI != nil,
+		func(obj client.Object) {
+			cni := obj.(*v1.IstioCNI)
+			cni.Spec.Version = istio.Spec.Version
+			cni.Spec.VersionFrom = &v1.VersionSource{Istio: istio.Name}
+			cni.Spec.Profile = istio.Spec.Profile
+			cni.Spec.Namespace = components.CNI.Namespace
+			cni.Spec.Values = components.CNI.Values.DeepCopy()
+			cni.Spec.CreateNamespace = components.CNI.CreateNamespace.DeepCopy()
+		}))
+	errs.Add(r.reconcileComponent(ctx, istio, &v1.ZTunnel{ObjectMeta: metav1.ObjectMeta{Name: componentName}}, components.ZTunnel != nil,
+		func(obj client.Object) {
+			ztunnel := obj.(*v1.ZTunnel)
+			ztunnel.Spec.Version = istio.Spec.Version
+			ztunnel.Spec.VersionFrom = &v1.VersionSource{Istio: istio.Name}
+			ztunnel.Spec.Profile = istio.Spec.Profile
+			ztunnel.Spec.Namespace = components.ZTunnel.Namespace
+			ztunnel.Spec.TargetRef = &v1.ZTunnelTargetReference{Kind: v1.IstioKind, Name: istio.Name}
+			ztunnel.Spec.Values = components.ZTunnel.Values.DeepCopy()
+			ztunnel.Spec.CreateNamespace = components.ZTunnel.CreateNamespace.DeepCopy()
+		}))
+	return errs.Error()
+}
+
+// reconcileComponent ensures that the given component exists and is configured by applySpec when it is enabled,
+// and that it doesn't exist when it is disabled. Resources that aren't controlled by the Istio are never modified.
+func (r *Reconciler) reconcileComponent(
+	ctx context.Context, istio *v1.Istio, obj client.Object, enabled bool, applySpec func(client.Object),
+) error {
+	kind := componentKind(obj)
+	log := logf.FromContext(ctx).WithValues(kind, obj.GetName())
+
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get %s %q: %w", kind, obj.GetName(), err)
+		}
+		if !enabled {
+			return nil
+		}
+
+		applySpec(obj)
+		obj.SetOwnerReferences([]metav1.OwnerReference{ownerReference(istio)})
+		log.Info("Creating " + kind)
+		if err := r.Client.Create(ctx, obj); err != nil {
+			return fmt.Errorf("failed to create %s %q: %w", kind, obj.GetName(), err)
+		}
+		return nil
+	}
+
+	if !metav1.IsControlledBy(obj, istio) {
+		if !enabled {
+			return nil
+		}
+		return fmt.Errorf("%s %q already exists and is not owned by Istio %q", kind, obj.GetName(), istio.Name)
+	}
+
+	if !enabled {
+		log.Info("Deleting " + kind + " that was removed from spec.components")
+		if err := r.Client.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete %s %q: %w", kind, obj.GetName(), err)
+		}
+		return nil
+	}
+
+	existing := obj.DeepCopyObject()
+	applySpec(obj)
+	if reflect.DeepEqual(existing, obj) {
+		return nil
+	}
+	log.Info("Updating " + kind)
+	if err := r.Client.Update(ctx, obj); err != nil {
+		return fmt.Errorf("failed to update %s %q: %w", kind, obj.GetName(), err)
+	}
+	return nil
+}
+
+// determineComponentStatus reports the readiness of each component defined in spec.components.
+func (r *Reconciler) determineComponentStatus(ctx context.Context, istio *v1.Istio) ([]v1.IstioComponentStatus, error) {
+	components := istio.Spec.Components
+	if components == nil || (components.CNI == nil && components.ZTunnel == nil) {
+		return nil, nil
+	}
+
+	var errs errlist.Builder
+	var statuses []v1.IstioComponentStatus
+	if components.CNI != nil {
+		cni := &v1.IstioCNI{}
+		status, err := r.getComponentStatus(ctx, istio, cni, func() v1.IstioComponentStatus {
+			ready := cni.Status.GetCondition(v1.IstioCNIConditionReady)
+			return v1.IstioComponentStatus{Ready: ready.Status, Message: ready.Message, Version: cni.Status.Version}
+		})
+		statuses = append(statuses, status)
+		errs.Add(err)
+	}
+	if components.ZTunnel != nil {
+		ztunnel := &v1.ZTunnel{}
+		status, err := r.getComponentStatus(ctx, istio, ztunnel, func() v1.IstioComponentStatus {
+			ready := ztunnel.Status.GetCondition(v1.ZTunnelConditionReady)
+			return v1.IstioComponentStatus{Ready: ready.Status, Message: ready.Message, Version: ztunnel.Status.Version}
+		})
+		statuses = append(statuses, status)
+		errs.Add(err)
+	}
+	return statuses, errs.Error()
+}
+
+func (r *Reconciler) getComponentStatus(
+	ctx context.Context, istio *v1.Istio, obj client.Object, readStatus func() v1.IstioComponentStatus,
+) (v1.IstioComponentStatus, error) {
+	kind := componentKind(obj)
+	status := v1.IstioComponentStatus{Ready: metav1.ConditionFalse}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: componentName}, obj)
+	switch {
+	case apierrors.IsNotFound(err):
+		status.Message = fmt.Sprintf("%s not found", kind)
+		err = nil
+	case err != nil:
+		status.Ready = metav1.ConditionUnknown
+		status.Message = fmt.Sprintf("failed to get %s: %s", kind, err)
+		err = fmt.Errorf("failed to get %s %q: %w", kind, componentName, err)
+	case !metav1.IsControlledBy(obj, istio):
+		status.Message = fmt.Sprintf("%s is not owned by this Istio", kind)
+	default:
+		status = readStatus()
+	}
+	status.Kind = kind
+	status.Name = componentName
+	return status, err
+}
+
+// describeUnreadyComponents returns a message listing the components that aren't ready, or an empty string
+// if all components are ready.
+func describeUnreadyComponents(statuses []v1.IstioComponentStatus) string {
+	var msgs []string
+	for _, s := range statuses {
+		if s.Ready == metav1.ConditionTrue {
+			continue
+		}
+		msg := fmt.Sprintf("%s %q is not ready", s.Kind, s.Name)
+		if s.Message != "" {
+			msg += ": " + s.Message
+		}
+		msgs = append(msgs, msg)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func componentKind(obj client.Object) string {
+	switch obj.(type) {
+	case *v1.IstioCNI:
+		return v1.IstioCNIKind
+	case *v1.ZTunnel:
+		return v1.ZTunnelKind
+	default:
+		panic(fmt.Sprintf("unsupported component type %T", obj))
+	}
+}
@@ -0,0 +1,263 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package istio
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"istio.io/istio/pkg/ptr"
+)
+
+var componentKey = types.NamespacedName{Name: componentName}
+
+func newComponentIstio() *v1.Istio {
+	return &v1.Istio{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: istioName,
+			UID:  istioUID,
+		},
+		Spec: v1.IstioSpec{
+			Version:   "v1.24.2",
+			Namespace: istioNamespace,
+			Profile:   "ambient",
+			Components: &v1.IstioComponents{
+				CNI: &v1.IstioCNIComponent{
+					Namespace: "istio-cni",
+					Values:    &v1.CNIValues{Cni: &v1.CNIConfig{Privileged: ptr.Of(true)}},
+				},
+				ZTunnel: &v1.ZTunnelComponent{Namespace: "ztunnel"},
+			},
+		},
+	}
+}
+
+func TestReconcileComponents(t *testing.T) {
+	cfg := newReconcilerTestConfig(t)
+
+	t.Run("creates components", func(t *testing.T) {
+		g := NewWithT(t)
+		istio := newComponentIstio()
+		cl := newFakeClientBuilder().WithObjects(istio).Build()
+		reconciler := NewReconciler(cfg, cl, scheme.Scheme, nil)
+
+		g.Expect(reconciler.reconcileComponents(ctx, istio)).To(Succeed())
+
+		cni := &v1.IstioCNI{}
+		g.Expect(cl.Get(ctx, componentKey, cni)).To(Succeed())
+		g.Expect(metav1.IsControlledBy(cni, istio)).To(BeTrue())
+		g.Expect(cni.Spec.Version).To(Equal("v1.24.2"))
+		g.Expect(cni.Spec.VersionFrom).To(Equal(&v1.VersionSource{Istio: istioName}))
+		g.Expect(cni.Spec.Profile).To(Equal("ambient"))
+		g.Expect(cni.Spec.Namespace).To(Equal("istio-cni"))
+		g.Expect(cni.Spec.Values).To(Equal(istio.Spec.Components.CNI.Values))
+
+		ztunnel := &v1.ZTunnel{}
+		g.Expect(cl.Get(ctx, componentKey, ztunnel)).To(Succeed())
+		g.Expect(metav1.IsControlledBy(ztunnel, istio)).To(BeTrue())
+		g.Expect(ztunnel.Spec.Version).To(Equal("v1.24.2"))
+		g.Expect(ztunnel.Spec.Profile).To(Equal("ambient"))
+		g.Expect(ztunnel.Spec.Namespace).To(Equal("ztunnel"))
+		g.Expect(ztunnel.Spec.TargetRef).To(Equal(&v1.ZTunnelTargetReference{Kind: v1.IstioKind, Name: istioName}))
+	})
+
+	t.Run("updates components", func(t *testing.T) {
+		g := NewWithT(t)
+		istio := newComponentIstio()
+		cl := newFakeClientBuilder().WithObjects(istio).Build()
+		reconciler := NewReconciler(cfg, cl, scheme.Scheme, nil)
+		g.Expect(reconciler.reconcileComponents(ctx, istio)).To(Succeed())
+
+		istio.Spec.Version = "v1.24.1"
+		istio.Spec.Components.ZTunnel.Namespace = "other-ztunnel"
+		g.Expect(reconciler.reconcileComponents(ctx, istio)).To(Succeed())
+
+		cni := &v1.IstioCNI{}
+		g.Expect(cl.Get(ctx, componentKey, cni)).To(Succeed())
+		g.Expect(cni.Spec.Version).To(Equal("v1.24.1"))
+
+		ztunnel := &v1.ZTunnel{}
+		g.Expect(cl.Get(ctx, componentKey, ztunnel)).To(Succeed())
+		g.Expect(ztunnel.Spec.Version).To(Equal("v1.24.1"))
+		g.Expect(ztunnel.Spec.Namespace).To(Equal("other-ztunnel"))
+	})
+
+	t.Run("deletes components removed from spec", func(t *testing.T) {
+		g := NewWithT(t)
+		istio := newComponentIstio()
+		cl := newFakeClientBuilder().WithObjects(istio).Build()
+		reconciler := NewReconciler(cfg, cl, scheme.Scheme, nil)
+		g.Expect(reconciler.reconcileComponents(ctx, istio)).To(Succeed())
+
+		istio.Spec.Components.CNI = nil
+		g.Expect(reconciler.reconcileComponents(ctx, istio)).To(Succeed())
+
+		g.Expect(apierrors.IsNotFound(cl.Get(ctx, componentKey, &v1.IstioCNI{}))).To(BeTrue())
+		g.Expect(cl.Get(ctx, componentKey, &v1.ZTunnel{})).To(Succeed())
+	})
+
+	t.Run("does not touch components it doesn't own", func(t *testing.T) {
+		g := NewWithT(t)
+		istio := newComponentIstio()
+		cni := &v1.IstioCNI{
+			ObjectMeta: metav1.ObjectMeta{Name: componentName},
+			Spec:       v1.IstioCNISpec{Version: "v1.23.0", Namespace: "istio-cni"},
+		}
+		cl := newFakeClientBuilder().WithObjects(istio, cni).Build()
+		reconciler := NewReconciler(cfg, cl, scheme.Scheme, nil)
+
+		err := reconciler.reconcileComponents(ctx, istio)
+		g.Expect(err).To(MatchError(ContainSubstring(`IstioCNI "default" already exists and is not owned by Istio "my-istio"`)))
+
+		actual := &v1.IstioCNI{}
+		g.Expect(cl.Get(ctx, componentKey, actual)).To(Succeed())
+		g.Expect(actual.Spec.Version).To(Equal("v1.23.0"))
+		g.Expect(cl.Get(ctx, componentKey, &v1.ZTunnel{})).To(Succeed())
+
+		istio.Spec.Components = nil
+		g.Expect(reconciler.reconcileComponents(ctx, istio)).To(Succeed())
+		g.Expect(cl.Get(ctx, componentKey, &v1.IstioCNI{})).To(Succeed())
+	})
+}
+
+func TestDetermineComponentStatus(t *testing.T) {
+	cfg := newReconcilerTestConfig(t)
+	istio := newComponentIstio()
+	owner := []metav1.OwnerReference{ownerReference(istio)}
+
+	testCases := []struct {
+		name            string
+		objects         []client.Object
+		expected        []v1.IstioComponentStatus
+		expectedMessage string
+	}{
+		{
+			name: "components not found",
+			expected: []v1.IstioComponentStatus{
+				{Kind: v1.IstioCNIKind, Name: componentName, Ready: metav1.ConditionFalse, Message: "IstioCNI not found"},
+				{Kind: v1.ZTunnelKind, Name: componentName, Ready: metav1.ConditionFalse, Message: "ZTunnel not found"},
+			},
+			expectedMessage: `IstioCNI "default" is not ready: IstioCNI not found; ZTunnel "default" is not ready: ZTunnel not found`,
+		},
+		{
+			name: "one component not ready",
+			objects: []client.Object{
+				&v1.IstioCNI{
+					ObjectMeta: metav1.ObjectMeta{Name: componentName, OwnerReferences: owner},
+					Status: v1.IstioCNIStatus{
+						Version: "v1.24.2",
+						Conditions: []v1.IstioCNICondition{
+							{Type: v1.IstioCNIConditionReady, Status: metav1.ConditionTrue},
+						},
+					},
+				},
+				&v1.ZTunnel{
+					ObjectMeta: metav1.ObjectMeta{Name: componentName, OwnerReferences: owner},
+					Status: v1.ZTunnelStatus{
+						Conditions: []v1.ZTunnelCondition{
+							{Type: v1.ZTunnelConditionReady, Status: metav1.ConditionFalse, Message: "not all ztunnel pods are ready"},
+						},
+					},
+				},
+			},
+			expected: []v1.IstioComponentStatus{
+				{Kind: v1.IstioCNIKind, Name: componentName, Ready: metav1.ConditionTrue, Version: "v1.24.2"},
+				{Kind: v1.ZTunnelKind, Name: componentName, Ready: metav1.ConditionFalse, Message: "not all ztunnel pods are ready"},
+			},
+			expectedMessage: `ZTunnel "default" is not ready: not all ztunnel pods are ready`,
+		},
+		{
+			name: "component owned by someone else",
+			objects: []client.Object{
+				&v1.IstioCNI{
+					ObjectMeta: metav1.ObjectMeta{Name: componentName},
+					Status: v1.IstioCNIStatus{
+						Conditions: []v1.IstioCNICondition{
+							{Type: v1.IstioCNIConditionReady, Status: metav1.ConditionTrue},
+						},
+					},
+				},
+				&v1.ZTunnel{
+					ObjectMeta: metav1.ObjectMeta{Name: componentName, OwnerReferences: owner},
+					Status: v1.ZTunnelStatus{
+						Version: "v1.24.2",
+						Conditions: []v1.ZTunnelCondition{
+							{Type: v1.ZTunnelConditionReady, Status: metav1.ConditionTrue},
+						},
+					},
+				},
+			},
+			expected: []v1.IstioComponentStatus{
+				{Kind: v1.IstioCNIKind, Name: componentName, Ready: metav1.ConditionFalse, Message: "IstioCNI is not owned by this Istio"},
+				{Kind: v1.ZTunnelKind, Name: componentName, Ready: metav1.ConditionTrue, Version: "v1.24.2"},
+			},
+			expectedMessage: `IstioCNI "default" is not ready: IstioCNI is not owned by this Istio`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cl := newFakeClientBuilder().WithObjects(append(tc.objects, istio)...).Build()
+			reconciler := NewReconciler(cfg, cl, scheme.Scheme, nil)
+
+			statuses, err := reconciler.determineComponentStatus(ctx, istio)
+			if err != nil {
+				t.Fatalf("determineComponentStatus() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, statuses); diff != "" {
+				t.Errorf("returned statuses weren't as expected; diff (-expected, +actual):\n%v", diff)
+			}
+			if msg := describeUnreadyComponents(statuses); msg != tc.expectedMessage {
+				t.Errorf("describeUnreadyComponents() = %q, want %q", msg, tc.expectedMessage)
+			}
+		})
+	}
+}
+
+func TestDetermineStatusWithComponents(t *testing.T) {
+	g := NewWithT(t)
+	cfg := newReconcilerTestConfig(t)
+	istio := newComponentIstio()
+	rev := &v1.IstioRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: istioName, OwnerReferences: []metav1.OwnerReference{ownerReference(istio)}},
+		Status: v1.IstioRevisionStatus{
+			State: v1.IstioRevisionReasonHealthy,
+			Conditions: []v1.IstioRevisionCondition{
+				{Type: v1.IstioRevisionConditionReconciled, Status: metav1.ConditionTrue},
+				{Type: v1.IstioRevisionConditionReady, Status: metav1.ConditionTrue},
+			},
+		},
+	}
+	cl := newFakeClientBuilder().WithObjects(istio, rev).Build()
+	reconciler := NewReconciler(cfg, cl, scheme.Scheme, nil)
+
+	status, err := reconciler.determineStatus(ctx, istio, nil, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(status.State).To(Equal(v1.IstioReasonComponentsNotReady))
+	ready := status.GetCondition(v1.IstioConditionReady)
+	g.Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(ready.Reason).To(Equal(v1.IstioReasonComponentsNotReady))
+	g.Expect(ready.Message).To(ContainSubstring(`IstioCNI "default" is not ready`))
+	g.Expect(status.Components).To(HaveLen(2))
+	g.Expect(status.GetCondition(v1.IstioConditionReconciled).Status).To(Equal(metav1.ConditionTrue))
+}
@@ -0,0 +1,242 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package istio
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	"github.com/istio-ecosystem/sail-operator/pkg/revision"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// namespaceClaims holds the namespaces that an Istio's control plane should discover.
+type namespaceClaims struct {
+	// namespaces is the sorted list of namespaces claimed by the Istio, including the control plane namespace
+	namespaces []string
+	// conflicts lists the claimed namespaces that are also claimed by other Istios
+	conflicts []v1.IstioNamespaceConflict
+}
+
+func usesAutomaticDiscovery(istio *v1.Istio) bool {
+	return istio.Spec.DiscoverySelectorPolicy == v1.DiscoverySelectorPolicyAutomatic
+}
+
+// getNamespaceClaims determines which namespaces are claimed by the given Istio. A namespace is claimed by an Istio if
+// the namespace or any of its pods reference one of the Istio's revisions or a revision tag that points to one of them,
+// or if the namespace was added to the Istio's mesh with a MeshMember. A namespace that is only labeled with
+// istio.io/dataplane-mode=ambient is claimed if there's just one Istio in the cluster; with multiple Istios, such a
+// namespace must also reference a revision or be added with a MeshMember. A namespace that is claimed by more than
+// one Istio is reported as a conflict.
+func (r *Reconciler) getNamespaceClaims(ctx context.Context, istio *v1.Istio) (*namespaceClaims, error) {
+	istioList := v1.IstioList{}
+	if err := r.Client.List(ctx, &istioList); err != nil {
+		return nil, fmt.Errorf("failed to list Istios: %w", err)
+	}
+	istiosByUID := map[string]string{}
+	istiosByNamespace := map[string]sets.Set[string]{}
+	for _, i := range istioList.Items {
+		istiosByUID[string(i.UID)] = i.Name
+		if istiosByNamespace[i.Spec.Namespace] == nil {
+			istiosByNamespace[i.Spec.Namespace] = sets.New[string]()
+		}
+		istiosByNamespace[i.Spec.Namespace].Insert(i.Name)
+	}
+
+	revList := v1.IstioRevisionList{}
+	if err := r.Client.List(ctx, &revList); err != nil {
+		return nil, fmt.Errorf("failed to list IstioRevisions: %w", err)
+	}
+	// owners maps the names of revisions and revision tags to the names of the Istios they belong to
+	owners := map[string]sets.Set[string]{}
+	addOwner := func(ref, istioName string) {
+		if owners[ref] == nil {
+			owners[ref] = sets.New[string]()
+		}
+		owners[ref].Insert(istioName)
+	}
+	for _, rev := range revList.Items {
+		if owner := metav1.GetControllerOf(&rev); owner != nil && owner.Kind == v1.IstioKind {
+			if istioName, found := istiosByUID[string(owner.UID)]; found {
+				addOwner(rev.Name, istioName)
+			}
+		}
+	}
+
+	tagList := v1.IstioRevisionTagList{}
+	if err := r.Client.List(ctx, &tagList); err != nil {
+		return nil, fmt.Errorf("failed to list IstioRevisionTags: %w", err)
+	}
+	for _, tag := range tagList.Items {
+		revNames := []string{tag.Status.IstioRevision}
+		for _, split := range tag.Status.Split {
+			revNames = append(revNames, split.IstioRevision)
+		}
+		for _, revName := range revNames {
+			for istioName := range owners[revName] {
+				addOwner(tag.Name, istioName)
+			}
+		}
+	}
+
+	nsList := corev1.NamespaceList{}
+	if err := r.Client.List(ctx, &nsList); err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	podList := corev1.PodList{}
+	if err := r.Client.List(ctx, &podList); err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	references := revision.GetReferencedRevisionsByNamespace(nsList.Items, podList.Items)
+
+	claimed := sets.New(istio.Spec.Namespace)
+	var conflicts []v1.IstioNamespaceConflict
+	for _, ns := range nsList.Items {
+		if ns.Labels[constants.IgnoreNamespaceKey] == "true" {
+			continue
+		}
+		claimants := sets.New[string]()
+		for ref := range references[ns.Name] {
+			claimants = claimants.Union(owners[ref])
+		}
+		if memberOf, found := ns.Labels[constants.MemberOfKey]; found {
+			claimants = claimants.Union(istiosByNamespace[memberOf])
+		}
+		if claimants.Len() == 0 && len(istioList.Items) == 1 &&
+			ns.Labels[constants.IstioDataplaneModeLabel] == constants.IstioDataplaneModeAmbient {
+			// an ambient namespace that doesn't reference a revision is served by whichever control plane
+			// ztunnel connects to, which can only be determined if there's a single Istio
+			claimants.Insert(istioList.Items[0].Name)
+		}
+		if !claimants.Has(istio.Name) {
+			continue
+		}
+		claimed.Insert(ns.Name)
+		if others := claimants.Delete(istio.Name); others.Len() > 0 {
+			conflicts = append(conflicts, v1.IstioNamespaceConflict{
+				Namespace: ns.Name,
+				Istios:    sets.List(others),
+			})
+		}
+	}
+	slices.SortFunc(conflicts, func(a, b v1.IstioNamespaceConflict) int {
+		return strings.Compare(a.Namespace, b.Namespace)
+	})
+
+	return &namespaceClaims{
+		namespaces: sets.List(claimed),
+		conflicts:  conflicts,
+	}, nil
+}
+
+// applyDiscoverySelectors configures the control plane to only discover the given namespaces.
+func applyDiscoverySelectors(values *v1.Values, namespaces []string) {
+	if values.MeshConfig == nil {
+		values.MeshConfig = &v1.MeshConfig{}
+	}
+	values.MeshConfig.DiscoverySelectors = []*metav1.LabelSelector{
+		{
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{
+					Key:      corev1.LabelMetadataName,
+					Operator: metav1.LabelSelectorOpIn,
+					Values:   namespaces,
+				},
+			},
+		},
+	}
+}
+
+func determineExclusiveNamespacesCondition(conflicts []v1.IstioNamespaceConflict) v1.IstioCondition {
+	c := v1.IstioCondition{
+		Type:   v1.IstioConditionExclusiveNamespaces,
+		Status: metav1.ConditionTrue,
+	}
+	if len(conflicts) > 0 {
+		var messages []string
+		for _, conflict := range conflicts {
+			messages = append(messages, fmt.Sprintf("namespace %s is also claimed by %s", conflict.Namespace, strings.Join(conflict.Istios, ", ")))
+		}
+		c.Status = metav1.ConditionFalse
+		c.Reason = v1.IstioReasonNamespaceConflict
+		c.Message = strings.Join(messages, "; ")
+	}
+	return c
+}
+
+func removeCondition(status *v1.IstioStatus, conditionType v1.IstioConditionType) {
+	status.Conditions = slices.DeleteFunc(status.Conditions, func(c v1.IstioCondition) bool {
+		return c.Type == conditionType
+	})
+}
+
+// mapToAutomaticDiscoveryIstios enqueues all Istios that use the Automatic discovery selector policy, since a change
+// to a namespace, pod or revision tag may change the namespaces that they claim.
+func (r *Reconciler) mapToAutomaticDiscoveryIstios(ctx context.Context, _ client.Object) []reconcile.Request {
+	istioList := v1.IstioList{}
+	if err := r.Client.List(ctx, &istioList); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, istio := range istioList.Items {
+		if usesAutomaticDiscovery(&istio) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&istio)})
+		}
+	}
+	return requests
+}
+
+// namespaceReferencesChanged filters out namespace and pod events that can't change the revisions
+// that the namespace references.
+func namespaceReferencesChanged() predicate.Funcs {
+	references := func(obj client.Object) []string {
+		if _, ok := obj.(*corev1.Namespace); ok {
+			return []string{
+				revision.GetReferencedRevisionFromNamespace(obj.GetLabels()),
+				obj.GetLabels()[constants.MemberOfKey],
+				obj.GetLabels()[constants.IstioDataplaneModeLabel],
+			}
+		}
+		return []string{revision.GetReferencedRevisionFromPod(obj.GetLabels()), revision.GetInjectedRevisionFromPod(obj.GetAnnotations())}
+	}
+	hasReferences := func(obj client.Object) bool {
+		return slices.ContainsFunc(references(obj), func(ref string) bool { return ref != "" })
+	}
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return hasReferences(e.Object)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return hasReferences(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return !slices.Equal(references(e.ObjectOld), references(e.ObjectNew)) ||
+				e.ObjectOld.GetLabels()[constants.IgnoreNamespaceKey] != e.ObjectNew.GetLabels()[constants.IgnoreNamespaceKey]
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
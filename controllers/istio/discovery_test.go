@@ -0,0 +1,283 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package istio
+
+import (
+	"errors"
+	"os"
+	"path"
+	"testing"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func newDiscoveryIstio() *v1.Istio {
+	return &v1.Istio{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: istioName,
+			UID:  istioUID,
+		},
+		Spec: v1.IstioSpec{
+			Version:                 "v1.24.2",
+			Namespace:               istioNamespace,
+			DiscoverySelectorPolicy: v1.DiscoverySelectorPolicyAutomatic,
+		},
+	}
+}
+
+// newDiscoveryObjects returns a second Istio called "other", a revision for each Istio, a revision tag "prod"
+// pointing to the revision of the first Istio, and namespaces that reference them in different ways.
+func newDiscoveryObjects() []client.Object {
+	other := &v1.Istio{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", UID: "other-uid"},
+		Spec:       v1.IstioSpec{Version: "v1.24.2", Namespace: "other-istio-namespace"},
+	}
+	newRevision := func(istio *v1.Istio) *v1.IstioRevision {
+		return &v1.IstioRevision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            istio.Name,
+				OwnerReferences: []metav1.OwnerReference{ownerReference(istio)},
+			},
+		}
+	}
+	newNamespace := func(name string, labels map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+	return []client.Object{
+		other,
+		newRevision(newDiscoveryIstio()),
+		newRevision(other),
+		&v1.IstioRevisionTag{
+			ObjectMeta: metav1.ObjectMeta{Name: "prod"},
+			Status:     v1.IstioRevisionTagStatus{IstioRevision: istioName},
+		},
+		newNamespace("by-revision", map[string]string{constants.IstioRevLabel: istioName}),
+		newNamespace("by-tag", map[string]string{constants.IstioRevLabel: "prod"}),
+		newNamespace("by-member", map[string]string{constants.MemberOfKey: istioNamespace}),
+		newNamespace("by-pod", nil),
+		newNamespace("conflict", map[string]string{constants.IstioRevLabel: "other"}),
+		newNamespace("other-only", map[string]string{constants.IstioRevLabel: "other"}),
+		newNamespace("ignored", map[string]string{constants.IstioRevLabel: istioName, constants.IgnoreNamespaceKey: "true"}),
+		newNamespace("unlabeled", nil),
+		newNamespace("ambient-only", map[string]string{constants.IstioDataplaneModeLabel: constants.IstioDataplaneModeAmbient}),
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "p1", Namespace: "by-pod", Labels: map[string]string{constants.IstioRevLabel: istioName}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "p2", Namespace: "conflict", Annotations: map[string]string{constants.IstioRevLabel: istioName}},
+		},
+	}
+}
+
+var expectedDiscoveredNamespaces = []string{"by-member", "by-pod", "by-revision", "by-tag", "conflict", istioNamespace}
+
+func TestGetNamespaceClaims(t *testing.T) {
+	g := NewWithT(t)
+	istio := newDiscoveryIstio()
+	cl := newFakeClientBuilder().WithObjects(istio).WithObjects(newDiscoveryObjects()...).Build()
+	reconciler := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme, nil)
+
+	claims, err := reconciler.getNamespaceClaims(ctx, istio)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(claims.namespaces).To(Equal(expectedDiscoveredNamespaces))
+	g.Expect(claims.conflicts).To(Equal([]v1.IstioNamespaceConflict{{Namespace: "conflict", Istios: []string{"other"}}}))
+}
+
+func TestGetNamespaceClaimsAmbientNamespaceWithSingleIstio(t *testing.T) {
+	g := NewWithT(t)
+	istio := newDiscoveryIstio()
+	cl := newFakeClientBuilder().WithObjects(istio,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   "ambient-only",
+			Labels: map[string]string{constants.IstioDataplaneModeLabel: constants.IstioDataplaneModeAmbient},
+		}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "unlabeled"}},
+	).Build()
+	reconciler := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme, nil)
+
+	claims, err := reconciler.getNamespaceClaims(ctx, istio)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(claims.namespaces).To(Equal([]string{"ambient-only", istioNamespace}))
+	g.Expect(claims.conflicts).To(BeEmpty())
+}
+
+func TestReconcileActiveRevisionAppliesDiscoverySelectors(t *testing.T) {
+	cfg := newReconcilerTestConfig(t)
+	profilesDir := path.Join(cfg.ResourceDirectory, "v1.24.2", "profiles")
+	Must(t, os.MkdirAll(profilesDir, 0o755))
+	Must(t, os.WriteFile(path.Join(profilesDir, "default.yaml"), []byte("apiVersion: sailoperator.io/v1\nkind: Istio\n"), 0o644))
+
+	t.Run("automatic", func(t *testing.T) {
+		g := NewWithT(t)
+		istio := newDiscoveryIstio()
+		cl := newFakeClientBuilder().WithObjects(istio).WithObjects(newDiscoveryObjects()...).Build()
+		reconciler := NewReconciler(cfg, cl, scheme.Scheme, nil)
+
+		claims, err := reconciler.getNamespaceClaims(ctx, istio)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(reconciler.reconcileActiveRevision(ctx, istio, claims)).To(Succeed())
+
+		rev := &v1.IstioRevision{}
+		g.Expect(cl.Get(ctx, types.NamespacedName{Name: istioName}, rev)).To(Succeed())
+		g.Expect(rev.Spec.Values.MeshConfig.DiscoverySelectors).To(Equal([]*metav1.LabelSelector{
+			{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{
+						Key:      corev1.LabelMetadataName,
+						Operator: metav1.LabelSelectorOpIn,
+						Values:   expectedDiscoveredNamespaces,
+					},
+				},
+			},
+		}))
+	})
+
+	t.Run("manual", func(t *testing.T) {
+		g := NewWithT(t)
+		istio := newDiscoveryIstio()
+		istio.Spec.DiscoverySelectorPolicy = v1.DiscoverySelectorPolicyManual
+		cl := newFakeClientBuilder().WithObjects(istio).WithObjects(newDiscoveryObjects()...).Build()
+		reconciler := NewReconciler(cfg, cl, scheme.Scheme, nil)
+
+		g.Expect(reconciler.reconcileActiveRevision(ctx, istio, nil)).To(Succeed())
+
+		rev := &v1.IstioRevision{}
+		g.Expect(cl.Get(ctx, types.NamespacedName{Name: istioName}, rev)).To(Succeed())
+		if rev.Spec.Values.MeshConfig != nil {
+			g.Expect(rev.Spec.Values.MeshConfig.DiscoverySelectors).To(BeEmpty())
+		}
+	})
+}
+
+func TestDetermineStatusReportsNamespaceConflicts(t *testing.T) {
+	cfg := newReconcilerTestConfig(t)
+
+	t.Run("automatic", func(t *testing.T) {
+		g := NewWithT(t)
+		istio := newDiscoveryIstio()
+		cl := newFakeClientBuilder().WithObjects(istio).WithObjects(newDiscoveryObjects()...).Build()
+		reconciler := NewReconciler(cfg, cl, scheme.Scheme, nil)
+
+		claims, err := reconciler.getNamespaceClaims(ctx, istio)
+		g.Expect(err).NotTo(HaveOccurred())
+		status, err := reconciler.determineStatus(ctx, istio, claims, nil)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(status.DiscoveredNamespaces).To(Equal(expectedDiscoveredNamespaces))
+		g.Expect(status.NamespaceConflicts).To(HaveLen(1))
+		condition := status.GetCondition(v1.IstioConditionExclusiveNamespaces)
+		g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		g.Expect(condition.Reason).To(Equal(v1.IstioReasonNamespaceConflict))
+		g.Expect(condition.Message).To(Equal("namespace conflict is also claimed by other"))
+	})
+
+	t.Run("automatic without claims", func(t *testing.T) {
+		g := NewWithT(t)
+		istio := newDiscoveryIstio()
+		istio.Status.DiscoveredNamespaces = []string{"stale"}
+		cl := newFakeClientBuilder().WithObjects(istio).WithObjects(newDiscoveryObjects()...).Build()
+		reconciler := NewReconciler(cfg, cl, scheme.Scheme, nil)
+
+		status, err := reconciler.determineStatus(ctx, istio, nil, errors.New("failed to list namespaces"))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(status.DiscoveredNamespaces).To(BeNil())
+		condition := status.GetCondition(v1.IstioConditionExclusiveNamespaces)
+		g.Expect(condition.Status).To(Equal(metav1.ConditionUnknown))
+		g.Expect(condition.Reason).To(Equal(v1.IstioReasonReconcileError))
+	})
+
+	t.Run("manual", func(t *testing.T) {
+		g := NewWithT(t)
+		istio := newDiscoveryIstio()
+		istio.Spec.DiscoverySelectorPolicy = v1.DiscoverySelectorPolicyManual
+		istio.Status.DiscoveredNamespaces = []string{"stale"}
+		istio.Status.SetCondition(v1.IstioCondition{Type: v1.IstioConditionExclusiveNamespaces, Status: metav1.ConditionTrue})
+		cl := newFakeClientBuilder().WithObjects(istio).WithObjects(newDiscoveryObjects()...).Build()
+		reconciler := NewReconciler(cfg, cl, scheme.Scheme, nil)
+
+		status, err := reconciler.determineStatus(ctx, istio, nil, nil)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(status.DiscoveredNamespaces).To(BeNil())
+		g.Expect(status.NamespaceConflicts).To(BeNil())
+		g.Expect(status.GetCondition(v1.IstioConditionExclusiveNamespaces).Status).To(Equal(metav1.ConditionUnknown))
+	})
+}
+
+func TestNamespaceReferencesChanged(t *testing.T) {
+	predicate := namespaceReferencesChanged()
+	newPod := func(labels map[string]string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "ns", Labels: labels}}
+	}
+	newNamespace := func(labels map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns", Labels: labels}}
+	}
+
+	testCases := []struct {
+		name     string
+		oldObj   client.Object
+		newObj   client.Object
+		expected bool
+	}{
+		{
+			name:     "pod label unrelated",
+			oldObj:   newPod(map[string]string{"app": "a"}),
+			newObj:   newPod(map[string]string{"app": "b"}),
+			expected: false,
+		},
+		{
+			name:     "pod revision changed",
+			oldObj:   newPod(nil),
+			newObj:   newPod(map[string]string{constants.IstioRevLabel: "rev"}),
+			expected: true,
+		},
+		{
+			name:     "namespace member-of changed",
+			oldObj:   newNamespace(nil),
+			newObj:   newNamespace(map[string]string{constants.MemberOfKey: "istio-system"}),
+			expected: true,
+		},
+		{
+			name:     "namespace dataplane mode changed",
+			oldObj:   newNamespace(nil),
+			newObj:   newNamespace(map[string]string{constants.IstioDataplaneModeLabel: constants.IstioDataplaneModeAmbient}),
+			expected: true,
+		},
+		{
+			name:     "namespace ignored",
+			oldObj:   newNamespace(map[string]string{constants.IstioRevLabel: "rev"}),
+			newObj:   newNamespace(map[string]string{constants.IstioRevLabel: "rev", constants.IgnoreNamespaceKey: "true"}),
+			expected: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(predicate.Update(event.UpdateEvent{ObjectOld: tc.oldObj, ObjectNew: tc.newObj})).To(Equal(tc.expected))
+		})
+	}
+
+	t.Run("create without references", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(predicate.Create(event.CreateEvent{Object: newPod(nil)})).To(BeFalse())
+		g.Expect(predicate.Create(event.CreateEvent{Object: newPod(map[string]string{constants.IstioRevLabel: "rev"})})).To(BeTrue())
+	})
+}
@@ -25,16 +25,22 @@ import (
 	"github.com/go-logr/logr"
 	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
 	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
 	"github.com/istio-ecosystem/sail-operator/pkg/enqueuelogger"
 	"github.com/istio-ecosystem/sail-operator/pkg/errlist"
+	"github.com/istio-ecosystem/sail-operator/pkg/helm"
 	"github.com/istio-ecosystem/sail-operator/pkg/kube"
+	"github.com/istio-ecosystem/sail-operator/pkg/namespace"
 	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
 	"github.com/istio-ecosystem/sail-operator/pkg/revision"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -48,14 +54,20 @@ import (
 type Reconciler struct {
 	Config config.ReconcilerConfig
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme       *runtime.Scheme
+	ChartManager *helm.ChartManager
+	// EventRecorder is set up by SetupWithManager
+	EventRecorder record.EventRecorder
+	// APIReader is set up by SetupWithManager and lists the workloads in the namespace before it's deleted
+	APIReader client.Reader
 }
 
-func NewReconciler(cfg config.ReconcilerConfig, client client.Client, scheme *runtime.Scheme) *Reconciler {
+func NewReconciler(cfg config.ReconcilerConfig, client client.Client, scheme *runtime.Scheme, chartManager *helm.ChartManager) *Reconciler {
 	return &Reconciler{
-		Config: cfg,
-		Client: client,
-		Scheme: scheme,
+		Config:       cfg,
+		Client:       client,
+		Scheme:       scheme,
+		ChartManager: chartManager,
 	}
 }
 
@@ -72,26 +84,191 @@ func (r *Reconciler) Reconcile(ctx context.Context, istio *v1.Istio) (ctrl.Resul
 	log := logf.FromContext(ctx)
 
 	log.Info("Reconciling")
-	result, reconcileErr := r.doReconcile(ctx, istio)
+	result, claims, reconcileErr := r.doReconcile(ctx, istio)
 
 	log.Info("Reconciliation done. Updating status.")
-	statusErr := r.updateStatus(ctx, istio, reconcileErr)
+	statusErr := r.updateStatus(ctx, istio, claims, reconcileErr)
 
 	return result, errors.Join(reconcileErr, statusErr)
 }
 
+// Finalize blocks the deletion of the Istio while any of its IstioRevisions is in use, unless the Istio is annotated
+// with sailoperator.io/force-delete=true. It also deletes the control plane namespace if the operator created it and
+// spec.createNamespace.owned is set. If spec.deletionPolicy is Orphan, it only passes the policy on to the owned
+// resources, so that they leave the control plane running when they're garbage-collected.
+func (r *Reconciler) Finalize(ctx context.Context, istio *v1.Istio) error {
+	revs, err := revision.ListOwned(ctx, r.Client, istio.UID)
+	if err != nil {
+		return err
+	}
+
+	// the owned resources are garbage-collected after the Istio is gone, so they must be updated now
+	if istio.Spec.DeletionPolicy == v1.DeletionPolicyOrphan {
+		return r.updateOwnedResources(ctx, istio, revs, setOrphanDeletionPolicy)
+	}
+	if kube.IsForceDeleteRequested(istio) {
+		if err := r.updateOwnedResources(ctx, istio, revs, setForceDeleteAnnotation); err != nil {
+			return err
+		}
+	} else if inUse := getRevisionsInUse(revs); len(inUse) > 0 {
+		return r.blockDeletion(ctx, istio, inUse)
+	}
+
+	if istio.Spec.CreateNamespace == nil || !istio.Spec.CreateNamespace.Owned {
+		return nil
+	}
+
+	// the IstioRevisions must be uninstalled before the namespace is deleted, since their Helm releases are stored in it
+	for _, rev := range revs {
+		if rev.DeletionTimestamp == nil {
+			if err := r.Client.Delete(ctx, &rev); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("failed to delete IstioRevision %q: %w", rev.Name, err)
+			}
+		}
+	}
+	if len(revs) > 0 {
+		return reconciler.NewTransientError("waiting for IstioRevisions to be deleted before deleting the namespace")
+	}
+
+	workloads, err := namespace.Delete(ctx, r.Client, r.APIReader, istio.Spec.Namespace, istio.Spec.CreateNamespace, namespace.Owner(v1.IstioKind, istio.Name))
+	namespace.RecordNotDeleted(r.EventRecorder, istio, istio.Spec.Namespace, workloads)
+	return err
+}
+
+func getRevisionsInUse(revs []v1.IstioRevision) []string {
+	var inUse []string
+	for _, rev := range revs {
+		if rev.Status.GetCondition(v1.IstioRevisionConditionInUse).Status == metav1.ConditionTrue {
+			inUse = append(inUse, rev.Name)
+		}
+	}
+	return inUse
+}
+
+// blockDeletion sets the DeletionBlocked condition and returns a TransientError, so that the finalization is retried
+// when the status of the IstioRevisions changes.
+func (r *Reconciler) blockDeletion(ctx context.Context, istio *v1.Istio, inUse []string) error {
+	status := *istio.Status.DeepCopy()
+	status.SetCondition(v1.IstioCondition{
+		Type:   v1.IstioConditionDeletionBlocked,
+		Status: metav1.ConditionTrue,
+		Reason: v1.IstioReasonRevisionsInUse,
+		Message: fmt.Sprintf("IstioRevisions %s are still in use; remove the references to them or annotate the Istio with %s=true",
+			strings.Join(inUse, ", "), constants.ForceDeleteKey),
+	})
+	if !reflect.DeepEqual(istio.Status, status) {
+		if err := r.Client.Status().Patch(ctx, istio, kube.NewStatusPatch(status)); err != nil {
+			return fmt.Errorf("failed to patch status: %w", err)
+		}
+	}
+	return reconciler.NewTransientError("IstioRevisions in use: " + strings.Join(inUse, ", "))
+}
+
+// updateOwnedResources applies the update function to the IstioRevisions and the components owned by the Istio,
+// and patches the resources that the function changed.
+func (r *Reconciler) updateOwnedResources(ctx context.Context, istio *v1.Istio, revs []v1.IstioRevision, update func(client.Object) bool) error {
+	objs := make([]client.Object, 0, len(revs)+2)
+	for i := range revs {
+		objs = append(objs, &revs[i])
+	}
+	for _, component := range []client.Object{&v1.IstioCNI{}, &v1.ZTunnel{}} {
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: componentName}, component); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to get %s: %w", componentKind(component), err)
+		}
+		if metav1.IsControlledBy(component, istio) {
+			objs = append(objs, component)
+		}
+	}
+
+	for _, obj := range objs {
+		patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+		if !update(obj) {
+			continue
+		}
+		if err := r.Client.Patch(ctx, obj, patch); client.IgnoreNotFound(err) != nil {
+			kind := v1.IstioRevisionKind
+			if _, ok := obj.(*v1.IstioRevision); !ok {
+				kind = componentKind(obj)
+			}
+			return fmt.Errorf("failed to update %s %q: %w", kind, obj.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// setForceDeleteAnnotation annotates the IstioRevisions and the IstioCNI with sailoperator.io/force-delete=true,
+// so that they're also uninstalled even though they're in use.
+func setForceDeleteAnnotation(obj client.Object) bool {
+	if _, ok := obj.(*v1.ZTunnel); ok || kube.IsForceDeleteRequested(obj) {
+		return false
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[constants.ForceDeleteKey] = "true"
+	obj.SetAnnotations(annotations)
+	return true
+}
+
+// setOrphanDeletionPolicy sets spec.deletionPolicy of the resource to Orphan.
+func setOrphanDeletionPolicy(obj client.Object) bool {
+	var policy *v1.DeletionPolicy
+	switch o := obj.(type) {
+	case *v1.IstioRevision:
+		policy = &o.Spec.DeletionPolicy
+	case *v1.IstioCNI:
+		policy = &o.Spec.DeletionPolicy
+	case *v1.ZTunnel:
+		policy = &o.Spec.DeletionPolicy
+	default:
+		panic(fmt.Sprintf("unsupported type %T", obj))
+	}
+	if *policy == v1.DeletionPolicyOrphan {
+		return false
+	}
+	*policy = v1.DeletionPolicyOrphan
+	return true
+}
+
 // doReconcile is the function that actually reconciles the Istio object. Any error reported by this
-// function should get reported in the status of the Istio object by the caller.
-func (r *Reconciler) doReconcile(ctx context.Context, istio *v1.Istio) (result ctrl.Result, err error) {
+// function should get reported in the status of the Istio object by the caller. If the Istio uses automatic
+// discovery selectors, the namespaces that it claims are returned, so that they can be reported in the status
+// without being computed again.
+func (r *Reconciler) doReconcile(ctx context.Context, istio *v1.Istio) (result ctrl.Result, claims *namespaceClaims, err error) {
 	if err := validate(istio); err != nil {
-		return ctrl.Result{}, err
+		return ctrl.Result{}, nil, err
+	}
+
+	if istio.Spec.CreateNamespace != nil {
+		if err = namespace.Reconcile(ctx, r.Client, istio.Spec.Namespace, istio.Spec.CreateNamespace, namespace.Owner(v1.IstioKind, istio.Name)); err != nil {
+			return ctrl.Result{}, nil, err
+		}
 	}
 
-	if err = r.reconcileActiveRevision(ctx, istio); err != nil {
-		return ctrl.Result{}, err
+	if err = r.adoptHelmRelease(ctx, istio); err != nil {
+		return ctrl.Result{}, nil, err
 	}
 
-	return revision.PruneInactive(ctx, r.Client, istio.UID, getActiveRevisionName(istio), getPruningGracePeriod(istio))
+	if usesAutomaticDiscovery(istio) {
+		if claims, err = r.getNamespaceClaims(ctx, istio); err != nil {
+			return ctrl.Result{}, nil, err
+		}
+	}
+
+	if err = r.reconcileActiveRevision(ctx, istio, claims); err != nil {
+		return ctrl.Result{}, claims, err
+	}
+
+	if err = r.reconcileComponents(ctx, istio); err != nil {
+		return ctrl.Result{}, claims, err
+	}
+
+	result, err = revision.PruneInactive(ctx, r.Client, istio.UID, getActiveRevisionName(istio), getPruningGracePeriod(istio))
+	return result, claims, err
 }
 
 func validate(istio *v1.Istio) error {
@@ -101,29 +278,41 @@ func validate(istio *v1.Istio) error {
 	if istio.Spec.Namespace == "" {
 		return reconciler.NewValidationError("spec.namespace not set")
 	}
+	if usesAutomaticDiscovery(istio) && istio.Spec.Values != nil && istio.Spec.Values.MeshConfig != nil &&
+		len(istio.Spec.Values.MeshConfig.DiscoverySelectors) > 0 {
+		return reconciler.NewValidationError("spec.values.meshConfig.discoverySelectors must not be set when spec.discoverySelectorPolicy is Automatic")
+	}
 	return nil
 }
 
-func (r *Reconciler) reconcileActiveRevision(ctx context.Context, istio *v1.Istio) error {
-	values, err := revision.ComputeValues(
+func (r *Reconciler) reconcileActiveRevision(ctx context.Context, istio *v1.Istio, claims *namespaceClaims) error {
+	values, err := revision.ComputeValues(r.Config.OperatorConfig.Get(),
 		istio.Spec.Values, istio.Spec.Namespace, istio.Spec.Version,
-		r.Config.Platform, r.Config.DefaultProfile, istio.Spec.Profile,
+		r.Config.ActivePlatform(), r.Config.ActiveDefaultProfile(), istio.Spec.Profile,
 		r.Config.ResourceDirectory, getActiveRevisionName(istio))
 	if err != nil {
 		return err
 	}
 
+	if claims != nil {
+		applyDiscoverySelectors(values, claims.namespaces)
+	}
+
 	return revision.CreateOrUpdate(ctx, r.Client,
 		getActiveRevisionName(istio),
 		istio.Spec.Version, istio.Spec.Namespace, values,
-		metav1.OwnerReference{
-			APIVersion:         v1.GroupVersion.String(),
-			Kind:               v1.IstioKind,
-			Name:               istio.Name,
-			UID:                istio.UID,
-			Controller:         ptr.Of(true),
-			BlockOwnerDeletion: ptr.Of(true),
-		})
+		ownerReference(istio))
+}
+
+func ownerReference(istio *v1.Istio) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion:         v1.GroupVersion.String(),
+		Kind:               v1.IstioKind,
+		Name:               istio.Name,
+		UID:                istio.UID,
+		Controller:         ptr.Of(true),
+		BlockOwnerDeletion: ptr.Of(true),
+	}
 }
 
 func getPruningGracePeriod(istio *v1.Istio) time.Duration {
@@ -172,6 +361,8 @@ func getActiveRevisionName(istio *v1.Istio) string {
 // SetupWithManager sets up the controller with the Manager.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	logger := mgr.GetLogger().WithName("ctrlr").WithName("istio")
+	r.EventRecorder = mgr.GetEventRecorderFor("sail-operator")
+	r.APIReader = mgr.GetAPIReader()
 
 	// mainObjectHandler handles the IstioRevision watch events
 	mainObjectHandler := wrapEventHandler(logger, &handler.EnqueueRequestForObject{})
@@ -180,6 +371,9 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	ownedResourceHandler := wrapEventHandler(logger,
 		handler.EnqueueRequestForOwner(r.Scheme, r.RESTMapper(), &v1.Istio{}, handler.OnlyControllerOwner()))
 
+	// discoveryHandler handles the resources that determine the namespaces claimed by Istios with automatic discovery selectors
+	discoveryHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapToAutomaticDiscoveryIstios))
+
 	return ctrl.NewControllerManagedBy(mgr).
 		WithOptions(controller.Options{
 			LogConstructor: func(req *reconcile.Request) logr.Logger {
@@ -195,10 +389,20 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Watches(&v1.Istio{}, mainObjectHandler).
 		Named("istio").
 		Watches(&v1.IstioRevision{}, ownedResourceHandler).
-		Complete(reconciler.NewStandardReconciler[*v1.Istio](r.Client, r.Reconcile))
+		Watches(&v1.IstioCNI{}, ownedResourceHandler).
+		Watches(&v1.ZTunnel{}, ownedResourceHandler).
+		Watches(&v1.IstioRevisionTag{}, discoveryHandler).
+		Watches(&corev1.Namespace{}, discoveryHandler, builder.WithPredicates(namespaceReferencesChanged())).
+		Watches(&corev1.Pod{}, discoveryHandler, builder.WithPredicates(namespaceReferencesChanged())).
+
+		// changes of the operator configuration affect the values and the profile of the IstioRevisions
+		WatchesRawSource(r.Config.OperatorConfig.WatchSource(r.mapOperatorConfigChangeToReconcileRequest)).
+		Complete(reconciler.NewStandardReconcilerWithFinalizer[*v1.Istio](r.Client, r.Reconcile, r.Finalize, constants.FinalizerName))
 }
 
-func (r *Reconciler) determineStatus(ctx context.Context, istio *v1.Istio, reconcileErr error) (v1.IstioStatus, error) {
+func (r *Reconciler) determineStatus(
+	ctx context.Context, istio *v1.Istio, claims *namespaceClaims, reconcileErr error,
+) (v1.IstioStatus, error) {
 	var errs errlist.Builder
 	status := *istio.Status.DeepCopy()
 	status.ObservedGeneration = istio.Generation
@@ -254,6 +458,45 @@ func (r *Reconciler) determineStatus(ctx context.Context, istio *v1.Istio, recon
 		}
 	}
 
+	// aggregate the readiness of the components into the Ready condition
+	components, err := r.determineComponentStatus(ctx, istio)
+	if err != nil {
+		errs.Add(err)
+	}
+	status.Components = components
+	if status.GetCondition(v1.IstioConditionReady).Status == metav1.ConditionTrue {
+		if msg := describeUnreadyComponents(components); msg != "" {
+			status.SetCondition(v1.IstioCondition{
+				Type:    v1.IstioConditionReady,
+				Status:  metav1.ConditionFalse,
+				Reason:  v1.IstioReasonComponentsNotReady,
+				Message: msg,
+			})
+			status.State = v1.IstioReasonComponentsNotReady
+		}
+	}
+
+	// report the namespaces discovered by the control plane and any conflicts with other Istios
+	status.DiscoveredNamespaces = nil
+	status.NamespaceConflicts = nil
+	if usesAutomaticDiscovery(istio) {
+		if claims != nil {
+			status.DiscoveredNamespaces = claims.namespaces
+			status.NamespaceConflicts = claims.conflicts
+			status.SetCondition(determineExclusiveNamespacesCondition(claims.conflicts))
+		} else {
+			// the reconciliation failed before the claims were determined; the error is reported in the Reconciled condition
+			status.SetCondition(v1.IstioCondition{
+				Type:    v1.IstioConditionExclusiveNamespaces,
+				Status:  metav1.ConditionUnknown,
+				Reason:  v1.IstioReasonReconcileError,
+				Message: "cannot determine the discovered namespaces due to reconciliation error",
+			})
+		}
+	} else {
+		removeCondition(&status, v1.IstioConditionExclusiveNamespaces)
+	}
+
 	// count the ready, in-use, and total revisions
 	if revs, err := revision.ListOwned(ctx, r.Client, istio.UID); err == nil {
 		status.Revisions.Total = int32(len(revs))
@@ -276,9 +519,9 @@ func (r *Reconciler) determineStatus(ctx context.Context, istio *v1.Istio, recon
 	return status, errs.Error()
 }
 
-func (r *Reconciler) updateStatus(ctx context.Context, istio *v1.Istio, reconcileErr error) error {
+func (r *Reconciler) updateStatus(ctx context.Context, istio *v1.Istio, claims *namespaceClaims, reconcileErr error) error {
 	var errs errlist.Builder
-	status, err := r.determineStatus(ctx, istio, reconcileErr)
+	status, err := r.determineStatus(ctx, istio, claims, reconcileErr)
 	if err != nil {
 		errs.Add(fmt.Errorf("failed to determine status: %w", err))
 	}
@@ -325,6 +568,8 @@ func convertConditionReason(reason v1.IstioRevisionConditionReason) v1.IstioCond
 		return v1.IstioReasonReconcileError
 	case v1.IstioRevisionReasonRemoteIstiodNotReady:
 		return v1.IstioReasonRemoteIstiodNotReady
+	case v1.IstioRevisionReasonImageVerificationFailed:
+		return v1.IstioReasonImageVerificationFailed
 	default:
 		panic(fmt.Sprintf("can't convert IstioRevisionConditionReason: %s", reason))
 	}
@@ -333,3 +578,20 @@ func convertConditionReason(reason v1.IstioRevisionConditionReason) v1.IstioCond
 func wrapEventHandler(logger logr.Logger, handler handler.EventHandler) handler.EventHandler {
 	return enqueuelogger.WrapIfNecessary(v1.IstioKind, logger, handler)
 }
+
+// mapOperatorConfigChangeToReconcileRequest enqueues the Istios affected by a change of the operator configuration.
+// When only image digests changed, these are the Istios whose version uses one of the changed digests.
+func (r *Reconciler) mapOperatorConfigChangeToReconcileRequest(ctx context.Context, change config.Change) []reconcile.Request {
+	istioList := v1.IstioList{}
+	if err := r.Client.List(ctx, &istioList); err != nil {
+		logf.FromContext(ctx).Error(err, "failed to list Istios")
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, istio := range istioList.Items {
+		if change.Affects(istio.Spec.Version) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&istio)})
+		}
+	}
+	return requests
+}
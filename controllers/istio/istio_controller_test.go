@@ -24,12 +24,19 @@ import (
 	"github.com/google/go-cmp/cmp"
 	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
 	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	"github.com/istio-ecosystem/sail-operator/pkg/namespace"
+	reconcilerpkg "github.com/istio-ecosystem/sail-operator/pkg/reconciler"
 	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
 	"github.com/istio-ecosystem/sail-operator/pkg/test/testtime"
 	"github.com/istio-ecosystem/sail-operator/pkg/test/util/supportedversion"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
@@ -61,7 +68,7 @@ func TestReconcile(t *testing.T) {
 		cl := newFakeClientBuilder().
 			WithObjects(istio).
 			Build()
-		reconciler := NewReconciler(cfg, cl, scheme.Scheme)
+		reconciler := NewReconciler(cfg, cl, scheme.Scheme, nil)
 
 		_, err := reconciler.Reconcile(ctx, istio)
 		if err == nil {
@@ -99,7 +106,7 @@ func TestReconcile(t *testing.T) {
 			Build()
 		cfg := newReconcilerTestConfig(t)
 		cfg.DefaultProfile = "invalid-profile"
-		reconciler := NewReconciler(cfg, cl, scheme.Scheme)
+		reconciler := NewReconciler(cfg, cl, scheme.Scheme, nil)
 
 		_, err := reconciler.Reconcile(ctx, istio)
 		if err == nil {
@@ -139,7 +146,7 @@ func TestReconcile(t *testing.T) {
 				},
 			}).
 			Build()
-		reconciler := NewReconciler(cfg, cl, scheme.Scheme)
+		reconciler := NewReconciler(cfg, cl, scheme.Scheme, nil)
 
 		_, err := reconciler.Reconcile(ctx, istio)
 		if err == nil {
@@ -207,6 +214,25 @@ func TestValidate(t *testing.T) {
 			},
 			expectErr: "spec.namespace not set",
 		},
+		{
+			name: "discovery selectors with automatic policy",
+			istio: &v1.Istio{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "default",
+				},
+				Spec: v1.IstioSpec{
+					Version:                 supportedversion.Default,
+					Namespace:               "istio-system",
+					DiscoverySelectorPolicy: v1.DiscoverySelectorPolicyAutomatic,
+					Values: &v1.Values{
+						MeshConfig: &v1.MeshConfig{
+							DiscoverySelectors: []*metav1.LabelSelector{{MatchLabels: map[string]string{"mesh": "a"}}},
+						},
+					},
+				},
+			},
+			expectErr: "spec.values.meshConfig.discoverySelectors must not be set when spec.discoverySelectorPolicy is Automatic",
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -534,9 +560,9 @@ func TestDetermineStatus(t *testing.T) {
 				WithObjects(initObjs...).
 				WithInterceptorFuncs(interceptorFuncs).
 				Build()
-			reconciler := NewReconciler(cfg, cl, scheme.Scheme)
+			reconciler := NewReconciler(cfg, cl, scheme.Scheme, nil)
 
-			status, err := reconciler.determineStatus(ctx, istio, tc.reconciliationErr)
+			status, err := reconciler.determineStatus(ctx, istio, nil, tc.reconciliationErr)
 			if (err != nil) != tc.wantErr {
 				t.Errorf("determineStatus() error = %v, wantErr %v", err, tc.wantErr)
 			}
@@ -734,9 +760,9 @@ func TestUpdateStatus(t *testing.T) {
 				WithObjects(initObjs...).
 				WithInterceptorFuncs(interceptorFuncs).
 				Build()
-			reconciler := NewReconciler(cfg, cl, scheme.Scheme)
+			reconciler := NewReconciler(cfg, cl, scheme.Scheme, nil)
 
-			err := reconciler.updateStatus(ctx, istio, tc.reconciliationErr)
+			err := reconciler.updateStatus(ctx, istio, nil, tc.reconciliationErr)
 			if (err != nil) != tc.wantErr {
 				t.Errorf("updateStatus() error = %v, wantErr %v", err, tc.wantErr)
 			}
@@ -763,6 +789,124 @@ func toConditionStatus(b bool) metav1.ConditionStatus {
 	return metav1.ConditionFalse
 }
 
+func TestFinalize(t *testing.T) {
+	cfg := newReconcilerTestConfig(t)
+	newIstio := func(spec *v1.NamespaceCreation) *v1.Istio {
+		return &v1.Istio{
+			ObjectMeta: metav1.ObjectMeta{Name: istioName, UID: istioUID},
+			Spec:       v1.IstioSpec{Version: "v1.24.2", Namespace: istioNamespace, CreateNamespace: spec},
+		}
+	}
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        istioNamespace,
+			Annotations: map[string]string{constants.NamespaceCreatedForKey: namespace.Owner(v1.IstioKind, istioName)},
+		},
+	}
+
+	t.Run("keeps namespace that isn't owned", func(t *testing.T) {
+		g := NewWithT(t)
+		istio := newIstio(&v1.NamespaceCreation{})
+		cl := newFakeClientBuilder().WithObjects(istio, ns.DeepCopy()).Build()
+
+		g.Expect(NewReconciler(cfg, cl, scheme.Scheme, nil).Finalize(ctx, istio)).To(Succeed())
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(ns), &corev1.Namespace{})).To(Succeed())
+	})
+
+	t.Run("deletes revisions before namespace", func(t *testing.T) {
+		g := NewWithT(t)
+		istio := newIstio(&v1.NamespaceCreation{Owned: true})
+		rev := &v1.IstioRevision{
+			ObjectMeta: metav1.ObjectMeta{Name: istioName, OwnerReferences: []metav1.OwnerReference{ownerReference(istio)}},
+		}
+		cl := newFakeClientBuilder().WithObjects(istio, rev, ns.DeepCopy()).Build()
+		reconciler := NewReconciler(cfg, cl, scheme.Scheme, nil)
+
+		err := reconciler.Finalize(ctx, istio)
+		g.Expect(reconcilerpkg.IsTransientError(err)).To(BeTrue())
+		g.Expect(apierrors.IsNotFound(cl.Get(ctx, client.ObjectKeyFromObject(rev), &v1.IstioRevision{}))).To(BeTrue())
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(ns), &corev1.Namespace{})).To(Succeed())
+
+		g.Expect(reconciler.Finalize(ctx, istio)).To(Succeed())
+		g.Expect(apierrors.IsNotFound(cl.Get(ctx, client.ObjectKeyFromObject(ns), &corev1.Namespace{}))).To(BeTrue())
+	})
+
+	t.Run("reports workloads that keep the namespace from being deleted", func(t *testing.T) {
+		g := NewWithT(t)
+		istio := newIstio(&v1.NamespaceCreation{Owned: true})
+		app := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: istioNamespace}}
+		cl := newFakeClientBuilder().WithObjects(istio, ns.DeepCopy(), app).Build()
+		reconciler := NewReconciler(cfg, cl, scheme.Scheme, nil)
+		recorder := record.NewFakeRecorder(10)
+		reconciler.EventRecorder = recorder
+
+		g.Expect(reconciler.Finalize(ctx, istio)).To(Succeed())
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(ns), &corev1.Namespace{})).To(Succeed())
+		g.Expect(recorder.Events).To(Receive(Equal("Warning NamespaceNotDeleted namespace " + istioNamespace +
+			" was not deleted, because it contains workloads that weren't installed by the operator: Deployment/app")))
+	})
+
+	newRevisionInUse := func(istio *v1.Istio) *v1.IstioRevision {
+		rev := &v1.IstioRevision{
+			ObjectMeta: metav1.ObjectMeta{Name: istioName, OwnerReferences: []metav1.OwnerReference{ownerReference(istio)}},
+		}
+		rev.Status.SetCondition(v1.IstioRevisionCondition{Type: v1.IstioRevisionConditionInUse, Status: metav1.ConditionTrue})
+		return rev
+	}
+
+	t.Run("blocks deletion while revisions are in use", func(t *testing.T) {
+		g := NewWithT(t)
+		istio := newIstio(nil)
+		cl := newFakeClientBuilder().WithObjects(istio, newRevisionInUse(istio)).Build()
+
+		err := NewReconciler(cfg, cl, scheme.Scheme, nil).Finalize(ctx, istio)
+		g.Expect(reconcilerpkg.IsTransientError(err)).To(BeTrue())
+
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(istio), istio)).To(Succeed())
+		condition := istio.Status.GetCondition(v1.IstioConditionDeletionBlocked)
+		g.Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		g.Expect(condition.Reason).To(Equal(v1.IstioReasonRevisionsInUse))
+		g.Expect(condition.Message).To(ContainSubstring("IstioRevisions my-istio are still in use"))
+	})
+
+	t.Run("propagates force-delete annotation", func(t *testing.T) {
+		g := NewWithT(t)
+		istio := newIstio(nil)
+		istio.Annotations = map[string]string{constants.ForceDeleteKey: "true"}
+		rev := newRevisionInUse(istio)
+		cni := &v1.IstioCNI{
+			ObjectMeta: metav1.ObjectMeta{Name: componentName, OwnerReferences: []metav1.OwnerReference{ownerReference(istio)}},
+		}
+		cl := newFakeClientBuilder().WithObjects(istio, rev, cni).Build()
+
+		g.Expect(NewReconciler(cfg, cl, scheme.Scheme, nil).Finalize(ctx, istio)).To(Succeed())
+
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(rev), rev)).To(Succeed())
+		g.Expect(rev.Annotations).To(HaveKeyWithValue(constants.ForceDeleteKey, "true"))
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(cni), cni)).To(Succeed())
+		g.Expect(cni.Annotations).To(HaveKeyWithValue(constants.ForceDeleteKey, "true"))
+	})
+
+	t.Run("passes orphan deletion policy on to owned resources", func(t *testing.T) {
+		g := NewWithT(t)
+		istio := newIstio(&v1.NamespaceCreation{Owned: true})
+		istio.Spec.DeletionPolicy = v1.DeletionPolicyOrphan
+		rev := newRevisionInUse(istio)
+		ztunnel := &v1.ZTunnel{
+			ObjectMeta: metav1.ObjectMeta{Name: componentName, OwnerReferences: []metav1.OwnerReference{ownerReference(istio)}},
+		}
+		cl := newFakeClientBuilder().WithObjects(istio, rev, ztunnel, ns.DeepCopy()).Build()
+
+		g.Expect(NewReconciler(cfg, cl, scheme.Scheme, nil).Finalize(ctx, istio)).To(Succeed())
+
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(rev), rev)).To(Succeed())
+		g.Expect(rev.Spec.DeletionPolicy).To(Equal(v1.DeletionPolicyOrphan))
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(ztunnel), ztunnel)).To(Succeed())
+		g.Expect(ztunnel.Spec.DeletionPolicy).To(Equal(v1.DeletionPolicyOrphan))
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(ns), &corev1.Namespace{})).To(Succeed())
+	})
+}
+
 func TestGetActiveRevisionName(t *testing.T) {
 	tests := []struct {
 		name                 string
@@ -916,6 +1060,12 @@ func noWrites(t *testing.T) interceptor.Funcs {
 	}
 }
 
+func TestConvertConditionReason(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(convertConditionReason(v1.IstioRevisionReasonImageVerificationFailed)).To(Equal(v1.IstioReasonImageVerificationFailed))
+	g.Expect(convertConditionReason(v1.IstioRevisionReasonReconcileError)).To(Equal(v1.IstioReasonReconcileError))
+}
+
 func newReconcilerTestConfig(t *testing.T) config.ReconcilerConfig {
 	return config.ReconcilerConfig{
 		ResourceDirectory: t.TempDir(),
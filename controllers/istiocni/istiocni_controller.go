@@ -18,8 +18,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path"
 	"reflect"
+	"sort"
+	"strings"
 
 	"github.com/go-logr/logr"
 	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
@@ -28,24 +31,35 @@ import (
 	"github.com/istio-ecosystem/sail-operator/pkg/enqueuelogger"
 	"github.com/istio-ecosystem/sail-operator/pkg/errlist"
 	"github.com/istio-ecosystem/sail-operator/pkg/helm"
+	"github.com/istio-ecosystem/sail-operator/pkg/imageverification"
 	"github.com/istio-ecosystem/sail-operator/pkg/istiovalues"
 	"github.com/istio-ecosystem/sail-operator/pkg/kube"
+	"github.com/istio-ecosystem/sail-operator/pkg/namespace"
 	"github.com/istio-ecosystem/sail-operator/pkg/predicate"
 	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	"github.com/istio-ecosystem/sail-operator/pkg/revision"
 	"github.com/istio-ecosystem/sail-operator/pkg/validation"
+	"helm.sh/helm/v3/pkg/release"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	ctrlpredicate "sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"istio.io/istio/pkg/ptr"
@@ -54,6 +68,16 @@ import (
 const (
 	cniReleaseName = "istio-cni"
 	cniChartName   = "cni"
+
+	// maxNodeGaps is the maximum number of nodes listed in status.daemonSet.nodeGaps
+	maxNodeGaps = 100
+
+	// maxListedPods is the maximum number of pods listed in the DeletionBlocked condition
+	maxListedPods = 5
+
+	// validationInitContainerName is the name of the init container that the sidecar injector adds instead of
+	// istio-init when the CNI plugin is used to configure the traffic redirection
+	validationInitContainerName = "istio-validation"
 )
 
 // Reconciler reconciles an IstioCNI object
@@ -62,14 +86,21 @@ type Reconciler struct {
 	Config       config.ReconcilerConfig
 	Scheme       *runtime.Scheme
 	ChartManager *helm.ChartManager
+	// imageVerifier is shared by all reconciliations, so that its caches outlive a single reconciliation
+	imageVerifier *imageverification.Verifier
+	// EventRecorder is set up by SetupWithManager
+	EventRecorder record.EventRecorder
+	// APIReader is set up by SetupWithManager and lists the workloads in the namespace before it's deleted
+	APIReader client.Reader
 }
 
 func NewReconciler(cfg config.ReconcilerConfig, client client.Client, scheme *runtime.Scheme, chartManager *helm.ChartManager) *Reconciler {
 	return &Reconciler{
-		Config:       cfg,
-		Client:       client,
-		Scheme:       scheme,
-		ChartManager: chartManager,
+		Config:        cfg,
+		Client:        client,
+		Scheme:        scheme,
+		ChartManager:  chartManager,
+		imageVerifier: imageverification.NewVerifier(clock.RealClock{}),
 	}
 }
 
@@ -96,42 +127,186 @@ func NewReconciler(cfg config.ReconcilerConfig, client client.Client, scheme *ru
 func (r *Reconciler) Reconcile(ctx context.Context, cni *v1.IstioCNI) (ctrl.Result, error) {
 	log := logf.FromContext(ctx)
 
-	reconcileErr := r.doReconcile(ctx, cni)
+	result, reconcileErr := r.doReconcile(ctx, cni)
 
 	log.Info("Reconciliation done. Updating status.")
-	statusErr := r.updateStatus(ctx, cni, reconcileErr)
+	statusErr := r.updateStatus(ctx, cni, result, reconcileErr)
 
 	return ctrl.Result{}, errors.Join(reconcileErr, statusErr)
 }
 
+// Finalize uninstalls the CNI plugin, or orphans it if spec.deletionPolicy is Orphan. If ambient or sidecar pods
+// still rely on the plugin, the uninstallation is blocked until they're gone, unless the IstioCNI is annotated with
+// sailoperator.io/force-delete=true.
 func (r *Reconciler) Finalize(ctx context.Context, cni *v1.IstioCNI) error {
-	return r.uninstallHelmChart(ctx, cni)
+	if cni.Spec.DeletionPolicy == v1.DeletionPolicyOrphan {
+		if err := r.ChartManager.OrphanRelease(ctx, cniReleaseName, cni.Spec.Namespace, cni.UID); err != nil {
+			return fmt.Errorf("failed to orphan Helm chart %q: %w", cniChartName, err)
+		}
+		return nil
+	}
+	if !kube.IsForceDeleteRequested(cni) {
+		pods, err := r.listPodsUsingCNI(ctx)
+		if err != nil {
+			return err
+		}
+		if len(pods) > 0 {
+			return r.blockDeletion(ctx, cni, pods)
+		}
+	}
+	if err := r.uninstallHelmChart(ctx, cni); err != nil {
+		return err
+	}
+	workloads, err := namespace.Delete(ctx, r.Client, r.APIReader, cni.Spec.Namespace, cni.Spec.CreateNamespace, namespace.Owner(v1.IstioCNIKind, cni.Name))
+	namespace.RecordNotDeleted(r.EventRecorder, cni, cni.Spec.Namespace, workloads)
+	return err
 }
 
-func (r *Reconciler) doReconcile(ctx context.Context, cni *v1.IstioCNI) error {
+// blockDeletion sets the DeletionBlocked condition and returns a TransientError, so that the finalization is retried.
+func (r *Reconciler) blockDeletion(ctx context.Context, cni *v1.IstioCNI, pods []string) error {
+	listed := pods
+	if len(listed) > maxListedPods {
+		listed = listed[:maxListedPods]
+	}
+	status := *cni.Status.DeepCopy()
+	status.SetCondition(v1.IstioCNICondition{
+		Type:   v1.IstioCNIConditionDeletionBlocked,
+		Status: metav1.ConditionTrue,
+		Reason: v1.IstioCNIReasonReferencedByWorkloads,
+		Message: fmt.Sprintf("%d pods rely on the CNI plugin (e.g. %s); remove them or annotate the IstioCNI with %s=true",
+			len(pods), strings.Join(listed, ", "), constants.ForceDeleteKey),
+	})
+	if !reflect.DeepEqual(cni.Status, status) {
+		if err := r.Client.Status().Patch(ctx, cni, kube.NewStatusPatch(status)); err != nil {
+			return fmt.Errorf("failed to patch status: %w", err)
+		}
+	}
+	return reconciler.NewTransientError(fmt.Sprintf("%d pods rely on the CNI plugin", len(pods)))
+}
+
+// listPodsUsingCNI returns the pods whose traffic redirection is configured by the CNI plugin.
+func (r *Reconciler) listPodsUsingCNI(ctx context.Context) ([]string, error) {
+	podList := corev1.PodList{}
+	if err := r.Client.List(ctx, &podList); err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	var pods []string
+	for _, pod := range podList.Items {
+		if podUsesCNI(&pod) {
+			pods = append(pods, client.ObjectKeyFromObject(&pod).String())
+		}
+	}
+	return pods, nil
+}
+
+// podUsesCNI returns true if the pod is part of the ambient mesh or if it has a sidecar whose traffic redirection
+// was configured by the CNI plugin instead of the istio-init container.
+func podUsesCNI(pod *corev1.Pod) bool {
+	if pod.Annotations[constants.IstioAmbientRedirectionAnnotation] == "enabled" {
+		return true
+	}
+	for _, c := range pod.Spec.InitContainers {
+		if c.Name == validationInitContainerName {
+			return true
+		}
+	}
+	return false
+}
+
+// reconcileResult describes the outcome of doReconcile, so that the status reports what was actually installed.
+type reconcileResult struct {
+	// version is the Istio version whose chart was installed, or an empty string if no chart was installed
+	version string
+	// pending describes what the upgrade to the version of the Istio referenced in spec.versionFrom waits for
+	pending string
+}
+
+func (r *Reconciler) doReconcile(ctx context.Context, cni *v1.IstioCNI) (reconcileResult, error) {
 	log := logf.FromContext(ctx)
+	if err := r.reconcileNamespace(ctx, cni); err != nil {
+		return reconcileResult{}, err
+	}
 	if err := r.validate(ctx, cni); err != nil {
-		return err
+		return reconcileResult{}, err
+	}
+	if err := r.adoptHelmRelease(ctx, cni); err != nil {
+		return reconcileResult{}, err
+	}
+
+	version, pending, err := r.determineVersion(ctx, cni)
+	if err != nil {
+		return reconcileResult{}, err
 	}
+	if version == "" {
+		log.Info("Waiting for control plane before installing Helm chart", "reason", pending)
+		return reconcileResult{pending: pending}, nil
+	}
+	if err := r.validateVersionSkew(ctx, cni, version); err != nil {
+		return reconcileResult{pending: pending}, err
+	}
+
+	log.Info("Installing Helm chart", "version", version)
+	if err := r.installHelmChart(ctx, cni, version); err != nil {
+		return reconcileResult{pending: pending}, err
+	}
+	return reconcileResult{version: version, pending: pending}, nil
+}
 
-	log.Info("Installing Helm chart")
-	return r.installHelmChart(ctx, cni)
+// reconcileNamespace creates the target namespace if spec.createNamespace is set.
+func (r *Reconciler) reconcileNamespace(ctx context.Context, cni *v1.IstioCNI) error {
+	if cni.Spec.CreateNamespace == nil || cni.Spec.Namespace == "" {
+		return nil
+	}
+	return namespace.Reconcile(ctx, r.Client, cni.Spec.Namespace, cni.Spec.CreateNamespace, namespace.Owner(v1.IstioCNIKind, cni.Name))
 }
 
 func (r *Reconciler) validate(ctx context.Context, cni *v1.IstioCNI) error {
-	if cni.Spec.Version == "" {
+	if cni.Spec.Version == "" && cni.Spec.VersionFrom == nil {
 		return reconciler.NewValidationError("spec.version not set")
 	}
 	if cni.Spec.Namespace == "" {
 		return reconciler.NewValidationError("spec.namespace not set")
 	}
-	if err := validation.ValidateTargetNamespace(ctx, r.Client, cni.Spec.Namespace); err != nil {
+	if err := validation.ValidateTargetNamespace(ctx, r.Client, r.Config.OperatorConfig.Get(), cni.Spec.Namespace); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *Reconciler) installHelmChart(ctx context.Context, cni *v1.IstioCNI) error {
+// determineVersion returns the Istio version to install. If spec.versionFrom is set, the version of the referenced
+// Istio is returned once its control plane has been upgraded. Until then, the previously installed version is returned
+// (or an empty string if IstioCNI hasn't been installed yet), along with a message describing what the upgrade waits for.
+func (r *Reconciler) determineVersion(ctx context.Context, cni *v1.IstioCNI) (version string, pending string, err error) {
+	if cni.Spec.VersionFrom == nil {
+		return cni.Spec.Version, "", nil
+	}
+	version, pending, err = revision.GetControlPlaneVersion(ctx, r.Client, cni.Spec.VersionFrom.Istio)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to determine version of Istio %q: %w", cni.Spec.VersionFrom.Istio, err)
+	}
+	if pending != "" {
+		return cni.Status.Version, pending, nil
+	}
+	return version, "", nil
+}
+
+// validateVersionSkew refuses to install or upgrade to a version that isn't supported by all in-use IstioRevisions,
+// if spec.versionSkewPolicy is Block. Reconciling the installed version is always allowed.
+func (r *Reconciler) validateVersionSkew(ctx context.Context, cni *v1.IstioCNI, version string) error {
+	if cni.Spec.VersionSkewPolicy != v1.VersionSkewPolicyBlock || version == cni.Status.Version {
+		return nil
+	}
+	revisions, err := revision.ListUnsupportedSkew(ctx, r.Client, version)
+	if err != nil {
+		return fmt.Errorf("failed to list IstioRevisions: %w", err)
+	}
+	if len(revisions) > 0 {
+		return revision.NewUnsupportedVersionSkewError(revision.DescribeUnsupportedSkew(v1.IstioCNIKind, version, revisions))
+	}
+	return nil
+}
+
+func (r *Reconciler) installHelmChart(ctx context.Context, cni *v1.IstioCNI, version string) error {
 	ownerReference := metav1.OwnerReference{
 		APIVersion:         v1.GroupVersion.String(),
 		Kind:               v1.IstioCNIKind,
@@ -144,29 +319,74 @@ func (r *Reconciler) installHelmChart(ctx context.Context, cni *v1.IstioCNI) err
 	// get userValues from Istio.spec.values
 	userValues := cni.Spec.Values
 
+	operatorConfig := r.Config.OperatorConfig.Get()
+
 	// apply image digests from configuration, if not already set by user
-	userValues = applyImageDigests(cni, userValues, config.Config)
+	userValues = applyImageDigests(version, userValues, operatorConfig)
 
 	// apply userValues on top of defaultValues from profiles
 	mergedHelmValues, err := istiovalues.ApplyProfilesAndPlatform(
-		r.Config.ResourceDirectory, cni.Spec.Version, r.Config.Platform, r.Config.DefaultProfile, cni.Spec.Profile, helm.FromValues(userValues))
+		r.Config.ResourceDirectory, version, r.Config.ActivePlatform(), r.Config.ActiveDefaultProfile(), cni.Spec.Profile, helm.FromValues(userValues))
 	if err != nil {
 		return fmt.Errorf("failed to apply profile: %w", err)
 	}
 
-	_, err = r.ChartManager.UpgradeOrInstallChart(ctx, r.getChartDir(cni), mergedHelmValues, cni.Spec.Namespace, cniReleaseName, ownerReference)
+	_, err = r.ChartManager.UpgradeOrInstallChart(ctx, r.getChartDir(version), mergedHelmValues, cni.Spec.Namespace, cniReleaseName, ownerReference,
+		helm.NewRegistryRewritePostRenderer(operatorConfig.RegistryRewrites),
+		helm.NewImageVerificationPostRenderer(ctx, r.imageVerifier, operatorConfig.ImageVerification))
 	if err != nil {
 		return fmt.Errorf("failed to install/update Helm chart %q: %w", cniChartName, err)
 	}
 	return nil
 }
 
-func (r *Reconciler) getChartDir(cni *v1.IstioCNI) string {
-	return path.Join(r.Config.ResourceDirectory, cni.Spec.Version, "charts", cniChartName)
+func (r *Reconciler) getChartDir(version string) string {
+	return path.Join(r.Config.ResourceDirectory, version, "charts", cniChartName)
+}
+
+// adoptHelmRelease takes over the istio-cni release named in the sailoperator.io/adopt-helm-release annotation.
+// The version and values of the release are copied into the spec before the release is renamed to istio-cni.
+func (r *Reconciler) adoptHelmRelease(ctx context.Context, cni *v1.IstioCNI) error {
+	return r.ChartManager.AdoptRelease(ctx, r.Client, r.Config.OperatorConfig.Get(), cni, cni.Spec.Namespace, cniReleaseName, func(rel *release.Release) error {
+		spec, err := cniSpecFromRelease(cni.Spec, rel)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(r.getChartDir(spec.Version)); err != nil {
+			return reconciler.NewValidationError(fmt.Sprintf("Helm release %q uses Istio version %s, which is not supported", rel.Name, spec.Version))
+		}
+
+		patch := client.MergeFrom(cni.DeepCopy())
+		cni.Spec = spec
+		if err := r.Client.Patch(ctx, cni, patch); err != nil {
+			return fmt.Errorf("failed to copy the values of Helm release %q into the IstioCNI spec: %w", rel.Name, err)
+		}
+		return nil
+	})
+}
+
+// cniSpecFromRelease returns the spec with the version of the release and, if the spec doesn't contain values yet,
+// the values the release was installed with. The istio-cni chart and the IstioCNI resource structure their values
+// the same way, so the values are copied as they are.
+func cniSpecFromRelease(spec v1.IstioCNISpec, rel *release.Release) (v1.IstioCNISpec, error) {
+	version := helm.ReleaseVersion(rel)
+	if version == "" {
+		return spec, reconciler.NewValidationError(fmt.Sprintf("cannot determine Istio version of Helm release %q", rel.Name))
+	}
+	spec.Version = version
+
+	if spec.Values == nil && len(rel.Config) > 0 {
+		values, err := helm.ToValues(helm.Values(rel.Config), &v1.CNIValues{})
+		if err != nil {
+			return spec, fmt.Errorf("failed to convert values of Helm release %q: %w", rel.Name, err)
+		}
+		spec.Values = values
+	}
+	return spec, nil
 }
 
-func applyImageDigests(cni *v1.IstioCNI, values *v1.CNIValues, config config.OperatorConfig) *v1.CNIValues {
-	imageDigests, digestsDefined := config.ImageDigests[cni.Spec.Version]
+func applyImageDigests(version string, values *v1.CNIValues, config config.OperatorConfig) *v1.CNIValues {
+	imageDigests, digestsDefined := config.ImageDigests[version]
 	// if we don't have default image digests defined for this version, it's a no-op
 	if !digestsDefined {
 		return values
@@ -197,6 +417,8 @@ func (r *Reconciler) uninstallHelmChart(ctx context.Context, cni *v1.IstioCNI) e
 // SetupWithManager sets up the controller with the Manager.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	logger := mgr.GetLogger().WithName("ctrlr").WithName("istiocni")
+	r.EventRecorder = mgr.GetEventRecorderFor("sail-operator")
+	r.APIReader = mgr.GetAPIReader()
 
 	// mainObjectHandler handles the IstioCNI watch events
 	mainObjectHandler := wrapEventHandler(logger, &handler.EnqueueRequestForObject{})
@@ -207,6 +429,15 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 
 	namespaceHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapNamespaceToReconcileRequest))
 
+	// controlPlaneHandler handles Istio and IstioRevision events, which affect the version skew and the tracked version
+	controlPlaneHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapControlPlaneToReconcileRequest))
+
+	// podHandler handles the deletion of pods that use the CNI plugin, which may unblock the deletion of an IstioCNI
+	podHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapPodToReconcileRequest))
+
+	// nodeHandler handles node events that may change which nodes the istio-cni-node DaemonSet can run on
+	nodeHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapNodeToReconcileRequest))
+
 	return ctrl.NewControllerManagedBy(mgr).
 		WithOptions(controller.Options{
 			LogConstructor: func(req *reconcile.Request) logr.Logger {
@@ -239,29 +470,51 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 		// cluster-scoped resources
 		// +lint-watches:ignore: Namespace (not present in charts, but must be watched to reconcile IstioCni when its namespace is created)
 		Watches(&corev1.Namespace{}, namespaceHandler).
+		// +lint-watches:ignore: Istio (not present in charts, but must be watched to track the control plane version)
+		Watches(&v1.Istio{}, controlPlaneHandler).
+		// +lint-watches:ignore: IstioRevision (not present in charts, but must be watched to validate the version skew)
+		Watches(&v1.IstioRevision{}, controlPlaneHandler).
+		// +lint-watches:ignore: Pod (not present in charts, but must be watched to unblock the deletion of IstioCNI when pods using it are deleted)
+		Watches(&corev1.Pod{}, podHandler, builder.WithPredicates(podUsingCNIDeleted())).
+		// +lint-watches:ignore: Node (not present in charts, but must be watched to keep status.daemonSet.nodeGaps up to date)
+		Watches(&corev1.Node{}, nodeHandler, builder.WithPredicates(nodeSchedulingChanged())).
 		Watches(&rbacv1.ClusterRole{}, ownedResourceHandler).
 		Watches(&rbacv1.ClusterRoleBinding{}, ownedResourceHandler).
+
+		// changes of the image digests or of other operator configuration affect the rendered values
+		WatchesRawSource(r.Config.OperatorConfig.WatchSource(r.mapOperatorConfigChangeToReconcileRequest)).
 		Complete(reconciler.NewStandardReconcilerWithFinalizer[*v1.IstioCNI](r.Client, r.Reconcile, r.Finalize, constants.FinalizerName))
 }
 
-func (r *Reconciler) determineStatus(ctx context.Context, cni *v1.IstioCNI, reconcileErr error) (v1.IstioCNIStatus, error) {
+func (r *Reconciler) determineStatus(ctx context.Context, cni *v1.IstioCNI, result reconcileResult, reconcileErr error) (v1.IstioCNIStatus, error) {
 	var errs errlist.Builder
-	reconciledCondition := r.determineReconciledCondition(reconcileErr)
+	reconciledCondition := r.determineReconciledCondition(reconcileErr, result.pending)
 	readyCondition, err := r.determineReadyCondition(ctx, cni)
 	errs.Add(err)
+	daemonSetStatus, err := r.determineDaemonSetStatus(ctx, cni)
+	errs.Add(err)
 
 	status := *cni.Status.DeepCopy()
 	status.ObservedGeneration = cni.Generation
+	if reconcileErr == nil && result.version != "" {
+		status.Version = result.version
+	}
 	status.SetCondition(reconciledCondition)
 	status.SetCondition(readyCondition)
+	if err == nil {
+		status.DaemonSet = daemonSetStatus
+	}
+	versionSkewCondition, err := r.determineVersionSkewCondition(ctx, status.Version)
+	errs.Add(err)
+	status.SetCondition(versionSkewCondition)
 	status.State = deriveState(reconciledCondition, readyCondition)
 	return status, errs.Error()
 }
 
-func (r *Reconciler) updateStatus(ctx context.Context, cni *v1.IstioCNI, reconcileErr error) error {
+func (r *Reconciler) updateStatus(ctx context.Context, cni *v1.IstioCNI, result reconcileResult, reconcileErr error) error {
 	var errs errlist.Builder
 
-	status, err := r.determineStatus(ctx, cni, reconcileErr)
+	status, err := r.determineStatus(ctx, cni, result, reconcileErr)
 	if err != nil {
 		errs.Add(fmt.Errorf("failed to determine status: %w", err))
 	}
@@ -283,11 +536,23 @@ func deriveState(reconciledCondition, readyCondition v1.IstioCNICondition) v1.Is
 	return v1.IstioCNIReasonHealthy
 }
 
-func (r *Reconciler) determineReconciledCondition(err error) v1.IstioCNICondition {
+func (r *Reconciler) determineReconciledCondition(err error, pending string) v1.IstioCNICondition {
 	c := v1.IstioCNICondition{Type: v1.IstioCNIConditionReconciled}
 
-	if err == nil {
+	if err == nil && pending == "" {
 		c.Status = metav1.ConditionTrue
+	} else if err == nil {
+		c.Status = metav1.ConditionFalse
+		c.Reason = v1.IstioCNIReasonWaitingForControlPlane
+		c.Message = pending
+	} else if revision.IsUnsupportedVersionSkewError(err) {
+		c.Status = metav1.ConditionFalse
+		c.Reason = v1.IstioCNIReasonUnsupportedVersionSkew
+		c.Message = err.Error()
+	} else if imageverification.IsVerificationError(err) {
+		c.Status = metav1.ConditionFalse
+		c.Reason = v1.IstioCNIReasonImageVerificationFailed
+		c.Message = err.Error()
 	} else {
 		c.Status = metav1.ConditionFalse
 		c.Reason = v1.IstioCNIReasonReconcileError
@@ -296,6 +561,31 @@ func (r *Reconciler) determineReconciledCondition(err error) v1.IstioCNIConditio
 	return c
 }
 
+// determineVersionSkewCondition checks whether the installed version is supported by all in-use IstioRevisions.
+func (r *Reconciler) determineVersionSkewCondition(ctx context.Context, version string) (v1.IstioCNICondition, error) {
+	c := v1.IstioCNICondition{Type: v1.IstioCNIConditionSupportedVersionSkew}
+	if version == "" {
+		c.Status = metav1.ConditionUnknown
+		c.Message = "IstioCNI has not been installed yet"
+		return c, nil
+	}
+
+	revisions, err := revision.ListUnsupportedSkew(ctx, r.Client, version)
+	if err != nil {
+		c.Status = metav1.ConditionUnknown
+		c.Message = fmt.Sprintf("failed to check version skew: %v", err)
+		return c, fmt.Errorf("failed to list IstioRevisions: %w", err)
+	}
+	if len(revisions) > 0 {
+		c.Status = metav1.ConditionFalse
+		c.Reason = v1.IstioCNIReasonUnsupportedVersionSkew
+		c.Message = revision.DescribeUnsupportedSkew(v1.IstioCNIKind, version, revisions)
+	} else {
+		c.Status = metav1.ConditionTrue
+	}
+	return c, nil
+}
+
 func (r *Reconciler) determineReadyCondition(ctx context.Context, cni *v1.IstioCNI) (v1.IstioCNICondition, error) {
 	c := v1.IstioCNICondition{
 		Type:   v1.IstioCNIConditionReady,
@@ -307,6 +597,10 @@ func (r *Reconciler) determineReadyCondition(ctx context.Context, cni *v1.IstioC
 		if ds.Status.CurrentNumberScheduled == 0 {
 			c.Reason = v1.IstioCNIDaemonSetNotReady
 			c.Message = "no istio-cni-node pods are currently scheduled"
+		} else if ds.Status.ObservedGeneration < ds.Generation || ds.Status.UpdatedNumberScheduled < ds.Status.DesiredNumberScheduled {
+			c.Reason = v1.IstioCNIReasonUpgrading
+			c.Message = fmt.Sprintf("%d of %d istio-cni-node pods have been updated",
+				ds.Status.UpdatedNumberScheduled, ds.Status.DesiredNumberScheduled)
 		} else if ds.Status.NumberReady < ds.Status.CurrentNumberScheduled {
 			c.Reason = v1.IstioCNIDaemonSetNotReady
 			c.Message = "not all istio-cni-node pods are ready"
@@ -325,6 +619,131 @@ func (r *Reconciler) determineReadyCondition(ctx context.Context, cni *v1.IstioC
 	return c, nil
 }
 
+// determineDaemonSetStatus reports the rollout of the istio-cni-node DaemonSet, the versions of the running
+// istio-cni-node pods, and the nodes that don't run a ready istio-cni-node pod. It returns nil if the
+// DaemonSet doesn't exist.
+func (r *Reconciler) determineDaemonSetStatus(ctx context.Context, cni *v1.IstioCNI) (*v1.IstioCNIDaemonSetStatus, error) {
+	ds := appsv1.DaemonSet{}
+	if err := r.Client.Get(ctx, r.cniDaemonSetKey(cni), &ds); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get DaemonSet: %w", err)
+	}
+
+	status := &v1.IstioCNIDaemonSetStatus{
+		DesiredNumberScheduled: ds.Status.DesiredNumberScheduled,
+		UpdatedNumberScheduled: ds.Status.UpdatedNumberScheduled,
+		NumberReady:            ds.Status.NumberReady,
+		NumberUnavailable:      ds.Status.NumberUnavailable,
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(ds.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DaemonSet selector: %w", err)
+	}
+	podList := corev1.PodList{}
+	if err := r.Client.List(ctx, &podList, client.InNamespace(ds.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("failed to list istio-cni-node pods: %w", err)
+	}
+	podsByNode := map[string]*corev1.Pod{}
+	versions := sets.New[string]()
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		if pod.Spec.NodeName != "" {
+			podsByNode[pod.Spec.NodeName] = pod
+		}
+		if v := pod.Labels[constants.KubernetesAppVersionKey]; v != "" {
+			versions.Insert(v)
+		}
+	}
+	status.Versions = sets.List(versions)
+
+	nodeList := corev1.NodeList{}
+	if err := r.Client.List(ctx, &nodeList); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	sort.Slice(nodeList.Items, func(i, j int) bool { return nodeList.Items[i].Name < nodeList.Items[j].Name })
+	for i := range nodeList.Items {
+		if len(status.NodeGaps) >= maxNodeGaps {
+			break
+		}
+		if gap := determineNodeGap(&nodeList.Items[i], &ds.Spec.Template.Spec, podsByNode[nodeList.Items[i].Name]); gap != nil {
+			status.NodeGaps = append(status.NodeGaps, *gap)
+		}
+	}
+	return status, nil
+}
+
+// determineNodeGap returns the reason why the node doesn't run a ready istio-cni-node pod, or nil if it does.
+func determineNodeGap(node *corev1.Node, podSpec *corev1.PodSpec, pod *corev1.Pod) *v1.IstioCNINodeGap {
+	if pod != nil {
+		if isPodReady(pod) {
+			return nil
+		}
+		return &v1.IstioCNINodeGap{
+			Node:    node.Name,
+			Reason:  v1.IstioCNINodeGapPodNotReady,
+			Message: fmt.Sprintf("pod %s is not ready", pod.Name),
+		}
+	}
+
+	if !labels.SelectorFromSet(podSpec.NodeSelector).Matches(labels.Set(node.Labels)) {
+		return &v1.IstioCNINodeGap{
+			Node:    node.Name,
+			Reason:  v1.IstioCNINodeGapNodeSelectorMismatch,
+			Message: "node labels don't match the DaemonSet's nodeSelector",
+		}
+	}
+	if affinity := podSpec.Affinity; affinity != nil && affinity.NodeAffinity != nil &&
+		!requiredNodeAffinityMatches(affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution, node) {
+		return &v1.IstioCNINodeGap{
+			Node:    node.Name,
+			Reason:  v1.IstioCNINodeGapNodeAffinityMismatch,
+			Message: "node doesn't match the DaemonSet's required node affinity",
+		}
+	}
+	if taint, found := corev1helpers.FindMatchingUntoleratedTaint(node.Spec.Taints, podSpec.Tolerations, isSchedulingTaint); found {
+		return &v1.IstioCNINodeGap{
+			Node:    node.Name,
+			Reason:  v1.IstioCNINodeGapTaintNotTolerated,
+			Message: fmt.Sprintf("DaemonSet doesn't tolerate taint %s", taint.ToString()),
+		}
+	}
+	return &v1.IstioCNINodeGap{
+		Node:    node.Name,
+		Reason:  v1.IstioCNINodeGapPodMissing,
+		Message: "no istio-cni-node pod is running on the node",
+	}
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// isSchedulingTaint returns true for the taints that prevent the DaemonSet controller from scheduling a pod on the node.
+func isSchedulingTaint(taint *corev1.Taint) bool {
+	return taint.Effect == corev1.TaintEffectNoSchedule || taint.Effect == corev1.TaintEffectNoExecute
+}
+
+// requiredNodeAffinityMatches returns true if the node matches any of the selector's terms. A nil selector matches all nodes.
+func requiredNodeAffinityMatches(nodeSelector *corev1.NodeSelector, node *corev1.Node) bool {
+	if nodeSelector == nil {
+		return true
+	}
+	// like the scheduler, we treat invalid terms as not matching
+	matches, _ := corev1helpers.MatchNodeSelectorTerms(node, nodeSelector)
+	return matches
+}
+
 func (r *Reconciler) cniDaemonSetKey(cni *v1.IstioCNI) client.ObjectKey {
 	return client.ObjectKey{
 		Namespace: cni.Spec.Namespace,
@@ -351,6 +770,104 @@ func (r *Reconciler) mapNamespaceToReconcileRequest(ctx context.Context, ns clie
 	return requests
 }
 
+// mapOperatorConfigChangeToReconcileRequest enqueues the IstioCNIs affected by a change of the operator configuration.
+// When only image digests changed, these are the IstioCNIs whose installed version uses one of the changed digests.
+func (r *Reconciler) mapOperatorConfigChangeToReconcileRequest(ctx context.Context, change config.Change) []reconcile.Request {
+	log := logf.FromContext(ctx)
+
+	cniList := v1.IstioCNIList{}
+	if err := r.Client.List(ctx, &cniList); err != nil {
+		log.Error(err, "failed to list IstioCNIs")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, cni := range cniList.Items {
+		if change.Affects(cni.Spec.Version, cni.Status.Version) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: cni.Name}})
+		}
+	}
+	return requests
+}
+
+// mapControlPlaneToReconcileRequest enqueues all IstioCNIs, since any change to an Istio or IstioRevision
+// may affect the version skew or the version tracked through spec.versionFrom.
+func (r *Reconciler) mapControlPlaneToReconcileRequest(ctx context.Context, _ client.Object) []reconcile.Request {
+	log := logf.FromContext(ctx)
+
+	cniList := v1.IstioCNIList{}
+	if err := r.Client.List(ctx, &cniList); err != nil {
+		log.Error(err, "failed to list IstioCNIs")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(cniList.Items))
+	for _, cni := range cniList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: cni.Name}})
+	}
+	return requests
+}
+
+// mapPodToReconcileRequest enqueues the IstioCNIs that are being deleted, since their deletion may have been
+// blocked by the pod.
+func (r *Reconciler) mapPodToReconcileRequest(ctx context.Context, _ client.Object) []reconcile.Request {
+	log := logf.FromContext(ctx)
+
+	cniList := v1.IstioCNIList{}
+	if err := r.Client.List(ctx, &cniList); err != nil {
+		log.Error(err, "failed to list IstioCNIs")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, cni := range cniList.Items {
+		if cni.DeletionTimestamp != nil {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: cni.Name}})
+		}
+	}
+	return requests
+}
+
+// podUsingCNIDeleted returns a predicate that only accepts the deletion of pods that use the CNI plugin.
+func podUsingCNIDeleted() ctrlpredicate.Funcs {
+	return ctrlpredicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return false },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return false },
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			pod, ok := e.Object.(*corev1.Pod)
+			return ok && podUsesCNI(pod)
+		},
+	}
+}
+
+// mapNodeToReconcileRequest enqueues all IstioCNIs, since a node change may open or close a gap in their DaemonSet.
+func (r *Reconciler) mapNodeToReconcileRequest(ctx context.Context, node client.Object) []reconcile.Request {
+	return r.mapControlPlaneToReconcileRequest(ctx, node)
+}
+
+// nodeSchedulingChanged passes node creations and deletions, and updates that change the node's labels or
+// taints, which are the only node properties that determineNodeGap looks at.
+func nodeSchedulingChanged() ctrlpredicate.Funcs {
+	return ctrlpredicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return true },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return true },
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldNode, ok := e.ObjectOld.(*corev1.Node)
+			if !ok {
+				return false
+			}
+			newNode, ok := e.ObjectNew.(*corev1.Node)
+			if !ok {
+				return false
+			}
+			return !reflect.DeepEqual(oldNode.Labels, newNode.Labels) ||
+				!reflect.DeepEqual(oldNode.Spec.Taints, newNode.Spec.Taints)
+		},
+	}
+}
+
 func wrapEventHandler(logger logr.Logger, handler handler.EventHandler) handler.EventHandler {
 	return enqueuelogger.WrapIfNecessary(v1.IstioCNIKind, logger, handler)
 }
@@ -22,15 +22,22 @@ import (
 	"github.com/google/go-cmp/cmp"
 	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
 	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	"github.com/istio-ecosystem/sail-operator/pkg/imageverification"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
 	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
+	"github.com/istio-ecosystem/sail-operator/pkg/test/testtime"
 	"github.com/istio-ecosystem/sail-operator/pkg/test/util/supportedversion"
 	. "github.com/onsi/gomega"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 
 	"istio.io/istio/pkg/ptr"
 )
@@ -77,6 +84,20 @@ func TestValidate(t *testing.T) {
 			objects:   []client.Object{ns},
 			expectErr: "spec.version not set",
 		},
+		{
+			name: "no version, but tracking Istio version",
+			cni: &v1.IstioCNI{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "default",
+				},
+				Spec: v1.IstioCNISpec{
+					Namespace:   "istio-cni",
+					VersionFrom: &v1.VersionSource{Istio: "default"},
+				},
+			},
+			objects:   []client.Object{ns},
+			expectErr: "",
+		},
 		{
 			name: "no namespace",
 			cni: &v1.IstioCNI{
@@ -229,6 +250,54 @@ func TestDetermineReadyCondition(t *testing.T) {
 				Message: "not all istio-cni-node pods are ready",
 			},
 		},
+		{
+			name: "CNI upgrading",
+			clientObjects: []client.Object{
+				&appsv1.DaemonSet{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "istio-cni-node",
+						Namespace: "istio-cni",
+					},
+					Status: appsv1.DaemonSetStatus{
+						CurrentNumberScheduled: 4,
+						DesiredNumberScheduled: 4,
+						UpdatedNumberScheduled: 2,
+						NumberReady:            4,
+					},
+				},
+			},
+			expected: v1.IstioCNICondition{
+				Type:    v1.IstioCNIConditionReady,
+				Status:  metav1.ConditionFalse,
+				Reason:  v1.IstioCNIReasonUpgrading,
+				Message: "2 of 4 istio-cni-node pods have been updated",
+			},
+		},
+		{
+			name: "CNI DaemonSet update not yet observed",
+			clientObjects: []client.Object{
+				&appsv1.DaemonSet{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:       "istio-cni-node",
+						Namespace:  "istio-cni",
+						Generation: 2,
+					},
+					Status: appsv1.DaemonSetStatus{
+						ObservedGeneration:     1,
+						CurrentNumberScheduled: 4,
+						DesiredNumberScheduled: 4,
+						UpdatedNumberScheduled: 4,
+						NumberReady:            4,
+					},
+				},
+			},
+			expected: v1.IstioCNICondition{
+				Type:    v1.IstioCNIConditionReady,
+				Status:  metav1.ConditionFalse,
+				Reason:  v1.IstioCNIReasonUpgrading,
+				Message: "4 of 4 istio-cni-node pods have been updated",
+			},
+		},
 		{
 			name: "CNI pods not scheduled",
 			clientObjects: []client.Object{
@@ -440,7 +509,7 @@ func TestApplyImageDigests(t *testing.T) {
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result := applyImageDigests(tc.input, tc.input.Spec.Values, tc.config)
+			result := applyImageDigests(tc.input.Spec.Version, tc.input.Spec.Values, tc.config)
 			if diff := cmp.Diff(tc.expectValues, result); diff != "" {
 				t.Errorf("unexpected merge result; diff (-expected, +actual):\n%v", diff)
 			}
@@ -448,20 +517,199 @@ func TestApplyImageDigests(t *testing.T) {
 	}
 }
 
+func TestDetermineDaemonSetStatus(t *testing.T) {
+	g := NewWithT(t)
+	cfg := newReconcilerTestConfig(t)
+
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "istio-cni-node",
+			Namespace: "istio-cni",
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"k8s-app": "istio-cni-node"}},
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					NodeSelector: map[string]string{"kubernetes.io/os": "linux"},
+					Affinity: &corev1.Affinity{
+						NodeAffinity: &corev1.NodeAffinity{
+							RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+								NodeSelectorTerms: []corev1.NodeSelectorTerm{
+									{
+										MatchExpressions: []corev1.NodeSelectorRequirement{
+											{Key: "cni", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"disabled"}},
+										},
+									},
+									{
+										MatchFields: []corev1.NodeSelectorRequirement{
+											{Key: "metadata.name", Operator: corev1.NodeSelectorOpIn, Values: []string{"opted-in-by-name"}},
+										},
+									},
+								},
+							},
+						},
+					},
+					Tolerations: []corev1.Toleration{
+						{Key: "CriticalAddonsOnly", Operator: corev1.TolerationOpExists},
+					},
+				},
+			},
+		},
+		Status: appsv1.DaemonSetStatus{
+			DesiredNumberScheduled: 4,
+			UpdatedNumberScheduled: 3,
+			NumberReady:            2,
+			NumberUnavailable:      2,
+		},
+	}
+
+	linux := map[string]string{"kubernetes.io/os": "linux"}
+	newNode := func(name string, labels map[string]string, taints ...corev1.Taint) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Spec:       corev1.NodeSpec{Taints: taints},
+		}
+	}
+	newPod := func(name, node, version string, ready bool) *corev1.Pod {
+		status := corev1.ConditionFalse
+		if ready {
+			status = corev1.ConditionTrue
+		}
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "istio-cni",
+				Labels:    map[string]string{"k8s-app": "istio-cni-node", "app.kubernetes.io/version": version},
+			},
+			Spec:   corev1.PodSpec{NodeName: node},
+			Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}}},
+		}
+	}
+
+	// pods in other namespaces are ignored, even if they match the DaemonSet's selector
+	podInOtherNamespace := newPod("cni-other-namespace", "node-d", "1.24.2", true)
+	podInOtherNamespace.Namespace = "other"
+
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(
+			ds,
+			newNode("node-a", linux),
+			newNode("node-b", linux),
+			newNode("node-c", linux),
+			newNode("node-d", linux),
+			newNode("windows", map[string]string{"kubernetes.io/os": "windows"}),
+			newNode("opted-out", map[string]string{"kubernetes.io/os": "linux", "cni": "disabled"}),
+			newNode("opted-in-by-name", map[string]string{"kubernetes.io/os": "linux", "cni": "disabled"}),
+			newNode("tainted", linux, corev1.Taint{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}),
+			newNode("prefer-no-schedule", linux, corev1.Taint{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectPreferNoSchedule}),
+			newPod("cni-a", "node-a", "1.24.2", true),
+			newPod("cni-b", "node-b", "1.24.2", false),
+			newPod("cni-c", "node-c", "1.23.4", true),
+			podInOtherNamespace,
+		).
+		Build()
+
+	r := NewReconciler(cfg, cl, scheme.Scheme, nil)
+	cni := &v1.IstioCNI{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec:       v1.IstioCNISpec{Namespace: "istio-cni"},
+	}
+
+	status, err := r.determineDaemonSetStatus(context.TODO(), cni)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(status.DesiredNumberScheduled).To(Equal(int32(4)))
+	g.Expect(status.UpdatedNumberScheduled).To(Equal(int32(3)))
+	g.Expect(status.NumberReady).To(Equal(int32(2)))
+	g.Expect(status.NumberUnavailable).To(Equal(int32(2)))
+	g.Expect(status.Versions).To(Equal([]string{"1.23.4", "1.24.2"}))
+
+	reasons := map[string]v1.IstioCNINodeGapReason{}
+	for _, gap := range status.NodeGaps {
+		reasons[gap.Node] = gap.Reason
+	}
+	g.Expect(reasons).To(Equal(map[string]v1.IstioCNINodeGapReason{
+		"node-b":             v1.IstioCNINodeGapPodNotReady,
+		"node-d":             v1.IstioCNINodeGapPodMissing,
+		"opted-in-by-name":   v1.IstioCNINodeGapPodMissing,
+		"opted-out":          v1.IstioCNINodeGapNodeAffinityMismatch,
+		"prefer-no-schedule": v1.IstioCNINodeGapPodMissing,
+		"tainted":            v1.IstioCNINodeGapTaintNotTolerated,
+		"windows":            v1.IstioCNINodeGapNodeSelectorMismatch,
+	}))
+
+	// no status is reported when the DaemonSet doesn't exist
+	cni.Spec.Namespace = "other"
+	status, err = r.determineDaemonSetStatus(context.TODO(), cni)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(status).To(BeNil())
+}
+
+func TestNodeSchedulingChanged(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node", Labels: map[string]string{"kubernetes.io/os": "linux"}},
+	}
+	withLabel := node.DeepCopy()
+	withLabel.Labels["cni"] = "disabled"
+	withTaint := node.DeepCopy()
+	withTaint.Spec.Taints = []corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}}
+	withStatusChange := node.DeepCopy()
+	withStatusChange.Status.Conditions = []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}}
+
+	p := nodeSchedulingChanged()
+	tests := []struct {
+		name     string
+		newNode  *corev1.Node
+		expected bool
+	}{
+		{name: "labels changed", newNode: withLabel, expected: true},
+		{name: "taints changed", newNode: withTaint, expected: true},
+		{name: "only status changed", newNode: withStatusChange, expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(p.Update(event.UpdateEvent{ObjectOld: node, ObjectNew: tt.newNode})).To(Equal(tt.expected))
+		})
+	}
+
+	g := NewWithT(t)
+	g.Expect(p.Create(event.CreateEvent{Object: node})).To(BeTrue())
+	g.Expect(p.Delete(event.DeleteEvent{Object: node})).To(BeTrue())
+	g.Expect(p.Generic(event.GenericEvent{Object: node})).To(BeFalse())
+}
+
 func TestDetermineStatus(t *testing.T) {
 	cfg := newReconcilerTestConfig(t)
 
 	tests := []struct {
-		name         string
-		reconcileErr error
+		name          string
+		result        reconcileResult
+		reconcileErr  error
+		expectVersion string
 	}{
 		{
-			name:         "no error",
-			reconcileErr: nil,
+			name:          "no error",
+			result:        reconcileResult{version: "1.24.2"},
+			expectVersion: "1.24.2",
+		},
+		{
+			// the status reports the installed version, even if spec.version or the version of the Istio referenced
+			// in spec.versionFrom has changed since the chart was installed
+			name:          "installed version differs from spec",
+			result:        reconcileResult{version: "1.24.1"},
+			expectVersion: "1.24.1",
+		},
+		{
+			name:          "waiting for control plane",
+			result:        reconcileResult{pending: "waiting for the control plane to be upgraded"},
+			expectVersion: "1.23.4",
 		},
 		{
-			name:         "reconcile error",
-			reconcileErr: fmt.Errorf("some reconcile error"),
+			name:          "reconcile error",
+			result:        reconcileResult{pending: "waiting for the control plane to be upgraded"},
+			reconcileErr:  fmt.Errorf("some reconcile error"),
+			expectVersion: "1.23.4",
 		},
 	}
 
@@ -478,14 +726,18 @@ func TestDetermineStatus(t *testing.T) {
 					Name:       "my-cni",
 					Generation: 123,
 				},
+				Spec:   v1.IstioCNISpec{Version: "1.24.2"},
+				Status: v1.IstioCNIStatus{Version: "1.23.4"},
 			}
 
-			status, err := r.determineStatus(ctx, cni, tt.reconcileErr)
+			status, err := r.determineStatus(ctx, cni, tt.result, tt.reconcileErr)
 			g.Expect(err).ToNot(HaveOccurred())
 
 			g.Expect(status.ObservedGeneration).To(Equal(cni.Generation))
 
-			reconciledCondition := r.determineReconciledCondition(tt.reconcileErr)
+			g.Expect(status.Version).To(Equal(tt.expectVersion))
+
+			reconciledCondition := r.determineReconciledCondition(tt.reconcileErr, tt.result.pending)
 			readyCondition, err := r.determineReadyCondition(ctx, cni)
 			g.Expect(err).ToNot(HaveOccurred())
 
@@ -496,11 +748,231 @@ func TestDetermineStatus(t *testing.T) {
 	}
 }
 
+func newRevision(name, version string, inUse, ready metav1.ConditionStatus) *v1.IstioRevision {
+	return &v1.IstioRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       v1.IstioRevisionSpec{Version: version},
+		Status: v1.IstioRevisionStatus{
+			Conditions: []v1.IstioRevisionCondition{
+				{Type: v1.IstioRevisionConditionInUse, Status: inUse},
+				{Type: v1.IstioRevisionConditionReady, Status: ready},
+			},
+		},
+	}
+}
+
+func TestDetermineVersion(t *testing.T) {
+	istio := &v1.Istio{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec:       v1.IstioSpec{Version: "v1.24.2"},
+		Status:     v1.IstioStatus{ActiveRevisionName: "default"},
+	}
+
+	testCases := []struct {
+		name             string
+		spec             v1.IstioCNISpec
+		installedVersion string
+		objects          []client.Object
+		expectedVersion  string
+		expectPending    bool
+	}{
+		{
+			name:            "spec.version",
+			spec:            v1.IstioCNISpec{Version: "v1.24.1"},
+			expectedVersion: "v1.24.1",
+		},
+		{
+			name:            "tracks version of upgraded control plane",
+			spec:            v1.IstioCNISpec{Version: "v1.24.1", VersionFrom: &v1.VersionSource{Istio: "default"}},
+			objects:         []client.Object{istio, newRevision("default", "v1.24.2", metav1.ConditionTrue, metav1.ConditionTrue)},
+			expectedVersion: "v1.24.2",
+		},
+		{
+			name:             "keeps installed version while control plane is upgrading",
+			spec:             v1.IstioCNISpec{VersionFrom: &v1.VersionSource{Istio: "default"}},
+			installedVersion: "v1.24.1",
+			objects:          []client.Object{istio, newRevision("default", "v1.24.1", metav1.ConditionTrue, metav1.ConditionTrue)},
+			expectedVersion:  "v1.24.1",
+			expectPending:    true,
+		},
+		{
+			name:          "nothing to install while control plane isn't ready",
+			spec:          v1.IstioCNISpec{VersionFrom: &v1.VersionSource{Istio: "default"}},
+			objects:       []client.Object{istio, newRevision("default", "v1.24.2", metav1.ConditionTrue, metav1.ConditionFalse)},
+			expectPending: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(tc.objects...).Build()
+			r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme, nil)
+
+			cni := &v1.IstioCNI{
+				ObjectMeta: metav1.ObjectMeta{Name: "default"},
+				Spec:       tc.spec,
+				Status:     v1.IstioCNIStatus{Version: tc.installedVersion},
+			}
+			version, pending, err := r.determineVersion(context.TODO(), cni)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(version).To(Equal(tc.expectedVersion))
+			g.Expect(pending != "").To(Equal(tc.expectPending))
+
+			reconciledCondition := r.determineReconciledCondition(nil, pending)
+			if tc.expectPending {
+				g.Expect(reconciledCondition.Status).To(Equal(metav1.ConditionFalse))
+				g.Expect(reconciledCondition.Reason).To(Equal(v1.IstioCNIReasonWaitingForControlPlane))
+			} else {
+				g.Expect(reconciledCondition.Status).To(Equal(metav1.ConditionTrue))
+			}
+		})
+	}
+}
+
+func TestVersionSkew(t *testing.T) {
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+		newRevision("old", "v1.22.8", metav1.ConditionTrue, metav1.ConditionTrue),
+		newRevision("new", "v1.23.4", metav1.ConditionTrue, metav1.ConditionTrue),
+		newRevision("unused", "v1.21.6", metav1.ConditionFalse, metav1.ConditionTrue),
+	).Build()
+
+	testCases := []struct {
+		name              string
+		policy            v1.VersionSkewPolicy
+		version           string
+		installedVersion  string
+		expectBlocked     bool
+		expectedSkewState metav1.ConditionStatus
+	}{
+		{
+			name:              "supported skew",
+			policy:            v1.VersionSkewPolicyBlock,
+			version:           "v1.23.3",
+			installedVersion:  "v1.23.3",
+			expectedSkewState: metav1.ConditionTrue,
+		},
+		{
+			name:              "unsupported skew with Warn policy",
+			policy:            v1.VersionSkewPolicyWarn,
+			version:           "v1.24.2",
+			installedVersion:  "v1.24.2",
+			expectedSkewState: metav1.ConditionFalse,
+		},
+		{
+			name:              "upgrade to unsupported skew with Block policy",
+			policy:            v1.VersionSkewPolicyBlock,
+			version:           "v1.24.2",
+			installedVersion:  "v1.23.3",
+			expectBlocked:     true,
+			expectedSkewState: metav1.ConditionTrue,
+		},
+		{
+			name:              "installed version with unsupported skew and Block policy",
+			policy:            v1.VersionSkewPolicyBlock,
+			version:           "v1.24.2",
+			installedVersion:  "v1.24.2",
+			expectedSkewState: metav1.ConditionFalse,
+		},
+		{
+			name:              "not yet installed",
+			policy:            v1.VersionSkewPolicyBlock,
+			version:           "v1.23.3",
+			expectedSkewState: metav1.ConditionUnknown,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme, nil)
+
+			cni := &v1.IstioCNI{
+				ObjectMeta: metav1.ObjectMeta{Name: "default"},
+				Spec:       v1.IstioCNISpec{Version: tc.version, VersionSkewPolicy: tc.policy},
+				Status:     v1.IstioCNIStatus{Version: tc.installedVersion},
+			}
+			err := r.validateVersionSkew(context.TODO(), cni, tc.version)
+			if tc.expectBlocked {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("IstioRevision old with version v1.22.8"))
+				g.Expect(err.Error()).ToNot(ContainSubstring("IstioRevision new"))
+
+				reconciledCondition := r.determineReconciledCondition(err, "")
+				g.Expect(reconciledCondition.Status).To(Equal(metav1.ConditionFalse))
+				g.Expect(reconciledCondition.Reason).To(Equal(v1.IstioCNIReasonUnsupportedVersionSkew))
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+
+			skewCondition, err := r.determineVersionSkewCondition(context.TODO(), tc.installedVersion)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(skewCondition.Status).To(Equal(tc.expectedSkewState))
+			if tc.expectedSkewState == metav1.ConditionFalse {
+				g.Expect(skewCondition.Reason).To(Equal(v1.IstioCNIReasonUnsupportedVersionSkew))
+			}
+		})
+	}
+}
+
+func TestFinalizeBlocksDeletionWhenInUse(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.TODO()
+	cni := &v1.IstioCNI{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "default",
+			DeletionTimestamp: testtime.OneMinuteAgo(),
+			Finalizers:        []string{constants.FinalizerName},
+		},
+		Spec: v1.IstioCNISpec{Namespace: "istio-cni"},
+	}
+	ambientPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:        "ambient",
+		Namespace:   "bookinfo",
+		Annotations: map[string]string{constants.IstioAmbientRedirectionAnnotation: "enabled"},
+	}}
+	sidecarPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "sidecar", Namespace: "bookinfo"},
+		Spec:       corev1.PodSpec{InitContainers: []corev1.Container{{Name: validationInitContainerName}}},
+	}
+	otherPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "bookinfo"},
+		Spec:       corev1.PodSpec{InitContainers: []corev1.Container{{Name: "istio-init"}}},
+	}
+
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithStatusSubresource(&v1.IstioCNI{}).
+		WithObjects(cni, ambientPod, sidecarPod, otherPod).
+		Build()
+	r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme, nil)
+
+	err := r.Finalize(ctx, cni)
+	g.Expect(reconciler.IsTransientError(err)).To(BeTrue())
+
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(cni), cni)).To(Succeed())
+	condition := cni.Status.GetCondition(v1.IstioCNIConditionDeletionBlocked)
+	g.Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+	g.Expect(condition.Reason).To(Equal(v1.IstioCNIReasonReferencedByWorkloads))
+	g.Expect(condition.Message).To(Equal("2 pods rely on the CNI plugin (e.g. bookinfo/ambient, bookinfo/sidecar); " +
+		"remove them or annotate the IstioCNI with sailoperator.io/force-delete=true"))
+}
+
 func normalize(condition v1.IstioCNICondition) v1.IstioCNICondition {
 	condition.LastTransitionTime = metav1.Time{}
 	return condition
 }
 
+func TestDetermineReconciledConditionImageVerificationFailed(t *testing.T) {
+	g := NewWithT(t)
+	r := NewReconciler(newReconcilerTestConfig(t), fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(), scheme.Scheme, nil)
+
+	image := "gcr.io/istio-release/install-cni:1.24.2"
+	err := fmt.Errorf("failed to install/update Helm chart: %w", imageverification.NewVerificationError(image, "digest sha256:1234 is not allowed"))
+	c := r.determineReconciledCondition(err, "")
+	g.Expect(c.Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(c.Reason).To(Equal(v1.IstioCNIReasonImageVerificationFailed))
+	g.Expect(c.Message).To(ContainSubstring(image))
+}
+
 func newReconcilerTestConfig(t *testing.T) config.ReconcilerConfig {
 	return config.ReconcilerConfig{
 		ResourceDirectory: t.TempDir(),
@@ -508,3 +980,34 @@ func newReconcilerTestConfig(t *testing.T) config.ReconcilerConfig {
 		DefaultProfile:    "",
 	}
 }
+
+func TestCNISpecFromRelease(t *testing.T) {
+	rel := &release.Release{
+		Name:  "istio-cni",
+		Chart: &chart.Chart{Metadata: &chart.Metadata{Name: "cni", Version: "1.23.4"}},
+		Config: map[string]any{
+			"cni":    map[string]any{"ambient": map[string]any{"enabled": true}},
+			"global": map[string]any{"hub": "quay.io/custom"},
+		},
+	}
+
+	t.Run("copies values", func(t *testing.T) {
+		g := NewWithT(t)
+		spec, err := cniSpecFromRelease(v1.IstioCNISpec{Version: "v1.24.2", Namespace: "istio-cni"}, rel)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(spec.Version).To(Equal("v1.23.4"))
+		g.Expect(spec.Namespace).To(Equal("istio-cni"))
+		g.Expect(spec.Values).To(Equal(&v1.CNIValues{
+			Cni:    &v1.CNIConfig{Ambient: &v1.CNIAmbientConfig{Enabled: ptr.Of(true)}},
+			Global: &v1.CNIGlobalConfig{Hub: ptr.Of("quay.io/custom")},
+		}))
+	})
+
+	t.Run("keeps values in spec", func(t *testing.T) {
+		g := NewWithT(t)
+		values := &v1.CNIValues{Cni: &v1.CNIConfig{Hub: ptr.Of("docker.io/istio")}}
+		spec, err := cniSpecFromRelease(v1.IstioCNISpec{Version: "v1.24.2", Values: values}, rel)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(spec.Values).To(Equal(values))
+	})
+}
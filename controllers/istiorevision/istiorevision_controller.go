@@ -21,6 +21,7 @@ import (
 	"path"
 	"reflect"
 	"regexp"
+	"strings"
 
 	"github.com/go-logr/logr"
 	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
@@ -29,6 +30,7 @@ import (
 	"github.com/istio-ecosystem/sail-operator/pkg/enqueuelogger"
 	"github.com/istio-ecosystem/sail-operator/pkg/errlist"
 	"github.com/istio-ecosystem/sail-operator/pkg/helm"
+	"github.com/istio-ecosystem/sail-operator/pkg/imageverification"
 	"github.com/istio-ecosystem/sail-operator/pkg/kube"
 	predicate2 "github.com/istio-ecosystem/sail-operator/pkg/predicate"
 	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
@@ -44,6 +46,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -63,14 +66,17 @@ type Reconciler struct {
 	Config       config.ReconcilerConfig
 	Scheme       *runtime.Scheme
 	ChartManager *helm.ChartManager
+	// imageVerifier is shared by all reconciliations, so that its caches outlive a single reconciliation
+	imageVerifier *imageverification.Verifier
 }
 
 func NewReconciler(cfg config.ReconcilerConfig, client client.Client, scheme *runtime.Scheme, chartManager *helm.ChartManager) *Reconciler {
 	return &Reconciler{
-		Config:       cfg,
-		Client:       client,
-		Scheme:       scheme,
-		ChartManager: chartManager,
+		Config:        cfg,
+		Client:        client,
+		Scheme:        scheme,
+		ChartManager:  chartManager,
+		imageVerifier: imageverification.NewVerifier(clock.RealClock{}),
 	}
 }
 
@@ -115,10 +121,44 @@ func (r *Reconciler) doReconcile(ctx context.Context, rev *v1.IstioRevision) err
 	return r.installHelmCharts(ctx, rev)
 }
 
+// Finalize uninstalls the control plane, or orphans it if spec.deletionPolicy is Orphan. If workloads still use
+// the revision, the uninstallation is blocked until they stop using it, unless the IstioRevision is annotated with
+// sailoperator.io/force-delete=true.
 func (r *Reconciler) Finalize(ctx context.Context, rev *v1.IstioRevision) error {
+	if rev.Spec.DeletionPolicy == v1.DeletionPolicyOrphan {
+		return r.orphanHelmCharts(ctx, rev)
+	}
+	if !kube.IsForceDeleteRequested(rev) {
+		refs, err := r.getRevisionReferences(ctx, rev)
+		if err != nil {
+			return err
+		}
+		if !refs.isEmpty() {
+			return r.blockDeletion(ctx, rev, refs)
+		}
+	}
 	return r.uninstallHelmCharts(ctx, rev)
 }
 
+// blockDeletion sets the DeletionBlocked condition, which lists the objects that reference the revision, and returns
+// a TransientError, so that the finalization is retried.
+func (r *Reconciler) blockDeletion(ctx context.Context, rev *v1.IstioRevision, refs revisionReferences) error {
+	status := *rev.Status.DeepCopy()
+	status.SetCondition(v1.IstioRevisionCondition{
+		Type:   v1.IstioRevisionConditionDeletionBlocked,
+		Status: metav1.ConditionTrue,
+		Reason: v1.IstioRevisionReasonReferencedByWorkloads,
+		Message: fmt.Sprintf("The revision is still referenced by %s; remove the references or annotate the IstioRevision with %s=true",
+			refs, constants.ForceDeleteKey),
+	})
+	if !reflect.DeepEqual(rev.Status, status) {
+		if err := r.patchStatus(ctx, rev, status); err != nil {
+			return fmt.Errorf("failed to patch status: %w", err)
+		}
+	}
+	return reconciler.NewTransientError("IstioRevision is in use: referenced by " + refs.String())
+}
+
 func (r *Reconciler) validate(ctx context.Context, rev *v1.IstioRevision) error {
 	if rev.Spec.Version == "" {
 		return reconciler.NewValidationError("spec.version not set")
@@ -126,7 +166,7 @@ func (r *Reconciler) validate(ctx context.Context, rev *v1.IstioRevision) error
 	if rev.Spec.Namespace == "" {
 		return reconciler.NewValidationError("spec.namespace not set")
 	}
-	if err := validation.ValidateTargetNamespace(ctx, r.Client, rev.Spec.Namespace); err != nil {
+	if err := validation.ValidateTargetNamespace(ctx, r.Client, r.Config.OperatorConfig.Get(), rev.Spec.Namespace); err != nil {
 		return err
 	}
 
@@ -163,8 +203,11 @@ func (r *Reconciler) installHelmCharts(ctx context.Context, rev *v1.IstioRevisio
 	}
 
 	values := helm.FromValues(rev.Spec.Values)
+	operatorConfig := r.Config.OperatorConfig.Get()
 	_, err := r.ChartManager.UpgradeOrInstallChart(ctx, r.getChartDir(rev),
-		values, rev.Spec.Namespace, getReleaseName(rev), ownerReference)
+		values, rev.Spec.Namespace, getReleaseName(rev), ownerReference,
+		helm.NewRegistryRewritePostRenderer(operatorConfig.RegistryRewrites),
+		helm.NewImageVerificationPostRenderer(ctx, r.imageVerifier, operatorConfig.ImageVerification))
 	if err != nil {
 		return fmt.Errorf("failed to install/update Helm chart %q: %w", constants.IstiodChartName, err)
 	}
@@ -186,6 +229,13 @@ func (r *Reconciler) uninstallHelmCharts(ctx context.Context, rev *v1.IstioRevis
 	return nil
 }
 
+func (r *Reconciler) orphanHelmCharts(ctx context.Context, rev *v1.IstioRevision) error {
+	if err := r.ChartManager.OrphanRelease(ctx, getReleaseName(rev), rev.Spec.Namespace, rev.UID); err != nil {
+		return fmt.Errorf("failed to orphan Helm chart %q: %w", constants.IstiodChartName, err)
+	}
+	return nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	logger := mgr.GetLogger().WithName("ctrlr").WithName("istiorev")
@@ -225,7 +275,8 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 		}).
 		// we use the Watches function instead of For(), so that we can wrap the handler so that events that cause the object to be enqueued are logged
 		// +lint-watches:ignore: IstioRevision (not found in charts, but this is the main resource watched by this controller)
-		Watches(&v1.IstioRevision{}, mainObjectHandler).
+		// status-only updates are ignored, except when the webhook controller stores remote istiod probe results that change the revision's readiness
+		Watches(&v1.IstioRevision{}, mainObjectHandler, builder.WithPredicates(predicate.Or(ignoreStatusChange(), remoteIstiodReadinessChanged()))).
 		Named("istiorevision").
 
 		// namespaced resources
@@ -258,6 +309,9 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Watches(&admissionv1.MutatingWebhookConfiguration{}, ownedResourceHandler).
 		Watches(&admissionv1.ValidatingWebhookConfiguration{}, ownedResourceHandler, builder.WithPredicates(validatingWebhookConfigPredicate())).
 
+		// the operator configuration restricts the namespaces that the IstioRevisions may be installed in
+		WatchesRawSource(r.Config.OperatorConfig.WatchSource(r.mapOperatorConfigChangeToReconcileRequest)).
+
 		// +lint-watches:ignore: ValidatingAdmissionPolicy (TODO: fix this when CI supports golang 1.22 and k8s 1.30)
 		// +lint-watches:ignore: ValidatingAdmissionPolicyBinding (TODO: fix this when CI supports golang 1.22 and k8s 1.30)
 		// +lint-watches:ignore: CustomResourceDefinition (prevents `make lint-watches` from bugging us about CRDs)
@@ -267,7 +321,11 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 func (r *Reconciler) determineStatus(ctx context.Context, rev *v1.IstioRevision, reconcileErr error) (v1.IstioRevisionStatus, error) {
 	var errs errlist.Builder
 	reconciledCondition := r.determineReconciledCondition(reconcileErr)
-	readyCondition, err := r.determineReadyCondition(ctx, rev)
+
+	mode, err := r.determineMode(rev)
+	errs.Add(err)
+
+	readyCondition, err := r.determineReadyCondition(ctx, rev, mode)
 	errs.Add(err)
 
 	inUseCondition, err := r.determineInUseCondition(ctx, rev)
@@ -279,6 +337,7 @@ func (r *Reconciler) determineStatus(ctx context.Context, rev *v1.IstioRevision,
 	status.SetCondition(readyCondition)
 	status.SetCondition(inUseCondition)
 	status.State = deriveState(reconciledCondition, readyCondition)
+	status.Mode = mode
 	return status, errs.Error()
 }
 
@@ -291,13 +350,22 @@ func (r *Reconciler) updateStatus(ctx context.Context, rev *v1.IstioRevision, re
 	}
 
 	if !reflect.DeepEqual(rev.Status, status) {
-		if err := r.Client.Status().Patch(ctx, rev, kube.NewStatusPatch(status)); err != nil {
+		if err := r.patchStatus(ctx, rev, status); err != nil {
 			errs.Add(fmt.Errorf("failed to patch status: %w", err))
 		}
 	}
 	return errs.Error()
 }
 
+// patchStatus updates the revision's status with a merge patch, which only contains the fields that changed. Unlike
+// a patch that replaces the whole status, it doesn't overwrite status.remoteIstiod, which is written by the webhook
+// controller.
+func (r *Reconciler) patchStatus(ctx context.Context, rev *v1.IstioRevision, status v1.IstioRevisionStatus) error {
+	patch := client.MergeFrom(rev.DeepCopy())
+	rev.Status = status
+	return r.Client.Status().Patch(ctx, rev, patch)
+}
+
 func deriveState(reconciledCondition, readyCondition v1.IstioRevisionCondition) v1.IstioRevisionConditionReason {
 	if reconciledCondition.Status != metav1.ConditionTrue {
 		return reconciledCondition.Reason
@@ -312,6 +380,10 @@ func (r *Reconciler) determineReconciledCondition(err error) v1.IstioRevisionCon
 
 	if err == nil {
 		c.Status = metav1.ConditionTrue
+	} else if imageverification.IsVerificationError(err) {
+		c.Status = metav1.ConditionFalse
+		c.Reason = v1.IstioRevisionReasonImageVerificationFailed
+		c.Message = err.Error()
 	} else {
 		c.Status = metav1.ConditionFalse
 		c.Reason = v1.IstioRevisionReasonReconcileError
@@ -320,13 +392,26 @@ func (r *Reconciler) determineReconciledCondition(err error) v1.IstioRevisionCon
 	return c
 }
 
-func (r *Reconciler) determineReadyCondition(ctx context.Context, rev *v1.IstioRevision) (v1.IstioRevisionCondition, error) {
+// determineMode derives the control plane mode from the fully evaluated values. If the values can't be
+// evaluated, the previously determined mode is retained.
+func (r *Reconciler) determineMode(rev *v1.IstioRevision) (v1.IstioRevisionMode, error) {
+	if rev.Spec.Version == "" {
+		return rev.Status.Mode, nil
+	}
+	mode, err := revision.ComputeMode(r.Config.ResourceDirectory, rev)
+	if err != nil {
+		return rev.Status.Mode, fmt.Errorf("failed to determine control plane mode: %w", err)
+	}
+	return mode, nil
+}
+
+func (r *Reconciler) determineReadyCondition(ctx context.Context, rev *v1.IstioRevision, mode v1.IstioRevisionMode) (v1.IstioRevisionCondition, error) {
 	c := v1.IstioRevisionCondition{
 		Type:   v1.IstioRevisionConditionReady,
 		Status: metav1.ConditionFalse,
 	}
 
-	if !revision.IsUsingRemoteControlPlane(rev) {
+	if mode != v1.IstioRevisionModeRemote {
 		istiod := appsv1.Deployment{}
 		if err := r.Client.Get(ctx, istiodDeploymentKey(rev), &istiod); err == nil {
 			if istiod.Status.Replicas == 0 {
@@ -351,16 +436,11 @@ func (r *Reconciler) determineReadyCondition(ctx context.Context, rev *v1.IstioR
 		webhook := admissionv1.MutatingWebhookConfiguration{}
 		webhookKey := injectionWebhookKey(rev)
 		if err := r.Client.Get(ctx, webhookKey, &webhook); err == nil {
-			switch webhook.Annotations[constants.WebhookReadinessProbeStatusAnnotationKey] {
-			case "true":
+			if ready, message := remoteIstiodReadiness(rev); ready {
 				c.Status = metav1.ConditionTrue
-			case "false":
-				c.Reason = v1.IstioRevisionReasonRemoteIstiodNotReady
-				c.Message = "readiness probe on remote istiod failed"
-			default:
+			} else {
 				c.Reason = v1.IstioRevisionReasonRemoteIstiodNotReady
-				c.Message = fmt.Sprintf("invalid or missing annotation %s on MutatingWebhookConfiguration %s",
-					constants.WebhookReadinessProbeStatusAnnotationKey, webhookKey.Name)
+				c.Message = message
 			}
 		} else if apierrors.IsNotFound(err) {
 			c.Reason = v1.IstioRevisionReasonRemoteIstiodNotReady
@@ -375,12 +455,33 @@ func (r *Reconciler) determineReadyCondition(ctx context.Context, rev *v1.IstioR
 	return c, nil
 }
 
+// remoteIstiodReadiness returns whether the probes of the remote istiod succeeded and, if they didn't, the reason why.
+// The probe results are stored in the revision's status by the webhook controller.
+func remoteIstiodReadiness(rev *v1.IstioRevision) (bool, string) {
+	webhookName := injectionWebhookKey(rev).Name
+	results := rev.Status.RemoteIstiod.GetWebhookConfiguration(webhookName)
+	switch {
+	case results.IsReady():
+		return true, ""
+	case results == nil:
+		return false, fmt.Sprintf("remote istiod hasn't been probed through MutatingWebhookConfiguration %s yet", webhookName)
+	case len(results.Webhooks) == 0:
+		return false, fmt.Sprintf("MutatingWebhookConfiguration %s contains no webhooks", webhookName)
+	}
+	for _, result := range results.Webhooks {
+		if !result.Ready {
+			return false, fmt.Sprintf("readiness probe on remote istiod failed for webhook %s: %s", result.Name, result.Message)
+		}
+	}
+	return false, ""
+}
+
 func (r *Reconciler) determineInUseCondition(ctx context.Context, rev *v1.IstioRevision) (v1.IstioRevisionCondition, error) {
 	c := v1.IstioRevisionCondition{Type: v1.IstioRevisionConditionInUse}
 
-	isReferenced, err := r.isRevisionReferenced(ctx, rev)
+	refs, err := r.getRevisionReferences(ctx, rev)
 	if err == nil {
-		if isReferenced {
+		if !refs.isEmpty() {
 			c.Status = metav1.ConditionTrue
 			c.Reason = v1.IstioRevisionReasonReferencedByWorkloads
 			c.Message = "Referenced by at least one pod or namespace"
@@ -397,68 +498,127 @@ func (r *Reconciler) determineInUseCondition(ctx context.Context, rev *v1.IstioR
 	return c, fmt.Errorf("failed to determine if IstioRevision is in use: %w", err)
 }
 
-func (r *Reconciler) isRevisionReferenced(ctx context.Context, rev *v1.IstioRevision) (bool, error) {
+// revisionReferences holds the names of the objects that reference an IstioRevision.
+type revisionReferences struct {
+	revisionTags []string
+	namespaces   []string
+	pods         []string
+}
+
+func (r revisionReferences) isEmpty() bool {
+	return len(r.revisionTags) == 0 && len(r.namespaces) == 0 && len(r.pods) == 0
+}
+
+func (r revisionReferences) String() string {
+	var parts []string
+	for _, refs := range []struct {
+		kind  string
+		names []string
+	}{
+		{"IstioRevisionTags", r.revisionTags},
+		{"Namespaces", r.namespaces},
+		{"Pods", r.pods},
+	} {
+		if len(refs.names) > 0 {
+			parts = append(parts, refs.kind+" "+summarizeNames(refs.names))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// maxListedReferences is the number of referencing objects of each kind that are listed in the DeletionBlocked
+// condition; if there are more, only their number is reported.
+const maxListedReferences = 5
+
+func summarizeNames(names []string) string {
+	if len(names) <= maxListedReferences {
+		return "[" + strings.Join(names, ", ") + "]"
+	}
+	return fmt.Sprintf("[%s and %d more]", strings.Join(names[:maxListedReferences], ", "), len(names)-maxListedReferences)
+}
+
+// getRevisionReferences returns the IstioRevisionTags, Namespaces and Pods that reference the IstioRevision.
+func (r *Reconciler) getRevisionReferences(ctx context.Context, rev *v1.IstioRevision) (revisionReferences, error) {
 	log := logf.FromContext(ctx)
+	var refs revisionReferences
 	nsList := corev1.NamespaceList{}
 	nsMap := map[string]corev1.Namespace{}
 	// if an IstioRevision is referenced by a revisionTag, it's considered as InUse
 	revisionTagList := v1.IstioRevisionTagList{}
 	if err := r.Client.List(ctx, &revisionTagList); err != nil {
-		return false, fmt.Errorf("failed to list IstioRevisionTags: %w", err)
+		return refs, fmt.Errorf("failed to list IstioRevisionTags: %w", err)
 	}
 	for _, tag := range revisionTagList.Items {
 		if tag.Status.IstioRevision == rev.Name {
 			log.V(2).Info("Revision is referenced by IstioRevisionTag", "IstioRevisionTag", tag.Name)
-			return true, nil
+			refs.revisionTags = append(refs.revisionTags, tag.Name)
+			continue
+		}
+		for _, split := range tag.Status.Split {
+			if split.IstioRevision == rev.Name {
+				log.V(2).Info("Revision is referenced by IstioRevisionTag split", "IstioRevisionTag", tag.Name)
+				refs.revisionTags = append(refs.revisionTags, tag.Name)
+				break
+			}
 		}
 	}
 
 	if err := r.Client.List(ctx, &nsList); err != nil { // TODO: can we optimize this by specifying a label selector
-		return false, fmt.Errorf("failed to list namespaces: %w", err)
+		return refs, fmt.Errorf("failed to list namespaces: %w", err)
 	}
 	for _, ns := range nsList.Items {
 		if namespaceReferencesRevision(ns, rev) {
 			log.V(2).Info("Revision is referenced by Namespace", "Namespace", ns.Name)
-			return true, nil
+			refs.namespaces = append(refs.namespaces, ns.Name)
+			continue
 		}
 		nsMap[ns.Name] = ns
 	}
 
+	injectionPolicy, err := r.getInjectionPolicy(ctx, rev)
+	if err != nil {
+		return refs, err
+	}
+
 	podList := corev1.PodList{}
 	if err := r.Client.List(ctx, &podList); err != nil { // TODO: can we optimize this by specifying a label selector
-		return false, fmt.Errorf("failed to list pods: %w", err)
+		return refs, fmt.Errorf("failed to list pods: %w", err)
 	}
 	for _, pod := range podList.Items {
-		if ns, found := nsMap[pod.Namespace]; found && podReferencesRevision(pod, ns, rev) {
+		if ns, found := nsMap[pod.Namespace]; found && podReferencesRevision(pod, ns, rev, injectionPolicy) {
 			log.V(2).Info("Revision is referenced by Pod", "Pod", client.ObjectKeyFromObject(&pod))
-			return true, nil
+			refs.pods = append(refs.pods, client.ObjectKeyFromObject(&pod).String())
 		}
 	}
 
-	if rev.Name == v1.DefaultRevision && rev.Spec.Values != nil &&
-		rev.Spec.Values.SidecarInjectorWebhook != nil &&
-		rev.Spec.Values.SidecarInjectorWebhook.EnableNamespacesByDefault != nil &&
-		*rev.Spec.Values.SidecarInjectorWebhook.EnableNamespacesByDefault {
-		return true, nil
+	if refs.isEmpty() {
+		log.V(2).Info("Revision is not referenced by any Pod or Namespace")
 	}
-
-	log.V(2).Info("Revision is not referenced by any Pod or Namespace")
-	return false, nil
+	return refs, nil
 }
 
 func namespaceReferencesRevision(ns corev1.Namespace, rev *v1.IstioRevision) bool {
 	return rev.Name == revision.GetReferencedRevisionFromNamespace(ns.Labels)
 }
 
-func podReferencesRevision(pod corev1.Pod, ns corev1.Namespace, rev *v1.IstioRevision) bool {
+func podReferencesRevision(pod corev1.Pod, ns corev1.Namespace, rev *v1.IstioRevision, injectionPolicy *revision.InjectionPolicy) bool {
 	if rev.Name == revision.GetInjectedRevisionFromPod(pod.GetAnnotations()) {
 		return true
 	}
-	if revision.GetReferencedRevisionFromNamespace(ns.Labels) == "" &&
-		rev.Name == revision.GetReferencedRevisionFromPod(pod.GetLabels()) {
-		return true
+	return injectionPolicy.InjectsPod(pod.GetLabels(), pod.GetAnnotations(), &ns)
+}
+
+// getInjectionPolicy returns the InjectionPolicy of the revision based on its values and the injection
+// MutatingWebhookConfiguration, if it has already been installed.
+func (r *Reconciler) getInjectionPolicy(ctx context.Context, rev *v1.IstioRevision) (*revision.InjectionPolicy, error) {
+	webhook := admissionv1.MutatingWebhookConfiguration{}
+	if err := r.Client.Get(ctx, injectionWebhookKey(rev), &webhook); err == nil {
+		return revision.NewInjectionPolicy(rev.Name, rev.Spec.Values, &webhook), nil
+	} else if apierrors.IsNotFound(err) {
+		return revision.NewInjectionPolicy(rev.Name, rev.Spec.Values, nil), nil
+	} else {
+		return nil, fmt.Errorf("failed to get injection MutatingWebhookConfiguration: %w", err)
 	}
-	return false
 }
 
 func istiodDeploymentKey(rev *v1.IstioRevision) client.ObjectKey {
@@ -506,6 +666,8 @@ func (r *Reconciler) mapNamespaceToReconcileRequest(ctx context.Context, ns clie
 	revisionName := revision.GetReferencedRevisionFromNamespace(ns.GetLabels())
 	if revisionName != "" {
 		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: revisionName}})
+	} else if r.defaultRevisionEnablesNamespacesByDefault(ctx) {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: v1.DefaultRevision}})
 	}
 	return requests
 }
@@ -532,6 +694,10 @@ func (r *Reconciler) mapPodToReconcileRequest(ctx context.Context, pod client.Ob
 		revisionNames = append(revisionNames, revisionName)
 	}
 
+	if len(revisionNames) == 0 && r.defaultRevisionEnablesNamespacesByDefault(ctx) {
+		revisionNames = append(revisionNames, v1.DefaultRevision)
+	}
+
 	if len(revisionNames) > 0 {
 		reqs := []reconcile.Request{}
 		for _, revName := range revisionNames {
@@ -542,12 +708,56 @@ func (r *Reconciler) mapPodToReconcileRequest(ctx context.Context, pod client.Ob
 	return nil
 }
 
+// defaultRevisionEnablesNamespacesByDefault returns true if the default IstioRevision exists and has
+// sidecarInjectorWebhook.enableNamespacesByDefault set, in which case its InUse condition also depends on
+// namespaces and pods that don't reference any revision.
+func (r *Reconciler) defaultRevisionEnablesNamespacesByDefault(ctx context.Context) bool {
+	rev := v1.IstioRevision{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: v1.DefaultRevision}, &rev); err != nil {
+		return false
+	}
+	values := rev.Spec.Values
+	return values != nil && values.SidecarInjectorWebhook != nil &&
+		values.SidecarInjectorWebhook.EnableNamespacesByDefault != nil && *values.SidecarInjectorWebhook.EnableNamespacesByDefault
+}
+
 func (r *Reconciler) mapRevisionTagToReconcileRequest(ctx context.Context, revisionTag client.Object) []reconcile.Request {
 	tag, ok := revisionTag.(*v1.IstioRevisionTag)
-	if ok && tag.Status.IstioRevision != "" {
-		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: tag.Status.IstioRevision}}}
+	if !ok {
+		return nil
 	}
-	return nil
+	var requests []reconcile.Request
+	if tag.Status.IstioRevision != "" {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: tag.Status.IstioRevision}})
+	}
+	for _, split := range tag.Status.Split {
+		if split.IstioRevision != tag.Status.IstioRevision {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: split.IstioRevision}})
+		}
+	}
+	return requests
+}
+
+// mapOperatorConfigChangeToReconcileRequest enqueues all IstioRevisions, unless the change only affects image
+// digests. The Istio controller copies the digests into the values of the IstioRevision, so the IstioRevision is
+// updated through its spec in that case.
+func (r *Reconciler) mapOperatorConfigChangeToReconcileRequest(ctx context.Context, change config.Change) []reconcile.Request {
+	log := logf.FromContext(ctx)
+	if !change.Affects() {
+		return nil
+	}
+
+	revList := v1.IstioRevisionList{}
+	if err := r.Client.List(ctx, &revList); err != nil {
+		log.Error(err, "failed to list IstioRevisions")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(revList.Items))
+	for _, rev := range revList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: rev.Name}})
+	}
+	return requests
 }
 
 // ignoreStatusChange returns a predicate that ignores watch events where only the resource status changes; if
@@ -567,6 +777,23 @@ func ignoreStatusChange() predicate.Funcs {
 	}
 }
 
+// remoteIstiodReadinessChanged returns a predicate that matches IstioRevision updates that change the readiness derived
+// from the remote istiod probe results, so that the Ready condition is updated when the webhook controller stores them.
+func remoteIstiodReadinessChanged() predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldRev, oldOk := e.ObjectOld.(*v1.IstioRevision)
+			newRev, newOk := e.ObjectNew.(*v1.IstioRevision)
+			if !oldOk || !newOk {
+				return false
+			}
+			oldReady, oldMessage := remoteIstiodReadiness(oldRev)
+			newReady, newMessage := remoteIstiodReadiness(newRev)
+			return oldReady != newReady || oldMessage != newMessage
+		},
+	}
+}
+
 func specWasUpdated(oldObject client.Object, newObject client.Object) bool {
 	// for HPAs, k8s doesn't set metadata.generation, so we actually have to check whether the spec was updated
 	if oldHpa, ok := oldObject.(*autoscalingv2.HorizontalPodAutoscaler); ok {
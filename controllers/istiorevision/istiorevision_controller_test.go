@@ -23,7 +23,10 @@ import (
 	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
 	"github.com/istio-ecosystem/sail-operator/pkg/config"
 	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	"github.com/istio-ecosystem/sail-operator/pkg/imageverification"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
 	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
+	"github.com/istio-ecosystem/sail-operator/pkg/test/testtime"
 	"github.com/istio-ecosystem/sail-operator/pkg/test/util/supportedversion"
 	. "github.com/onsi/gomega"
 	admissionv1 "k8s.io/api/admissionregistration/v1"
@@ -203,6 +206,48 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+func TestDetermineReconciledCondition(t *testing.T) {
+	verificationErr := imageverification.NewVerificationError("gcr.io/istio-release/pilot:1.24.2", "digest sha256:1234 is not allowed")
+
+	testCases := []struct {
+		name            string
+		err             error
+		expectStatus    metav1.ConditionStatus
+		expectReason    v1.IstioRevisionConditionReason
+		expectInMessage string
+	}{
+		{
+			name:         "no error",
+			expectStatus: metav1.ConditionTrue,
+		},
+		{
+			name:            "reconcile error",
+			err:             fmt.Errorf("some reconcile error"),
+			expectStatus:    metav1.ConditionFalse,
+			expectReason:    v1.IstioRevisionReasonReconcileError,
+			expectInMessage: "some reconcile error",
+		},
+		{
+			name:            "image rejected by verification policy",
+			err:             fmt.Errorf("failed to install/update Helm chart %q: %w", constants.IstiodChartName, verificationErr),
+			expectStatus:    metav1.ConditionFalse,
+			expectReason:    v1.IstioRevisionReasonImageVerificationFailed,
+			expectInMessage: "gcr.io/istio-release/pilot:1.24.2",
+		},
+	}
+	r := NewReconciler(newReconcilerTestConfig(t), fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(), scheme.Scheme, nil)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			c := r.determineReconciledCondition(tc.err)
+			g.Expect(c.Type).To(Equal(v1.IstioRevisionConditionReconciled))
+			g.Expect(c.Status).To(Equal(tc.expectStatus))
+			g.Expect(c.Reason).To(Equal(tc.expectReason))
+			g.Expect(c.Message).To(ContainSubstring(tc.expectInMessage))
+		})
+	}
+}
+
 func TestDeriveState(t *testing.T) {
 	testCases := []struct {
 		name                string
@@ -267,14 +312,15 @@ func TestDetermineReadyCondition(t *testing.T) {
 	testCases := []struct {
 		name          string
 		values        *v1.Values
+		mode          v1.IstioRevisionMode
 		clientObjects []client.Object
+		remoteIstiod  *v1.RemoteIstiodStatus
 		interceptors  interceptor.Funcs
 		expected      v1.IstioRevisionCondition
 		expectErr     bool
 	}{
 		{
-			name:   "Istiod ready",
-			values: nil,
+			name: "Istiod ready",
 			clientObjects: []client.Object{
 				&appsv1.Deployment{
 					ObjectMeta: metav1.ObjectMeta{
@@ -294,8 +340,7 @@ func TestDetermineReadyCondition(t *testing.T) {
 			},
 		},
 		{
-			name:   "Istiod not ready",
-			values: nil,
+			name: "Istiod not ready",
 			clientObjects: []client.Object{
 				&appsv1.Deployment{
 					ObjectMeta: metav1.ObjectMeta{
@@ -317,8 +362,7 @@ func TestDetermineReadyCondition(t *testing.T) {
 			},
 		},
 		{
-			name:   "Istiod scaled to zero",
-			values: nil,
+			name: "Istiod scaled to zero",
 			clientObjects: []client.Object{
 				&appsv1.Deployment{
 					ObjectMeta: metav1.ObjectMeta{
@@ -390,16 +434,29 @@ func TestDetermineReadyCondition(t *testing.T) {
 			expectErr: true,
 		},
 		{
-			name:   "Istiod-remote ready",
-			values: &v1.Values{Profile: ptr.Of("remote")},
+			name: "Istiod-remote ready",
+			mode: v1.IstioRevisionModeRemote,
 			clientObjects: []client.Object{
 				&admissionv1.MutatingWebhookConfiguration{
 					ObjectMeta: metav1.ObjectMeta{
 						Name: "istio-sidecar-injector",
-						Annotations: map[string]string{
-							constants.WebhookReadinessProbeStatusAnnotationKey: "true",
+					},
+				},
+			},
+			remoteIstiod: &v1.RemoteIstiodStatus{
+				WebhookConfigurations: []v1.WebhookConfigurationProbeStatus{
+					{
+						Name: "istio-sidecar-injector",
+						Webhooks: []v1.WebhookProbeResult{
+							{Name: "rev.namespace.sidecar-injector.istio.io", Ready: true},
+							{Name: "rev.object.sidecar-injector.istio.io", Ready: true},
 						},
 					},
+					{
+						// the results of other configurations don't affect the readiness
+						Name:     "istio-revision-tag-default",
+						Webhooks: []v1.WebhookProbeResult{{Name: "rev.namespace.sidecar-injector.istio.io", Message: "connection refused"}},
+					},
 				},
 			},
 			expected: v1.IstioRevisionCondition{
@@ -408,14 +465,22 @@ func TestDetermineReadyCondition(t *testing.T) {
 			},
 		},
 		{
-			name:   "Istiod-remote not ready",
-			values: &v1.Values{Profile: ptr.Of("remote")},
+			name: "Istiod-remote not ready",
+			mode: v1.IstioRevisionModeRemote,
 			clientObjects: []client.Object{
 				&admissionv1.MutatingWebhookConfiguration{
 					ObjectMeta: metav1.ObjectMeta{
 						Name: "istio-sidecar-injector",
-						Annotations: map[string]string{
-							constants.WebhookReadinessProbeStatusAnnotationKey: "false",
+					},
+				},
+			},
+			remoteIstiod: &v1.RemoteIstiodStatus{
+				WebhookConfigurations: []v1.WebhookConfigurationProbeStatus{
+					{
+						Name: "istio-sidecar-injector",
+						Webhooks: []v1.WebhookProbeResult{
+							{Name: "rev.namespace.sidecar-injector.istio.io", Ready: true},
+							{Name: "rev.object.sidecar-injector.istio.io", Ready: false, Message: "connection refused"},
 						},
 					},
 				},
@@ -424,30 +489,49 @@ func TestDetermineReadyCondition(t *testing.T) {
 				Type:    v1.IstioRevisionConditionReady,
 				Status:  metav1.ConditionFalse,
 				Reason:  v1.IstioRevisionReasonRemoteIstiodNotReady,
-				Message: "readiness probe on remote istiod failed",
+				Message: "readiness probe on remote istiod failed for webhook rev.object.sidecar-injector.istio.io: connection refused",
 			},
 		},
 		{
-			name:   "Istiod-remote no readiness probe status annotation",
-			values: &v1.Values{Profile: ptr.Of("remote")},
+			name: "Istiod-remote webhook config contains no webhooks",
+			mode: v1.IstioRevisionModeRemote,
 			clientObjects: []client.Object{
 				&admissionv1.MutatingWebhookConfiguration{
 					ObjectMeta: metav1.ObjectMeta{
-						Name:        "istio-sidecar-injector",
-						Annotations: map[string]string{},
+						Name: "istio-sidecar-injector",
 					},
 				},
 			},
+			remoteIstiod: &v1.RemoteIstiodStatus{
+				WebhookConfigurations: []v1.WebhookConfigurationProbeStatus{{Name: "istio-sidecar-injector"}},
+			},
 			expected: v1.IstioRevisionCondition{
 				Type:    v1.IstioRevisionConditionReady,
 				Status:  metav1.ConditionFalse,
 				Reason:  v1.IstioRevisionReasonRemoteIstiodNotReady,
-				Message: "invalid or missing annotation sailoperator.io/readinessProbe.status on MutatingWebhookConfiguration istio-sidecar-injector",
+				Message: "MutatingWebhookConfiguration istio-sidecar-injector contains no webhooks",
+			},
+		},
+		{
+			name: "Istiod-remote not probed yet",
+			mode: v1.IstioRevisionModeRemote,
+			clientObjects: []client.Object{
+				&admissionv1.MutatingWebhookConfiguration{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "istio-sidecar-injector",
+					},
+				},
+			},
+			expected: v1.IstioRevisionCondition{
+				Type:    v1.IstioRevisionConditionReady,
+				Status:  metav1.ConditionFalse,
+				Reason:  v1.IstioRevisionReasonRemoteIstiodNotReady,
+				Message: "remote istiod hasn't been probed through MutatingWebhookConfiguration istio-sidecar-injector yet",
 			},
 		},
 		{
 			name:          "Istiod-remote webhook config not found",
-			values:        &v1.Values{Profile: ptr.Of("remote")},
+			mode:          v1.IstioRevisionModeRemote,
 			clientObjects: []client.Object{},
 			expected: v1.IstioRevisionCondition{
 				Type:    v1.IstioRevisionConditionReady,
@@ -458,7 +542,7 @@ func TestDetermineReadyCondition(t *testing.T) {
 		},
 		{
 			name:          "Istiod-remote client error on get",
-			values:        &v1.Values{Profile: ptr.Of("remote")},
+			mode:          v1.IstioRevisionModeRemote,
 			clientObjects: []client.Object{},
 			interceptors: interceptor.Funcs{
 				Get: func(_ context.Context, _ client.WithWatch, _ client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
@@ -491,9 +575,12 @@ func TestDetermineReadyCondition(t *testing.T) {
 					Namespace: "istio-system",
 					Values:    tt.values,
 				},
+				Status: v1.IstioRevisionStatus{
+					RemoteIstiod: tt.remoteIstiod,
+				},
 			}
 
-			result, err := r.determineReadyCondition(context.TODO(), rev)
+			result, err := r.determineReadyCondition(context.TODO(), rev, tt.mode)
 			if tt.expectErr {
 				g.Expect(err).To(HaveOccurred())
 			} else {
@@ -635,6 +722,16 @@ func TestDetermineInUseCondition(t *testing.T) {
 			enableAllNamespaces: true,
 			matchesRevision:     "default",
 		},
+		{
+			enableAllNamespaces: true,
+			podLabels:           map[string]string{"sidecar.istio.io/inject": "false"},
+			matchesRevision:     "",
+		},
+		{
+			enableAllNamespaces: true,
+			nsLabels:            map[string]string{"istio-injection": "disabled"},
+			matchesRevision:     "",
+		},
 		{
 			interceptors: interceptor.Funcs{
 				List: func(ctx context.Context, client client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
@@ -734,6 +831,51 @@ func TestDetermineInUseCondition(t *testing.T) {
 	}
 }
 
+func TestFinalizeBlocksDeletionWhenInUse(t *testing.T) {
+	g := NewWithT(t)
+	rev := &v1.IstioRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "my-rev",
+			DeletionTimestamp: testtime.OneMinuteAgo(),
+			Finalizers:        []string{constants.FinalizerName},
+		},
+		Spec: v1.IstioRevisionSpec{Namespace: "istio-system", Version: "my-version"},
+	}
+	tag := &v1.IstioRevisionTag{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod"},
+		Status:     v1.IstioRevisionTagStatus{IstioRevision: rev.Name},
+	}
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "bookinfo", Labels: map[string]string{constants.IstioRevLabel: rev.Name}}}
+	var objects []client.Object
+	objects = append(objects, rev, tag, ns)
+	for i := range 7 {
+		objects = append(objects, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("pod-%d", i),
+			Namespace:   "default",
+			Annotations: map[string]string{"istio.io/rev": rev.Name},
+		}})
+	}
+	objects = append(objects, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}})
+
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithStatusSubresource(&v1.IstioRevision{}).
+		WithObjects(objects...).
+		Build()
+	r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme, nil)
+
+	err := r.Finalize(context.TODO(), rev)
+	g.Expect(reconciler.IsTransientError(err)).To(BeTrue())
+
+	g.Expect(cl.Get(context.TODO(), client.ObjectKeyFromObject(rev), rev)).To(Succeed())
+	condition := rev.Status.GetCondition(v1.IstioRevisionConditionDeletionBlocked)
+	g.Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+	g.Expect(condition.Reason).To(Equal(v1.IstioRevisionReasonReferencedByWorkloads))
+	g.Expect(condition.Message).To(Equal("The revision is still referenced by IstioRevisionTags [prod], Namespaces [bookinfo], " +
+		"Pods [default/pod-0, default/pod-1, default/pod-2, default/pod-3, default/pod-4 and 2 more]; " +
+		"remove the references or annotate the IstioRevision with sailoperator.io/force-delete=true"))
+}
+
 func TestIgnoreStatusChangePredicate(t *testing.T) {
 	predicate := ignoreStatusChange()
 
@@ -864,3 +1006,93 @@ func newReconcilerTestConfig(t *testing.T) config.ReconcilerConfig {
 		DefaultProfile:    "",
 	}
 }
+
+func TestUpdateStatusPreservesRemoteIstiodStatus(t *testing.T) {
+	g := NewWithT(t)
+	rev := &v1.IstioRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-rev", Generation: 2},
+		Spec:       v1.IstioRevisionSpec{Namespace: "istio-system", Version: "my-version"},
+		Status: v1.IstioRevisionStatus{
+			Mode: v1.IstioRevisionModeRemote,
+			RemoteIstiod: &v1.RemoteIstiodStatus{
+				WebhookConfigurations: []v1.WebhookConfigurationProbeStatus{
+					{
+						Name:     "istio-sidecar-injector",
+						Webhooks: []v1.WebhookProbeResult{{Name: "rev.namespace.sidecar-injector.istio.io", Ready: true}},
+					},
+				},
+			},
+		},
+	}
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithStatusSubresource(&v1.IstioRevision{}).
+		WithObjects(rev).
+		Build()
+	r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme, nil)
+
+	// the revision in the reconciler's cache predates the latest probe results stored by the webhook controller
+	staleRev := rev.DeepCopy()
+	staleRev.Status.RemoteIstiod.WebhookConfigurations[0].Webhooks[0].Ready = false
+
+	_ = r.updateStatus(context.TODO(), staleRev, nil)
+
+	g.Expect(cl.Get(context.TODO(), client.ObjectKeyFromObject(rev), rev)).To(Succeed())
+	g.Expect(rev.Status.ObservedGeneration).To(Equal(int64(2)))
+	g.Expect(rev.Status.RemoteIstiod.WebhookConfigurations[0].Webhooks[0].Ready).To(BeTrue())
+}
+
+func TestRemoteIstiodReadinessChangedPredicate(t *testing.T) {
+	newRev := func(results ...v1.WebhookProbeResult) *v1.IstioRevision {
+		return &v1.IstioRevision{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-rev"},
+			Spec:       v1.IstioRevisionSpec{Namespace: "istio-system"},
+			Status: v1.IstioRevisionStatus{
+				Mode: v1.IstioRevisionModeRemote,
+				RemoteIstiod: &v1.RemoteIstiodStatus{
+					WebhookConfigurations: []v1.WebhookConfigurationProbeStatus{{Name: "istio-sidecar-injector", Webhooks: results}},
+				},
+			},
+		}
+	}
+	notReady := v1.WebhookProbeResult{Name: "ns.sidecar-injector.istio.io", Message: "connection refused"}
+	ready := v1.WebhookProbeResult{Name: "ns.sidecar-injector.istio.io", Ready: true}
+
+	tests := []struct {
+		name     string
+		oldRev   *v1.IstioRevision
+		newRev   *v1.IstioRevision
+		expected bool
+	}{
+		{
+			name:     "becomes ready",
+			oldRev:   newRev(notReady),
+			newRev:   newRev(ready),
+			expected: true,
+		},
+		{
+			name:     "failure message changes",
+			oldRev:   newRev(notReady),
+			newRev:   newRev(v1.WebhookProbeResult{Name: notReady.Name, Message: "i/o timeout"}),
+			expected: true,
+		},
+		{
+			name:     "transition time changes",
+			oldRev:   newRev(ready),
+			newRev:   newRev(v1.WebhookProbeResult{Name: ready.Name, Ready: true, LastTransitionTime: metav1.Now()}),
+			expected: false,
+		},
+		{
+			name:     "no change",
+			oldRev:   newRev(ready),
+			newRev:   newRev(ready),
+			expected: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(remoteIstiodReadinessChanged().Update(event.UpdateEvent{ObjectOld: tt.oldRev, ObjectNew: tt.newRev})).To(Equal(tt.expected))
+		})
+	}
+}
@@ -15,12 +15,18 @@
 package istiorevisiontag
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"io"
 	"path"
 	"reflect"
+	"strconv"
+	"time"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/go-logr/logr"
 	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
 	"github.com/istio-ecosystem/sail-operator/pkg/config"
@@ -31,12 +37,17 @@ import (
 	"github.com/istio-ecosystem/sail-operator/pkg/kube"
 	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
 	"github.com/istio-ecosystem/sail-operator/pkg/revision"
+	"github.com/istio-ecosystem/sail-operator/pkg/version"
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/postrender"
 	admissionv1 "k8s.io/api/admissionregistration/v1"
 	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
@@ -53,6 +64,12 @@ import (
 
 const (
 	revisionTagsChartName = "revisiontags"
+
+	// canarySuffix is appended to the names of the Helm release and the MutatingWebhookConfiguration of the canary revision
+	canarySuffix = "-canary"
+
+	// namespaceBuckets is the number of buckets the namespaces are distributed into when the tag has a canary revision
+	namespaceBuckets = 100
 )
 
 // Reconciler reconciles an IstioRevisionTag object
@@ -84,56 +101,128 @@ func NewReconciler(reconcilerCfg config.ReconcilerConfig, client client.Client,
 func (r *Reconciler) Reconcile(ctx context.Context, tag *v1.IstioRevisionTag) (ctrl.Result, error) {
 	log := logf.FromContext(ctx).WithValues("IstioRevisionTag", tag.Name)
 
-	rev, reconcileErr := r.doReconcile(ctx, tag)
+	p, reconcileErr := r.doReconcile(ctx, tag)
 
 	log.Info("Reconciliation done. Updating status.")
-	statusErr := r.updateStatus(ctx, tag, rev, reconcileErr)
+	statusErr := r.updateStatus(ctx, tag, p, reconcileErr)
 
 	reconcileErr = errors.Unwrap(reconcileErr)
 
-	return ctrl.Result{}, errors.Join(reconcileErr, statusErr)
+	return ctrl.Result{RequeueAfter: p.requeueAfter}, errors.Join(reconcileErr, statusErr)
 }
 
-func (r *Reconciler) doReconcile(ctx context.Context, tag *v1.IstioRevisionTag) (*v1.IstioRevision, error) {
+func (r *Reconciler) doReconcile(ctx context.Context, tag *v1.IstioRevisionTag) (promotion, error) {
 	log := logf.FromContext(ctx).WithValues("IstioRevisionTag", tag.Name)
 	if err := r.validate(ctx, tag); err != nil {
-		return nil, err
+		return promotion{}, err
 	}
 
 	log.Info("Retrieving referenced IstioRevision for IstioRevisionTag")
-	rev, err := r.getIstioRevision(ctx, tag.Spec.TargetRef)
-	if rev == nil || err != nil {
-		return nil, err
+	targetRev, err := r.getIstioRevision(ctx, tag.Spec.TargetRef)
+	if targetRev == nil || err != nil {
+		return promotion{}, err
+	}
+
+	p, err := r.determinePromotion(ctx, tag, targetRev)
+	if err != nil {
+		return promotion{}, err
+	}
+	if p.pending != nil {
+		log.Info("Moving IstioRevisionTag to new IstioRevision is held back by promotion policy",
+			"IstioRevision", p.revision.Name, "PendingIstioRevision", p.pending.Name, "reason", p.condition.Message)
+	}
+
+	// the canary webhooks select namespaces by their bucket label, so the labels must be in place first
+	if err := r.syncNamespaceBucketLabels(ctx, tag); err != nil {
+		return p, err
+	}
+	canaryBuckets := getCanaryBuckets(tag)
+
+	if err := r.adoptExistingWebhook(ctx, tag, p.revision); err != nil {
+		return p, err
 	}
 
 	log.Info("Installing Helm chart")
-	return rev, r.installHelmCharts(ctx, tag, rev)
+	if err := r.installHelmCharts(ctx, tag, p.revision, canaryBuckets); err != nil {
+		return p, err
+	}
+	return p, r.reconcileCanary(ctx, tag, canaryBuckets)
 }
 
+// Finalize uninstalls the tag's Helm charts and removes the canary bucket labels from the namespaces that
+// reference the tag, or orphans the charts and leaves the labels in place if spec.deletionPolicy is Orphan.
 func (r *Reconciler) Finalize(ctx context.Context, tag *v1.IstioRevisionTag) error {
-	return r.uninstallHelmCharts(ctx, tag)
+	if tag.Spec.DeletionPolicy == v1.DeletionPolicyOrphan {
+		return r.orphanHelmCharts(ctx, tag)
+	}
+	if err := r.uninstallCanaryHelmChart(ctx, tag); err != nil {
+		return err
+	}
+	if err := r.uninstallHelmCharts(ctx, tag); err != nil {
+		return err
+	}
+	return r.syncNamespaceBucketLabels(ctx, tag)
 }
 
 func (r *Reconciler) validate(ctx context.Context, tag *v1.IstioRevisionTag) error {
-	if tag.Spec.TargetRef.Kind == "" || tag.Spec.TargetRef.Name == "" {
+	if !isTargetRefSet(tag.Spec.TargetRef) {
 		return reconciler.NewValidationError("spec.targetRef not set")
 	}
+	if tag.Spec.Canary != nil && !isTargetRefSet(tag.Spec.Canary.TargetRef) {
+		return reconciler.NewValidationError("spec.canary.targetRef not set")
+	}
 	rev := v1.IstioRevision{}
 	if err := r.Client.Get(ctx, types.NamespacedName{Name: tag.Name}, &rev); err == nil {
 		return NewNameAlreadyExistsError("there is an IstioRevision with this name", err)
 	} else if !apierrors.IsNotFound(err) {
 		return err
 	}
-	if tag.Spec.TargetRef.Kind == v1.IstioKind {
+	if err := r.validateTargetRef(ctx, tag.Spec.TargetRef); err != nil {
+		return err
+	}
+	if tag.Spec.Canary != nil {
+		return r.validateTargetRef(ctx, tag.Spec.Canary.TargetRef)
+	}
+	return nil
+}
+
+func isTargetRefSet(ref v1.IstioRevisionTagTargetReference) bool {
+	return ref.Kind != "" && (ref.Name != "" || isSelectorTargetRef(ref))
+}
+
+// isSelectorTargetRef returns true if the reference selects IstioRevisions by their labels or version instead of by name
+func isSelectorTargetRef(ref v1.IstioRevisionTagTargetReference) bool {
+	return ref.Name == "" && (ref.Selector != nil || ref.VersionConstraint != "")
+}
+
+func (r *Reconciler) validateTargetRef(ctx context.Context, ref v1.IstioRevisionTagTargetReference) error {
+	if isSelectorTargetRef(ref) {
+		if ref.Kind != v1.IstioRevisionKind {
+			return reconciler.NewValidationError("selector and versionConstraint can only be used with kind IstioRevision")
+		}
+		if ref.Selector != nil {
+			if _, err := metav1.LabelSelectorAsSelector(ref.Selector); err != nil {
+				return reconciler.NewValidationError("invalid selector: " + err.Error())
+			}
+		}
+		if ref.VersionConstraint != "" {
+			if _, err := semver.NewConstraint(ref.VersionConstraint); err != nil {
+				return reconciler.NewValidationError(fmt.Sprintf("invalid versionConstraint %q: %v", ref.VersionConstraint, err))
+			}
+		}
+		return nil
+	}
+	if ref.Kind == v1.IstioKind {
 		i := v1.Istio{}
-		if err := r.Client.Get(ctx, types.NamespacedName{Name: tag.Spec.TargetRef.Name}, &i); err != nil {
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: ref.Name}, &i); err != nil {
 			if apierrors.IsNotFound(err) {
 				return NewReferenceNotFoundError("referenced Istio resource does not exist", err)
 			}
 			return reconciler.NewValidationError("failed to get referenced Istio resource: " + err.Error())
 		}
-	} else if tag.Spec.TargetRef.Kind == v1.IstioRevisionKind {
-		if err := r.Client.Get(ctx, types.NamespacedName{Name: tag.Spec.TargetRef.Name}, &rev); err != nil {
+	} else if ref.Kind == v1.IstioRevisionKind {
+		rev := v1.IstioRevision{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: ref.Name}, &rev); err != nil {
 			if apierrors.IsNotFound(err) {
 				return NewReferenceNotFoundError("referenced IstioRevision resource does not exist", err)
 			}
@@ -145,7 +234,9 @@ func (r *Reconciler) validate(ctx context.Context, tag *v1.IstioRevisionTag) err
 
 func (r *Reconciler) getIstioRevision(ctx context.Context, ref v1.IstioRevisionTagTargetReference) (*v1.IstioRevision, error) {
 	var revisionName string
-	if ref.Kind == v1.IstioRevisionKind {
+	if isSelectorTargetRef(ref) {
+		return r.selectIstioRevision(ctx, ref)
+	} else if ref.Kind == v1.IstioRevisionKind {
 		revisionName = ref.Name
 	} else if ref.Kind == v1.IstioKind {
 		i := v1.Istio{}
@@ -169,33 +260,367 @@ func (r *Reconciler) getIstioRevision(ctx context.Context, ref v1.IstioRevisionT
 	return &rev, nil
 }
 
-func (r *Reconciler) installHelmCharts(ctx context.Context, tag *v1.IstioRevisionTag, rev *v1.IstioRevision) error {
-	ownerReference := metav1.OwnerReference{
-		APIVersion:         v1.GroupVersion.String(),
-		Kind:               v1.IstioRevisionTagKind,
-		Name:               tag.Name,
-		UID:                tag.UID,
-		Controller:         ptr.Of(true),
-		BlockOwnerDeletion: ptr.Of(true),
+// selectIstioRevision returns the newest Ready IstioRevision that matches the selector and version constraint of
+// the reference. Revisions are ordered by their version first, and then by their creation time.
+func (r *Reconciler) selectIstioRevision(ctx context.Context, ref v1.IstioRevisionTagTargetReference) (*v1.IstioRevision, error) {
+	selector := labels.Everything()
+	if ref.Selector != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(ref.Selector); err != nil {
+			return nil, reconciler.NewValidationError("invalid selector: " + err.Error())
+		}
+	}
+
+	revList := v1.IstioRevisionList{}
+	if err := r.Client.List(ctx, &revList); err != nil {
+		return nil, fmt.Errorf("failed to list IstioRevisions: %w", err)
+	}
+	istioList := v1.IstioList{}
+	if err := r.Client.List(ctx, &istioList); err != nil {
+		return nil, fmt.Errorf("failed to list Istios: %w", err)
+	}
+	istioLabels := map[types.UID]map[string]string{}
+	for _, i := range istioList.Items {
+		istioLabels[i.UID] = i.Labels
+	}
+
+	var selected *v1.IstioRevision
+	for i := range revList.Items {
+		rev := &revList.Items[i]
+		if rev.Status.GetCondition(v1.IstioRevisionConditionReady).Status != metav1.ConditionTrue {
+			continue
+		}
+		if !selector.Matches(labels.Set(revisionLabels(rev, istioLabels))) {
+			continue
+		}
+		if ref.VersionConstraint != "" {
+			if matches, err := version.MatchesConstraint(rev.Spec.Version, ref.VersionConstraint); err != nil || !matches {
+				continue
+			}
+		}
+		if selected == nil || isNewerRevision(rev, selected) {
+			selected = rev
+		}
+	}
+	if selected == nil {
+		return nil, NewReferenceNotFoundError("no Ready IstioRevision matches the targetRef selector and versionConstraint", nil)
+	}
+	return selected, nil
+}
+
+// revisionLabels returns the labels of the revision, merged with the labels of the Istio that owns it. The
+// revision's own labels take precedence.
+func revisionLabels(rev *v1.IstioRevision, istioLabels map[types.UID]map[string]string) map[string]string {
+	result := map[string]string{}
+	for _, ref := range rev.OwnerReferences {
+		if ref.Kind == v1.IstioKind {
+			for k, v := range istioLabels[ref.UID] {
+				result[k] = v
+			}
+		}
+	}
+	for k, v := range rev.Labels {
+		result[k] = v
+	}
+	return result
+}
+
+// isNewerRevision returns true if a has a higher version than b or, if the versions are equal or can't be
+// compared, if a was created after b.
+func isNewerRevision(a, b *v1.IstioRevision) bool {
+	aVersion, aErr := semver.NewVersion(a.Spec.Version)
+	bVersion, bErr := semver.NewVersion(b.Spec.Version)
+	switch {
+	case aErr == nil && bErr == nil && !aVersion.Equal(bVersion):
+		return aVersion.GreaterThan(bVersion)
+	case aErr == nil && bErr != nil:
+		return true
+	case aErr != nil && bErr == nil:
+		return false
+	}
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return b.CreationTimestamp.Before(&a.CreationTimestamp)
+	}
+	return a.Name > b.Name
+}
+
+// promotion describes which IstioRevision the tag points to after the tag's promotion policy has been applied
+type promotion struct {
+	// revision is the IstioRevision the tag points to
+	revision *v1.IstioRevision
+	// pending is the IstioRevision referenced by the tag's targetRef, if the policy doesn't allow moving the tag to it yet
+	pending *v1.IstioRevision
+	// condition is the tag's Promoted condition
+	condition v1.IstioRevisionTagCondition
+	// requeueAfter is the time after which the policy must be re-evaluated, if any
+	requeueAfter time.Duration
+}
+
+// determinePromotion applies the tag's promotion policy and determines whether the tag can be moved from the
+// IstioRevision it currently points to to the IstioRevision referenced by its targetRef. The policy doesn't apply
+// when the tag doesn't point to any revision yet or when the revision it points to no longer exists.
+func (r *Reconciler) determinePromotion(ctx context.Context, tag *v1.IstioRevisionTag, targetRev *v1.IstioRevision) (promotion, error) {
+	promoted := promotion{
+		revision:  targetRev,
+		condition: v1.IstioRevisionTagCondition{Type: v1.IstioRevisionTagConditionPromoted, Status: metav1.ConditionTrue},
+	}
+
+	policy := tag.Spec.PromotionPolicy
+	currentRevName := tag.Status.IstioRevision
+	if policy == nil || currentRevName == "" || currentRevName == targetRev.Name {
+		return promoted, nil
+	}
+
+	currentRev := &v1.IstioRevision{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: currentRevName}, currentRev); err != nil {
+		if apierrors.IsNotFound(err) {
+			return promoted, nil
+		}
+		return promotion{}, fmt.Errorf("failed to get IstioRevision %s: %w", currentRevName, err)
 	}
 
+	pending := promotion{
+		revision: currentRev,
+		pending:  targetRev,
+		condition: v1.IstioRevisionTagCondition{
+			Type:   v1.IstioRevisionTagConditionPromoted,
+			Status: metav1.ConditionFalse,
+			Reason: v1.IstioRevisionTagReasonPromotionPending,
+		},
+	}
+
+	readyCondition := targetRev.Status.GetCondition(v1.IstioRevisionConditionReady)
+	if readyCondition.Status != metav1.ConditionTrue {
+		pending.condition.Message = fmt.Sprintf("waiting for IstioRevision %s to become ready", targetRev.Name)
+		return pending, nil
+	}
+
+	if policy.SoakPeriodSeconds != nil {
+		soakPeriod := time.Duration(*policy.SoakPeriodSeconds) * time.Second
+		if remaining := soakPeriod - time.Since(readyCondition.LastTransitionTime.Time); remaining > 0 {
+			pending.condition.Message = fmt.Sprintf("waiting for IstioRevision %s to be ready for %s", targetRev.Name, soakPeriod)
+			pending.requeueAfter = remaining
+			return pending, nil
+		}
+	}
+
+	if policy.RequireApproval && tag.Annotations[constants.IstioRevisionTagApprovedRevisionAnnotationKey] != targetRev.Name {
+		pending.condition.Reason = v1.IstioRevisionTagReasonAwaitingApproval
+		pending.condition.Message = fmt.Sprintf("waiting for approval; set the annotation %s=%s to move the tag to IstioRevision %s",
+			constants.IstioRevisionTagApprovedRevisionAnnotationKey, targetRev.Name, targetRev.Name)
+		return pending, nil
+	}
+	return promoted, nil
+}
+
+// adoptExistingWebhook checks whether the tag's injection MutatingWebhookConfiguration already exists without
+// being owned by a Sail resource, which is the case when the tag was previously created with `istioctl tag set`.
+// If the tag has the adoption annotation, the webhook configuration is marked as belonging to the tag's Helm
+// release, so that Helm updates it in place when the chart is installed. The webhook configuration is never
+// deleted, so there is no window in which pods are not injected.
+func (r *Reconciler) adoptExistingWebhook(ctx context.Context, tag *v1.IstioRevisionTag, rev *v1.IstioRevision) error {
+	log := logf.FromContext(ctx)
+
+	webhook := admissionv1.MutatingWebhookConfiguration{}
+	if err := r.Client.Get(ctx, injectionWebhookKey(tag, rev), &webhook); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get MutatingWebhookConfiguration: %w", err)
+	}
+	if isOwnedBySailResource(&webhook) || helm.HasReleaseOwnership(&webhook, getReleaseName(tag), rev.Spec.Namespace) {
+		// webhooks orphaned by a previous IstioRevisionTag with the same name are adopted by Helm
+		return nil
+	}
+
+	if tag.Annotations[constants.IstioRevisionTagAdoptWebhookAnnotationKey] != "true" {
+		return NewUnmanagedWebhookError(fmt.Sprintf(
+			"MutatingWebhookConfiguration %s already exists and is not managed by the operator; set the annotation %s=true to adopt it",
+			webhook.Name, constants.IstioRevisionTagAdoptWebhookAnnotationKey))
+	}
+
+	log.Info("Adopting existing MutatingWebhookConfiguration", "MutatingWebhookConfiguration", webhook.Name)
+	patch := client.MergeFrom(webhook.DeepCopy())
+	helm.SetReleaseOwnership(&webhook, getReleaseName(tag), rev.Spec.Namespace)
+	if err := r.Client.Patch(ctx, &webhook, patch); err != nil {
+		return fmt.Errorf("failed to adopt MutatingWebhookConfiguration %s: %w", webhook.Name, err)
+	}
+	return nil
+}
+
+func isOwnedBySailResource(obj client.Object) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if gv, err := schema.ParseGroupVersion(ref.APIVersion); err == nil && gv.Group == v1.GroupVersion.Group {
+			return true
+		}
+	}
+	return false
+}
+
+// installHelmCharts installs the revision tag chart for the specified revision. The injection webhooks don't
+// select the namespaces in the excludedBuckets, which are served by the tag's canary revision.
+func (r *Reconciler) installHelmCharts(ctx context.Context, tag *v1.IstioRevisionTag, rev *v1.IstioRevision, excludedBuckets []string) error {
 	values := helm.FromValues(rev.Spec.Values)
 	if err := values.SetStringSlice("revisionTags", []string{tag.Name}); err != nil {
 		return err
 	}
 
 	_, err := r.ChartManager.UpgradeOrInstallChart(ctx, r.getChartDir(rev),
-		values, rev.Spec.Namespace, getReleaseName(tag), ownerReference)
+		values, rev.Spec.Namespace, getReleaseName(tag), getOwnerReference(tag),
+		newWebhookNamespacePostRenderer("", metav1.LabelSelectorOpNotIn, excludedBuckets))
 	if err != nil {
 		return fmt.Errorf("failed to install/update Helm chart %q: %w", revisionTagsChartName, err)
 	}
 	return nil
 }
 
+// reconcileCanary installs the revision tag chart for the tag's canary revision so that its injection webhooks
+// only select the namespaces in the specified buckets. If the tag has no canary or its weight is zero, the
+// canary chart is uninstalled; syncNamespaceBucketLabels has already removed the bucket labels in that case.
+func (r *Reconciler) reconcileCanary(ctx context.Context, tag *v1.IstioRevisionTag, buckets []string) error {
+	if tag.Spec.Canary == nil || len(buckets) == 0 {
+		return r.uninstallCanaryHelmChart(ctx, tag)
+	}
+
+	canaryRev, err := r.getIstioRevision(ctx, tag.Spec.Canary.TargetRef)
+	if err != nil {
+		return err
+	}
+
+	if status := getCanarySplitStatus(tag); status != nil && status.IstiodNamespace != canaryRev.Spec.Namespace {
+		// the canary revision was moved to a different namespace; remove the release from the old one
+		if err := r.uninstallCanaryHelmChart(ctx, tag); err != nil {
+			return err
+		}
+	}
+
+	values := helm.FromValues(canaryRev.Spec.Values)
+	if err := values.SetStringSlice("revisionTags", []string{tag.Name}); err != nil {
+		return err
+	}
+
+	_, err = r.ChartManager.UpgradeOrInstallChart(ctx, r.getChartDir(canaryRev),
+		values, canaryRev.Spec.Namespace, getCanaryReleaseName(tag), getOwnerReference(tag),
+		newWebhookNamespacePostRenderer(canarySuffix, metav1.LabelSelectorOpIn, buckets))
+	if err != nil {
+		return fmt.Errorf("failed to install/update canary Helm chart %q: %w", revisionTagsChartName, err)
+	}
+	return nil
+}
+
+// getCanaryBuckets returns the buckets whose namespaces are injected by the tag's canary revision, or nil if the
+// tag has no canary or its weight is zero.
+func getCanaryBuckets(tag *v1.IstioRevisionTag) []string {
+	if !hasActiveCanary(tag) {
+		return nil
+	}
+	buckets := make([]string, 0, tag.Spec.Canary.Weight)
+	for bucket := range tag.Spec.Canary.Weight {
+		buckets = append(buckets, strconv.Itoa(int(bucket)))
+	}
+	return buckets
+}
+
+// hasActiveCanary returns true if the tag has a canary revision with a non-zero weight and isn't being deleted.
+func hasActiveCanary(tag *v1.IstioRevisionTag) bool {
+	return tag.DeletionTimestamp == nil && tag.Spec.Canary != nil && tag.Spec.Canary.Weight > 0
+}
+
+// syncNamespaceBucketLabels sets the CanaryBucketLabel on the namespaces that reference the tag while the tag
+// has an active canary, and removes it from them otherwise. The label is also removed from namespaces that no
+// longer reference any IstioRevisionTag. Namespaces that reference another tag are left to that tag.
+func (r *Reconciler) syncNamespaceBucketLabels(ctx context.Context, tag *v1.IstioRevisionTag) error {
+	tagList := v1.IstioRevisionTagList{}
+	if err := r.Client.List(ctx, &tagList); err != nil {
+		return fmt.Errorf("failed to list IstioRevisionTags: %w", err)
+	}
+	tags := map[string]bool{}
+	for _, t := range tagList.Items {
+		tags[t.Name] = true
+	}
+
+	nsList := corev1.NamespaceList{}
+	if err := r.Client.List(ctx, &nsList); err != nil {
+		return fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	for i := range nsList.Items {
+		ns := &nsList.Items[i]
+		referencedTag := revision.GetReferencedRevisionFromNamespace(ns.Labels)
+		if referencedTag != tag.Name && tags[referencedTag] {
+			continue
+		}
+
+		patch := client.MergeFrom(ns.DeepCopy())
+		if referencedTag == tag.Name && hasActiveCanary(tag) {
+			if hasBucketLabel(ns) {
+				continue
+			}
+			if ns.Labels == nil {
+				ns.Labels = map[string]string{}
+			}
+			ns.Labels[constants.CanaryBucketLabel] = namespaceBucketLabelValue(ns.Name)
+		} else if _, found := ns.Labels[constants.CanaryBucketLabel]; found {
+			delete(ns.Labels, constants.CanaryBucketLabel)
+		} else {
+			continue
+		}
+		if err := r.Client.Patch(ctx, ns, patch); err != nil {
+			return fmt.Errorf("failed to update canary bucket label of namespace %s: %w", ns.Name, err)
+		}
+	}
+	return nil
+}
+
+// hasBucketLabel returns true if the namespace is labeled with the bucket it's assigned to.
+func hasBucketLabel(ns client.Object) bool {
+	return ns.GetLabels()[constants.CanaryBucketLabel] == namespaceBucketLabelValue(ns.GetName())
+}
+
+// isCanaryNamespace returns true if the namespace with the specified name falls into one of the first weight buckets.
+func isCanaryNamespace(name string, weight int32) bool {
+	return namespaceBucket(name) < weight
+}
+
+// namespaceBucket returns the bucket the namespace with the specified name is assigned to. The bucket only
+// depends on the name, so once the namespace is labeled with it, the namespace only moves between the primary
+// and the canary revision when the weight changes.
+func namespaceBucket(name string) int32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	return int32(h.Sum32() % namespaceBuckets)
+}
+
+func namespaceBucketLabelValue(name string) string {
+	return strconv.Itoa(int(namespaceBucket(name)))
+}
+
+func getOwnerReference(tag *v1.IstioRevisionTag) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion:         v1.GroupVersion.String(),
+		Kind:               v1.IstioRevisionTagKind,
+		Name:               tag.Name,
+		UID:                tag.UID,
+		Controller:         ptr.Of(true),
+		BlockOwnerDeletion: ptr.Of(true),
+	}
+}
+
 func getReleaseName(tag *v1.IstioRevisionTag) string {
 	return fmt.Sprintf("%s-%s", tag.Name, revisionTagsChartName)
 }
 
+func getCanaryReleaseName(tag *v1.IstioRevisionTag) string {
+	return fmt.Sprintf("%s%s-%s", tag.Name, canarySuffix, revisionTagsChartName)
+}
+
+// getCanarySplitStatus returns the split status entry of the tag's canary revision, if the status has one.
+func getCanarySplitStatus(tag *v1.IstioRevisionTag) *v1.IstioRevisionTagSplitStatus {
+	if len(tag.Status.Split) < 2 {
+		return nil
+	}
+	return &tag.Status.Split[1]
+}
+
 func (r *Reconciler) getChartDir(tag *v1.IstioRevision) string {
 	return path.Join(r.Config.ResourceDirectory, tag.Spec.Version, "charts", revisionTagsChartName)
 }
@@ -207,6 +632,29 @@ func (r *Reconciler) uninstallHelmCharts(ctx context.Context, tag *v1.IstioRevis
 	return nil
 }
 
+func (r *Reconciler) uninstallCanaryHelmChart(ctx context.Context, tag *v1.IstioRevisionTag) error {
+	status := getCanarySplitStatus(tag)
+	if status == nil || status.IstiodNamespace == "" {
+		return nil
+	}
+	if _, err := r.ChartManager.UninstallChart(ctx, getCanaryReleaseName(tag), status.IstiodNamespace); err != nil {
+		return fmt.Errorf("failed to uninstall canary Helm chart %q: %w", revisionTagsChartName, err)
+	}
+	return nil
+}
+
+func (r *Reconciler) orphanHelmCharts(ctx context.Context, tag *v1.IstioRevisionTag) error {
+	if status := getCanarySplitStatus(tag); status != nil && status.IstiodNamespace != "" {
+		if err := r.ChartManager.OrphanRelease(ctx, getCanaryReleaseName(tag), status.IstiodNamespace, tag.UID); err != nil {
+			return fmt.Errorf("failed to orphan canary Helm chart %q: %w", revisionTagsChartName, err)
+		}
+	}
+	if err := r.ChartManager.OrphanRelease(ctx, getReleaseName(tag), tag.Status.IstiodNamespace, tag.UID); err != nil {
+		return fmt.Errorf("failed to orphan Helm chart %q: %w", revisionTagsChartName, err)
+	}
+	return nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	logger := mgr.GetLogger().WithName("ctrlr").WithName("revtag")
@@ -224,12 +672,18 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	// - when a namespace that references the IstioRevisionTag CR via the istio.io/rev
 	//   or istio-injection labels is updated, so that the InUse condition of
 	//   the IstioRevisionTag CR is updated.
+	// - when a namespace stops referencing an IstioRevisionTag, so that the canary
+	//   bucket label is removed from it.
 	nsHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapNamespaceToReconcileRequest))
 
 	// podHandler handles pods that reference the IstioRevisionTag CR via the istio.io/rev or sidecar.istio.io/inject labels.
 	// The handler triggers the reconciliation of the referenced IstioRevision CR so that its InUse condition is updated.
 	podHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapPodToReconcileRequest))
 
+	// unmanagedWebhookHandler handles revision tag MutatingWebhookConfigurations that aren't owned by the operator
+	// (e.g. those created by istioctl), so that the IstioRevisionTag CR is reconciled when they are created or deleted.
+	unmanagedWebhookHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapUnmanagedWebhookToReconcileRequest))
+
 	return ctrl.NewControllerManagedBy(mgr).
 		WithOptions(controller.Options{
 			LogConstructor: func(req *reconcile.Request) logr.Logger {
@@ -251,34 +705,108 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Watches(&v1.Istio{}, operatorResourcesHandler).
 		Watches(&v1.IstioRevision{}, operatorResourcesHandler).
 		Watches(&admissionv1.MutatingWebhookConfiguration{}, ownedResourceHandler).
+		Watches(&admissionv1.MutatingWebhookConfiguration{}, unmanagedWebhookHandler).
 		Complete(reconciler.NewStandardReconcilerWithFinalizer[*v1.IstioRevisionTag](r.Client, r.Reconcile, r.Finalize, constants.FinalizerName))
 }
 
 func (r *Reconciler) determineStatus(ctx context.Context, tag *v1.IstioRevisionTag,
-	rev *v1.IstioRevision, reconcileErr error,
+	p promotion, reconcileErr error,
 ) (v1.IstioRevisionTagStatus, error) {
 	var errs errlist.Builder
 	reconciledCondition := r.determineReconciledCondition(reconcileErr)
 
-	inUseCondition, err := r.determineInUseCondition(ctx, tag)
+	inUseCondition, err := r.determineInUseCondition(ctx, tag, p.revision)
 	errs.Add(err)
 
 	status := *tag.Status.DeepCopy()
 	status.ObservedGeneration = tag.Generation
-	if reconciledCondition.Status == metav1.ConditionTrue && rev != nil {
-		status.IstiodNamespace = rev.Spec.Namespace
-		status.IstioRevision = rev.Name
+	if reconciledCondition.Status == metav1.ConditionTrue && p.revision != nil {
+		if status.IstioRevision != "" && status.IstioRevision != p.revision.Name {
+			status.PreviousIstioRevision = status.IstioRevision
+			status.LastPromotionTime = ptr.Of(metav1.NewTime(time.Now().Truncate(time.Second)))
+		}
+		status.IstiodNamespace = p.revision.Spec.Namespace
+		status.IstioRevision = p.revision.Name
+		status.PendingIstioRevision = ""
+		if p.pending != nil {
+			status.PendingIstioRevision = p.pending.Name
+		}
+		status.SetCondition(p.condition)
+
+		split, err := r.determineSplit(ctx, tag, p.revision)
+		errs.Add(err)
+		status.Split = split
 	}
 	status.SetCondition(reconciledCondition)
 	status.SetCondition(inUseCondition)
-	status.State = deriveState(reconciledCondition, inUseCondition)
+	status.State = deriveState(reconciledCondition, status.GetCondition(v1.IstioRevisionTagConditionPromoted), inUseCondition)
 	return status, errs.Error()
 }
 
-func (r *Reconciler) updateStatus(ctx context.Context, tag *v1.IstioRevisionTag, rev *v1.IstioRevision, reconcileErr error) error {
+// determineSplit reports how the namespaces and pods that use the tag are split between the primary revision
+// and the tag's canary revision. It returns nil if the tag has no canary.
+func (r *Reconciler) determineSplit(ctx context.Context, tag *v1.IstioRevisionTag, rev *v1.IstioRevision) ([]v1.IstioRevisionTagSplitStatus, error) {
+	if tag.Spec.Canary == nil {
+		return nil, nil
+	}
+	canaryRev, err := r.getIstioRevision(ctx, tag.Spec.Canary.TargetRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get canary IstioRevision: %w", err)
+	}
+
+	split := []v1.IstioRevisionTagSplitStatus{
+		{IstioRevision: rev.Name, IstiodNamespace: rev.Spec.Namespace, Weight: namespaceBuckets - tag.Spec.Canary.Weight},
+		{IstioRevision: canaryRev.Name, IstiodNamespace: canaryRev.Spec.Namespace, Weight: tag.Spec.Canary.Weight},
+	}
+
+	nsList := corev1.NamespaceList{}
+	if err := r.Client.List(ctx, &nsList); err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	// namespaces that use the tag, either through their labels or because they contain pods that reference it
+	usingNamespaces := map[string]bool{}
+	referencingNamespaces := map[string]bool{}
+	for _, ns := range nsList.Items {
+		if namespaceReferencesRevisionTag(ns, tag) {
+			referencingNamespaces[ns.Name] = true
+			usingNamespaces[ns.Name] = true
+		}
+	}
+
+	podList := corev1.PodList{}
+	if err := r.Client.List(ctx, &podList); err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	for _, pod := range podList.Items {
+		if !referencingNamespaces[pod.Namespace] && revision.GetReferencedRevisionFromPod(pod.GetLabels()) != tag.Name {
+			continue
+		}
+		usingNamespaces[pod.Namespace] = true
+		injectedRevision := revision.GetInjectedRevisionFromPod(pod.GetAnnotations())
+		for i := range split {
+			if split[i].IstioRevision == injectedRevision {
+				split[i].Pods++
+				break
+			}
+		}
+	}
+
+	// only the namespaces that reference the tag are labeled with their bucket, so pods that reference the tag
+	// in other namespaces are injected by the primary revision
+	for ns := range usingNamespaces {
+		if referencingNamespaces[ns] && isCanaryNamespace(ns, tag.Spec.Canary.Weight) {
+			split[1].Namespaces++
+		} else {
+			split[0].Namespaces++
+		}
+	}
+	return split, nil
+}
+
+func (r *Reconciler) updateStatus(ctx context.Context, tag *v1.IstioRevisionTag, p promotion, reconcileErr error) error {
 	var errs errlist.Builder
 
-	status, err := r.determineStatus(ctx, tag, rev, reconcileErr)
+	status, err := r.determineStatus(ctx, tag, p, reconcileErr)
 	if err != nil {
 		errs.Add(fmt.Errorf("failed to determine status: %w", err))
 	}
@@ -291,10 +819,13 @@ func (r *Reconciler) updateStatus(ctx context.Context, tag *v1.IstioRevisionTag,
 	return errs.Error()
 }
 
-func deriveState(reconciledCondition, inUseCondition v1.IstioRevisionTagCondition) v1.IstioRevisionTagConditionReason {
+func deriveState(reconciledCondition, promotedCondition, inUseCondition v1.IstioRevisionTagCondition) v1.IstioRevisionTagConditionReason {
 	if reconciledCondition.Status != metav1.ConditionTrue {
 		return reconciledCondition.Reason
 	}
+	if promotedCondition.Status == metav1.ConditionFalse {
+		return promotedCondition.Reason
+	}
 	if inUseCondition.Status != metav1.ConditionTrue {
 		return inUseCondition.Reason
 	}
@@ -314,6 +845,10 @@ func (r *Reconciler) determineReconciledCondition(err error) v1.IstioRevisionTag
 		c.Status = metav1.ConditionFalse
 		c.Reason = v1.IstioRevisionTagReasonReferenceNotFound
 		c.Message = err.Error()
+	} else if IsUnmanagedWebhookError(err) {
+		c.Status = metav1.ConditionFalse
+		c.Reason = v1.IstioRevisionTagReasonUnmanagedWebhookExists
+		c.Message = err.Error()
 	} else {
 		c.Status = metav1.ConditionFalse
 		c.Reason = v1.IstioRevisionTagReasonReconcileError
@@ -322,10 +857,12 @@ func (r *Reconciler) determineReconciledCondition(err error) v1.IstioRevisionTag
 	return c
 }
 
-func (r *Reconciler) determineInUseCondition(ctx context.Context, tag *v1.IstioRevisionTag) (v1.IstioRevisionTagCondition, error) {
+// determineInUseCondition determines whether the tag is used by any workload. The rev argument is the IstioRevision
+// the tag points to; if it's nil, the revision referenced by the tag's targetRef is used.
+func (r *Reconciler) determineInUseCondition(ctx context.Context, tag *v1.IstioRevisionTag, rev *v1.IstioRevision) (v1.IstioRevisionTagCondition, error) {
 	c := v1.IstioRevisionTagCondition{Type: v1.IstioRevisionTagConditionInUse}
 
-	isReferenced, err := r.isRevisionTagReferencedByWorkloads(ctx, tag)
+	isReferenced, err := r.isRevisionTagReferencedByWorkloads(ctx, tag, rev)
 	if err == nil {
 		if isReferenced {
 			c.Status = metav1.ConditionTrue
@@ -344,7 +881,7 @@ func (r *Reconciler) determineInUseCondition(ctx context.Context, tag *v1.IstioR
 	return c, fmt.Errorf("failed to determine if IstioRevisionTag is in use: %w", err)
 }
 
-func (r *Reconciler) isRevisionTagReferencedByWorkloads(ctx context.Context, tag *v1.IstioRevisionTag) (bool, error) {
+func (r *Reconciler) isRevisionTagReferencedByWorkloads(ctx context.Context, tag *v1.IstioRevisionTag, rev *v1.IstioRevision) (bool, error) {
 	log := logf.FromContext(ctx)
 	nsList := corev1.NamespaceList{}
 	nsMap := map[string]corev1.Namespace{}
@@ -359,27 +896,47 @@ func (r *Reconciler) isRevisionTagReferencedByWorkloads(ctx context.Context, tag
 		nsMap[ns.Name] = ns
 	}
 
-	podList := corev1.PodList{}
-	if err := r.Client.List(ctx, &podList); err != nil { // TODO: can we optimize this by specifying a label selector
-		return false, fmt.Errorf("failed to list pods: %w", err)
-	}
-	for _, pod := range podList.Items {
-		if ns, found := nsMap[pod.Namespace]; found && podReferencesRevisionTag(pod, tag, ns) {
-			log.V(2).Info("RevisionTag is referenced by Pod", "Pod", client.ObjectKeyFromObject(&pod))
-			return true, nil
+	if rev == nil {
+		var err error
+		if rev, err = r.getIstioRevision(ctx, tag.Spec.TargetRef); err != nil {
+			return false, err
 		}
 	}
 
-	rev, err := r.getIstioRevision(ctx, tag.Spec.TargetRef)
+	injectionPolicy, err := r.getInjectionPolicy(ctx, tag, rev, injectionWebhookKey(tag, rev))
 	if err != nil {
 		return false, err
 	}
+	injectionPolicies := []*revision.InjectionPolicy{injectionPolicy}
+	if tag.Spec.Canary != nil {
+		canaryRev, err := r.getIstioRevision(ctx, tag.Spec.Canary.TargetRef)
+		if err != nil {
+			return false, err
+		}
+		canaryKey := injectionWebhookKey(tag, canaryRev)
+		canaryKey.Name += canarySuffix
+		canaryInjectionPolicy, err := r.getInjectionPolicy(ctx, tag, canaryRev, canaryKey)
+		if err != nil {
+			return false, err
+		}
+		injectionPolicies = append(injectionPolicies, canaryInjectionPolicy)
+	}
 
-	if tag.Name == v1.DefaultRevision && rev.Spec.Values != nil &&
-		rev.Spec.Values.SidecarInjectorWebhook != nil &&
-		rev.Spec.Values.SidecarInjectorWebhook.EnableNamespacesByDefault != nil &&
-		*rev.Spec.Values.SidecarInjectorWebhook.EnableNamespacesByDefault {
-		return true, nil
+	podList := corev1.PodList{}
+	if err := r.Client.List(ctx, &podList); err != nil { // TODO: can we optimize this by specifying a label selector
+		return false, fmt.Errorf("failed to list pods: %w", err)
+	}
+	for _, pod := range podList.Items {
+		ns, found := nsMap[pod.Namespace]
+		if !found {
+			continue
+		}
+		for _, injectionPolicy := range injectionPolicies {
+			if injectionPolicy.InjectsPod(pod.GetLabels(), pod.GetAnnotations(), &ns) {
+				log.V(2).Info("RevisionTag is referenced by Pod", "Pod", client.ObjectKeyFromObject(&pod))
+				return true, nil
+			}
+		}
 	}
 
 	log.V(2).Info("RevisionTag is not referenced by any Pod or Namespace")
@@ -390,9 +947,29 @@ func namespaceReferencesRevisionTag(ns corev1.Namespace, tag *v1.IstioRevisionTa
 	return tag.Name == revision.GetReferencedRevisionFromNamespace(ns.Labels)
 }
 
-func podReferencesRevisionTag(pod corev1.Pod, tag *v1.IstioRevisionTag, ns corev1.Namespace) bool {
-	return revision.GetReferencedRevisionFromNamespace(ns.Labels) == "" &&
-		tag.Name == revision.GetReferencedRevisionFromPod(pod.GetLabels())
+// getInjectionPolicy returns the InjectionPolicy of the revision tag based on the values of the referenced
+// revision and the tag's MutatingWebhookConfiguration with the given key, if it has already been installed.
+func (r *Reconciler) getInjectionPolicy(
+	ctx context.Context, tag *v1.IstioRevisionTag, rev *v1.IstioRevision, webhookKey client.ObjectKey,
+) (*revision.InjectionPolicy, error) {
+	webhook := admissionv1.MutatingWebhookConfiguration{}
+	if err := r.Client.Get(ctx, webhookKey, &webhook); err == nil {
+		return revision.NewInjectionPolicy(tag.Name, rev.Spec.Values, &webhook), nil
+	} else if apierrors.IsNotFound(err) {
+		return revision.NewInjectionPolicy(tag.Name, rev.Spec.Values, nil), nil
+	} else {
+		return nil, fmt.Errorf("failed to get injection MutatingWebhookConfiguration: %w", err)
+	}
+}
+
+func injectionWebhookKey(tag *v1.IstioRevisionTag, rev *v1.IstioRevision) client.ObjectKey {
+	name := "istio-revision-tag-" + tag.Name
+	if rev.Spec.Namespace != "istio-system" {
+		name += "-" + rev.Spec.Namespace
+	}
+	return client.ObjectKey{
+		Name: name,
+	}
 }
 
 func (r *Reconciler) mapNamespaceToReconcileRequest(ctx context.Context, ns client.Object) []reconcile.Request {
@@ -403,6 +980,26 @@ func (r *Reconciler) mapNamespaceToReconcileRequest(ctx context.Context, ns clie
 	if tag != "" {
 		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: tag}})
 	}
+
+	// The referenced tag manages the namespace's canary bucket label. If the namespace no longer references
+	// a tag, but still has the label, the tags with a canary remove it.
+	if _, labeled := ns.GetLabels()[constants.CanaryBucketLabel]; labeled {
+		tags := v1.IstioRevisionTagList{}
+		if err := r.Client.List(ctx, &tags); err != nil {
+			logf.FromContext(ctx).Error(err, "failed to list IstioRevisionTags")
+			return requests
+		}
+		for _, t := range tags.Items {
+			if t.Name == tag {
+				return requests
+			}
+		}
+		for _, t := range tags.Items {
+			if hasActiveCanary(&t) {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: t.Name}})
+			}
+		}
+	}
 	return requests
 }
 
@@ -414,10 +1011,19 @@ func (r *Reconciler) mapPodToReconcileRequest(ctx context.Context, pod client.Ob
 	return nil
 }
 
+func (r *Reconciler) mapUnmanagedWebhookToReconcileRequest(ctx context.Context, webhook client.Object) []reconcile.Request {
+	tag := webhook.GetLabels()[constants.IstioTagLabel]
+	if tag != "" && !isOwnedBySailResource(webhook) {
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: tag}}}
+	}
+	return nil
+}
+
 func (r *Reconciler) mapOperatorResourceToReconcileRequest(ctx context.Context, obj client.Object) []reconcile.Request {
-	var revisionName string
+	var revisionName, istioName string
 	if i, ok := obj.(*v1.Istio); ok && i.Status.ActiveRevisionName != "" {
 		revisionName = i.Status.ActiveRevisionName
+		istioName = i.Name
 	} else if rev, ok := obj.(*v1.IstioRevision); ok {
 		revisionName = rev.Name
 	} else {
@@ -430,13 +1036,34 @@ func (r *Reconciler) mapOperatorResourceToReconcileRequest(ctx context.Context,
 	}
 	requests := []reconcile.Request{}
 	for _, tag := range tags.Items {
-		if tag.Status.IstioRevision == revisionName {
+		// tags that are waiting to be moved to the revision are also enqueued, so that the promotion
+		// policy is re-evaluated when the revision becomes ready
+		if tag.Status.IstioRevision == revisionName || tag.Status.PendingIstioRevision == revisionName ||
+			(istioName != "" && tag.Spec.TargetRef.Kind == v1.IstioKind && tag.Spec.TargetRef.Name == istioName) ||
+			canaryReferences(&tag, revisionName, istioName) || usesSelectorTargetRef(&tag) {
 			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: tag.Name}})
 		}
 	}
 	return requests
 }
 
+// usesSelectorTargetRef returns true if the tag or its canary selects IstioRevisions by their labels or version.
+// Such tags must be reconciled whenever an Istio or IstioRevision changes, since the change may affect which
+// revision they select.
+func usesSelectorTargetRef(tag *v1.IstioRevisionTag) bool {
+	return isSelectorTargetRef(tag.Spec.TargetRef) || (tag.Spec.Canary != nil && isSelectorTargetRef(tag.Spec.Canary.TargetRef))
+}
+
+// canaryReferences returns true if the tag's canary references the IstioRevision or the Istio with the specified name.
+func canaryReferences(tag *v1.IstioRevisionTag, revisionName, istioName string) bool {
+	if tag.Spec.Canary == nil {
+		return false
+	}
+	ref := tag.Spec.Canary.TargetRef
+	return (ref.Kind == v1.IstioRevisionKind && ref.Name == revisionName) ||
+		(istioName != "" && ref.Kind == v1.IstioKind && ref.Name == istioName)
+}
+
 // ignoreStatusChange returns a predicate that ignores watch events where only the resource status changes; if
 // there are any other changes to the resource, the event is not ignored.
 // This ensures that the controller doesn't reconcile the entire IstioRevisionTag every time the status of an owned
@@ -470,6 +1097,97 @@ func wrapEventHandler(logger logr.Logger, handler handler.EventHandler) handler.
 	return enqueuelogger.WrapIfNecessary(v1.IstioRevisionTagKind, logger, handler)
 }
 
+// webhookNamespacePostRenderer is a Helm PostRenderer that restricts the webhooks in the rendered
+// MutatingWebhookConfigurations to the namespaces in (or not in) a set of buckets by adding a CanaryBucketLabel
+// expression to their namespaceSelector. It also appends the nameSuffix to the name of each MutatingWebhookConfiguration,
+// so that the configurations rendered for the primary and the canary revision don't collide.
+type webhookNamespacePostRenderer struct {
+	nameSuffix string
+	operator   metav1.LabelSelectorOperator
+	buckets    []string
+}
+
+var _ postrender.PostRenderer = webhookNamespacePostRenderer{}
+
+func newWebhookNamespacePostRenderer(nameSuffix string, operator metav1.LabelSelectorOperator, buckets []string) webhookNamespacePostRenderer {
+	return webhookNamespacePostRenderer{
+		nameSuffix: nameSuffix,
+		operator:   operator,
+		buckets:    buckets,
+	}
+}
+
+func (pr webhookNamespacePostRenderer) Run(renderedManifests *bytes.Buffer) (modifiedManifests *bytes.Buffer, err error) {
+	modifiedManifests = &bytes.Buffer{}
+	encoder := yaml.NewEncoder(modifiedManifests)
+	encoder.SetIndent(2)
+	decoder := yaml.NewDecoder(renderedManifests)
+	for {
+		manifest := map[string]any{}
+
+		if err := decoder.Decode(&manifest); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+
+		if manifest == nil {
+			continue
+		}
+
+		if manifest["kind"] == "MutatingWebhookConfiguration" {
+			if err := pr.restrictWebhooks(manifest); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := encoder.Encode(manifest); err != nil {
+			return nil, err
+		}
+	}
+	return modifiedManifests, nil
+}
+
+func (pr webhookNamespacePostRenderer) restrictWebhooks(manifest map[string]any) error {
+	if pr.nameSuffix != "" {
+		metadata, ok := manifest["metadata"].(map[string]any)
+		if !ok {
+			return fmt.Errorf("MutatingWebhookConfiguration has no metadata")
+		}
+		metadata["name"] = fmt.Sprintf("%v%s", metadata["name"], pr.nameSuffix)
+	}
+
+	if len(pr.buckets) == 0 {
+		return nil
+	}
+
+	values := make([]any, 0, len(pr.buckets))
+	for _, bucket := range pr.buckets {
+		values = append(values, bucket)
+	}
+
+	webhooks, _ := manifest["webhooks"].([]any)
+	for _, w := range webhooks {
+		webhook, ok := w.(map[string]any)
+		if !ok {
+			return fmt.Errorf("unexpected webhook in MutatingWebhookConfiguration: %v", w)
+		}
+		namespaceSelector, _ := webhook["namespaceSelector"].(map[string]any)
+		if namespaceSelector == nil {
+			namespaceSelector = map[string]any{}
+			webhook["namespaceSelector"] = namespaceSelector
+		}
+		matchExpressions, _ := namespaceSelector["matchExpressions"].([]any)
+		namespaceSelector["matchExpressions"] = append(matchExpressions, map[string]any{
+			"key":      constants.CanaryBucketLabel,
+			"operator": string(pr.operator),
+			"values":   values,
+		})
+	}
+	return nil
+}
+
 type NameAlreadyExistsError struct {
 	Message       string
 	originalError error
@@ -523,3 +1241,24 @@ func IsReferenceNotFoundError(err error) bool {
 	}
 	return false
 }
+
+type UnmanagedWebhookError struct {
+	Message string
+}
+
+func (err UnmanagedWebhookError) Error() string {
+	return err.Message
+}
+
+func NewUnmanagedWebhookError(message string) UnmanagedWebhookError {
+	return UnmanagedWebhookError{
+		Message: message,
+	}
+}
+
+func IsUnmanagedWebhookError(err error) bool {
+	if _, ok := err.(UnmanagedWebhookError); ok {
+		return true
+	}
+	return false
+}
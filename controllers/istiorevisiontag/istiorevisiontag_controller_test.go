@@ -15,20 +15,30 @@
 package istiorevisiontag
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
 	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	"github.com/istio-ecosystem/sail-operator/pkg/helm"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
 	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
 	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admissionregistration/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"istio.io/istio/pkg/ptr"
 )
@@ -250,7 +260,7 @@ func TestDetermineInUseCondition(t *testing.T) {
 
 				r := NewReconciler(cfg, cl, scheme.Scheme, nil)
 
-				result, _ := r.determineInUseCondition(context.TODO(), tag)
+				result, _ := r.determineInUseCondition(context.TODO(), tag, nil)
 				g.Expect(result.Type).To(Equal(v1.IstioRevisionTagConditionInUse))
 
 				if tc.expectUnknownState {
@@ -282,3 +292,989 @@ func newReconcilerTestConfig(t *testing.T) config.ReconcilerConfig {
 		DefaultProfile:    "",
 	}
 }
+
+func TestDeterminePromotion(t *testing.T) {
+	cfg := newReconcilerTestConfig(t)
+
+	newRevision := func(name string, readySince *time.Time) *v1.IstioRevision {
+		rev := &v1.IstioRevision{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       v1.IstioRevisionSpec{Namespace: "istio-system"},
+		}
+		if readySince != nil {
+			rev.Status.Conditions = []v1.IstioRevisionCondition{
+				{
+					Type:               v1.IstioRevisionConditionReady,
+					Status:             metav1.ConditionTrue,
+					LastTransitionTime: metav1.NewTime(*readySince),
+				},
+			}
+		}
+		return rev
+	}
+
+	longAgo := time.Now().Add(-time.Hour)
+	justNow := time.Now().Add(-10 * time.Second)
+
+	testCases := []struct {
+		name            string
+		policy          *v1.IstioRevisionTagPromotionPolicy
+		currentRevision string
+		annotations     map[string]string
+		target          *v1.IstioRevision
+		expectRevision  string
+		expectPending   string
+		expectReason    v1.IstioRevisionTagConditionReason
+		expectRequeue   bool
+	}{
+		{
+			name:            "no policy moves tag immediately",
+			currentRevision: "old",
+			target:          newRevision("new", nil),
+			expectRevision:  "new",
+		},
+		{
+			name:           "new tag is not held back",
+			policy:         &v1.IstioRevisionTagPromotionPolicy{SoakPeriodSeconds: ptr.Of(int64(300)), RequireApproval: true},
+			target:         newRevision("new", nil),
+			expectRevision: "new",
+		},
+		{
+			name:            "tag is not held back when current revision no longer exists",
+			policy:          &v1.IstioRevisionTagPromotionPolicy{RequireApproval: true},
+			currentRevision: "deleted",
+			target:          newRevision("new", nil),
+			expectRevision:  "new",
+		},
+		{
+			name:            "target revision not ready",
+			policy:          &v1.IstioRevisionTagPromotionPolicy{},
+			currentRevision: "old",
+			target:          newRevision("new", nil),
+			expectRevision:  "old",
+			expectPending:   "new",
+			expectReason:    v1.IstioRevisionTagReasonPromotionPending,
+		},
+		{
+			name:            "target revision ready",
+			policy:          &v1.IstioRevisionTagPromotionPolicy{},
+			currentRevision: "old",
+			target:          newRevision("new", &justNow),
+			expectRevision:  "new",
+		},
+		{
+			name:            "soak period not elapsed",
+			policy:          &v1.IstioRevisionTagPromotionPolicy{SoakPeriodSeconds: ptr.Of(int64(300))},
+			currentRevision: "old",
+			target:          newRevision("new", &justNow),
+			expectRevision:  "old",
+			expectPending:   "new",
+			expectReason:    v1.IstioRevisionTagReasonPromotionPending,
+			expectRequeue:   true,
+		},
+		{
+			name:            "soak period elapsed",
+			policy:          &v1.IstioRevisionTagPromotionPolicy{SoakPeriodSeconds: ptr.Of(int64(300))},
+			currentRevision: "old",
+			target:          newRevision("new", &longAgo),
+			expectRevision:  "new",
+		},
+		{
+			name:            "approval required",
+			policy:          &v1.IstioRevisionTagPromotionPolicy{SoakPeriodSeconds: ptr.Of(int64(300)), RequireApproval: true},
+			currentRevision: "old",
+			target:          newRevision("new", &longAgo),
+			expectRevision:  "old",
+			expectPending:   "new",
+			expectReason:    v1.IstioRevisionTagReasonAwaitingApproval,
+		},
+		{
+			name:            "approval for another revision",
+			policy:          &v1.IstioRevisionTagPromotionPolicy{RequireApproval: true},
+			currentRevision: "old",
+			annotations:     map[string]string{constants.IstioRevisionTagApprovedRevisionAnnotationKey: "old"},
+			target:          newRevision("new", &longAgo),
+			expectRevision:  "old",
+			expectPending:   "new",
+			expectReason:    v1.IstioRevisionTagReasonAwaitingApproval,
+		},
+		{
+			name:            "approved",
+			policy:          &v1.IstioRevisionTagPromotionPolicy{RequireApproval: true},
+			currentRevision: "old",
+			annotations:     map[string]string{constants.IstioRevisionTagApprovedRevisionAnnotationKey: "new"},
+			target:          newRevision("new", &longAgo),
+			expectRevision:  "new",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			tag := &v1.IstioRevisionTag{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "prod",
+					Annotations: tc.annotations,
+				},
+				Spec: v1.IstioRevisionTagSpec{
+					TargetRef:       v1.IstioRevisionTagTargetReference{Kind: v1.IstioKind, Name: "default"},
+					PromotionPolicy: tc.policy,
+				},
+				Status: v1.IstioRevisionTagStatus{
+					IstioRevision: tc.currentRevision,
+				},
+			}
+
+			cl := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(newRevision("old", &longAgo), tc.target).
+				Build()
+			r := NewReconciler(cfg, cl, scheme.Scheme, nil)
+
+			p, err := r.determinePromotion(context.TODO(), tag, tc.target)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			g.Expect(p.revision.Name).To(Equal(tc.expectRevision))
+			if tc.expectPending == "" {
+				g.Expect(p.pending).To(BeNil())
+				g.Expect(p.condition.Status).To(Equal(metav1.ConditionTrue))
+			} else {
+				g.Expect(p.pending).ToNot(BeNil())
+				g.Expect(p.pending.Name).To(Equal(tc.expectPending))
+				g.Expect(p.condition.Status).To(Equal(metav1.ConditionFalse))
+				g.Expect(p.condition.Reason).To(Equal(tc.expectReason))
+			}
+			if tc.expectRequeue {
+				g.Expect(p.requeueAfter).To(BeNumerically("~", 290*time.Second, 5*time.Second))
+			} else {
+				g.Expect(p.requeueAfter).To(BeZero())
+			}
+		})
+	}
+}
+
+func TestDetermineStatusRecordsPromotion(t *testing.T) {
+	g := NewWithT(t)
+	cfg := newReconcilerTestConfig(t)
+
+	oldRev := &v1.IstioRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "old"},
+		Spec:       v1.IstioRevisionSpec{Namespace: "istio-system"},
+	}
+	newRev := &v1.IstioRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "new"},
+		Spec:       v1.IstioRevisionSpec{Namespace: "istio-system"},
+	}
+	tag := &v1.IstioRevisionTag{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod"},
+		Spec: v1.IstioRevisionTagSpec{
+			TargetRef: v1.IstioRevisionTagTargetReference{Kind: v1.IstioRevisionKind, Name: "new"},
+		},
+		Status: v1.IstioRevisionTagStatus{
+			IstioRevision:   "old",
+			IstiodNamespace: "istio-system",
+		},
+	}
+
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(oldRev, newRev, tag).
+		Build()
+	r := NewReconciler(cfg, cl, scheme.Scheme, nil)
+
+	// promotion held back
+	status, err := r.determineStatus(context.TODO(), tag, promotion{
+		revision: oldRev,
+		pending:  newRev,
+		condition: v1.IstioRevisionTagCondition{
+			Type:   v1.IstioRevisionTagConditionPromoted,
+			Status: metav1.ConditionFalse,
+			Reason: v1.IstioRevisionTagReasonAwaitingApproval,
+		},
+	}, nil)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(status.IstioRevision).To(Equal("old"))
+	g.Expect(status.PendingIstioRevision).To(Equal("new"))
+	g.Expect(status.PreviousIstioRevision).To(BeEmpty())
+	g.Expect(status.LastPromotionTime).To(BeNil())
+	g.Expect(status.State).To(Equal(v1.IstioRevisionTagReasonAwaitingApproval))
+
+	// promotion done
+	tag.Status = status
+	status, err = r.determineStatus(context.TODO(), tag, promotion{
+		revision:  newRev,
+		condition: v1.IstioRevisionTagCondition{Type: v1.IstioRevisionTagConditionPromoted, Status: metav1.ConditionTrue},
+	}, nil)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(status.IstioRevision).To(Equal("new"))
+	g.Expect(status.PendingIstioRevision).To(BeEmpty())
+	g.Expect(status.PreviousIstioRevision).To(Equal("old"))
+	g.Expect(status.LastPromotionTime).ToNot(BeNil())
+	g.Expect(status.GetCondition(v1.IstioRevisionTagConditionPromoted).Status).To(Equal(metav1.ConditionTrue))
+}
+
+func TestNamespaceBucket(t *testing.T) {
+	g := NewWithT(t)
+
+	for i := 0; i < 1000; i++ {
+		name := fmt.Sprintf("ns-%d", i)
+		bucket := namespaceBucket(name)
+		g.Expect(bucket).To(And(BeNumerically(">=", 0), BeNumerically("<", namespaceBuckets)))
+		g.Expect(namespaceBucket(name)).To(Equal(bucket), "bucket must be stable")
+
+		// increasing the weight never moves a namespace back to the primary revision
+		g.Expect(isCanaryNamespace(name, 0)).To(BeFalse())
+		g.Expect(isCanaryNamespace(name, 100)).To(BeTrue())
+		for weight := int32(1); weight <= 100; weight++ {
+			if isCanaryNamespace(name, weight-1) {
+				g.Expect(isCanaryNamespace(name, weight)).To(BeTrue())
+			}
+		}
+	}
+}
+
+// findNamespaceNames returns a namespace name that is assigned to the canary revision and one that isn't, at the given weight
+func findNamespaceNames(t *testing.T, weight int32) (canary string, primary string) {
+	for i := 0; canary == "" || primary == ""; i++ {
+		if i > 10000 {
+			t.Fatalf("could not find namespace names for weight %d", weight)
+		}
+		name := fmt.Sprintf("ns-%d", i)
+		if isCanaryNamespace(name, weight) {
+			if canary == "" {
+				canary = name
+			}
+		} else if primary == "" {
+			primary = name
+		}
+	}
+	return canary, primary
+}
+
+func TestGetCanaryBuckets(t *testing.T) {
+	var allBuckets []string
+	for bucket := range namespaceBuckets {
+		allBuckets = append(allBuckets, strconv.Itoa(bucket))
+	}
+	canaryTarget := v1.IstioRevisionTagTargetReference{Kind: v1.IstioRevisionKind, Name: "new"}
+
+	testCases := []struct {
+		name     string
+		canary   *v1.IstioRevisionTagCanary
+		expected []string
+	}{
+		{
+			name:     "no canary",
+			expected: nil,
+		},
+		{
+			name:     "weight 0",
+			canary:   &v1.IstioRevisionTagCanary{TargetRef: canaryTarget},
+			expected: nil,
+		},
+		{
+			name:     "weight 3",
+			canary:   &v1.IstioRevisionTagCanary{TargetRef: canaryTarget, Weight: 3},
+			expected: []string{"0", "1", "2"},
+		},
+		{
+			name:     "weight 100",
+			canary:   &v1.IstioRevisionTagCanary{TargetRef: canaryTarget, Weight: 100},
+			expected: allBuckets,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			tag := &v1.IstioRevisionTag{
+				ObjectMeta: metav1.ObjectMeta{Name: "prod"},
+				Spec: v1.IstioRevisionTagSpec{
+					TargetRef: v1.IstioRevisionTagTargetReference{Kind: v1.IstioRevisionKind, Name: "old"},
+					Canary:    tc.canary,
+				},
+			}
+			g.Expect(getCanaryBuckets(tag)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestSyncNamespaceBucketLabels(t *testing.T) {
+	cfg := newReconcilerTestConfig(t)
+	canaryTarget := v1.IstioRevisionTagTargetReference{Kind: v1.IstioRevisionKind, Name: "new"}
+	bucketLabel := func(name string) map[string]string {
+		return map[string]string{constants.CanaryBucketLabel: namespaceBucketLabelValue(name)}
+	}
+
+	testCases := []struct {
+		name             string
+		canary           *v1.IstioRevisionTagCanary
+		deleted          bool
+		initiallyLabeled bool
+		expectLabeled    []string
+	}{
+		{
+			name:          "labels only namespaces that reference the tag",
+			canary:        &v1.IstioRevisionTagCanary{TargetRef: canaryTarget, Weight: 30},
+			expectLabeled: []string{"references-tag", "other-tag"},
+		},
+		{
+			name:             "corrects modified labels and removes stale ones",
+			canary:           &v1.IstioRevisionTagCanary{TargetRef: canaryTarget, Weight: 30},
+			initiallyLabeled: true,
+			expectLabeled:    []string{"references-tag", "other-tag"},
+		},
+		{
+			name:             "removes labels when the weight drops to 0",
+			canary:           &v1.IstioRevisionTagCanary{TargetRef: canaryTarget},
+			initiallyLabeled: true,
+			expectLabeled:    []string{"other-tag"},
+		},
+		{
+			name:             "removes labels when the canary is removed",
+			initiallyLabeled: true,
+			expectLabeled:    []string{"other-tag"},
+		},
+		{
+			name:             "removes labels when the tag is deleted",
+			canary:           &v1.IstioRevisionTagCanary{TargetRef: canaryTarget, Weight: 30},
+			deleted:          true,
+			initiallyLabeled: true,
+			expectLabeled:    []string{"other-tag"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			tag := &v1.IstioRevisionTag{
+				ObjectMeta: metav1.ObjectMeta{Name: "prod"},
+				Spec: v1.IstioRevisionTagSpec{
+					TargetRef: v1.IstioRevisionTagTargetReference{Kind: v1.IstioRevisionKind, Name: "old"},
+					Canary:    tc.canary,
+				},
+			}
+			if tc.deleted {
+				tag.DeletionTimestamp = ptr.Of(metav1.Now())
+				tag.Finalizers = []string{constants.FinalizerName}
+			}
+			otherTag := &v1.IstioRevisionTag{
+				ObjectMeta: metav1.ObjectMeta{Name: "staging"},
+				Spec: v1.IstioRevisionTagSpec{
+					TargetRef: v1.IstioRevisionTagTargetReference{Kind: v1.IstioRevisionKind, Name: "old"},
+					Canary:    &v1.IstioRevisionTagCanary{TargetRef: canaryTarget, Weight: 30},
+				},
+			}
+
+			newNamespace := func(name string, labels map[string]string) *corev1.Namespace {
+				ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{}}}
+				for k, v := range labels {
+					ns.Labels[k] = v
+				}
+				if tc.initiallyLabeled {
+					ns.Labels[constants.CanaryBucketLabel] = "modified"
+				}
+				return ns
+			}
+			cl := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(
+					tag, otherTag,
+					newNamespace("references-tag", map[string]string{constants.IstioRevLabel: "prod"}),
+					newNamespace("references-revision", map[string]string{constants.IstioRevLabel: "old"}),
+					newNamespace("kube-system", nil),
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+						Name: "other-tag",
+						// the other tag has already labeled its namespace
+						Labels: mergeMaps(map[string]string{constants.IstioRevLabel: "staging"}, bucketLabel("other-tag")),
+					}},
+				).
+				Build()
+			r := NewReconciler(cfg, cl, scheme.Scheme, nil)
+
+			g.Expect(r.syncNamespaceBucketLabels(context.TODO(), tag)).To(Succeed())
+
+			nsList := &corev1.NamespaceList{}
+			g.Expect(cl.List(context.TODO(), nsList)).To(Succeed())
+			var labeled []string
+			for _, ns := range nsList.Items {
+				if value, found := ns.Labels[constants.CanaryBucketLabel]; found {
+					g.Expect(value).To(Equal(namespaceBucketLabelValue(ns.Name)))
+					labeled = append(labeled, ns.Name)
+				}
+			}
+			g.Expect(labeled).To(ConsistOf(tc.expectLabeled))
+		})
+	}
+}
+
+func TestMapLabeledNamespaceToReconcileRequest(t *testing.T) {
+	g := NewWithT(t)
+	canaryTarget := v1.IstioRevisionTagTargetReference{Kind: v1.IstioRevisionKind, Name: "new"}
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(
+			&v1.IstioRevisionTag{ObjectMeta: metav1.ObjectMeta{Name: "with-canary"}, Spec: v1.IstioRevisionTagSpec{
+				Canary: &v1.IstioRevisionTagCanary{TargetRef: canaryTarget, Weight: 10},
+			}},
+			&v1.IstioRevisionTag{ObjectMeta: metav1.ObjectMeta{Name: "zero-weight"}, Spec: v1.IstioRevisionTagSpec{
+				Canary: &v1.IstioRevisionTagCanary{TargetRef: canaryTarget},
+			}},
+			&v1.IstioRevisionTag{ObjectMeta: metav1.ObjectMeta{Name: "without-canary"}},
+		).
+		Build()
+	r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme, nil)
+
+	// an unlabeled namespace that doesn't reference a tag isn't relevant
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "new-namespace"}}
+	g.Expect(r.mapNamespaceToReconcileRequest(context.TODO(), ns)).To(BeEmpty())
+
+	// a namespace that references a tag is handled by that tag
+	ns.Labels = map[string]string{constants.IstioRevLabel: "zero-weight", constants.CanaryBucketLabel: namespaceBucketLabelValue(ns.Name)}
+	g.Expect(r.mapNamespaceToReconcileRequest(context.TODO(), ns)).
+		To(ConsistOf(reconcile.Request{NamespacedName: types.NamespacedName{Name: "zero-weight"}}))
+
+	// a labeled namespace that no longer references a tag is cleaned up by the tags with a canary
+	ns.Labels = map[string]string{constants.IstioRevLabel: "some-revision", constants.CanaryBucketLabel: namespaceBucketLabelValue(ns.Name)}
+	g.Expect(r.mapNamespaceToReconcileRequest(context.TODO(), ns)).To(ConsistOf(
+		reconcile.Request{NamespacedName: types.NamespacedName{Name: "some-revision"}},
+		reconcile.Request{NamespacedName: types.NamespacedName{Name: "with-canary"}},
+	))
+}
+
+func mergeMaps(maps ...map[string]string) map[string]string {
+	merged := map[string]string{}
+	for _, m := range maps {
+		for k, v := range m {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+func TestWebhookNamespacePostRenderer(t *testing.T) {
+	input := `---
+apiVersion: admissionregistration.k8s.io/v1
+kind: MutatingWebhookConfiguration
+metadata:
+  name: istio-revision-tag-prod
+webhooks:
+- name: rev.namespace.sidecar-injector.istio.io
+  clientConfig:
+    service:
+      name: istiod-old
+      port: 443
+  namespaceSelector:
+    matchExpressions:
+    - key: istio.io/rev
+      operator: In
+      values:
+      - prod
+- name: rev.object.sidecar-injector.istio.io
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: not-a-webhook
+`
+
+	testCases := []struct {
+		name         string
+		postRenderer webhookNamespacePostRenderer
+		expected     string
+	}{
+		{
+			name:         "no buckets",
+			postRenderer: newWebhookNamespacePostRenderer("", metav1.LabelSelectorOpNotIn, nil),
+			expected: `apiVersion: admissionregistration.k8s.io/v1
+kind: MutatingWebhookConfiguration
+metadata:
+  name: istio-revision-tag-prod
+webhooks:
+  - clientConfig:
+      service:
+        name: istiod-old
+        port: 443
+    name: rev.namespace.sidecar-injector.istio.io
+    namespaceSelector:
+      matchExpressions:
+        - key: istio.io/rev
+          operator: In
+          values:
+            - prod
+  - name: rev.object.sidecar-injector.istio.io
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: not-a-webhook
+`,
+		},
+		{
+			name:         "excluded buckets",
+			postRenderer: newWebhookNamespacePostRenderer("", metav1.LabelSelectorOpNotIn, []string{"0", "1"}),
+			expected: `apiVersion: admissionregistration.k8s.io/v1
+kind: MutatingWebhookConfiguration
+metadata:
+  name: istio-revision-tag-prod
+webhooks:
+  - clientConfig:
+      service:
+        name: istiod-old
+        port: 443
+    name: rev.namespace.sidecar-injector.istio.io
+    namespaceSelector:
+      matchExpressions:
+        - key: istio.io/rev
+          operator: In
+          values:
+            - prod
+        - key: sailoperator.io/canary-bucket
+          operator: NotIn
+          values:
+            - "0"
+            - "1"
+  - name: rev.object.sidecar-injector.istio.io
+    namespaceSelector:
+      matchExpressions:
+        - key: sailoperator.io/canary-bucket
+          operator: NotIn
+          values:
+            - "0"
+            - "1"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: not-a-webhook
+`,
+		},
+		{
+			name:         "canary",
+			postRenderer: newWebhookNamespacePostRenderer(canarySuffix, metav1.LabelSelectorOpIn, []string{"0"}),
+			expected: `apiVersion: admissionregistration.k8s.io/v1
+kind: MutatingWebhookConfiguration
+metadata:
+  name: istio-revision-tag-prod-canary
+webhooks:
+  - clientConfig:
+      service:
+        name: istiod-old
+        port: 443
+    name: rev.namespace.sidecar-injector.istio.io
+    namespaceSelector:
+      matchExpressions:
+        - key: istio.io/rev
+          operator: In
+          values:
+            - prod
+        - key: sailoperator.io/canary-bucket
+          operator: In
+          values:
+            - "0"
+  - name: rev.object.sidecar-injector.istio.io
+    namespaceSelector:
+      matchExpressions:
+        - key: sailoperator.io/canary-bucket
+          operator: In
+          values:
+            - "0"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: not-a-webhook
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			actual, err := tc.postRenderer.Run(bytes.NewBufferString(input))
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(actual.String()).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestDetermineSplit(t *testing.T) {
+	g := NewWithT(t)
+	cfg := newReconcilerTestConfig(t)
+	canaryNs, primaryNs := findNamespaceNames(t, 30)
+	// a namespace in one of the canary buckets, whose pods reference the tag
+	podReferenceNs := ""
+	for i := 0; podReferenceNs == ""; i++ {
+		if name := fmt.Sprintf("pod-reference-%d", i); isCanaryNamespace(name, 30) {
+			podReferenceNs = name
+		}
+	}
+
+	oldRev := &v1.IstioRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "old"},
+		Spec:       v1.IstioRevisionSpec{Namespace: "istio-system"},
+	}
+	newRev := &v1.IstioRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "new"},
+		Spec:       v1.IstioRevisionSpec{Namespace: "istio-canary"},
+	}
+	tag := &v1.IstioRevisionTag{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod"},
+		Spec: v1.IstioRevisionTagSpec{
+			TargetRef: v1.IstioRevisionTagTargetReference{Kind: v1.IstioRevisionKind, Name: "old"},
+			Canary: &v1.IstioRevisionTagCanary{
+				TargetRef: v1.IstioRevisionTagTargetReference{Kind: v1.IstioRevisionKind, Name: "new"},
+				Weight:    30,
+			},
+		},
+	}
+
+	newPod := func(name, namespace string, labels map[string]string, injectedBy string) *corev1.Pod {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels}}
+		if injectedBy != "" {
+			pod.Annotations = map[string]string{constants.IstioRevLabel: injectedBy}
+		}
+		return pod
+	}
+
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(
+			oldRev, newRev, tag,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: primaryNs, Labels: map[string]string{constants.IstioRevLabel: "prod"}}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: canaryNs, Labels: map[string]string{constants.IstioRevLabel: "prod"}}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: podReferenceNs}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "unrelated"}},
+			newPod("primary-1", primaryNs, nil, "old"),
+			newPod("primary-2", primaryNs, nil, "old"),
+			newPod("not-yet-injected", primaryNs, nil, ""),
+			newPod("canary-1", canaryNs, nil, "new"),
+			newPod("opted-out", canaryNs, map[string]string{constants.IstioSidecarInjectLabel: "false"}, ""),
+			// the namespace doesn't reference the tag, so it isn't labeled with its bucket and the pod is injected by the primary revision
+			newPod("pod-reference", podReferenceNs, map[string]string{constants.IstioRevLabel: "prod"}, "old"),
+			newPod("unrelated", "unrelated", map[string]string{constants.IstioRevLabel: "other"}, "other"),
+		).
+		Build()
+	r := NewReconciler(cfg, cl, scheme.Scheme, nil)
+
+	split, err := r.determineSplit(context.TODO(), tag, oldRev)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(split).To(Equal([]v1.IstioRevisionTagSplitStatus{
+		{IstioRevision: "old", IstiodNamespace: "istio-system", Weight: 70, Namespaces: 2, Pods: 3},
+		{IstioRevision: "new", IstiodNamespace: "istio-canary", Weight: 30, Namespaces: 1, Pods: 1},
+	}))
+
+	tag.Spec.Canary = nil
+	split, err = r.determineSplit(context.TODO(), tag, oldRev)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(split).To(BeNil())
+}
+
+func TestAdoptExistingWebhook(t *testing.T) {
+	cfg := newReconcilerTestConfig(t)
+
+	rev := &v1.IstioRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "default-v1-24-2"},
+		Spec:       v1.IstioRevisionSpec{Namespace: "istio-system"},
+	}
+
+	testCases := []struct {
+		name              string
+		webhook           *admissionv1.MutatingWebhookConfiguration
+		annotations       map[string]string
+		expectUnmanaged   bool
+		expectReleaseName string
+	}{
+		{
+			name: "no existing webhook",
+		},
+		{
+			name: "webhook owned by the tag",
+			webhook: &admissionv1.MutatingWebhookConfiguration{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "istio-revision-tag-prod",
+					OwnerReferences: []metav1.OwnerReference{
+						{APIVersion: v1.GroupVersion.String(), Kind: v1.IstioRevisionTagKind, Name: "prod", UID: "123"},
+					},
+				},
+			},
+		},
+		{
+			name: "webhook orphaned by a tag with the same name",
+			webhook: &admissionv1.MutatingWebhookConfiguration{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "istio-revision-tag-prod",
+					Labels: map[string]string{
+						constants.IstioTagLabel:             "prod",
+						constants.KubernetesAppManagedByKey: helm.ManagedByHelmValue,
+					},
+					Annotations: map[string]string{
+						helm.AnnotationReleaseName:      "prod-revisiontags",
+						helm.AnnotationReleaseNamespace: "istio-system",
+					},
+				},
+			},
+			expectReleaseName: "prod-revisiontags",
+		},
+		{
+			name: "unmanaged webhook without adoption annotation",
+			webhook: &admissionv1.MutatingWebhookConfiguration{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "istio-revision-tag-prod",
+					Labels: map[string]string{constants.IstioTagLabel: "prod"},
+				},
+			},
+			expectUnmanaged: true,
+		},
+		{
+			name: "unmanaged webhook with adoption annotation set to false",
+			webhook: &admissionv1.MutatingWebhookConfiguration{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "istio-revision-tag-prod",
+					Labels: map[string]string{constants.IstioTagLabel: "prod"},
+				},
+			},
+			annotations:     map[string]string{constants.IstioRevisionTagAdoptWebhookAnnotationKey: "false"},
+			expectUnmanaged: true,
+		},
+		{
+			name: "unmanaged webhook with adoption annotation",
+			webhook: &admissionv1.MutatingWebhookConfiguration{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "istio-revision-tag-prod",
+					Labels: map[string]string{constants.IstioTagLabel: "prod"},
+				},
+			},
+			annotations:       map[string]string{constants.IstioRevisionTagAdoptWebhookAnnotationKey: "true"},
+			expectReleaseName: "prod-revisiontags",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			tag := &v1.IstioRevisionTag{
+				ObjectMeta: metav1.ObjectMeta{Name: "prod", UID: "123", Annotations: tc.annotations},
+				Spec: v1.IstioRevisionTagSpec{
+					TargetRef: v1.IstioRevisionTagTargetReference{Kind: v1.IstioRevisionKind, Name: rev.Name},
+				},
+			}
+			builder := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(rev, tag)
+			if tc.webhook != nil {
+				builder.WithObjects(tc.webhook)
+			}
+			cl := builder.Build()
+			r := NewReconciler(cfg, cl, scheme.Scheme, nil)
+
+			err := r.adoptExistingWebhook(context.TODO(), tag, rev)
+			if tc.expectUnmanaged {
+				g.Expect(IsUnmanagedWebhookError(err)).To(BeTrue())
+				g.Expect(err.Error()).To(ContainSubstring("istio-revision-tag-prod"))
+				g.Expect(errors.Unwrap(err)).To(BeNil(), "an unmanaged webhook must not cause the tag to be requeued")
+
+				condition := r.determineReconciledCondition(err)
+				g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+				g.Expect(condition.Reason).To(Equal(v1.IstioRevisionTagReasonUnmanagedWebhookExists))
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+
+			if tc.webhook != nil {
+				webhook := &admissionv1.MutatingWebhookConfiguration{}
+				g.Expect(cl.Get(context.TODO(), client.ObjectKeyFromObject(tc.webhook), webhook)).To(Succeed())
+				if tc.expectReleaseName != "" {
+					g.Expect(webhook.Labels).To(HaveKeyWithValue(constants.KubernetesAppManagedByKey, helm.ManagedByHelmValue))
+					g.Expect(webhook.Labels).To(HaveKeyWithValue(constants.IstioTagLabel, "prod"))
+					g.Expect(webhook.Annotations).To(HaveKeyWithValue(helm.AnnotationReleaseName, tc.expectReleaseName))
+					g.Expect(webhook.Annotations).To(HaveKeyWithValue(helm.AnnotationReleaseNamespace, "istio-system"))
+				} else {
+					g.Expect(webhook.Annotations).ToNot(HaveKey(helm.AnnotationReleaseName))
+				}
+			}
+		})
+	}
+}
+
+func TestMapUnmanagedWebhookToReconcileRequest(t *testing.T) {
+	g := NewWithT(t)
+	r := NewReconciler(newReconcilerTestConfig(t), fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(), scheme.Scheme, nil)
+
+	unmanaged := &admissionv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "istio-revision-tag-prod", Labels: map[string]string{constants.IstioTagLabel: "prod"}},
+	}
+	g.Expect(r.mapUnmanagedWebhookToReconcileRequest(context.TODO(), unmanaged)).
+		To(Equal([]reconcile.Request{{NamespacedName: types.NamespacedName{Name: "prod"}}}))
+
+	owned := unmanaged.DeepCopy()
+	owned.OwnerReferences = []metav1.OwnerReference{{APIVersion: v1.GroupVersion.String(), Kind: v1.IstioRevisionTagKind, Name: "prod"}}
+	g.Expect(r.mapUnmanagedWebhookToReconcileRequest(context.TODO(), owned)).To(BeNil())
+
+	other := &admissionv1.MutatingWebhookConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "istio-sidecar-injector"}}
+	g.Expect(r.mapUnmanagedWebhookToReconcileRequest(context.TODO(), other)).To(BeNil())
+}
+
+func TestSelectIstioRevision(t *testing.T) {
+	cfg := newReconcilerTestConfig(t)
+
+	now := time.Now()
+	istio := &v1.Istio{
+		ObjectMeta: metav1.ObjectMeta{Name: "blue", UID: "blue-uid", Labels: map[string]string{"channel": "stable"}},
+	}
+	newRevision := func(name, version string, ready bool, created time.Time, labels map[string]string, ownedByIstio bool) *v1.IstioRevision {
+		rev := &v1.IstioRevision{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels, CreationTimestamp: metav1.NewTime(created)},
+			Spec:       v1.IstioRevisionSpec{Version: version, Namespace: "istio-system"},
+		}
+		if ownedByIstio {
+			rev.OwnerReferences = []metav1.OwnerReference{{APIVersion: v1.GroupVersion.String(), Kind: v1.IstioKind, Name: istio.Name, UID: istio.UID}}
+		}
+		status := metav1.ConditionFalse
+		if ready {
+			status = metav1.ConditionTrue
+		}
+		rev.Status.Conditions = []v1.IstioRevisionCondition{{Type: v1.IstioRevisionConditionReady, Status: status}}
+		return rev
+	}
+
+	revisions := []client.Object{
+		newRevision("blue-v1-23-4", "v1.23.4", true, now.Add(-3*time.Hour), nil, true),
+		newRevision("blue-v1-24-2", "v1.24.2", true, now.Add(-2*time.Hour), nil, true),
+		newRevision("canary-v1-24-2", "v1.24.2", true, now.Add(-time.Hour), map[string]string{"channel": "canary"}, false),
+		newRevision("unready-v1-25-0", "v1.25.0", false, now, map[string]string{"channel": "canary"}, false),
+		newRevision("latest", "latest", true, now, map[string]string{"channel": "latest"}, false),
+		istio,
+	}
+
+	testCases := []struct {
+		name           string
+		ref            v1.IstioRevisionTagTargetReference
+		expectRevision string
+		expectNotFound bool
+	}{
+		{
+			name: "selector matching labels of owning Istio",
+			ref: v1.IstioRevisionTagTargetReference{
+				Kind:     v1.IstioRevisionKind,
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"channel": "stable"}},
+			},
+			expectRevision: "blue-v1-24-2",
+		},
+		{
+			name: "selector matching labels of revision",
+			ref: v1.IstioRevisionTagTargetReference{
+				Kind:     v1.IstioRevisionKind,
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"channel": "canary"}},
+			},
+			expectRevision: "canary-v1-24-2",
+		},
+		{
+			name:           "version constraint",
+			ref:            v1.IstioRevisionTagTargetReference{Kind: v1.IstioRevisionKind, VersionConstraint: "~1.23"},
+			expectRevision: "blue-v1-23-4",
+		},
+		{
+			name:           "same version selects newest revision",
+			ref:            v1.IstioRevisionTagTargetReference{Kind: v1.IstioRevisionKind, VersionConstraint: ">=1.24"},
+			expectRevision: "canary-v1-24-2",
+		},
+		{
+			name: "selector and version constraint",
+			ref: v1.IstioRevisionTagTargetReference{
+				Kind:              v1.IstioRevisionKind,
+				Selector:          &metav1.LabelSelector{MatchLabels: map[string]string{"channel": "stable"}},
+				VersionConstraint: "<1.24",
+			},
+			expectRevision: "blue-v1-23-4",
+		},
+		{
+			name: "non-semver version only matches selector",
+			ref: v1.IstioRevisionTagTargetReference{
+				Kind:     v1.IstioRevisionKind,
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"channel": "latest"}},
+			},
+			expectRevision: "latest",
+		},
+		{
+			name:           "no ready revision matches",
+			ref:            v1.IstioRevisionTagTargetReference{Kind: v1.IstioRevisionKind, VersionConstraint: ">=1.25"},
+			expectNotFound: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(revisions...).Build()
+			r := NewReconciler(cfg, cl, scheme.Scheme, nil)
+
+			rev, err := r.getIstioRevision(context.TODO(), tc.ref)
+			if tc.expectNotFound {
+				g.Expect(IsReferenceNotFoundError(err)).To(BeTrue())
+				g.Expect(errors.Unwrap(err)).To(BeNil())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(rev.Name).To(Equal(tc.expectRevision))
+		})
+	}
+}
+
+func TestValidateSelectorTargetRef(t *testing.T) {
+	cfg := newReconcilerTestConfig(t)
+
+	testCases := []struct {
+		name        string
+		ref         v1.IstioRevisionTagTargetReference
+		expectError string
+	}{
+		{
+			name: "valid selector",
+			ref:  v1.IstioRevisionTagTargetReference{Kind: v1.IstioRevisionKind, Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"a": "b"}}},
+		},
+		{
+			name: "valid version constraint",
+			ref:  v1.IstioRevisionTagTargetReference{Kind: v1.IstioRevisionKind, VersionConstraint: ">=1.24, <1.25"},
+		},
+		{
+			name:        "invalid version constraint",
+			ref:         v1.IstioRevisionTagTargetReference{Kind: v1.IstioRevisionKind, VersionConstraint: "not-a-constraint"},
+			expectError: "invalid versionConstraint",
+		},
+		{
+			name: "invalid selector",
+			ref: v1.IstioRevisionTagTargetReference{
+				Kind: v1.IstioRevisionKind,
+				Selector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "a", Operator: "Bogus"}},
+				},
+			},
+			expectError: "invalid selector",
+		},
+		{
+			name:        "selector with kind Istio",
+			ref:         v1.IstioRevisionTagTargetReference{Kind: v1.IstioKind, VersionConstraint: ">=1.24"},
+			expectError: "can only be used with kind IstioRevision",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			tag := &v1.IstioRevisionTag{
+				ObjectMeta: metav1.ObjectMeta{Name: "prod"},
+				Spec:       v1.IstioRevisionTagSpec{TargetRef: tc.ref},
+			}
+			cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+			r := NewReconciler(cfg, cl, scheme.Scheme, nil)
+
+			err := r.validate(context.TODO(), tag)
+			if tc.expectError == "" {
+				g.Expect(err).ToNot(HaveOccurred())
+			} else {
+				g.Expect(reconciler.IsValidationError(err)).To(BeTrue())
+				g.Expect(err.Error()).To(ContainSubstring(tc.expectError))
+			}
+		})
+	}
+}
@@ -0,0 +1,451 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mesh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/api/v1alpha1"
+	"github.com/istio-ecosystem/sail-operator/pkg/enqueuelogger"
+	"github.com/istio-ecosystem/sail-operator/pkg/errlist"
+	"github.com/istio-ecosystem/sail-operator/pkg/kube"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	"github.com/istio-ecosystem/sail-operator/pkg/revision"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// meshName is the name of the only Mesh object that the operator reconciles
+	meshName = "default"
+)
+
+// Reconciler aggregates the status of all Istio resources in the cluster into the Mesh object
+type Reconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+func NewReconciler(client client.Client, scheme *runtime.Scheme) *Reconciler {
+	return &Reconciler{
+		Client: client,
+		Scheme: scheme,
+	}
+}
+
+// +kubebuilder:rbac:groups=sailoperator.io,resources=meshes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=sailoperator.io,resources=meshes/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=sailoperator.io,resources=meshes/finalizers,verbs=update
+// +kubebuilder:rbac:groups=sailoperator.io,resources=istiorevisions;istiorevisiontags;istiocnis;ztunnels,verbs=get;list;watch
+// +kubebuilder:rbac:groups="apps",resources=daemonsets,verbs=get;list;watch
+
+// Reconcile collects the status of all IstioRevisions, IstioRevisionTags, the IstioCNI and the ZTunnel
+// in the cluster and stores a summary of it in the status of the Mesh object.
+func (r *Reconciler) Reconcile(ctx context.Context, mesh *v1alpha1.Mesh) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	log.Info("Collecting status of mesh components")
+	status, reconcileErr := r.determineStatus(ctx, mesh)
+
+	var statusErr error
+	if !reflect.DeepEqual(mesh.Status, status) {
+		if err := r.Client.Status().Patch(ctx, mesh, kube.NewStatusPatch(status)); err != nil {
+			statusErr = fmt.Errorf("failed to patch status: %w", err)
+		}
+	}
+	return ctrl.Result{}, errors.Join(reconcileErr, statusErr)
+}
+
+func (r *Reconciler) determineStatus(ctx context.Context, mesh *v1alpha1.Mesh) (v1alpha1.MeshStatus, error) {
+	var errs errlist.Builder
+
+	status := *mesh.Status.DeepCopy()
+	status.ObservedGeneration = mesh.Generation
+
+	revisions, err := r.getRevisions(ctx)
+	errs.Add(err)
+	tags, err := r.getTags(ctx)
+	errs.Add(err)
+	cni, err := r.getCNI(ctx)
+	errs.Add(err)
+	ztunnel, err := r.getZTunnel(ctx)
+	errs.Add(err)
+	versionSkew, err := r.determineVersionSkew(ctx, cni, ztunnel)
+	errs.Add(err)
+
+	reconciledCondition := determineReconciledCondition(errs.Error())
+	status.SetCondition(reconciledCondition)
+	if reconciledCondition.Status != metav1.ConditionTrue {
+		// keep the previously reported summary, since it can't be determined reliably
+		status.State = deriveState(status.Conditions)
+		return status, errs.Error()
+	}
+
+	status.Revisions = revisions
+	status.Tags = tags
+	status.CNI = cni
+	status.ZTunnel = ztunnel
+	status.Versions = collectVersions(revisions, cni, ztunnel)
+	status.VersionSkew = versionSkew
+
+	status.SetCondition(determineReadyCondition(revisions, cni, ztunnel))
+	status.SetCondition(determineVersionSkewCondition(status.VersionSkew))
+	status.State = deriveState(status.Conditions)
+	return status, nil
+}
+
+func (r *Reconciler) getRevisions(ctx context.Context) ([]v1alpha1.MeshRevisionStatus, error) {
+	revList := v1.IstioRevisionList{}
+	if err := r.Client.List(ctx, &revList); err != nil {
+		return nil, fmt.Errorf("failed to list IstioRevisions: %w", err)
+	}
+
+	var revisions []v1alpha1.MeshRevisionStatus
+	for _, rev := range revList.Items {
+		summary := v1alpha1.MeshRevisionStatus{
+			Name:      rev.Name,
+			Version:   rev.Spec.Version,
+			Namespace: rev.Spec.Namespace,
+			Ready:     rev.Status.GetCondition(v1.IstioRevisionConditionReady).Status,
+			InUse:     rev.Status.GetCondition(v1.IstioRevisionConditionInUse).Status,
+			State:     string(rev.Status.State),
+		}
+		for _, ownerRef := range rev.OwnerReferences {
+			if ownerRef.APIVersion == v1.GroupVersion.String() && ownerRef.Kind == v1.IstioKind {
+				summary.Istio = ownerRef.Name
+				break
+			}
+		}
+		revisions = append(revisions, summary)
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Name < revisions[j].Name })
+	return revisions, nil
+}
+
+func (r *Reconciler) getTags(ctx context.Context) ([]v1alpha1.MeshTagStatus, error) {
+	tagList := v1.IstioRevisionTagList{}
+	if err := r.Client.List(ctx, &tagList); err != nil {
+		return nil, fmt.Errorf("failed to list IstioRevisionTags: %w", err)
+	}
+
+	var tags []v1alpha1.MeshTagStatus
+	for _, tag := range tagList.Items {
+		tags = append(tags, v1alpha1.MeshTagStatus{
+			Name:       tag.Name,
+			TargetKind: tag.Spec.TargetRef.Kind,
+			TargetName: tag.Spec.TargetRef.Name,
+			Revision:   tag.Status.IstioRevision,
+			InUse:      tag.Status.GetCondition(v1.IstioRevisionTagConditionInUse).Status,
+			State:      string(tag.Status.State),
+		})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags, nil
+}
+
+func (r *Reconciler) getCNI(ctx context.Context) (*v1alpha1.MeshDaemonSetComponentStatus, error) {
+	cniList := v1.IstioCNIList{}
+	if err := r.Client.List(ctx, &cniList); err != nil {
+		return nil, fmt.Errorf("failed to list IstioCNIs: %w", err)
+	}
+	if len(cniList.Items) == 0 {
+		return nil, nil
+	}
+
+	cni := cniList.Items[0]
+	summary := &v1alpha1.MeshDaemonSetComponentStatus{
+		Name:      cni.Name,
+		Version:   installedVersion(cni.Status.Version, cni.Spec.Version),
+		Namespace: cni.Spec.Namespace,
+		Ready:     cni.Status.GetCondition(v1.IstioCNIConditionReady).Status,
+		State:     string(cni.Status.State),
+	}
+	if err := r.setDaemonSetStatus(ctx, summary, client.ObjectKey{Namespace: cni.Spec.Namespace, Name: "istio-cni-node"}); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+func (r *Reconciler) getZTunnel(ctx context.Context) (*v1alpha1.MeshDaemonSetComponentStatus, error) {
+	ztunnelList := v1.ZTunnelList{}
+	if err := r.Client.List(ctx, &ztunnelList); err != nil {
+		return nil, fmt.Errorf("failed to list ZTunnels: %w", err)
+	}
+	if len(ztunnelList.Items) == 0 {
+		return nil, nil
+	}
+
+	ztunnel := ztunnelList.Items[0]
+	summary := &v1alpha1.MeshDaemonSetComponentStatus{
+		Name:      ztunnel.Name,
+		Version:   installedVersion(ztunnel.Status.Version, ztunnel.Spec.Version),
+		Namespace: ztunnel.Spec.Namespace,
+		Ready:     ztunnel.Status.GetCondition(v1.ZTunnelConditionReady).Status,
+		State:     string(ztunnel.Status.State),
+	}
+	if err := r.setDaemonSetStatus(ctx, summary, client.ObjectKey{Namespace: ztunnel.Spec.Namespace, Name: "ztunnel"}); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// installedVersion returns the version reported in the component's status, which differs from spec.version
+// when the component tracks the version of an Istio resource. Older components don't report it yet.
+func installedVersion(statusVersion, specVersion string) string {
+	if statusVersion != "" {
+		return statusVersion
+	}
+	return specVersion
+}
+
+// setDaemonSetStatus copies the rollout status of the component's DaemonSet into the summary
+func (r *Reconciler) setDaemonSetStatus(ctx context.Context, summary *v1alpha1.MeshDaemonSetComponentStatus, key client.ObjectKey) error {
+	ds := appsv1.DaemonSet{}
+	if err := r.Client.Get(ctx, key, &ds); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get DaemonSet %s: %w", key, err)
+	}
+	summary.DesiredNumberScheduled = ds.Status.DesiredNumberScheduled
+	summary.UpdatedNumberScheduled = ds.Status.UpdatedNumberScheduled
+	summary.NumberReady = ds.Status.NumberReady
+	summary.NumberAvailable = ds.Status.NumberAvailable
+	return nil
+}
+
+func collectVersions(revisions []v1alpha1.MeshRevisionStatus, components ...*v1alpha1.MeshDaemonSetComponentStatus) []string {
+	versionSet := map[string]struct{}{}
+	for _, rev := range revisions {
+		if rev.Version != "" {
+			versionSet[rev.Version] = struct{}{}
+		}
+	}
+	for _, component := range components {
+		if component != nil && component.Version != "" {
+			versionSet[component.Version] = struct{}{}
+		}
+	}
+
+	var versions []string
+	for v := range versionSet {
+		versions = append(versions, v)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// determineVersionSkew returns the pairs of data plane components and in-use IstioRevisions whose versions are too
+// far apart. It performs the same check as the IstioCNI and ZTunnel controllers, which refuse to install or upgrade
+// a component with such a version.
+func (r *Reconciler) determineVersionSkew(
+	ctx context.Context, cni, ztunnel *v1alpha1.MeshDaemonSetComponentStatus,
+) ([]v1alpha1.MeshVersionSkew, error) {
+	var skew []v1alpha1.MeshVersionSkew
+	for _, component := range []struct {
+		kind   string
+		status *v1alpha1.MeshDaemonSetComponentStatus
+	}{
+		{kind: v1.IstioCNIKind, status: cni},
+		{kind: v1.ZTunnelKind, status: ztunnel},
+	} {
+		if component.status == nil {
+			continue
+		}
+		revisions, err := revision.ListUnsupportedSkew(ctx, r.Client, component.status.Version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check version skew of %s: %w", component.kind, err)
+		}
+		sort.Slice(revisions, func(i, j int) bool { return revisions[i].Name < revisions[j].Name })
+		for _, rev := range revisions {
+			skew = append(skew, v1alpha1.MeshVersionSkew{
+				Component:       component.kind,
+				Version:         component.status.Version,
+				Revision:        rev.Name,
+				RevisionVersion: rev.Spec.Version,
+			})
+		}
+	}
+	return skew, nil
+}
+
+func determineReconciledCondition(err error) v1alpha1.MeshCondition {
+	c := v1alpha1.MeshCondition{Type: v1alpha1.MeshConditionReconciled}
+
+	if err == nil {
+		c.Status = metav1.ConditionTrue
+	} else {
+		c.Status = metav1.ConditionFalse
+		c.Reason = v1alpha1.MeshReasonReconcileError
+		c.Message = fmt.Sprintf("error reconciling resource: %v", err)
+	}
+	return c
+}
+
+func determineReadyCondition(revisions []v1alpha1.MeshRevisionStatus, cni, ztunnel *v1alpha1.MeshDaemonSetComponentStatus) v1alpha1.MeshCondition {
+	c := v1alpha1.MeshCondition{
+		Type:   v1alpha1.MeshConditionReady,
+		Status: metav1.ConditionTrue,
+	}
+
+	var notReady []string
+	for _, rev := range revisions {
+		if rev.Ready != metav1.ConditionTrue {
+			notReady = append(notReady, fmt.Sprintf("%s %s", v1.IstioRevisionKind, rev.Name))
+		}
+	}
+	if cni != nil && cni.Ready != metav1.ConditionTrue {
+		notReady = append(notReady, fmt.Sprintf("%s %s", v1.IstioCNIKind, cni.Name))
+	}
+	if ztunnel != nil && ztunnel.Ready != metav1.ConditionTrue {
+		notReady = append(notReady, fmt.Sprintf("%s %s", v1.ZTunnelKind, ztunnel.Name))
+	}
+
+	if len(revisions) == 0 {
+		c.Status = metav1.ConditionFalse
+		c.Reason = v1alpha1.MeshReasonComponentsNotReady
+		c.Message = "no IstioRevisions found"
+	} else if len(notReady) > 0 {
+		c.Status = metav1.ConditionFalse
+		c.Reason = v1alpha1.MeshReasonComponentsNotReady
+		c.Message = "not ready: " + strings.Join(notReady, ", ")
+	}
+	return c
+}
+
+func determineVersionSkewCondition(skew []v1alpha1.MeshVersionSkew) v1alpha1.MeshCondition {
+	c := v1alpha1.MeshCondition{
+		Type:   v1alpha1.MeshConditionSupportedVersionSkew,
+		Status: metav1.ConditionTrue,
+	}
+	if len(skew) > 0 {
+		var messages []string
+		for _, s := range skew {
+			messages = append(messages, fmt.Sprintf("%s version %s is not supported by IstioRevision %s with version %s",
+				s.Component, s.Version, s.Revision, s.RevisionVersion))
+		}
+		c.Status = metav1.ConditionFalse
+		c.Reason = v1alpha1.MeshReasonUnsupportedVersionSkew
+		c.Message = strings.Join(messages, "; ")
+	}
+	return c
+}
+
+func deriveState(conditions []v1alpha1.MeshCondition) v1alpha1.MeshConditionReason {
+	for _, c := range conditions {
+		if c.Status != metav1.ConditionTrue {
+			return c.Reason
+		}
+	}
+	return v1alpha1.MeshReasonHealthy
+}
+
+// ensureMesh creates the Mesh object, so that the summary is available without creating the object by hand. If the
+// Mesh is deleted, it's created again when the operator restarts.
+func (r *Reconciler) ensureMesh(ctx context.Context) error {
+	log := logf.FromContext(ctx)
+
+	mesh := &v1alpha1.Mesh{ObjectMeta: metav1.ObjectMeta{Name: meshName}}
+	if err := r.Client.Create(ctx, mesh); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to create Mesh %s: %w", meshName, err)
+	}
+	log.Info("Created Mesh", "Mesh", meshName)
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager. Once the manager is started and elected leader, the
+// Mesh object is created if it doesn't exist.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	logger := mgr.GetLogger().WithName("ctrlr").WithName("mesh")
+
+	err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		if err := r.ensureMesh(logf.IntoContext(ctx, logger)); err != nil {
+			logger.Error(err, "failed to create Mesh")
+		}
+		return nil
+	}))
+	if err != nil {
+		return err
+	}
+
+	// mainObjectHandler handles the Mesh watch events
+	mainObjectHandler := wrapEventHandler(logger, &handler.EnqueueRequestForObject{})
+
+	// componentHandler enqueues the Mesh whenever any of the aggregated resources changes
+	componentHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(mapToMesh))
+
+	// daemonSetHandler enqueues the Mesh whenever the DaemonSet of the IstioCNI or ZTunnel changes
+	daemonSetHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(mapDaemonSetToMesh))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(controller.Options{
+			LogConstructor: func(req *reconcile.Request) logr.Logger {
+				log := logger
+				if req != nil {
+					log = log.WithValues("Mesh", req.Name)
+				}
+				return log
+			},
+		}).
+
+		// we use the Watches function instead of For(), so that we can wrap the handler so that events that cause the object to be enqueued are logged
+		Watches(&v1alpha1.Mesh{}, mainObjectHandler).Named("mesh").
+		Watches(&v1.IstioRevision{}, componentHandler).
+		Watches(&v1.IstioRevisionTag{}, componentHandler).
+		Watches(&v1.IstioCNI{}, componentHandler).
+		Watches(&v1.ZTunnel{}, componentHandler).
+		Watches(&appsv1.DaemonSet{}, daemonSetHandler).
+		Complete(reconciler.NewStandardReconciler[*v1alpha1.Mesh](r.Client, r.Reconcile))
+}
+
+func mapToMesh(_ context.Context, _ client.Object) []reconcile.Request {
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: meshName}}}
+}
+
+func mapDaemonSetToMesh(ctx context.Context, obj client.Object) []reconcile.Request {
+	for _, ownerRef := range obj.GetOwnerReferences() {
+		// DaemonSets created before ZTunnel was promoted to v1 still reference the v1alpha1 ZTunnel
+		if (ownerRef.APIVersion == v1.GroupVersion.String() && ownerRef.Kind == v1.IstioCNIKind) ||
+			(ownerRef.APIVersion == v1.GroupVersion.String() && ownerRef.Kind == v1.ZTunnelKind) ||
+			(ownerRef.APIVersion == v1alpha1.GroupVersion.String() && ownerRef.Kind == v1.ZTunnelKind) {
+			return mapToMesh(ctx, obj)
+		}
+	}
+	return nil
+}
+
+func wrapEventHandler(logger logr.Logger, handler handler.EventHandler) handler.EventHandler {
+	return enqueuelogger.WrapIfNecessary(v1alpha1.MeshKind, logger, handler)
+}
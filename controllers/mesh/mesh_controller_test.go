@@ -0,0 +1,392 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mesh
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/api/v1alpha1"
+	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var ctx = context.Background()
+
+func TestReconcile(t *testing.T) {
+	g := NewWithT(t)
+
+	objects := []client.Object{
+		newMesh(),
+		newRevision("default-v1-24-2", "v1.24.2", metav1.ConditionTrue, metav1.ConditionTrue),
+		newRevision("default-v1-23-4", "v1.23.4", metav1.ConditionFalse, metav1.ConditionFalse),
+		&v1.IstioRevisionTag{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Spec: v1.IstioRevisionTagSpec{
+				TargetRef: v1.IstioRevisionTagTargetReference{Kind: v1.IstioKind, Name: "default"},
+			},
+			Status: v1.IstioRevisionTagStatus{
+				IstioRevision: "default-v1-24-2",
+				State:         v1.IstioRevisionTagReasonHealthy,
+				Conditions: []v1.IstioRevisionTagCondition{
+					{Type: v1.IstioRevisionTagConditionInUse, Status: metav1.ConditionTrue},
+				},
+			},
+		},
+		&v1.IstioCNI{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Spec:       v1.IstioCNISpec{Version: "v1.24.2", Namespace: "istio-cni"},
+			Status: v1.IstioCNIStatus{
+				State: v1.IstioCNIDaemonSetNotReady,
+				Conditions: []v1.IstioCNICondition{
+					{Type: v1.IstioCNIConditionReady, Status: metav1.ConditionFalse},
+				},
+			},
+		},
+		newDaemonSet("istio-cni", "istio-cni-node", appsv1.DaemonSetStatus{
+			DesiredNumberScheduled: 3,
+			UpdatedNumberScheduled: 2,
+			NumberReady:            2,
+			NumberAvailable:        2,
+		}),
+	}
+
+	cl := newFakeClientBuilder().WithObjects(objects...).Build()
+	r := NewReconciler(cl, scheme.Scheme)
+
+	mesh := &v1alpha1.Mesh{}
+	g.Expect(cl.Get(ctx, types.NamespacedName{Name: meshName}, mesh)).To(Succeed())
+
+	_, err := r.Reconcile(ctx, mesh)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(cl.Get(ctx, types.NamespacedName{Name: meshName}, mesh)).To(Succeed())
+	status := mesh.Status
+
+	g.Expect(status.Versions).To(Equal([]string{"v1.23.4", "v1.24.2"}))
+	g.Expect(status.Revisions).To(Equal([]v1alpha1.MeshRevisionStatus{
+		{
+			Name:      "default-v1-23-4",
+			Istio:     "default",
+			Version:   "v1.23.4",
+			Namespace: "istio-system",
+			Ready:     metav1.ConditionFalse,
+			InUse:     metav1.ConditionFalse,
+		},
+		{
+			Name:      "default-v1-24-2",
+			Istio:     "default",
+			Version:   "v1.24.2",
+			Namespace: "istio-system",
+			Ready:     metav1.ConditionTrue,
+			InUse:     metav1.ConditionTrue,
+		},
+	}))
+	g.Expect(status.Tags).To(Equal([]v1alpha1.MeshTagStatus{
+		{
+			Name:       "default",
+			TargetKind: v1.IstioKind,
+			TargetName: "default",
+			Revision:   "default-v1-24-2",
+			InUse:      metav1.ConditionTrue,
+			State:      string(v1.IstioRevisionTagReasonHealthy),
+		},
+	}))
+	g.Expect(status.CNI).To(Equal(&v1alpha1.MeshDaemonSetComponentStatus{
+		Name:                   "default",
+		Version:                "v1.24.2",
+		Namespace:              "istio-cni",
+		Ready:                  metav1.ConditionFalse,
+		State:                  string(v1.IstioCNIDaemonSetNotReady),
+		DesiredNumberScheduled: 3,
+		UpdatedNumberScheduled: 2,
+		NumberReady:            2,
+		NumberAvailable:        2,
+	}))
+	g.Expect(status.ZTunnel).To(BeNil())
+	g.Expect(status.VersionSkew).To(BeEmpty())
+
+	g.Expect(status.GetCondition(v1alpha1.MeshConditionReconciled).Status).To(Equal(metav1.ConditionTrue))
+	readyCondition := status.GetCondition(v1alpha1.MeshConditionReady)
+	g.Expect(readyCondition.Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(readyCondition.Reason).To(Equal(v1alpha1.MeshReasonComponentsNotReady))
+	g.Expect(readyCondition.Message).To(Equal("not ready: IstioRevision default-v1-23-4, IstioCNI default"))
+	g.Expect(status.GetCondition(v1alpha1.MeshConditionSupportedVersionSkew).Status).To(Equal(metav1.ConditionTrue))
+	g.Expect(status.State).To(Equal(v1alpha1.MeshReasonComponentsNotReady))
+}
+
+func TestReconcileListError(t *testing.T) {
+	g := NewWithT(t)
+
+	mesh := newMesh()
+	mesh.Status.Versions = []string{"v1.24.2"}
+	cl := newFakeClientBuilder().
+		WithObjects(mesh).
+		WithInterceptorFuncs(interceptor.Funcs{
+			List: func(_ context.Context, _ client.WithWatch, list client.ObjectList, _ ...client.ListOption) error {
+				if _, ok := list.(*v1.IstioRevisionTagList); ok {
+					return errors.New("simulated error")
+				}
+				return nil
+			},
+		}).
+		Build()
+	r := NewReconciler(cl, scheme.Scheme)
+
+	_, err := r.Reconcile(ctx, mesh)
+	g.Expect(err).To(HaveOccurred())
+
+	g.Expect(cl.Get(ctx, types.NamespacedName{Name: meshName}, mesh)).To(Succeed())
+	g.Expect(mesh.Status.Versions).To(Equal([]string{"v1.24.2"}), "previous summary should be preserved")
+	reconciledCondition := mesh.Status.GetCondition(v1alpha1.MeshConditionReconciled)
+	g.Expect(reconciledCondition.Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(reconciledCondition.Message).To(ContainSubstring("failed to list IstioRevisionTags: simulated error"))
+	g.Expect(mesh.Status.State).To(Equal(v1alpha1.MeshReasonReconcileError))
+}
+
+func TestDetermineVersionSkew(t *testing.T) {
+	testCases := []struct {
+		name             string
+		revisionVersions []string
+		unusedVersions   []string
+		cniVersion       string
+		ztunnelVersion   string
+		expected         []v1alpha1.MeshVersionSkew
+	}{
+		{
+			name:             "same versions",
+			revisionVersions: []string{"v1.24.2"},
+			cniVersion:       "v1.24.2",
+			ztunnelVersion:   "v1.24.2",
+		},
+		{
+			name:             "one minor version apart",
+			revisionVersions: []string{"v1.23.4", "v1.24.2"},
+			cniVersion:       "v1.24.0",
+			ztunnelVersion:   "v1.23.4",
+		},
+		{
+			name:             "two minor versions apart",
+			revisionVersions: []string{"v1.22.8", "v1.24.2"},
+			cniVersion:       "v1.24.2",
+			ztunnelVersion:   "v1.23.4",
+			expected: []v1alpha1.MeshVersionSkew{
+				{Component: v1.IstioCNIKind, Version: "v1.24.2", Revision: "rev-v1.22.8", RevisionVersion: "v1.22.8"},
+			},
+		},
+		{
+			name:             "different major version",
+			revisionVersions: []string{"v1.24.2"},
+			ztunnelVersion:   "v2.24.2",
+			expected: []v1alpha1.MeshVersionSkew{
+				{Component: v1.ZTunnelKind, Version: "v2.24.2", Revision: "rev-v1.24.2", RevisionVersion: "v1.24.2"},
+			},
+		},
+		{
+			name:             "ignores revisions that aren't in use",
+			revisionVersions: []string{"v1.24.2"},
+			unusedVersions:   []string{"v1.22.8"},
+			cniVersion:       "v1.24.2",
+		},
+		{
+			name:             "ignores versions that aren't semantic versions",
+			revisionVersions: []string{"latest", "v1.22.8"},
+			cniVersion:       "latest",
+			ztunnelVersion:   "v1.22.0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			var objects []client.Object
+			for _, version := range tc.revisionVersions {
+				objects = append(objects, newRevision("rev-"+version, version, metav1.ConditionTrue, metav1.ConditionTrue))
+			}
+			for _, version := range tc.unusedVersions {
+				objects = append(objects, newRevision("rev-"+version, version, metav1.ConditionTrue, metav1.ConditionFalse))
+			}
+			var cni, ztunnel *v1alpha1.MeshDaemonSetComponentStatus
+			if tc.cniVersion != "" {
+				cni = &v1alpha1.MeshDaemonSetComponentStatus{Name: "default", Version: tc.cniVersion}
+			}
+			if tc.ztunnelVersion != "" {
+				ztunnel = &v1alpha1.MeshDaemonSetComponentStatus{Name: "default", Version: tc.ztunnelVersion}
+			}
+
+			r := NewReconciler(newFakeClientBuilder().WithObjects(objects...).Build(), scheme.Scheme)
+			skew, err := r.determineVersionSkew(ctx, cni, ztunnel)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(skew).To(Equal(tc.expected))
+
+			condition := determineVersionSkewCondition(tc.expected)
+			if len(tc.expected) == 0 {
+				g.Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			} else {
+				g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+				g.Expect(condition.Reason).To(Equal(v1alpha1.MeshReasonUnsupportedVersionSkew))
+			}
+		})
+	}
+}
+
+func TestEnsureMesh(t *testing.T) {
+	g := NewWithT(t)
+
+	cl := newFakeClientBuilder().Build()
+	r := NewReconciler(cl, scheme.Scheme)
+
+	g.Expect(r.ensureMesh(ctx)).To(Succeed())
+	mesh := &v1alpha1.Mesh{}
+	g.Expect(cl.Get(ctx, types.NamespacedName{Name: meshName}, mesh)).To(Succeed())
+
+	// the existing Mesh is kept
+	mesh.Status.Versions = []string{"v1.24.2"}
+	g.Expect(cl.Status().Update(ctx, mesh)).To(Succeed())
+	g.Expect(r.ensureMesh(ctx)).To(Succeed())
+	g.Expect(cl.Get(ctx, types.NamespacedName{Name: meshName}, mesh)).To(Succeed())
+	g.Expect(mesh.Status.Versions).To(Equal([]string{"v1.24.2"}))
+}
+
+func TestDetermineReadyCondition(t *testing.T) {
+	ready := &v1alpha1.MeshDaemonSetComponentStatus{Name: "default", Ready: metav1.ConditionTrue}
+	notReady := &v1alpha1.MeshDaemonSetComponentStatus{Name: "default", Ready: metav1.ConditionFalse}
+
+	testCases := []struct {
+		name          string
+		revisions     []v1alpha1.MeshRevisionStatus
+		cni           *v1alpha1.MeshDaemonSetComponentStatus
+		ztunnel       *v1alpha1.MeshDaemonSetComponentStatus
+		expectStatus  metav1.ConditionStatus
+		expectMessage string
+	}{
+		{
+			name:          "no revisions",
+			expectStatus:  metav1.ConditionFalse,
+			expectMessage: "no IstioRevisions found",
+		},
+		{
+			name:         "all ready",
+			revisions:    []v1alpha1.MeshRevisionStatus{{Name: "default", Ready: metav1.ConditionTrue}},
+			cni:          ready,
+			ztunnel:      ready,
+			expectStatus: metav1.ConditionTrue,
+		},
+		{
+			name:          "revision readiness unknown",
+			revisions:     []v1alpha1.MeshRevisionStatus{{Name: "default", Ready: metav1.ConditionUnknown}},
+			expectStatus:  metav1.ConditionFalse,
+			expectMessage: "not ready: IstioRevision default",
+		},
+		{
+			name:          "ztunnel not ready",
+			revisions:     []v1alpha1.MeshRevisionStatus{{Name: "default", Ready: metav1.ConditionTrue}},
+			cni:           ready,
+			ztunnel:       notReady,
+			expectStatus:  metav1.ConditionFalse,
+			expectMessage: "not ready: ZTunnel default",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			condition := determineReadyCondition(tc.revisions, tc.cni, tc.ztunnel)
+			g.Expect(condition.Status).To(Equal(tc.expectStatus))
+			g.Expect(condition.Message).To(Equal(tc.expectMessage))
+		})
+	}
+}
+
+func TestMapDaemonSetToMesh(t *testing.T) {
+	g := NewWithT(t)
+
+	expected := []reconcile.Request{{NamespacedName: types.NamespacedName{Name: meshName}}}
+
+	cniDaemonSet := newDaemonSet("istio-cni", "istio-cni-node", appsv1.DaemonSetStatus{})
+	g.Expect(mapDaemonSetToMesh(ctx, cniDaemonSet)).To(Equal(expected))
+
+	ztunnelDaemonSet := newDaemonSet("ztunnel", "ztunnel", appsv1.DaemonSetStatus{})
+	ztunnelDaemonSet.OwnerReferences = []metav1.OwnerReference{
+		{APIVersion: v1alpha1.GroupVersion.String(), Kind: v1.ZTunnelKind, Name: "default"},
+	}
+	g.Expect(mapDaemonSetToMesh(ctx, ztunnelDaemonSet)).To(Equal(expected))
+
+	ztunnelDaemonSet.OwnerReferences = []metav1.OwnerReference{
+		{APIVersion: v1.GroupVersion.String(), Kind: v1.ZTunnelKind, Name: "default"},
+	}
+	g.Expect(mapDaemonSetToMesh(ctx, ztunnelDaemonSet)).To(Equal(expected))
+
+	otherDaemonSet := newDaemonSet("kube-system", "kube-proxy", appsv1.DaemonSetStatus{})
+	otherDaemonSet.OwnerReferences = nil
+	g.Expect(mapDaemonSetToMesh(ctx, otherDaemonSet)).To(BeEmpty())
+}
+
+func newMesh() *v1alpha1.Mesh {
+	return &v1alpha1.Mesh{
+		ObjectMeta: metav1.ObjectMeta{Name: meshName},
+	}
+}
+
+func newRevision(name, version string, ready, inUse metav1.ConditionStatus) *v1.IstioRevision {
+	return &v1.IstioRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: v1.GroupVersion.String(), Kind: v1.IstioKind, Name: "default"},
+			},
+		},
+		Spec: v1.IstioRevisionSpec{
+			Version:   version,
+			Namespace: "istio-system",
+		},
+		Status: v1.IstioRevisionStatus{
+			Conditions: []v1.IstioRevisionCondition{
+				{Type: v1.IstioRevisionConditionReady, Status: ready},
+				{Type: v1.IstioRevisionConditionInUse, Status: inUse},
+			},
+		},
+	}
+}
+
+func newDaemonSet(namespace, name string, status appsv1.DaemonSetStatus) *appsv1.DaemonSet {
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: v1.GroupVersion.String(), Kind: v1.IstioCNIKind, Name: "default"},
+			},
+		},
+		Status: status,
+	}
+}
+
+func newFakeClientBuilder() *fake.ClientBuilder {
+	return fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithStatusSubresource(&v1alpha1.Mesh{})
+}
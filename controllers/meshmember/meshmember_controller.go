@@ -0,0 +1,468 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package meshmember
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/api/v1alpha1"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	"github.com/istio-ecosystem/sail-operator/pkg/enqueuelogger"
+	"github.com/istio-ecosystem/sail-operator/pkg/errlist"
+	"github.com/istio-ecosystem/sail-operator/pkg/kube"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Reconciler applies the mesh membership labels requested by MeshMember objects to their namespaces
+type Reconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+func NewReconciler(client client.Client, scheme *runtime.Scheme) *Reconciler {
+	return &Reconciler{
+		Client: client,
+		Scheme: scheme,
+	}
+}
+
+// membership describes the control plane that a member namespace is assigned to
+type membership struct {
+	// the value of the istio.io/rev label
+	revLabel string
+	// the IstioRevision that the namespace's workloads are assigned to
+	revision string
+	// the namespace of the control plane, used as the value of the sailoperator.io/member-of label
+	controlPlaneNamespace string
+	// the namespaces that are allowed to join through the target; nil allows all namespaces
+	memberSelector *metav1.LabelSelector
+}
+
+// +kubebuilder:rbac:groups=sailoperator.io,resources=meshmembers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=sailoperator.io,resources=meshmembers/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=sailoperator.io,resources=meshmembers/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources="*",verbs="*"
+
+// Reconcile applies the labels that add the MeshMember's namespace to the referenced control plane.
+func (r *Reconciler) Reconcile(ctx context.Context, member *v1alpha1.MeshMember) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	m, reconcileErr := r.doReconcile(ctx, member)
+
+	log.Info("Reconciliation done. Updating status.")
+	statusErr := r.updateStatus(ctx, member, m, reconcileErr)
+
+	return ctrl.Result{}, errors.Join(reconcileErr, statusErr)
+}
+
+// Finalize removes the membership labels from the namespace.
+func (r *Reconciler) Finalize(ctx context.Context, member *v1alpha1.MeshMember) error {
+	ns := &corev1.Namespace{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: member.Namespace}, ns); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if _, found := ns.Labels[constants.MemberOfKey]; !found {
+		return nil
+	}
+	return r.patchNamespaceLabels(ctx, ns, nil)
+}
+
+func (r *Reconciler) doReconcile(ctx context.Context, member *v1alpha1.MeshMember) (*membership, error) {
+	ns := &corev1.Namespace{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: member.Namespace}, ns); err != nil {
+		return nil, fmt.Errorf("failed to get namespace %q: %w", member.Namespace, err)
+	}
+	if ns.Labels[constants.IgnoreNamespaceKey] == "true" {
+		return nil, NewNamespaceIgnoredError(fmt.Sprintf("namespace %q is labeled with %s=true", ns.Name, constants.IgnoreNamespaceKey))
+	}
+
+	m, err := r.resolveTarget(ctx, member)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkMemberSelector(member, m, ns); err != nil {
+		// the namespace may have joined before the target's memberSelector was changed
+		if _, found := ns.Labels[constants.MemberOfKey]; found {
+			return nil, errors.Join(err, r.patchNamespaceLabels(ctx, ns, nil))
+		}
+		return nil, err
+	}
+	return m, r.patchNamespaceLabels(ctx, ns, desiredLabels(member, m))
+}
+
+// checkMemberSelector returns a NamespaceNotAllowedError if the target's memberSelector doesn't match the namespace.
+func checkMemberSelector(member *v1alpha1.MeshMember, m *membership, ns *corev1.Namespace) error {
+	if m.memberSelector == nil {
+		return nil
+	}
+	target := member.Spec.Target
+	selector, err := metav1.LabelSelectorAsSelector(m.memberSelector)
+	if err != nil {
+		return NewNamespaceNotAllowedError(fmt.Sprintf("invalid memberSelector in %s %q: %v", target.Kind, target.Name, err))
+	}
+	if !selector.Matches(labels.Set(ns.Labels)) {
+		return NewNamespaceNotAllowedError(fmt.Sprintf("namespace %q doesn't match the memberSelector of %s %q", ns.Name, target.Kind, target.Name))
+	}
+	return nil
+}
+
+// resolveTarget determines the revision label and the IstioRevision for the MeshMember's target. When the target is an
+// Istio, the namespace is assigned to its active revision; when it is an IstioRevisionTag, the namespace references
+// the tag, so that the tag can move the namespace between revisions.
+func (r *Reconciler) resolveTarget(ctx context.Context, member *v1alpha1.MeshMember) (*membership, error) {
+	target := member.Spec.Target
+	switch target.Kind {
+	case v1.IstioKind:
+		istio := &v1.Istio{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: target.Name}, istio); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, NewTargetNotFoundError(fmt.Sprintf("Istio %q not found", target.Name))
+			}
+			return nil, fmt.Errorf("failed to get Istio %q: %w", target.Name, err)
+		}
+		if istio.Status.ActiveRevisionName == "" {
+			return nil, NewTargetNotFoundError(fmt.Sprintf("Istio %q has no active revision", target.Name))
+		}
+		return &membership{
+			revLabel:              istio.Status.ActiveRevisionName,
+			revision:              istio.Status.ActiveRevisionName,
+			controlPlaneNamespace: istio.Spec.Namespace,
+			memberSelector:        istio.Spec.MemberSelector,
+		}, nil
+	case v1.IstioRevisionTagKind:
+		tag := &v1.IstioRevisionTag{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: target.Name}, tag); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, NewTargetNotFoundError(fmt.Sprintf("IstioRevisionTag %q not found", target.Name))
+			}
+			return nil, fmt.Errorf("failed to get IstioRevisionTag %q: %w", target.Name, err)
+		}
+		if tag.Status.IstioRevision == "" {
+			return nil, NewTargetNotFoundError(fmt.Sprintf("IstioRevisionTag %q doesn't reference an IstioRevision yet", target.Name))
+		}
+		return &membership{
+			revLabel:              tag.Name,
+			revision:              tag.Status.IstioRevision,
+			controlPlaneNamespace: tag.Status.IstiodNamespace,
+			memberSelector:        tag.Spec.MemberSelector,
+		}, nil
+	default:
+		return nil, reconciler.NewValidationError(fmt.Sprintf("unsupported target kind %q", target.Kind))
+	}
+}
+
+// desiredLabels returns the membership labels that should be set on the namespace. The istio.io/rev label is set in
+// both modes: in Ambient mode, it selects the revision that the namespace's waypoints and ztunnel use.
+func desiredLabels(member *v1alpha1.MeshMember, m *membership) map[string]string {
+	labels := map[string]string{
+		constants.MemberOfKey:   m.controlPlaneNamespace,
+		constants.IstioRevLabel: m.revLabel,
+	}
+	if member.Spec.DataplaneMode == v1alpha1.DataplaneModeAmbient {
+		labels[constants.IstioDataplaneModeLabel] = constants.IstioDataplaneModeAmbient
+	}
+	return labels
+}
+
+// patchNamespaceLabels sets the given membership labels on the namespace and removes all other labels that
+// control the mesh membership of the namespace. Passing nil removes all membership labels.
+func (r *Reconciler) patchNamespaceLabels(ctx context.Context, ns *corev1.Namespace, labels map[string]string) error {
+	updated := ns.DeepCopy()
+	if updated.Labels == nil {
+		updated.Labels = map[string]string{}
+	}
+	for _, key := range []string{
+		constants.MemberOfKey, constants.IstioRevLabel, constants.IstioInjectionLabel, constants.IstioDataplaneModeLabel,
+	} {
+		if value, found := labels[key]; found {
+			updated.Labels[key] = value
+		} else {
+			delete(updated.Labels, key)
+		}
+	}
+	if reflect.DeepEqual(ns.Labels, updated.Labels) || (len(ns.Labels) == 0 && len(updated.Labels) == 0) {
+		return nil
+	}
+
+	logf.FromContext(ctx).Info("Updating mesh membership labels of namespace", "labels", labels)
+	if err := r.Client.Patch(ctx, updated, client.MergeFrom(ns)); err != nil {
+		return fmt.Errorf("failed to update labels of namespace %q: %w", ns.Name, err)
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	logger := mgr.GetLogger().WithName("ctrlr").WithName("meshmember")
+
+	// mainObjectHandler handles the MeshMember watch events
+	mainObjectHandler := wrapEventHandler(logger, &handler.EnqueueRequestForObject{})
+
+	// namespaceHandler enqueues the MeshMember in the namespace, so that the labels are restored if they're changed
+	namespaceHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(mapNamespaceToReconcileRequest))
+
+	// targetHandler enqueues the MeshMembers that reference the Istio or IstioRevisionTag
+	targetHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapTargetToReconcileRequests))
+
+	// revisionHandler enqueues the MeshMembers whose namespace is assigned to the IstioRevision
+	revisionHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapRevisionToReconcileRequests))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(controller.Options{
+			LogConstructor: func(req *reconcile.Request) logr.Logger {
+				log := logger
+				if req != nil {
+					log = log.WithValues("MeshMember", req.NamespacedName.String())
+				}
+				return log
+			},
+		}).
+		// we use the Watches function instead of For(), so that we can wrap the handler so that events that cause the object to be enqueued are logged
+		Watches(&v1alpha1.MeshMember{}, mainObjectHandler).Named("meshmember").
+		Watches(&corev1.Namespace{}, namespaceHandler).
+		Watches(&v1.Istio{}, targetHandler).
+		Watches(&v1.IstioRevisionTag{}, targetHandler).
+		Watches(&v1.IstioRevision{}, revisionHandler).
+		Complete(reconciler.NewStandardReconcilerWithFinalizer[*v1alpha1.MeshMember](r.Client, r.Reconcile, r.Finalize, constants.FinalizerName))
+}
+
+func (r *Reconciler) determineStatus(
+	ctx context.Context, member *v1alpha1.MeshMember, m *membership, reconcileErr error,
+) (v1alpha1.MeshMemberStatus, error) {
+	var errs errlist.Builder
+	reconciledCondition := determineReconciledCondition(reconcileErr)
+
+	status := *member.Status.DeepCopy()
+	status.ObservedGeneration = member.Generation
+	status.IstioRevision = ""
+
+	readyCondition := v1alpha1.MeshMemberCondition{
+		Type:   v1alpha1.MeshMemberConditionReady,
+		Status: metav1.ConditionFalse,
+	}
+	if m == nil {
+		readyCondition.Status = metav1.ConditionUnknown
+		readyCondition.Reason = reconciledCondition.Reason
+		readyCondition.Message = "the namespace isn't assigned to a control plane"
+	} else {
+		status.IstioRevision = m.revision
+		rev := &v1.IstioRevision{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: m.revision}, rev); err == nil {
+			revReady := rev.Status.GetCondition(v1.IstioRevisionConditionReady)
+			if revReady.Status == metav1.ConditionTrue {
+				readyCondition.Status = metav1.ConditionTrue
+			} else {
+				readyCondition.Reason = v1alpha1.MeshMemberReasonIstioRevisionNotReady
+				readyCondition.Message = fmt.Sprintf("IstioRevision %q is not ready", m.revision)
+				if revReady.Message != "" {
+					readyCondition.Message += ": " + revReady.Message
+				}
+			}
+		} else if apierrors.IsNotFound(err) {
+			readyCondition.Reason = v1alpha1.MeshMemberReasonIstioRevisionNotReady
+			readyCondition.Message = fmt.Sprintf("IstioRevision %q not found", m.revision)
+		} else {
+			readyCondition.Status = metav1.ConditionUnknown
+			readyCondition.Reason = v1alpha1.MeshMemberReasonReadinessCheckFailed
+			readyCondition.Message = fmt.Sprintf("failed to get IstioRevision %q: %v", m.revision, err)
+			errs.Add(fmt.Errorf("failed to get IstioRevision %q: %w", m.revision, err))
+		}
+	}
+
+	status.SetCondition(reconciledCondition)
+	status.SetCondition(readyCondition)
+	status.State = deriveState(reconciledCondition, readyCondition)
+	return status, errs.Error()
+}
+
+func (r *Reconciler) updateStatus(ctx context.Context, member *v1alpha1.MeshMember, m *membership, reconcileErr error) error {
+	var errs errlist.Builder
+
+	status, err := r.determineStatus(ctx, member, m, reconcileErr)
+	if err != nil {
+		errs.Add(fmt.Errorf("failed to determine status: %w", err))
+	}
+
+	if !reflect.DeepEqual(member.Status, status) {
+		if err := r.Client.Status().Patch(ctx, member, kube.NewStatusPatch(status)); err != nil {
+			errs.Add(fmt.Errorf("failed to patch status: %w", err))
+		}
+	}
+	return errs.Error()
+}
+
+func deriveState(reconciledCondition, readyCondition v1alpha1.MeshMemberCondition) v1alpha1.MeshMemberConditionReason {
+	if reconciledCondition.Status != metav1.ConditionTrue {
+		return reconciledCondition.Reason
+	} else if readyCondition.Status != metav1.ConditionTrue {
+		return readyCondition.Reason
+	}
+	return v1alpha1.MeshMemberReasonHealthy
+}
+
+func determineReconciledCondition(err error) v1alpha1.MeshMemberCondition {
+	c := v1alpha1.MeshMemberCondition{Type: v1alpha1.MeshMemberConditionReconciled}
+
+	switch {
+	case err == nil:
+		c.Status = metav1.ConditionTrue
+	case IsTargetNotFoundError(err):
+		c.Status = metav1.ConditionFalse
+		c.Reason = v1alpha1.MeshMemberReasonTargetNotFound
+		c.Message = err.Error()
+	case IsNamespaceIgnoredError(err):
+		c.Status = metav1.ConditionFalse
+		c.Reason = v1alpha1.MeshMemberReasonNamespaceIgnored
+		c.Message = err.Error()
+	case IsNamespaceNotAllowedError(err):
+		c.Status = metav1.ConditionFalse
+		c.Reason = v1alpha1.MeshMemberReasonNamespaceNotAllowed
+		c.Message = err.Error()
+	default:
+		c.Status = metav1.ConditionFalse
+		c.Reason = v1alpha1.MeshMemberReasonReconcileError
+		c.Message = fmt.Sprintf("error reconciling resource: %v", err)
+	}
+	return c
+}
+
+func mapNamespaceToReconcileRequest(_ context.Context, ns client.Object) []reconcile.Request {
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: ns.GetName(), Name: v1alpha1.MeshMemberName}}}
+}
+
+func (r *Reconciler) mapTargetToReconcileRequests(ctx context.Context, obj client.Object) []reconcile.Request {
+	kind := v1.IstioKind
+	if _, ok := obj.(*v1.IstioRevisionTag); ok {
+		kind = v1.IstioRevisionTagKind
+	}
+	return r.mapMembers(ctx, func(member *v1alpha1.MeshMember) bool {
+		return member.Spec.Target.Kind == kind && member.Spec.Target.Name == obj.GetName()
+	})
+}
+
+func (r *Reconciler) mapRevisionToReconcileRequests(ctx context.Context, rev client.Object) []reconcile.Request {
+	return r.mapMembers(ctx, func(member *v1alpha1.MeshMember) bool {
+		return member.Status.IstioRevision == rev.GetName()
+	})
+}
+
+func (r *Reconciler) mapMembers(ctx context.Context, matches func(*v1alpha1.MeshMember) bool) []reconcile.Request {
+	log := logf.FromContext(ctx)
+
+	memberList := v1alpha1.MeshMemberList{}
+	if err := r.Client.List(ctx, &memberList); err != nil {
+		log.Error(err, "failed to list MeshMembers")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range memberList.Items {
+		if matches(&memberList.Items[i]) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&memberList.Items[i])})
+		}
+	}
+	return requests
+}
+
+// TargetNotFoundError is returned when the Istio or IstioRevisionTag referenced by the MeshMember doesn't exist or
+// doesn't reference an IstioRevision yet. It wraps a validation error, so the reconciliation is only retried when the
+// target changes.
+type TargetNotFoundError struct {
+	err error
+}
+
+func NewTargetNotFoundError(message string) error {
+	return &TargetNotFoundError{err: reconciler.NewValidationError(message)}
+}
+
+func (e TargetNotFoundError) Error() string {
+	return e.err.Error()
+}
+
+func (e TargetNotFoundError) Unwrap() error {
+	return e.err
+}
+
+func IsTargetNotFoundError(err error) bool {
+	e := &TargetNotFoundError{}
+	return errors.As(err, &e)
+}
+
+// NamespaceIgnoredError is returned when the MeshMember's namespace is excluded from the mesh with the
+// sailoperator.io/ignore-namespace label.
+type NamespaceIgnoredError struct {
+	err error
+}
+
+func NewNamespaceIgnoredError(message string) error {
+	return &NamespaceIgnoredError{err: reconciler.NewValidationError(message)}
+}
+
+func (e NamespaceIgnoredError) Error() string {
+	return e.err.Error()
+}
+
+func (e NamespaceIgnoredError) Unwrap() error {
+	return e.err
+}
+
+func IsNamespaceIgnoredError(err error) bool {
+	e := &NamespaceIgnoredError{}
+	return errors.As(err, &e)
+}
+
+// NamespaceNotAllowedError is returned when the MeshMember's namespace doesn't match the memberSelector of its
+// target. The reconciliation is retried when the namespace or the target changes.
+type NamespaceNotAllowedError struct {
+	err error
+}
+
+func NewNamespaceNotAllowedError(message string) error {
+	return &NamespaceNotAllowedError{err: reconciler.NewValidationError(message)}
+}
+
+func (e NamespaceNotAllowedError) Error() string {
+	return e.err.Error()
+}
+
+func (e NamespaceNotAllowedError) Unwrap() error {
+	return e.err
+}
+
+func IsNamespaceNotAllowedError(err error) bool {
+	e := &NamespaceNotAllowedError{}
+	return errors.As(err, &e)
+}
+
+func wrapEventHandler(logger logr.Logger, handler handler.EventHandler) handler.EventHandler {
+	return enqueuelogger.WrapIfNecessary(v1alpha1.MeshMemberKind, logger, handler)
+}
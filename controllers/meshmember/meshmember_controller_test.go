@@ -0,0 +1,263 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package meshmember
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/api/v1alpha1"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var ctx = context.Background()
+
+const nsName = "bookinfo"
+
+func newMember(kind, name string, mode v1alpha1.DataplaneMode) *v1alpha1.MeshMember {
+	return &v1alpha1.MeshMember{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      v1alpha1.MeshMemberName,
+			Namespace: nsName,
+		},
+		Spec: v1alpha1.MeshMemberSpec{
+			Target:        v1alpha1.MeshMemberTargetReference{Kind: kind, Name: name},
+			DataplaneMode: mode,
+		},
+	}
+}
+
+func newIstio() *v1.Istio {
+	return &v1.Istio{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec:       v1.IstioSpec{Namespace: "istio-system"},
+		Status:     v1.IstioStatus{ActiveRevisionName: "default-v1-24-2"},
+	}
+}
+
+func newRevision(name string, ready bool) *v1.IstioRevision {
+	status := metav1.ConditionFalse
+	if ready {
+		status = metav1.ConditionTrue
+	}
+	return &v1.IstioRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: v1.IstioRevisionStatus{
+			Conditions: []v1.IstioRevisionCondition{
+				{Type: v1.IstioRevisionConditionReady, Status: status, Message: "istiod not ready"},
+			},
+		},
+	}
+}
+
+func newNamespace(labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: nsName, Labels: labels}}
+}
+
+func newFakeClient(objs ...client.Object) client.Client {
+	return fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithStatusSubresource(&v1alpha1.MeshMember{}).
+		WithObjects(objs...).
+		Build()
+}
+
+func getNamespaceLabels(g *WithT, cl client.Client) map[string]string {
+	ns := &corev1.Namespace{}
+	g.Expect(cl.Get(ctx, types.NamespacedName{Name: nsName}, ns)).To(Succeed())
+	return ns.Labels
+}
+
+func TestReconcile(t *testing.T) {
+	t.Run("assigns namespace to active revision of Istio", func(t *testing.T) {
+		g := NewWithT(t)
+		member := newMember(v1.IstioKind, "default", v1alpha1.DataplaneModeSidecar)
+		cl := newFakeClient(member, newIstio(), newRevision("default-v1-24-2", true),
+			newNamespace(map[string]string{constants.IstioInjectionLabel: "enabled", "team": "a"}))
+
+		_, err := NewReconciler(cl, scheme.Scheme).Reconcile(ctx, member)
+		g.Expect(err).NotTo(HaveOccurred())
+
+		g.Expect(getNamespaceLabels(g, cl)).To(Equal(map[string]string{
+			constants.IstioRevLabel: "default-v1-24-2",
+			constants.MemberOfKey:   "istio-system",
+			"team":                  "a",
+		}))
+
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(member), member)).To(Succeed())
+		g.Expect(member.Status.IstioRevision).To(Equal("default-v1-24-2"))
+		g.Expect(member.Status.State).To(Equal(v1alpha1.MeshMemberReasonHealthy))
+		g.Expect(member.Status.GetCondition(v1alpha1.MeshMemberConditionReady).Status).To(Equal(metav1.ConditionTrue))
+	})
+
+	t.Run("references revision tag", func(t *testing.T) {
+		g := NewWithT(t)
+		member := newMember(v1.IstioRevisionTagKind, "prod", v1alpha1.DataplaneModeSidecar)
+		tag := &v1.IstioRevisionTag{
+			ObjectMeta: metav1.ObjectMeta{Name: "prod"},
+			Status:     v1.IstioRevisionTagStatus{IstioRevision: "canary", IstiodNamespace: "istio-system"},
+		}
+		cl := newFakeClient(member, tag, newRevision("canary", false), newNamespace(nil))
+
+		_, err := NewReconciler(cl, scheme.Scheme).Reconcile(ctx, member)
+		g.Expect(err).NotTo(HaveOccurred())
+
+		g.Expect(getNamespaceLabels(g, cl)).To(Equal(map[string]string{
+			constants.IstioRevLabel: "prod",
+			constants.MemberOfKey:   "istio-system",
+		}))
+
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(member), member)).To(Succeed())
+		g.Expect(member.Status.IstioRevision).To(Equal("canary"))
+		ready := member.Status.GetCondition(v1alpha1.MeshMemberConditionReady)
+		g.Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+		g.Expect(ready.Reason).To(Equal(v1alpha1.MeshMemberReasonIstioRevisionNotReady))
+		g.Expect(ready.Message).To(Equal(`IstioRevision "canary" is not ready: istiod not ready`))
+	})
+
+	t.Run("enrolls namespace in ambient mode", func(t *testing.T) {
+		g := NewWithT(t)
+		member := newMember(v1.IstioKind, "default", v1alpha1.DataplaneModeAmbient)
+		cl := newFakeClient(member, newIstio(), newRevision("default-v1-24-2", true),
+			newNamespace(map[string]string{constants.IstioRevLabel: "old"}))
+
+		_, err := NewReconciler(cl, scheme.Scheme).Reconcile(ctx, member)
+		g.Expect(err).NotTo(HaveOccurred())
+
+		g.Expect(getNamespaceLabels(g, cl)).To(Equal(map[string]string{
+			constants.IstioDataplaneModeLabel: constants.IstioDataplaneModeAmbient,
+			constants.IstioRevLabel:           "default-v1-24-2",
+			constants.MemberOfKey:             "istio-system",
+		}))
+	})
+
+	t.Run("reports missing target", func(t *testing.T) {
+		g := NewWithT(t)
+		member := newMember(v1.IstioKind, "missing", v1alpha1.DataplaneModeSidecar)
+		cl := newFakeClient(member, newNamespace(nil))
+
+		_, err := NewReconciler(cl, scheme.Scheme).Reconcile(ctx, member)
+		g.Expect(IsTargetNotFoundError(err)).To(BeTrue())
+		g.Expect(getNamespaceLabels(g, cl)).To(BeEmpty())
+
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(member), member)).To(Succeed())
+		reconciled := member.Status.GetCondition(v1alpha1.MeshMemberConditionReconciled)
+		g.Expect(reconciled.Status).To(Equal(metav1.ConditionFalse))
+		g.Expect(reconciled.Reason).To(Equal(v1alpha1.MeshMemberReasonTargetNotFound))
+		g.Expect(reconciled.Message).To(ContainSubstring(`Istio "missing" not found`))
+		g.Expect(member.Status.State).To(Equal(v1alpha1.MeshMemberReasonTargetNotFound))
+	})
+
+	t.Run("refuses ignored namespace", func(t *testing.T) {
+		g := NewWithT(t)
+		member := newMember(v1.IstioKind, "default", v1alpha1.DataplaneModeSidecar)
+		cl := newFakeClient(member, newIstio(), newNamespace(map[string]string{constants.IgnoreNamespaceKey: "true"}))
+
+		_, err := NewReconciler(cl, scheme.Scheme).Reconcile(ctx, member)
+		g.Expect(IsNamespaceIgnoredError(err)).To(BeTrue())
+		g.Expect(getNamespaceLabels(g, cl)).To(Equal(map[string]string{constants.IgnoreNamespaceKey: "true"}))
+
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(member), member)).To(Succeed())
+		g.Expect(member.Status.State).To(Equal(v1alpha1.MeshMemberReasonNamespaceIgnored))
+	})
+
+	t.Run("admits namespace matching memberSelector", func(t *testing.T) {
+		g := NewWithT(t)
+		member := newMember(v1.IstioKind, "default", v1alpha1.DataplaneModeSidecar)
+		istio := newIstio()
+		istio.Spec.MemberSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}
+		cl := newFakeClient(member, istio, newRevision("default-v1-24-2", true), newNamespace(map[string]string{"team": "a"}))
+
+		_, err := NewReconciler(cl, scheme.Scheme).Reconcile(ctx, member)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(getNamespaceLabels(g, cl)).To(HaveKeyWithValue(constants.IstioRevLabel, "default-v1-24-2"))
+	})
+
+	t.Run("refuses namespace not matching memberSelector", func(t *testing.T) {
+		g := NewWithT(t)
+		member := newMember(v1.IstioRevisionTagKind, "prod", v1alpha1.DataplaneModeSidecar)
+		tag := &v1.IstioRevisionTag{
+			ObjectMeta: metav1.ObjectMeta{Name: "prod"},
+			Spec:       v1.IstioRevisionTagSpec{MemberSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}},
+			Status:     v1.IstioRevisionTagStatus{IstioRevision: "canary", IstiodNamespace: "istio-system"},
+		}
+		// the namespace joined before the selector was set
+		cl := newFakeClient(member, tag, newRevision("canary", true), newNamespace(map[string]string{
+			"team":                  "b",
+			constants.IstioRevLabel: "prod",
+			constants.MemberOfKey:   "istio-system",
+		}))
+
+		_, err := NewReconciler(cl, scheme.Scheme).Reconcile(ctx, member)
+		g.Expect(IsNamespaceNotAllowedError(err)).To(BeTrue())
+		g.Expect(getNamespaceLabels(g, cl)).To(Equal(map[string]string{"team": "b"}))
+
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(member), member)).To(Succeed())
+		reconciled := member.Status.GetCondition(v1alpha1.MeshMemberConditionReconciled)
+		g.Expect(reconciled.Reason).To(Equal(v1alpha1.MeshMemberReasonNamespaceNotAllowed))
+		g.Expect(reconciled.Message).To(ContainSubstring(`namespace "bookinfo" doesn't match the memberSelector of IstioRevisionTag "prod"`))
+		g.Expect(member.Status.IstioRevision).To(BeEmpty())
+	})
+}
+
+func TestFinalize(t *testing.T) {
+	t.Run("removes membership labels", func(t *testing.T) {
+		g := NewWithT(t)
+		member := newMember(v1.IstioKind, "default", v1alpha1.DataplaneModeSidecar)
+		cl := newFakeClient(member, newNamespace(map[string]string{
+			constants.IstioRevLabel: "default-v1-24-2",
+			constants.MemberOfKey:   "istio-system",
+			"team":                  "a",
+		}))
+
+		g.Expect(NewReconciler(cl, scheme.Scheme).Finalize(ctx, member)).To(Succeed())
+		g.Expect(getNamespaceLabels(g, cl)).To(Equal(map[string]string{"team": "a"}))
+	})
+
+	t.Run("leaves labels that weren't set by the operator", func(t *testing.T) {
+		g := NewWithT(t)
+		member := newMember(v1.IstioKind, "default", v1alpha1.DataplaneModeSidecar)
+		cl := newFakeClient(member, newNamespace(map[string]string{constants.IstioRevLabel: "manual"}))
+
+		g.Expect(NewReconciler(cl, scheme.Scheme).Finalize(ctx, member)).To(Succeed())
+		g.Expect(getNamespaceLabels(g, cl)).To(Equal(map[string]string{constants.IstioRevLabel: "manual"}))
+	})
+}
+
+func TestMapToReconcileRequests(t *testing.T) {
+	g := NewWithT(t)
+	byIstio := newMember(v1.IstioKind, "default", v1alpha1.DataplaneModeSidecar)
+	byTag := newMember(v1.IstioRevisionTagKind, "default", v1alpha1.DataplaneModeSidecar)
+	byTag.Namespace = "other"
+	byTag.Status.IstioRevision = "canary"
+	cl := newFakeClient(byIstio, byTag)
+	r := NewReconciler(cl, scheme.Scheme)
+
+	g.Expect(r.mapTargetToReconcileRequests(ctx, newIstio())).To(HaveLen(1))
+	requests := r.mapTargetToReconcileRequests(ctx, &v1.IstioRevisionTag{ObjectMeta: metav1.ObjectMeta{Name: "default"}})
+	g.Expect(requests).To(HaveLen(1))
+	g.Expect(requests[0].Namespace).To(Equal("other"))
+	g.Expect(r.mapRevisionToReconcileRequests(ctx, newRevision("canary", true))).To(HaveLen(1))
+	g.Expect(mapNamespaceToReconcileRequest(ctx, newNamespace(nil))[0].NamespacedName).
+		To(Equal(types.NamespacedName{Namespace: nsName, Name: v1alpha1.MeshMemberName}))
+}
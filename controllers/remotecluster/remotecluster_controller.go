@@ -0,0 +1,719 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotecluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/api/v1alpha1"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	"github.com/istio-ecosystem/sail-operator/pkg/enqueuelogger"
+	"github.com/istio-ecosystem/sail-operator/pkg/errlist"
+	"github.com/istio-ecosystem/sail-operator/pkg/kube"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/utils/clock"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"istio.io/istio/pkg/ptr"
+)
+
+const (
+	remoteSecretPrefix = "istio-remote-secret-"
+
+	// healthCheckPeriod is how often the operator checks whether the remote cluster is still reachable
+	healthCheckPeriod = time.Minute
+
+	// remoteClusterTimeout is the timeout for requests sent to the remote cluster's API server. It's kept short,
+	// because an unreachable remote cluster blocks the reconciliation until the request times out.
+	remoteClusterTimeout = 5 * time.Second
+
+	// kubeconfigSecretField is the name of the field index that maps the kubeconfig secret referenced by a
+	// RemoteCluster, in namespace/name format, to the RemoteCluster
+	kubeconfigSecretField = "spec.kubeconfig"
+
+	defaultTokenExpirationSeconds = int64(86400)
+)
+
+// Reconciler reconciles the RemoteCluster object
+type Reconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// apiReader reads the kubeconfig secrets that aren't in the cache yet, because they don't have the
+	// RemoteClusterSecretLabel
+	apiReader client.Reader
+
+	clock clock.PassiveClock
+
+	// newRemoteClient creates a client for the remote cluster's API server; it's replaced in tests
+	newRemoteClient func(restConfig *rest.Config) (kubernetes.Interface, error)
+}
+
+func NewReconciler(client client.Client, apiReader client.Reader, scheme *runtime.Scheme) *Reconciler {
+	return &Reconciler{
+		Client:    client,
+		Scheme:    scheme,
+		apiReader: apiReader,
+		clock:     clock.RealClock{},
+		newRemoteClient: func(restConfig *rest.Config) (kubernetes.Interface, error) {
+			return kubernetes.NewForConfig(restConfig)
+		},
+	}
+}
+
+// SecretCacheSelector selects the secrets that the manager's cache holds. The operator only needs the remote secrets
+// it creates and the kubeconfig secrets referenced by RemoteClusters, both of which it labels, so it doesn't have to
+// cache every secret in the cluster.
+var SecretCacheSelector = labels.SelectorFromSet(labels.Set{constants.RemoteClusterSecretLabel: "true"})
+
+// +kubebuilder:rbac:groups=sailoperator.io,resources=remoteclusters,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=sailoperator.io,resources=remoteclusters/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources="*",verbs="*"
+
+// Reconcile creates or updates the remote secret that the Istio control plane uses to
+// access the remote cluster and checks whether the remote cluster is reachable.
+func (r *Reconciler) Reconcile(ctx context.Context, rc *v1alpha1.RemoteCluster) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	result, reconcileErr := r.doReconcile(ctx, rc)
+
+	log.Info("Reconciliation done. Updating status.")
+	statusErr := r.updateStatus(ctx, rc, reconcileErr)
+
+	return result, errors.Join(reconcileErr, statusErr)
+}
+
+func (r *Reconciler) doReconcile(ctx context.Context, rc *v1alpha1.RemoteCluster) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+	if err := r.validate(rc); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	secretKey, err := r.getRemoteSecretKey(ctx, rc)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.pruneRemoteSecrets(ctx, rc, secretKey); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	existingSecret := &corev1.Secret{}
+	if err := r.Client.Get(ctx, secretKey, existingSecret); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, fmt.Errorf("failed to get remote secret: %w", err)
+		}
+		existingSecret = nil
+	} else if !metav1.IsControlledBy(existingSecret, rc) {
+		return ctrl.Result{}, reconciler.NewValidationError(
+			fmt.Sprintf("secret %s/%s already exists and is not managed by this RemoteCluster", secretKey.Namespace, secretKey.Name))
+	}
+
+	sourceKubeconfig, err := r.getSourceKubeconfig(ctx, rc)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	desiredSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretKey.Name,
+			Namespace: secretKey.Namespace,
+			Labels: map[string]string{
+				constants.IstioMultiClusterSecretLabel: "true",
+				constants.RemoteClusterSecretLabel:     "true",
+				constants.KubernetesAppManagedByKey:    constants.KubernetesAppManagedByValue,
+			},
+			Annotations: map[string]string{
+				constants.IstioClusterAnnotation: clusterName(rc),
+			},
+		},
+		Data: map[string][]byte{
+			clusterName(rc): sourceKubeconfig,
+		},
+	}
+
+	result := ctrl.Result{RequeueAfter: healthCheckPeriod}
+	if rc.Spec.ServiceAccount != nil {
+		kubeconfig, expiration, err := r.buildServiceAccountKubeconfig(ctx, rc, sourceKubeconfig, existingSecret)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		desiredSecret.Data[clusterName(rc)] = kubeconfig
+		desiredSecret.Annotations[constants.RemoteClusterTokenExpirationAnnotationKey] = expiration.UTC().Format(time.RFC3339)
+		desiredSecret.Annotations[constants.RemoteClusterServiceAccountAnnotationKey] = serviceAccountName(rc)
+
+		if untilRotation := rotationTime(rc, expiration).Sub(r.clock.Now()); untilRotation < result.RequeueAfter {
+			result.RequeueAfter = max(untilRotation, time.Second)
+		}
+	}
+
+	if existingSecret == nil {
+		desiredSecret.OwnerReferences = []metav1.OwnerReference{ownerReference(rc)}
+		log.Info("Creating remote secret", "Secret", secretKey)
+		if err := r.Client.Create(ctx, desiredSecret); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to create remote secret %s: %w", secretKey, err)
+		}
+	} else if !reflect.DeepEqual(existingSecret.Data, desiredSecret.Data) ||
+		!isSubset(desiredSecret.Labels, existingSecret.Labels) ||
+		!isSubset(desiredSecret.Annotations, existingSecret.Annotations) {
+		if existingSecret.Labels == nil {
+			existingSecret.Labels = map[string]string{}
+		}
+		if existingSecret.Annotations == nil {
+			existingSecret.Annotations = map[string]string{}
+		}
+		for k, v := range desiredSecret.Labels {
+			existingSecret.Labels[k] = v
+		}
+		for k, v := range desiredSecret.Annotations {
+			existingSecret.Annotations[k] = v
+		}
+		existingSecret.Data = desiredSecret.Data
+		log.Info("Updating remote secret", "Secret", secretKey)
+		if err := r.Client.Update(ctx, existingSecret); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to update remote secret %s: %w", secretKey, err)
+		}
+	}
+	return result, nil
+}
+
+func (r *Reconciler) validate(rc *v1alpha1.RemoteCluster) error {
+	if rc.Spec.Istio == "" {
+		return reconciler.NewValidationError("spec.istio not set")
+	}
+	if rc.Spec.Kubeconfig.Name == "" {
+		return reconciler.NewValidationError("spec.kubeconfig.name not set")
+	}
+	if rc.Spec.Kubeconfig.Namespace == "" {
+		return reconciler.NewValidationError("spec.kubeconfig.namespace not set")
+	}
+	return nil
+}
+
+// getRemoteSecretKey returns the key of the remote secret, which is created in the namespace of the referenced Istio's control plane
+func (r *Reconciler) getRemoteSecretKey(ctx context.Context, rc *v1alpha1.RemoteCluster) (client.ObjectKey, error) {
+	istio := v1.Istio{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: rc.Spec.Istio}, &istio); err != nil {
+		if apierrors.IsNotFound(err) {
+			return client.ObjectKey{}, reconciler.NewValidationError(fmt.Sprintf("Istio %q not found", rc.Spec.Istio))
+		}
+		return client.ObjectKey{}, fmt.Errorf("failed to get Istio %q: %w", rc.Spec.Istio, err)
+	}
+	if istio.Spec.Namespace == "" {
+		return client.ObjectKey{}, reconciler.NewValidationError(fmt.Sprintf("spec.namespace of Istio %q not set", istio.Name))
+	}
+	return types.NamespacedName{Namespace: istio.Spec.Namespace, Name: remoteSecretPrefix + clusterName(rc)}, nil
+}
+
+// pruneRemoteSecrets deletes the remote secrets previously created for this RemoteCluster that no longer match the given key,
+// for example because the cluster name or the control plane namespace has changed
+func (r *Reconciler) pruneRemoteSecrets(ctx context.Context, rc *v1alpha1.RemoteCluster, secretKey client.ObjectKey) error {
+	log := logf.FromContext(ctx)
+	secrets, err := r.listRemoteSecrets(ctx, rc)
+	if err != nil {
+		return err
+	}
+	for _, secret := range secrets {
+		if client.ObjectKeyFromObject(&secret) == secretKey {
+			continue
+		}
+		log.Info("Deleting stale remote secret", "Secret", client.ObjectKeyFromObject(&secret))
+		if err := r.Client.Delete(ctx, &secret); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete remote secret %s/%s: %w", secret.Namespace, secret.Name, err)
+		}
+	}
+	return nil
+}
+
+// listRemoteSecrets returns the remote secrets controlled by the given RemoteCluster
+func (r *Reconciler) listRemoteSecrets(ctx context.Context, rc *v1alpha1.RemoteCluster) ([]corev1.Secret, error) {
+	secretList := corev1.SecretList{}
+	if err := r.Client.List(ctx, &secretList, client.MatchingLabels{constants.IstioMultiClusterSecretLabel: "true"}); err != nil {
+		return nil, fmt.Errorf("failed to list remote secrets: %w", err)
+	}
+	var secrets []corev1.Secret
+	for _, secret := range secretList.Items {
+		if metav1.IsControlledBy(&secret, rc) {
+			secrets = append(secrets, secret)
+		}
+	}
+	return secrets, nil
+}
+
+// getSourceKubeconfig returns the kubeconfig that the operator uses to connect to the remote cluster
+func (r *Reconciler) getSourceKubeconfig(ctx context.Context, rc *v1alpha1.RemoteCluster) ([]byte, error) {
+	ref := rc.Spec.Kubeconfig
+	secret, err := r.getKubeconfigSecret(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, reconciler.NewValidationError(fmt.Sprintf("kubeconfig secret %s/%s not found", ref.Namespace, ref.Name))
+		}
+		return nil, fmt.Errorf("failed to get kubeconfig secret: %w", err)
+	}
+
+	key := kubeconfigKey(rc)
+	kubeconfig, found := secret.Data[key]
+	if !found || len(kubeconfig) == 0 {
+		return nil, reconciler.NewValidationError(fmt.Sprintf("kubeconfig secret %s/%s does not contain key %q", ref.Namespace, ref.Name, key))
+	}
+	config, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return nil, reconciler.NewValidationError(fmt.Sprintf("kubeconfig secret %s/%s contains an invalid kubeconfig: %v", ref.Namespace, ref.Name, err))
+	}
+	// istiod only uses the current context, so we drop everything else
+	if err := clientcmdapi.MinifyConfig(config); err != nil {
+		return nil, reconciler.NewValidationError(fmt.Sprintf("kubeconfig secret %s/%s contains an invalid kubeconfig: %v", ref.Namespace, ref.Name, err))
+	}
+	if err := validateSelfContained(config); err != nil {
+		return nil, reconciler.NewValidationError(fmt.Sprintf("kubeconfig secret %s/%s contains an unsupported kubeconfig: %v", ref.Namespace, ref.Name, err))
+	}
+	minified, err := clientcmd.Write(*config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize kubeconfig: %w", err)
+	}
+	return minified, nil
+}
+
+// getKubeconfigSecret returns the kubeconfig secret and sets the RemoteClusterSecretLabel on it, so that the secret
+// is added to the cache and changes to it trigger a reconciliation. Until the label is set, the secret isn't in the
+// cache and is read from the API server.
+func (r *Reconciler) getKubeconfigSecret(ctx context.Context, key client.ObjectKey) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(ctx, key, secret); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		if err := r.apiReader.Get(ctx, key, secret); err != nil {
+			return nil, err
+		}
+	}
+	if secret.Labels[constants.RemoteClusterSecretLabel] != "true" {
+		logf.FromContext(ctx).Info("Labeling kubeconfig secret", "Secret", key)
+		patch := client.MergeFrom(secret.DeepCopy())
+		if secret.Labels == nil {
+			secret.Labels = map[string]string{}
+		}
+		secret.Labels[constants.RemoteClusterSecretLabel] = "true"
+		if err := r.Client.Patch(ctx, secret, patch); err != nil {
+			return nil, fmt.Errorf("failed to label kubeconfig secret %s: %w", key, err)
+		}
+	}
+	return secret, nil
+}
+
+// validateSelfContained checks that the kubeconfig can be used without access to the operator's file system
+// or to credential plugins, neither of which are available to istiod (nor to the operator).
+func validateSelfContained(config *clientcmdapi.Config) error {
+	for name, cluster := range config.Clusters {
+		if cluster.CertificateAuthority != "" {
+			return fmt.Errorf("cluster %q references certificate-authority file %q; use certificate-authority-data instead",
+				name, cluster.CertificateAuthority)
+		}
+	}
+	for name, authInfo := range config.AuthInfos {
+		switch {
+		case authInfo.ClientCertificate != "":
+			return fmt.Errorf("user %q references client-certificate file %q; use client-certificate-data instead", name, authInfo.ClientCertificate)
+		case authInfo.ClientKey != "":
+			return fmt.Errorf("user %q references client-key file %q; use client-key-data instead", name, authInfo.ClientKey)
+		case authInfo.TokenFile != "":
+			return fmt.Errorf("user %q references token file %q; use token instead", name, authInfo.TokenFile)
+		case authInfo.Exec != nil:
+			return fmt.Errorf("user %q uses exec credential plugin %q, which isn't supported", name, authInfo.Exec.Command)
+		case authInfo.AuthProvider != nil:
+			return fmt.Errorf("user %q uses auth provider %q, which isn't supported", name, authInfo.AuthProvider.Name)
+		}
+	}
+	return nil
+}
+
+// buildServiceAccountKubeconfig returns a kubeconfig that authenticates to the remote cluster using a token issued
+// for the ServiceAccount specified in the RemoteCluster. The token in the existing remote secret is reused until it's
+// due for rotation.
+func (r *Reconciler) buildServiceAccountKubeconfig(
+	ctx context.Context, rc *v1alpha1.RemoteCluster, sourceKubeconfig []byte, existingSecret *corev1.Secret,
+) ([]byte, time.Time, error) {
+	log := logf.FromContext(ctx)
+
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(sourceKubeconfig)
+	if err != nil {
+		return nil, time.Time{}, reconciler.NewValidationError(fmt.Sprintf("failed to create client config from kubeconfig: %v", err))
+	}
+
+	token, expiration, found := getExistingToken(rc, restConfig.Host, existingSecret)
+	if !found || !r.clock.Now().Before(rotationTime(rc, expiration)) {
+		remoteClient, err := r.newRemoteClient(withTimeout(restConfig))
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("failed to create client for remote cluster: %w", err)
+		}
+
+		sa := rc.Spec.ServiceAccount
+		log.Info("Requesting ServiceAccount token from remote cluster", "ServiceAccount", serviceAccountName(rc))
+		ctx, cancel := context.WithTimeout(ctx, remoteClusterTimeout)
+		defer cancel()
+		tokenRequest, err := remoteClient.CoreV1().ServiceAccounts(sa.Namespace).CreateToken(ctx, sa.Name, &authenticationv1.TokenRequest{
+			Spec: authenticationv1.TokenRequestSpec{
+				ExpirationSeconds: ptr.Of(tokenExpirationSeconds(rc)),
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("failed to request token for ServiceAccount %s in remote cluster: %w", serviceAccountName(rc), err)
+		}
+		token = tokenRequest.Status.Token
+		expiration = tokenRequest.Status.ExpirationTimestamp.Time
+	}
+
+	name := clusterName(rc)
+	config := clientcmdapi.NewConfig()
+	config.Clusters[name] = &clientcmdapi.Cluster{
+		Server:                   restConfig.Host,
+		TLSServerName:            restConfig.ServerName,
+		InsecureSkipTLSVerify:    restConfig.Insecure,
+		CertificateAuthorityData: restConfig.CAData,
+	}
+	config.AuthInfos[name] = &clientcmdapi.AuthInfo{Token: token}
+	config.Contexts[name] = &clientcmdapi.Context{Cluster: name, AuthInfo: name}
+	config.CurrentContext = name
+
+	kubeconfig, err := clientcmd.Write(*config)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to write kubeconfig: %w", err)
+	}
+	return kubeconfig, expiration, nil
+}
+
+// getExistingToken returns the token in the existing remote secret, if it was issued for the ServiceAccount and API server
+// currently specified in the RemoteCluster
+func getExistingToken(rc *v1alpha1.RemoteCluster, server string, secret *corev1.Secret) (token string, expiration time.Time, found bool) {
+	if secret == nil || secret.Annotations[constants.RemoteClusterServiceAccountAnnotationKey] != serviceAccountName(rc) {
+		return "", time.Time{}, false
+	}
+	expiration, err := time.Parse(time.RFC3339, secret.Annotations[constants.RemoteClusterTokenExpirationAnnotationKey])
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	config, err := clientcmd.Load(secret.Data[clusterName(rc)])
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	kubeContext, found := config.Contexts[config.CurrentContext]
+	if !found {
+		return "", time.Time{}, false
+	}
+	cluster, clusterFound := config.Clusters[kubeContext.Cluster]
+	authInfo, authInfoFound := config.AuthInfos[kubeContext.AuthInfo]
+	if !clusterFound || !authInfoFound || cluster.Server != server || authInfo.Token == "" {
+		return "", time.Time{}, false
+	}
+	return authInfo.Token, expiration, true
+}
+
+// rotationTime returns the time at which a token expiring at the given time should be replaced,
+// which is when 80% of its lifetime has elapsed
+func rotationTime(rc *v1alpha1.RemoteCluster, expiration time.Time) time.Time {
+	lifetime := time.Duration(tokenExpirationSeconds(rc)) * time.Second
+	return expiration.Add(-lifetime / 5)
+}
+
+func tokenExpirationSeconds(rc *v1alpha1.RemoteCluster) int64 {
+	if rc.Spec.ServiceAccount != nil && rc.Spec.ServiceAccount.TokenExpirationSeconds != nil {
+		return *rc.Spec.ServiceAccount.TokenExpirationSeconds
+	}
+	return defaultTokenExpirationSeconds
+}
+
+func serviceAccountName(rc *v1alpha1.RemoteCluster) string {
+	if rc.Spec.ServiceAccount == nil {
+		return ""
+	}
+	return rc.Spec.ServiceAccount.Namespace + "/" + rc.Spec.ServiceAccount.Name
+}
+
+func clusterName(rc *v1alpha1.RemoteCluster) string {
+	if rc.Spec.ClusterName != "" {
+		return rc.Spec.ClusterName
+	}
+	return rc.Name
+}
+
+func kubeconfigKey(rc *v1alpha1.RemoteCluster) string {
+	if rc.Spec.Kubeconfig.Key != "" {
+		return rc.Spec.Kubeconfig.Key
+	}
+	return "kubeconfig"
+}
+
+func withTimeout(restConfig *rest.Config) *rest.Config {
+	restConfig = rest.CopyConfig(restConfig)
+	restConfig.Timeout = remoteClusterTimeout
+	return restConfig
+}
+
+func ownerReference(rc *v1alpha1.RemoteCluster) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion:         v1alpha1.GroupVersion.String(),
+		Kind:               v1alpha1.RemoteClusterKind,
+		Name:               rc.Name,
+		UID:                rc.UID,
+		Controller:         ptr.Of(true),
+		BlockOwnerDeletion: ptr.Of(true),
+	}
+}
+
+func isSubset(subset, set map[string]string) bool {
+	for k, v := range subset {
+		if actual, found := set[k]; !found || actual != v {
+			return false
+		}
+	}
+	return true
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	logger := mgr.GetLogger().WithName("ctrlr").WithName("remotecluster")
+
+	// mainObjectHandler handles the RemoteCluster watch events
+	mainObjectHandler := wrapEventHandler(logger, &handler.EnqueueRequestForObject{})
+
+	// secretHandler handles the remote secrets owned by the RemoteCluster and the kubeconfig secrets referenced by it
+	secretHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapSecretToReconcileRequests))
+
+	istioHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapIstioToReconcileRequests))
+
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &v1alpha1.RemoteCluster{}, kubeconfigSecretField, indexKubeconfigSecret); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(controller.Options{
+			LogConstructor: func(req *reconcile.Request) logr.Logger {
+				log := logger
+				if req != nil {
+					log = log.WithValues("RemoteCluster", req.Name)
+				}
+				return log
+			},
+		}).
+
+		// we use the Watches function instead of For(), so that we can wrap the handler so that events that cause the object to be enqueued are logged
+		Watches(&v1alpha1.RemoteCluster{}, mainObjectHandler).Named("remotecluster").
+		// the manager only caches the secrets selected by SecretCacheSelector, see cmd/main.go
+		Watches(&corev1.Secret{}, secretHandler).
+		Watches(&v1.Istio{}, istioHandler).
+		Complete(reconciler.NewStandardReconciler[*v1alpha1.RemoteCluster](r.Client, r.Reconcile))
+}
+
+func (r *Reconciler) determineStatus(ctx context.Context, rc *v1alpha1.RemoteCluster, reconcileErr error) (v1alpha1.RemoteClusterStatus, error) {
+	var errs errlist.Builder
+	reconciledCondition := r.determineReconciledCondition(reconcileErr)
+
+	status := *rc.Status.DeepCopy()
+	status.ObservedGeneration = rc.Generation
+	status.SecretName = ""
+	status.SecretNamespace = ""
+	status.TokenExpirationTime = nil
+
+	var readyCondition v1alpha1.RemoteClusterCondition
+	secrets, err := r.listRemoteSecrets(ctx, rc)
+	if err != nil {
+		readyCondition = v1alpha1.RemoteClusterCondition{
+			Type:    v1alpha1.RemoteClusterConditionReady,
+			Status:  metav1.ConditionUnknown,
+			Reason:  v1alpha1.RemoteClusterReasonReadinessCheckFailed,
+			Message: fmt.Sprintf("failed to get remote secret: %v", err),
+		}
+		errs.Add(err)
+	} else if len(secrets) == 0 {
+		status.ServerVersion = ""
+		status.LastProbeTime = nil
+		status.ProbedSecretResourceVersion = ""
+		readyCondition = v1alpha1.RemoteClusterCondition{
+			Type:    v1alpha1.RemoteClusterConditionReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  v1alpha1.RemoteClusterReasonSecretNotFound,
+			Message: "remote secret not found",
+		}
+	} else {
+		secret := secrets[0]
+		status.SecretName = secret.Name
+		status.SecretNamespace = secret.Namespace
+		if expiration, err := time.Parse(time.RFC3339, secret.Annotations[constants.RemoteClusterTokenExpirationAnnotationKey]); err == nil {
+			status.TokenExpirationTime = ptr.Of(metav1.NewTime(expiration))
+		}
+		if r.probeDue(&status, &secret) {
+			readyCondition, status.ServerVersion = r.determineReadyCondition(rc, &secret)
+			status.LastProbeTime = ptr.Of(metav1.NewTime(r.clock.Now().Truncate(time.Second)))
+			status.ProbedSecretResourceVersion = secret.ResourceVersion
+		} else {
+			readyCondition = status.GetCondition(v1alpha1.RemoteClusterConditionReady)
+		}
+	}
+
+	status.SetCondition(reconciledCondition)
+	status.SetCondition(readyCondition)
+	status.State = deriveState(reconciledCondition, readyCondition)
+	return status, errs.Error()
+}
+
+func (r *Reconciler) updateStatus(ctx context.Context, rc *v1alpha1.RemoteCluster, reconcileErr error) error {
+	var errs errlist.Builder
+
+	status, err := r.determineStatus(ctx, rc, reconcileErr)
+	if err != nil {
+		errs.Add(fmt.Errorf("failed to determine status: %w", err))
+	}
+
+	if !reflect.DeepEqual(rc.Status, status) {
+		if err := r.Client.Status().Patch(ctx, rc, kube.NewStatusPatch(status)); err != nil {
+			errs.Add(fmt.Errorf("failed to patch status: %w", err))
+		}
+	}
+	return errs.Error()
+}
+
+func deriveState(reconciledCondition, readyCondition v1alpha1.RemoteClusterCondition) v1alpha1.RemoteClusterConditionReason {
+	if reconciledCondition.Status != metav1.ConditionTrue {
+		return reconciledCondition.Reason
+	} else if readyCondition.Status != metav1.ConditionTrue {
+		return readyCondition.Reason
+	}
+	return v1alpha1.RemoteClusterReasonHealthy
+}
+
+func (r *Reconciler) determineReconciledCondition(err error) v1alpha1.RemoteClusterCondition {
+	c := v1alpha1.RemoteClusterCondition{Type: v1alpha1.RemoteClusterConditionReconciled}
+
+	if err == nil {
+		c.Status = metav1.ConditionTrue
+	} else {
+		c.Status = metav1.ConditionFalse
+		c.Reason = v1alpha1.RemoteClusterReasonReconcileError
+		c.Message = fmt.Sprintf("error reconciling resource: %v", err)
+	}
+	return c
+}
+
+// probeDue returns whether the remote cluster's reachability must be checked again. The check requires a request
+// to the remote cluster, so it isn't repeated on every reconciliation, but only every healthCheckPeriod or when the
+// credentials in the remote secret have changed. Otherwise, the result of the last check is kept.
+func (r *Reconciler) probeDue(status *v1alpha1.RemoteClusterStatus, secret *corev1.Secret) bool {
+	return status.LastProbeTime == nil ||
+		status.ProbedSecretResourceVersion != secret.ResourceVersion ||
+		status.GetCondition(v1alpha1.RemoteClusterConditionReady).Status == metav1.ConditionUnknown ||
+		!r.clock.Now().Before(status.LastProbeTime.Add(healthCheckPeriod))
+}
+
+// determineReadyCondition checks whether the remote cluster's API server can be reached using the kubeconfig
+// in the remote secret. It returns the condition and the version of the remote cluster.
+func (r *Reconciler) determineReadyCondition(rc *v1alpha1.RemoteCluster, secret *corev1.Secret) (v1alpha1.RemoteClusterCondition, string) {
+	c := v1alpha1.RemoteClusterCondition{
+		Type:   v1alpha1.RemoteClusterConditionReady,
+		Status: metav1.ConditionFalse,
+		Reason: v1alpha1.RemoteClusterReasonClusterUnreachable,
+	}
+
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(secret.Data[clusterName(rc)])
+	if err != nil {
+		c.Message = fmt.Sprintf("remote secret contains an invalid kubeconfig: %v", err)
+		return c, ""
+	}
+	remoteClient, err := r.newRemoteClient(withTimeout(restConfig))
+	if err != nil {
+		c.Message = fmt.Sprintf("failed to create client for remote cluster: %v", err)
+		return c, ""
+	}
+	serverVersion, err := remoteClient.Discovery().ServerVersion()
+	if err != nil {
+		c.Message = fmt.Sprintf("failed to connect to remote cluster: %v", err)
+		return c, ""
+	}
+
+	c.Status = metav1.ConditionTrue
+	c.Reason = ""
+	return c, serverVersion.GitVersion
+}
+
+func (r *Reconciler) mapSecretToReconcileRequests(ctx context.Context, obj client.Object) []reconcile.Request {
+	log := logf.FromContext(ctx)
+
+	var requests []reconcile.Request
+	if ref := metav1.GetControllerOf(obj); ref != nil && ref.Kind == v1alpha1.RemoteClusterKind && ref.APIVersion == v1alpha1.GroupVersion.String() {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: ref.Name}})
+	}
+
+	rcList := v1alpha1.RemoteClusterList{}
+	if err := r.Client.List(ctx, &rcList, client.MatchingFields{kubeconfigSecretField: obj.GetNamespace() + "/" + obj.GetName()}); err != nil {
+		log.Error(err, "failed to list RemoteClusters")
+		return requests
+	}
+	for _, rc := range rcList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: rc.Name}})
+	}
+	return requests
+}
+
+// indexKubeconfigSecret returns the kubeconfig secret referenced by the RemoteCluster, in namespace/name format
+func indexKubeconfigSecret(obj client.Object) []string {
+	ref := obj.(*v1alpha1.RemoteCluster).Spec.Kubeconfig
+	if ref.Namespace == "" || ref.Name == "" {
+		return nil
+	}
+	return []string{ref.Namespace + "/" + ref.Name}
+}
+
+func (r *Reconciler) mapIstioToReconcileRequests(ctx context.Context, istio client.Object) []reconcile.Request {
+	log := logf.FromContext(ctx)
+
+	rcList := v1alpha1.RemoteClusterList{}
+	if err := r.Client.List(ctx, &rcList); err != nil {
+		log.Error(err, "failed to list RemoteClusters")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, rc := range rcList.Items {
+		if rc.Spec.Istio == istio.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: rc.Name}})
+		}
+	}
+	return requests
+}
+
+func wrapEventHandler(logger logr.Logger, handler handler.EventHandler) handler.EventHandler {
+	return enqueuelogger.WrapIfNecessary(v1alpha1.RemoteClusterKind, logger, handler)
+}
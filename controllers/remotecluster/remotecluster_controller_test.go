@@ -0,0 +1,580 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotecluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/api/v1alpha1"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
+	. "github.com/onsi/gomega"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"istio.io/istio/pkg/ptr"
+)
+
+const (
+	remoteServer       = "https://remote.example.com:6443"
+	remoteVersion      = "v1.32.1"
+	controlPlaneNs     = "istio-system"
+	kubeconfigSecretNs = "sail-operator"
+)
+
+// fakeRemoteCluster emulates the API server of the remote cluster
+type fakeRemoteCluster struct {
+	clientset     *k8sfake.Clientset
+	tokenRequests int
+	unreachable   bool
+	// restConfigs records the configs that the reconciler used to connect to the remote cluster
+	restConfigs []*rest.Config
+}
+
+func newFakeRemoteCluster() *fakeRemoteCluster {
+	remote := &fakeRemoteCluster{clientset: k8sfake.NewClientset()}
+	remote.clientset.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: remoteVersion}
+	remote.clientset.PrependReactor("get", "version", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if remote.unreachable {
+			return true, nil, errors.New("connection refused")
+		}
+		return false, nil, nil
+	})
+	remote.clientset.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		createAction := action.(k8stesting.CreateActionImpl)
+		if createAction.GetSubresource() != "token" {
+			return false, nil, nil
+		}
+		remote.tokenRequests++
+		tokenRequest := createAction.GetObject().(*authenticationv1.TokenRequest)
+		expiration := time.Now().Add(time.Duration(*tokenRequest.Spec.ExpirationSeconds) * time.Second).Truncate(time.Second)
+		return true, &authenticationv1.TokenRequest{
+			Status: authenticationv1.TokenRequestStatus{
+				Token:               fmt.Sprintf("%s-%s-token-%d", action.GetNamespace(), createAction.Name, remote.tokenRequests),
+				ExpirationTimestamp: metav1.NewTime(expiration),
+			},
+		}, nil
+	})
+	return remote
+}
+
+func (f *fakeRemoteCluster) newClient(restConfig *rest.Config) (kubernetes.Interface, error) {
+	f.restConfigs = append(f.restConfigs, restConfig)
+	return f.clientset, nil
+}
+
+func TestReconcile(t *testing.T) {
+	ctx := context.Background()
+
+	testCases := []struct {
+		name              string
+		remoteCluster     *v1alpha1.RemoteCluster
+		objects           []client.Object
+		unreachable       bool
+		expectErr         bool
+		expectSecret      bool
+		expectToken       string
+		expectReconciled  metav1.ConditionStatus
+		expectReady       metav1.ConditionStatus
+		expectState       v1alpha1.RemoteClusterConditionReason
+		expectTokenExpiry bool
+	}{
+		{
+			name:             "copies kubeconfig into remote secret",
+			remoteCluster:    newRemoteCluster("cluster2", nil),
+			objects:          []client.Object{newIstio(), newKubeconfigSecret()},
+			expectSecret:     true,
+			expectToken:      "admin-token",
+			expectReconciled: metav1.ConditionTrue,
+			expectReady:      metav1.ConditionTrue,
+			expectState:      v1alpha1.RemoteClusterReasonHealthy,
+		},
+		{
+			name:              "issues ServiceAccount token",
+			remoteCluster:     newRemoteCluster("cluster2", newServiceAccount()),
+			objects:           []client.Object{newIstio(), newKubeconfigSecret()},
+			expectSecret:      true,
+			expectToken:       "istio-system-istio-reader-service-account-token-1",
+			expectReconciled:  metav1.ConditionTrue,
+			expectReady:       metav1.ConditionTrue,
+			expectState:       v1alpha1.RemoteClusterReasonHealthy,
+			expectTokenExpiry: true,
+		},
+		{
+			name:             "remote cluster unreachable",
+			remoteCluster:    newRemoteCluster("cluster2", nil),
+			objects:          []client.Object{newIstio(), newKubeconfigSecret()},
+			unreachable:      true,
+			expectSecret:     true,
+			expectToken:      "admin-token",
+			expectReconciled: metav1.ConditionTrue,
+			expectReady:      metav1.ConditionFalse,
+			expectState:      v1alpha1.RemoteClusterReasonClusterUnreachable,
+		},
+		{
+			name:             "Istio not found",
+			remoteCluster:    newRemoteCluster("cluster2", nil),
+			objects:          []client.Object{newKubeconfigSecret()},
+			expectErr:        true,
+			expectReconciled: metav1.ConditionFalse,
+			expectReady:      metav1.ConditionFalse,
+			expectState:      v1alpha1.RemoteClusterReasonReconcileError,
+		},
+		{
+			name:             "kubeconfig secret not found",
+			remoteCluster:    newRemoteCluster("cluster2", nil),
+			objects:          []client.Object{newIstio()},
+			expectErr:        true,
+			expectReconciled: metav1.ConditionFalse,
+			expectReady:      metav1.ConditionFalse,
+			expectState:      v1alpha1.RemoteClusterReasonReconcileError,
+		},
+		{
+			name:             "kubeconfig references token file",
+			remoteCluster:    newRemoteCluster("cluster2", nil),
+			objects:          []client.Object{newIstio(), newKubeconfigSecretWithAuthInfo(&clientcmdapi.AuthInfo{TokenFile: "/var/run/token"})},
+			expectErr:        true,
+			expectReconciled: metav1.ConditionFalse,
+			expectReady:      metav1.ConditionFalse,
+			expectState:      v1alpha1.RemoteClusterReasonReconcileError,
+		},
+		{
+			name:          "kubeconfig uses exec plugin",
+			remoteCluster: newRemoteCluster("cluster2", newServiceAccount()),
+			objects: []client.Object{
+				newIstio(),
+				newKubeconfigSecretWithAuthInfo(&clientcmdapi.AuthInfo{Exec: &clientcmdapi.ExecConfig{Command: "aws"}}),
+			},
+			expectErr:        true,
+			expectReconciled: metav1.ConditionFalse,
+			expectReady:      metav1.ConditionFalse,
+			expectState:      v1alpha1.RemoteClusterReasonReconcileError,
+		},
+		{
+			name:          "remote secret not managed by operator",
+			remoteCluster: newRemoteCluster("cluster2", nil),
+			objects: []client.Object{
+				newIstio(),
+				newKubeconfigSecret(),
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "istio-remote-secret-cluster2",
+						Namespace: controlPlaneNs,
+					},
+				},
+			},
+			expectErr:        true,
+			expectReconciled: metav1.ConditionFalse,
+			expectReady:      metav1.ConditionFalse,
+			expectState:      v1alpha1.RemoteClusterReasonReconcileError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			cl := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(append(tc.objects, tc.remoteCluster)...).
+				WithStatusSubresource(&v1alpha1.RemoteCluster{}).
+				Build()
+			remote := newFakeRemoteCluster()
+			remote.unreachable = tc.unreachable
+			r := NewReconciler(cl, cl, scheme.Scheme)
+			r.newRemoteClient = remote.newClient
+
+			result, err := r.Reconcile(ctx, tc.remoteCluster)
+			if tc.expectErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			}
+
+			secret := &corev1.Secret{}
+			err = cl.Get(ctx, client.ObjectKey{Namespace: controlPlaneNs, Name: "istio-remote-secret-cluster2"}, secret)
+			if tc.expectSecret {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(secret.Labels).To(HaveKeyWithValue(constants.IstioMultiClusterSecretLabel, "true"))
+				g.Expect(secret.Annotations).To(HaveKeyWithValue(constants.IstioClusterAnnotation, "cluster2"))
+				g.Expect(metav1.IsControlledBy(secret, tc.remoteCluster)).To(BeTrue())
+				g.Expect(secret.Data).To(HaveKey("cluster2"))
+				g.Expect(getToken(t, secret.Data["cluster2"])).To(Equal(tc.expectToken))
+				kubeconfig, err := clientcmd.Load(secret.Data["cluster2"])
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(kubeconfig.Contexts).To(HaveLen(1))
+			} else {
+				g.Expect(secret.OwnerReferences).To(BeEmpty())
+			}
+
+			rc := &v1alpha1.RemoteCluster{}
+			g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(tc.remoteCluster), rc)).To(Succeed())
+			g.Expect(rc.Status.GetCondition(v1alpha1.RemoteClusterConditionReconciled).Status).To(Equal(tc.expectReconciled))
+			g.Expect(rc.Status.GetCondition(v1alpha1.RemoteClusterConditionReady).Status).To(Equal(tc.expectReady))
+			g.Expect(rc.Status.State).To(Equal(tc.expectState))
+			if tc.expectReady == metav1.ConditionTrue {
+				g.Expect(rc.Status.ServerVersion).To(Equal(remoteVersion))
+				g.Expect(rc.Status.SecretName).To(Equal("istio-remote-secret-cluster2"))
+				g.Expect(rc.Status.SecretNamespace).To(Equal(controlPlaneNs))
+			} else {
+				g.Expect(rc.Status.ServerVersion).To(BeEmpty())
+			}
+			g.Expect(rc.Status.TokenExpirationTime != nil).To(Equal(tc.expectTokenExpiry))
+		})
+	}
+}
+
+func TestTokenRotation(t *testing.T) {
+	ctx := context.Background()
+	g := NewWithT(t)
+
+	rc := newRemoteCluster("cluster2", newServiceAccount())
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(newIstio(), newKubeconfigSecret(), rc).
+		WithStatusSubresource(&v1alpha1.RemoteCluster{}).
+		Build()
+	remote := newFakeRemoteCluster()
+	r := NewReconciler(cl, cl, scheme.Scheme)
+	r.newRemoteClient = remote.newClient
+
+	secretKey := client.ObjectKey{Namespace: controlPlaneNs, Name: "istio-remote-secret-cluster2"}
+	getSecret := func(g Gomega) *corev1.Secret {
+		secret := &corev1.Secret{}
+		g.Expect(cl.Get(ctx, secretKey, secret)).To(Succeed())
+		return secret
+	}
+
+	result, err := r.Reconcile(ctx, rc)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(healthCheckPeriod))
+	g.Expect(remote.tokenRequests).To(Equal(1))
+	g.Expect(getToken(t, getSecret(g).Data["cluster2"])).To(Equal("istio-system-istio-reader-service-account-token-1"))
+
+	// the connectivity check must use the token, not the credentials in the kubeconfig secret
+	lastConfig := remote.restConfigs[len(remote.restConfigs)-1]
+	g.Expect(lastConfig.BearerToken).To(Equal("istio-system-istio-reader-service-account-token-1"))
+	g.Expect(lastConfig.Host).To(Equal(remoteServer))
+	g.Expect(lastConfig.CAData).To(Equal([]byte("remote-ca")))
+
+	t.Run("token is reused before it is due for rotation", func(t *testing.T) {
+		g := NewWithT(t)
+		_, err := r.Reconcile(ctx, rc)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(remote.tokenRequests).To(Equal(1))
+	})
+
+	t.Run("token is rotated when 80% of its lifetime has elapsed", func(t *testing.T) {
+		g := NewWithT(t)
+		secret := getSecret(g)
+		expiration := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+		secret.Annotations[constants.RemoteClusterTokenExpirationAnnotationKey] = expiration
+		g.Expect(cl.Update(ctx, secret)).To(Succeed())
+
+		_, err := r.Reconcile(ctx, rc)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(remote.tokenRequests).To(Equal(2))
+		g.Expect(getToken(t, getSecret(g).Data["cluster2"])).To(Equal("istio-system-istio-reader-service-account-token-2"))
+	})
+
+	t.Run("token is reissued when ServiceAccount changes", func(t *testing.T) {
+		g := NewWithT(t)
+		rc.Spec.ServiceAccount.Name = "other"
+		g.Expect(cl.Update(ctx, rc)).To(Succeed())
+		_, err := r.Reconcile(ctx, rc)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(remote.tokenRequests).To(Equal(3))
+		g.Expect(getToken(t, getSecret(g).Data["cluster2"])).To(Equal("istio-system-other-token-3"))
+		g.Expect(getSecret(g).Annotations).To(HaveKeyWithValue(constants.RemoteClusterServiceAccountAnnotationKey, "istio-system/other"))
+	})
+
+	t.Run("requeues when the token is due for rotation", func(t *testing.T) {
+		g := NewWithT(t)
+		secret := getSecret(g)
+		// the token is due for rotation 20% of its lifetime (86400s) before it expires
+		expiration := time.Now().Add(17280*time.Second + 30*time.Second).UTC().Format(time.RFC3339)
+		secret.Annotations[constants.RemoteClusterTokenExpirationAnnotationKey] = expiration
+		g.Expect(cl.Update(ctx, secret)).To(Succeed())
+
+		result, err := r.Reconcile(ctx, rc)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(remote.tokenRequests).To(Equal(3))
+		g.Expect(result.RequeueAfter).To(BeNumerically("<=", 30*time.Second))
+		g.Expect(result.RequeueAfter).To(BeNumerically(">", 20*time.Second))
+	})
+}
+
+func TestStaleSecretIsPruned(t *testing.T) {
+	ctx := context.Background()
+	g := NewWithT(t)
+
+	rc := newRemoteCluster("cluster2", nil)
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(newIstio(), newKubeconfigSecret(), rc).
+		WithStatusSubresource(&v1alpha1.RemoteCluster{}).
+		Build()
+	remote := newFakeRemoteCluster()
+	r := NewReconciler(cl, cl, scheme.Scheme)
+	r.newRemoteClient = remote.newClient
+
+	_, err := r.Reconcile(ctx, rc)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	rc.Spec.ClusterName = "renamed"
+	g.Expect(cl.Update(ctx, rc)).To(Succeed())
+	_, err = r.Reconcile(ctx, rc)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	secretList := &corev1.SecretList{}
+	g.Expect(cl.List(ctx, secretList, client.InNamespace(controlPlaneNs))).To(Succeed())
+	g.Expect(secretList.Items).To(HaveLen(1))
+	g.Expect(secretList.Items[0].Name).To(Equal("istio-remote-secret-renamed"))
+	g.Expect(secretList.Items[0].Data).To(HaveKey("renamed"))
+}
+
+func TestMapSecretToReconcileRequests(t *testing.T) {
+	g := NewWithT(t)
+
+	rc := newRemoteCluster("cluster2", nil)
+	other := newRemoteCluster("cluster3", nil)
+	other.Spec.Kubeconfig.Name = "other-kubeconfig"
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(rc, other).
+		WithIndex(&v1alpha1.RemoteCluster{}, kubeconfigSecretField, indexKubeconfigSecret).
+		Build()
+	r := NewReconciler(cl, cl, scheme.Scheme)
+
+	requests := r.mapSecretToReconcileRequests(context.Background(), newKubeconfigSecret())
+	g.Expect(requests).To(HaveLen(1))
+	g.Expect(requests[0].Name).To(Equal("cluster2"))
+
+	ownedSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "istio-remote-secret-cluster3",
+			Namespace:       controlPlaneNs,
+			OwnerReferences: []metav1.OwnerReference{ownerReference(other)},
+		},
+	}
+	requests = r.mapSecretToReconcileRequests(context.Background(), ownedSecret)
+	g.Expect(requests).To(HaveLen(1))
+	g.Expect(requests[0].Name).To(Equal("cluster3"))
+}
+
+func TestKubeconfigSecretIsLabeled(t *testing.T) {
+	ctx := context.Background()
+	g := NewWithT(t)
+
+	rc := newRemoteCluster("cluster2", nil)
+	apiServer := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(newIstio(), newKubeconfigSecret(), rc).
+		WithStatusSubresource(&v1alpha1.RemoteCluster{}).
+		Build()
+	// the cache only holds the secrets selected by SecretCacheSelector
+	cl := interceptor.NewClient(apiServer, interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			if err := c.Get(ctx, key, obj, opts...); err != nil {
+				return err
+			}
+			if _, isSecret := obj.(*corev1.Secret); isSecret && !SecretCacheSelector.Matches(labels.Set(obj.GetLabels())) {
+				return apierrors.NewNotFound(corev1.Resource("secrets"), key.Name)
+			}
+			return nil
+		},
+	})
+	remote := newFakeRemoteCluster()
+	r := NewReconciler(cl, apiServer, scheme.Scheme)
+	r.newRemoteClient = remote.newClient
+
+	_, err := r.Reconcile(ctx, rc)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	kubeconfigSecret := &corev1.Secret{}
+	g.Expect(cl.Get(ctx, client.ObjectKey{Namespace: kubeconfigSecretNs, Name: "remote-kubeconfig"}, kubeconfigSecret)).To(Succeed())
+	g.Expect(kubeconfigSecret.Labels).To(HaveKeyWithValue(constants.RemoteClusterSecretLabel, "true"))
+
+	remoteSecret := &corev1.Secret{}
+	g.Expect(cl.Get(ctx, client.ObjectKey{Namespace: controlPlaneNs, Name: "istio-remote-secret-cluster2"}, remoteSecret)).To(Succeed())
+	g.Expect(remoteSecret.Labels).To(HaveKeyWithValue(constants.RemoteClusterSecretLabel, "true"))
+
+	g.Expect(rc.Status.GetCondition(v1alpha1.RemoteClusterConditionReconciled).Status).To(Equal(metav1.ConditionTrue))
+}
+
+func TestRemoteClusterIsProbedOnlyWhenDue(t *testing.T) {
+	ctx := context.Background()
+	g := NewWithT(t)
+
+	rc := newRemoteCluster("cluster2", nil)
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(newIstio(), newKubeconfigSecret(), rc).
+		WithStatusSubresource(&v1alpha1.RemoteCluster{}).
+		Build()
+	remote := newFakeRemoteCluster()
+	fakeClock := clocktesting.NewFakePassiveClock(time.Now())
+	r := NewReconciler(cl, cl, scheme.Scheme)
+	r.newRemoteClient = remote.newClient
+	r.clock = fakeClock
+
+	// without a ServiceAccount, the remote cluster is only contacted to check whether it's reachable
+	probes := func() int {
+		return len(remote.restConfigs)
+	}
+
+	_, err := r.Reconcile(ctx, rc)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(probes()).To(Equal(1))
+	g.Expect(rc.Status.GetCondition(v1alpha1.RemoteClusterConditionReady).Status).To(Equal(metav1.ConditionTrue))
+	g.Expect(rc.Status.LastProbeTime).ToNot(BeNil())
+	g.Expect(rc.Status.ProbedSecretResourceVersion).ToNot(BeEmpty())
+
+	t.Run("result of the last probe is kept until the health check period elapses", func(t *testing.T) {
+		g := NewWithT(t)
+		remote.unreachable = true
+		fakeClock.SetTime(fakeClock.Now().Add(healthCheckPeriod / 2))
+
+		_, err := r.Reconcile(ctx, rc)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(probes()).To(Equal(1))
+		g.Expect(rc.Status.GetCondition(v1alpha1.RemoteClusterConditionReady).Status).To(Equal(metav1.ConditionTrue))
+		g.Expect(rc.Status.ServerVersion).To(Equal(remoteVersion))
+	})
+
+	t.Run("remote cluster is probed when the health check period has elapsed", func(t *testing.T) {
+		g := NewWithT(t)
+		fakeClock.SetTime(fakeClock.Now().Add(healthCheckPeriod))
+
+		_, err := r.Reconcile(ctx, rc)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(probes()).To(Equal(2))
+		g.Expect(rc.Status.GetCondition(v1alpha1.RemoteClusterConditionReady).Status).To(Equal(metav1.ConditionFalse))
+		g.Expect(rc.Status.State).To(Equal(v1alpha1.RemoteClusterReasonClusterUnreachable))
+		g.Expect(rc.Status.ServerVersion).To(BeEmpty())
+	})
+
+	t.Run("remote cluster is probed when the remote secret changes", func(t *testing.T) {
+		g := NewWithT(t)
+		remote.unreachable = false
+		secret := &corev1.Secret{}
+		g.Expect(cl.Get(ctx, client.ObjectKey{Namespace: controlPlaneNs, Name: "istio-remote-secret-cluster2"}, secret)).To(Succeed())
+		secret.Annotations["example.com/touched"] = "true"
+		g.Expect(cl.Update(ctx, secret)).To(Succeed())
+
+		_, err := r.Reconcile(ctx, rc)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(probes()).To(Equal(3))
+		g.Expect(rc.Status.GetCondition(v1alpha1.RemoteClusterConditionReady).Status).To(Equal(metav1.ConditionTrue))
+		g.Expect(rc.Status.ProbedSecretResourceVersion).To(Equal(secret.ResourceVersion))
+	})
+}
+
+func newRemoteCluster(name string, sa *v1alpha1.RemoteServiceAccount) *v1alpha1.RemoteCluster {
+	return &v1alpha1.RemoteCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			UID:  types.UID("uid-" + name),
+		},
+		Spec: v1alpha1.RemoteClusterSpec{
+			Istio: "default",
+			Kubeconfig: v1alpha1.KubeconfigSecretReference{
+				Name:      "remote-kubeconfig",
+				Namespace: kubeconfigSecretNs,
+				Key:       "kubeconfig",
+			},
+			ServiceAccount: sa,
+		},
+	}
+}
+
+func newServiceAccount() *v1alpha1.RemoteServiceAccount {
+	return &v1alpha1.RemoteServiceAccount{
+		Name:                   "istio-reader-service-account",
+		Namespace:              "istio-system",
+		TokenExpirationSeconds: ptr.Of(int64(86400)),
+	}
+}
+
+func newIstio() *v1.Istio {
+	return &v1.Istio{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+		Spec: v1.IstioSpec{
+			Namespace: controlPlaneNs,
+		},
+	}
+}
+
+func newKubeconfigSecret() *corev1.Secret {
+	return newKubeconfigSecretWithAuthInfo(&clientcmdapi.AuthInfo{Token: "admin-token"})
+}
+
+func newKubeconfigSecretWithAuthInfo(authInfo *clientcmdapi.AuthInfo) *corev1.Secret {
+	config := clientcmdapi.NewConfig()
+	config.Clusters["remote"] = &clientcmdapi.Cluster{Server: remoteServer, CertificateAuthorityData: []byte("remote-ca")}
+	config.AuthInfos["admin"] = authInfo
+	config.Contexts["remote"] = &clientcmdapi.Context{Cluster: "remote", AuthInfo: "admin"}
+	// contexts other than the current one are dropped, so they may reference files
+	config.Clusters["local"] = &clientcmdapi.Cluster{Server: "https://localhost:6443", CertificateAuthority: "/etc/kubernetes/ca.crt"}
+	config.AuthInfos["local"] = &clientcmdapi.AuthInfo{TokenFile: "/var/run/token"}
+	config.Contexts["local"] = &clientcmdapi.Context{Cluster: "local", AuthInfo: "local"}
+	config.CurrentContext = "remote"
+	kubeconfig, err := clientcmd.Write(*config)
+	if err != nil {
+		panic(err)
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "remote-kubeconfig",
+			Namespace: kubeconfigSecretNs,
+		},
+		Data: map[string][]byte{
+			"kubeconfig": kubeconfig,
+		},
+	}
+}
+
+func getToken(t *testing.T, kubeconfig []byte) string {
+	t.Helper()
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		t.Fatalf("invalid kubeconfig: %v", err)
+	}
+	return restConfig.BearerToken
+}
@@ -0,0 +1,257 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sailoperatorconfig
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"sync/atomic"
+
+	"github.com/go-logr/logr"
+	"github.com/istio-ecosystem/sail-operator/api/v1alpha1"
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/enqueuelogger"
+	"github.com/istio-ecosystem/sail-operator/pkg/imageverification"
+	"github.com/istio-ecosystem/sail-operator/pkg/kube"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	uberzap "go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Logging holds the logging settings of the operator that the SailOperatorConfig can change at runtime.
+type Logging struct {
+	// Level is the level of the operator's logger
+	Level uberzap.AtomicLevel
+	// APIRequests controls whether the requests sent to the Kubernetes API server are logged
+	APIRequests *atomic.Bool
+}
+
+// loggingState is a snapshot of the logging settings
+type loggingState struct {
+	level         zapcore.Level
+	enqueueEvents bool
+	apiRequests   bool
+}
+
+// Reconciler applies the SailOperatorConfig to the running operator. The configuration from the properties file and
+// the command-line flags is restored when the SailOperatorConfig is deleted or when one of its fields is unset.
+type Reconciler struct {
+	client.Client
+	Scheme         *runtime.Scheme
+	operatorConfig *config.Provider
+	logging        Logging
+	startupLogging loggingState
+}
+
+// NewReconciler creates the reconciler. The current logging settings are what the operator returns to when the
+// SailOperatorConfig no longer sets them.
+func NewReconciler(client client.Client, scheme *runtime.Scheme, operatorConfig *config.Provider, logging Logging) *Reconciler {
+	return &Reconciler{
+		Client:         client,
+		Scheme:         scheme,
+		operatorConfig: operatorConfig,
+		logging:        logging,
+		startupLogging: loggingState{
+			level:         logging.Level.Level(),
+			enqueueEvents: enqueuelogger.LogEnqueueEvents.Load(),
+			apiRequests:   logging.APIRequests.Load(),
+		},
+	}
+}
+
+// +kubebuilder:rbac:groups=sailoperator.io,resources=sailoperatorconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=sailoperator.io,resources=sailoperatorconfigs/status,verbs=get;update;patch
+
+// Reconcile applies the SailOperatorConfig. Unlike the other controllers, this one doesn't use the
+// StandardReconciler, since it must also act when the object is not found.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+	if req.Name != v1alpha1.SailOperatorConfigName {
+		return ctrl.Result{}, nil
+	}
+
+	cfg := &v1alpha1.SailOperatorConfig{}
+	if err := r.Client.Get(ctx, req.NamespacedName, cfg); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("SailOperatorConfig not found; restoring startup configuration")
+			r.apply(config.OperatorConfig{}, nil)
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if !cfg.DeletionTimestamp.IsZero() {
+		log.Info("SailOperatorConfig is being deleted; restoring startup configuration")
+		r.apply(config.OperatorConfig{}, nil)
+		return ctrl.Result{}, nil
+	}
+
+	overrides, reconcileErr := toOverrides(cfg.Spec)
+	if reconcileErr == nil {
+		log.Info("Applying operator configuration")
+		r.apply(overrides, cfg.Spec.Logging)
+	}
+
+	status := r.determineStatus(cfg, reconcileErr)
+	if !reflect.DeepEqual(cfg.Status, status) {
+		if err := r.Client.Status().Patch(ctx, cfg, kube.NewStatusPatch(status)); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to patch status: %w", err)
+		}
+	}
+	if reconciler.IsValidationError(reconcileErr) {
+		log.Info("Validation failed", "error", reconcileErr)
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{}, reconcileErr
+}
+
+// apply passes the overrides to the Provider and updates the logging settings. The Provider only notifies the
+// controllers if the resulting configuration changed.
+func (r *Reconciler) apply(overrides config.OperatorConfig, logging *v1alpha1.OperatorLogging) {
+	r.operatorConfig.SetOverrides(overrides)
+
+	state := r.startupLogging
+	if logging != nil {
+		if logging.Level != "" {
+			state.level = toZapLevel(logging.Level)
+		}
+		if logging.EnqueueEvents != nil {
+			state.enqueueEvents = *logging.EnqueueEvents
+		}
+		if logging.APIRequests != nil {
+			state.apiRequests = *logging.APIRequests
+		}
+	}
+	r.logging.Level.SetLevel(state.level)
+	enqueuelogger.LogEnqueueEvents.Store(state.enqueueEvents)
+	r.logging.APIRequests.Store(state.apiRequests)
+}
+
+// toOverrides converts the spec into the overrides that the Provider applies on top of the configuration read from
+// the properties file.
+func toOverrides(spec v1alpha1.SailOperatorConfigSpec) (config.OperatorConfig, error) {
+	overrides := config.OperatorConfig{
+		DefaultProfile:    spec.DefaultProfile,
+		WatchedNamespaces: slices.Clone(spec.WatchedNamespaces),
+		FeatureGates:      maps.Clone(spec.FeatureGates),
+	}
+	if spec.Platform != "" {
+		platform, err := config.ParsePlatform(spec.Platform)
+		if err != nil {
+			return config.OperatorConfig{}, reconciler.NewValidationError(err.Error())
+		}
+		overrides.Platform = platform
+	}
+	for name := range spec.FeatureGates {
+		if !config.IsKnownFeatureGate(name) {
+			return config.OperatorConfig{}, reconciler.NewValidationError(fmt.Sprintf("unknown feature gate %q", name))
+		}
+	}
+
+	if len(spec.ImageDigests) > 0 {
+		overrides.ImageDigests = make(map[string]config.IstioImageConfig, len(spec.ImageDigests))
+		for version, images := range spec.ImageDigests {
+			overrides.ImageDigests[version] = config.IstioImageConfig{
+				IstiodImage:  images.Istiod,
+				ProxyImage:   images.Proxy,
+				CNIImage:     images.CNI,
+				ZTunnelImage: images.ZTunnel,
+			}
+		}
+	}
+	for _, rule := range spec.RegistryRewrites {
+		overrides.RegistryRewrites = append(overrides.RegistryRewrites, config.RegistryRewrite{Source: rule.Source, Mirror: rule.Mirror})
+	}
+	if spec.ImageVerification != nil {
+		if spec.ImageVerification.PublicKey != "" {
+			if _, err := imageverification.ParsePublicKey(spec.ImageVerification.PublicKey); err != nil {
+				return config.OperatorConfig{}, reconciler.NewValidationError(fmt.Sprintf("invalid imageVerification.publicKey: %v", err))
+			}
+		}
+		overrides.ImageVerification = &config.ImageVerificationPolicy{
+			AllowedDigests: slices.Clone(spec.ImageVerification.AllowedDigests),
+			PublicKey:      spec.ImageVerification.PublicKey,
+		}
+	}
+	if spec.ProbeDefaults != nil {
+		overrides.ProbeDefaults = config.ProbeDefaults{
+			ReadinessInitialDelaySeconds: spec.ProbeDefaults.ReadinessInitialDelaySeconds,
+			ReadinessPeriodSeconds:       spec.ProbeDefaults.ReadinessPeriodSeconds,
+			ReadinessFailureThreshold:    spec.ProbeDefaults.ReadinessFailureThreshold,
+		}
+	}
+	return overrides, nil
+}
+
+func toZapLevel(level v1alpha1.LogLevel) zapcore.Level {
+	switch level {
+	case v1alpha1.LogLevelError:
+		return zapcore.ErrorLevel
+	case v1alpha1.LogLevelInfo:
+		return zapcore.InfoLevel
+	default:
+		return zapcore.DebugLevel
+	}
+}
+
+func (r *Reconciler) determineStatus(cfg *v1alpha1.SailOperatorConfig, err error) v1alpha1.SailOperatorConfigStatus {
+	status := *cfg.Status.DeepCopy()
+	status.ObservedGeneration = cfg.Generation
+
+	c := v1alpha1.SailOperatorConfigCondition{Type: v1alpha1.SailOperatorConfigConditionReconciled}
+	if err == nil {
+		c.Status = metav1.ConditionTrue
+		status.State = v1alpha1.SailOperatorConfigReasonApplied
+	} else {
+		c.Status = metav1.ConditionFalse
+		c.Reason = v1alpha1.SailOperatorConfigReasonReconcileError
+		c.Message = fmt.Sprintf("error reconciling resource: %v", err)
+		status.State = c.Reason
+	}
+	status.SetCondition(c)
+	return status
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	logger := mgr.GetLogger().WithName("ctrlr").WithName("operatorcfg")
+
+	// we use the Watches function instead of For(), so that we can wrap the handler so that events that cause the object to be enqueued are logged
+	mainObjectHandler := enqueuelogger.WrapIfNecessary(v1alpha1.SailOperatorConfigKind, logger, &handler.EnqueueRequestForObject{})
+
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(controller.Options{
+			LogConstructor: func(req *reconcile.Request) logr.Logger {
+				log := logger
+				if req != nil {
+					log = log.WithValues("SailOperatorConfig", req.Name)
+				}
+				return log
+			},
+		}).
+		Watches(&v1alpha1.SailOperatorConfig{}, mainObjectHandler).Named("sailoperatorconfig").
+		Complete(r)
+}
@@ -0,0 +1,203 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sailoperatorconfig
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/istio-ecosystem/sail-operator/api/v1alpha1"
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/enqueuelogger"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
+	. "github.com/onsi/gomega"
+	uberzap "go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"istio.io/istio/pkg/ptr"
+)
+
+var (
+	ctx = context.Background()
+	key = types.NamespacedName{Name: v1alpha1.SailOperatorConfigName}
+)
+
+var fileConfig = config.OperatorConfig{
+	ImageDigests: map[string]config.IstioImageConfig{
+		"v1.24.2": {IstiodImage: "istiod-1.24.2", ProxyImage: "proxy-1.24.2"},
+		"v1.24.1": {IstiodImage: "istiod-1.24.1", ProxyImage: "proxy-1.24.1"},
+	},
+}
+
+func TestToOverrides(t *testing.T) {
+	testCases := []struct {
+		name        string
+		spec        v1alpha1.SailOperatorConfigSpec
+		expected    config.OperatorConfig
+		expectedErr bool
+	}{
+		{
+			name:     "empty spec",
+			spec:     v1alpha1.SailOperatorConfigSpec{},
+			expected: config.OperatorConfig{},
+		},
+		{
+			name: "all fields",
+			spec: v1alpha1.SailOperatorConfigSpec{
+				ImageDigests:      map[string]v1alpha1.ImageDigests{"v1.25.0": {Istiod: "istiod-1.25.0", CNI: "cni-1.25.0"}},
+				DefaultProfile:    "ambient",
+				Platform:          "openshift",
+				ProbeDefaults:     &v1alpha1.ProbeDefaults{ReadinessPeriodSeconds: ptr.Of(uint32(5))},
+				WatchedNamespaces: []string{"istio-system"},
+				FeatureGates:      map[string]bool{config.FeatureHelmReleaseAdoption: false},
+				RegistryRewrites:  []v1alpha1.RegistryRewrite{{Source: "docker.io/istio", Mirror: "mirror.example.com/istio"}},
+				ImageVerification: &v1alpha1.ImageVerification{AllowedDigests: []string{"sha256:abc"}},
+			},
+			expected: config.OperatorConfig{
+				ImageDigests:      map[string]config.IstioImageConfig{"v1.25.0": {IstiodImage: "istiod-1.25.0", CNIImage: "cni-1.25.0"}},
+				DefaultProfile:    "ambient",
+				Platform:          config.PlatformOpenShift,
+				ProbeDefaults:     config.ProbeDefaults{ReadinessPeriodSeconds: ptr.Of(uint32(5))},
+				WatchedNamespaces: []string{"istio-system"},
+				FeatureGates:      map[string]bool{config.FeatureHelmReleaseAdoption: false},
+				RegistryRewrites:  []config.RegistryRewrite{{Source: "docker.io/istio", Mirror: "mirror.example.com/istio"}},
+				ImageVerification: &config.ImageVerificationPolicy{AllowedDigests: []string{"sha256:abc"}},
+			},
+		},
+		{
+			name: "unknown feature gate",
+			spec: v1alpha1.SailOperatorConfigSpec{
+				FeatureGates: map[string]bool{"Unknown": true},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "invalid public key",
+			spec: v1alpha1.SailOperatorConfigSpec{
+				ImageVerification: &v1alpha1.ImageVerification{PublicKey: "not a key"},
+			},
+			expectedErr: true,
+		},
+		{
+			name:        "unknown platform",
+			spec:        v1alpha1.SailOperatorConfigSpec{Platform: "aks"},
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			overrides, err := toOverrides(tc.spec)
+			if tc.expectedErr {
+				g.Expect(reconciler.IsValidationError(err)).To(BeTrue())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(overrides).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	defer enqueuelogger.LogEnqueueEvents.Store(enqueuelogger.LogEnqueueEvents.Load())
+	enqueuelogger.LogEnqueueEvents.Store(false)
+
+	g := NewWithT(t)
+	cfg := &v1alpha1.SailOperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: v1alpha1.SailOperatorConfigName, Generation: 2},
+		Spec: v1alpha1.SailOperatorConfigSpec{
+			ImageDigests:   map[string]v1alpha1.ImageDigests{"v1.24.2": {Istiod: "mirror/istiod"}},
+			DefaultProfile: "ambient",
+			Logging: &v1alpha1.OperatorLogging{
+				Level:         v1alpha1.LogLevelError,
+				EnqueueEvents: ptr.Of(true),
+				APIRequests:   ptr.Of(true),
+			},
+		},
+	}
+	cl := newFakeClientBuilder().WithObjects(cfg).Build()
+
+	provider := config.NewProvider(fileConfig)
+	logging := Logging{Level: uberzap.NewAtomicLevelAt(zapcore.DebugLevel), APIRequests: &atomic.Bool{}}
+	r := NewReconciler(cl, scheme.Scheme, provider, logging)
+
+	_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(provider.Get().DefaultProfile).To(Equal("ambient"))
+	g.Expect(provider.Get().ImageDigests).To(Equal(map[string]config.IstioImageConfig{
+		"v1.24.2": {IstiodImage: "mirror/istiod"},
+		"v1.24.1": {IstiodImage: "istiod-1.24.1", ProxyImage: "proxy-1.24.1"},
+	}))
+	g.Expect(logging.Level.Level()).To(Equal(zapcore.ErrorLevel))
+	g.Expect(enqueuelogger.LogEnqueueEvents.Load()).To(BeTrue())
+	g.Expect(logging.APIRequests.Load()).To(BeTrue())
+
+	g.Expect(cl.Get(ctx, key, cfg)).To(Succeed())
+	g.Expect(cfg.Status.ObservedGeneration).To(Equal(int64(2)))
+	g.Expect(cfg.Status.State).To(Equal(v1alpha1.SailOperatorConfigReasonApplied))
+	g.Expect(cfg.Status.GetCondition(v1alpha1.SailOperatorConfigConditionReconciled).Status).To(Equal(metav1.ConditionTrue))
+
+	// unsetting the logging settings restores the startup values
+	cfg.Spec.Logging = nil
+	g.Expect(cl.Update(ctx, cfg)).To(Succeed())
+	_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(logging.Level.Level()).To(Equal(zapcore.DebugLevel))
+	g.Expect(enqueuelogger.LogEnqueueEvents.Load()).To(BeFalse())
+	g.Expect(logging.APIRequests.Load()).To(BeFalse())
+
+	// deleting the object restores the configuration from the file
+	g.Expect(cl.Delete(ctx, cfg)).To(Succeed())
+	_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(provider.Get()).To(Equal(fileConfig))
+}
+
+func TestReconcileInvalidConfig(t *testing.T) {
+	g := NewWithT(t)
+	cfg := &v1alpha1.SailOperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: v1alpha1.SailOperatorConfigName},
+		Spec: v1alpha1.SailOperatorConfigSpec{
+			DefaultProfile: "ambient",
+			FeatureGates:   map[string]bool{"Unknown": true},
+		},
+	}
+	cl := newFakeClientBuilder().WithObjects(cfg).Build()
+	provider := config.NewProvider(fileConfig)
+	r := NewReconciler(cl, scheme.Scheme, provider, Logging{Level: uberzap.NewAtomicLevel(), APIRequests: &atomic.Bool{}})
+
+	_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(provider.Get()).To(Equal(fileConfig))
+
+	g.Expect(cl.Get(ctx, key, cfg)).To(Succeed())
+	g.Expect(cfg.Status.State).To(Equal(v1alpha1.SailOperatorConfigReasonReconcileError))
+	condition := cfg.Status.GetCondition(v1alpha1.SailOperatorConfigConditionReconciled)
+	g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(condition.Message).To(ContainSubstring(`unknown feature gate "Unknown"`))
+}
+
+func newFakeClientBuilder() *fake.ClientBuilder {
+	return fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithStatusSubresource(&v1alpha1.SailOperatorConfig{})
+}
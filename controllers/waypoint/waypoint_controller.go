@@ -0,0 +1,410 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package waypoint
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	"github.com/istio-ecosystem/sail-operator/pkg/enqueuelogger"
+	"github.com/istio-ecosystem/sail-operator/pkg/errlist"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	"github.com/istio-ecosystem/sail-operator/pkg/revision"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"istio.io/istio/pkg/ptr"
+)
+
+const (
+	// namespaceWaypointName is the name of the waypoint Gateway created for a labeled Namespace.
+	// It matches the default name used by `istioctl waypoint apply`.
+	namespaceWaypointName = "waypoint"
+
+	// serviceAccountWaypointSuffix is appended to the name of a labeled ServiceAccount to get the name of its waypoint Gateway
+	serviceAccountWaypointSuffix = "-waypoint"
+
+	waypointGatewayClassName = "istio-waypoint"
+
+	// waypointStatusReady is the value of the waypoint status annotation when the waypoint Gateway is programmed
+	waypointStatusReady = "Ready"
+
+	// crdPollInterval is how often the operator checks whether the Gateway API CRDs have been installed, when they
+	// weren't installed at startup
+	crdPollInterval = 30 * time.Second
+)
+
+var gatewayGVK = schema.GroupVersionKind{Group: "gateway.networking.k8s.io", Version: "v1", Kind: "Gateway"}
+
+// Reconciler creates, updates and deletes waypoint Gateways for Namespaces and ServiceAccounts
+// that are labeled with the sailoperator.io/waypoint label.
+type Reconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+func NewReconciler(client client.Client, scheme *runtime.Scheme) *Reconciler {
+	return &Reconciler{
+		Client: client,
+		Scheme: scheme,
+	}
+}
+
+// NewClient creates the client for the Reconciler. The Gateway API types aren't part of the scheme, so the
+// Reconciler reads Gateways as unstructured objects. Unlike the manager's client, which reads unstructured objects
+// from the API server, this client serves these reads from the informer that backs the controller's Gateway watch.
+func NewClient(mgr ctrl.Manager) (client.Client, error) {
+	return client.New(mgr.GetConfig(), client.Options{
+		HTTPClient: mgr.GetHTTPClient(),
+		Scheme:     mgr.GetScheme(),
+		Mapper:     mgr.GetRESTMapper(),
+		Cache:      &client.CacheOptions{Reader: mgr.GetCache(), Unstructured: true},
+	})
+}
+
+// +kubebuilder:rbac:groups="gateway.networking.k8s.io",resources=gateways,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile ensures that the Namespace and each of its ServiceAccounts has a waypoint Gateway if and only if it is
+// labeled with the sailoperator.io/waypoint label, and reports the readiness of each waypoint in the
+// sailoperator.io/waypoint-status annotation of the labeled object.
+func (r *Reconciler) Reconcile(ctx context.Context, ns *corev1.Namespace) (ctrl.Result, error) {
+	serviceAccounts := &corev1.ServiceAccountList{}
+	if err := r.Client.List(ctx, serviceAccounts, client.InNamespace(ns.Name)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list ServiceAccounts: %w", err)
+	}
+
+	var errs errlist.Builder
+	desired := map[string]bool{}
+	reconcileOwner := func(owner client.Object, ownerKind, waypointName string) {
+		trafficType, found := owner.GetLabels()[constants.WaypointLabel]
+		if !found {
+			errs.Add(r.updateStatusAnnotation(ctx, owner, ""))
+			return
+		}
+		desired[waypointName] = true
+		status, err := r.reconcileWaypoint(ctx, ns, owner, ownerKind, waypointName, trafficType)
+		errs.Add(err)
+		if status != "" {
+			errs.Add(r.updateStatusAnnotation(ctx, owner, status))
+		}
+	}
+
+	reconcileOwner(ns, "Namespace", namespaceWaypointName)
+	for i := range serviceAccounts.Items {
+		sa := &serviceAccounts.Items[i]
+		reconcileOwner(sa, "ServiceAccount", sa.Name+serviceAccountWaypointSuffix)
+	}
+
+	errs.Add(r.pruneWaypoints(ctx, ns.Name, desired))
+	return ctrl.Result{}, errs.Error()
+}
+
+// reconcileWaypoint creates or updates the waypoint Gateway of the given owner and returns the value of the owner's
+// status annotation. An empty status is returned when the status can't be determined due to an error.
+func (r *Reconciler) reconcileWaypoint(
+	ctx context.Context, ns *corev1.Namespace, owner client.Object, ownerKind, name, trafficType string,
+) (string, error) {
+	log := logf.FromContext(ctx).WithValues("Gateway", name)
+
+	if trafficType == "" {
+		trafficType = "service"
+	}
+	if trafficType != "service" && trafficType != "workload" && trafficType != "all" {
+		return notReady(fmt.Sprintf("invalid value %q for label %s; must be one of: service, workload, all",
+			trafficType, constants.WaypointLabel)), nil
+	}
+
+	gateway := newGateway()
+	err := r.Client.Get(ctx, types.NamespacedName{Namespace: ns.Name, Name: name}, gateway)
+	if apierrors.IsNotFound(err) {
+		gateway = newGateway()
+		gateway.SetName(name)
+		gateway.SetNamespace(ns.Name)
+		gateway.SetOwnerReferences([]metav1.OwnerReference{{
+			APIVersion:         corev1.SchemeGroupVersion.String(),
+			Kind:               ownerKind,
+			Name:               owner.GetName(),
+			UID:                owner.GetUID(),
+			Controller:         ptr.Of(true),
+			BlockOwnerDeletion: ptr.Of(true),
+		}})
+		applyDesiredState(gateway, ns, trafficType)
+		if err := unstructured.SetNestedSlice(gateway.Object, []any{
+			map[string]any{
+				"name":     "mesh",
+				"port":     int64(15008),
+				"protocol": "HBONE",
+			},
+		}, "spec", "listeners"); err != nil {
+			return "", err
+		}
+
+		log.Info("Creating waypoint Gateway")
+		if err := r.Client.Create(ctx, gateway); err != nil {
+			return "", fmt.Errorf("failed to create waypoint Gateway %s/%s: %w", ns.Name, name, err)
+		}
+		return getGatewayStatus(gateway), nil
+	} else if err != nil {
+		return "", fmt.Errorf("failed to get waypoint Gateway %s/%s: %w", ns.Name, name, err)
+	}
+
+	if !metav1.IsControlledBy(gateway, owner) {
+		return notReady(fmt.Sprintf("Gateway %q already exists and is not managed by the operator", name)), nil
+	}
+
+	existing := gateway.DeepCopy()
+	applyDesiredState(gateway, ns, trafficType)
+	if !reflect.DeepEqual(existing, gateway) {
+		log.Info("Updating waypoint Gateway")
+		if err := r.Client.Update(ctx, gateway); err != nil {
+			return "", fmt.Errorf("failed to update waypoint Gateway %s/%s: %w", ns.Name, name, err)
+		}
+	}
+	return getGatewayStatus(gateway), nil
+}
+
+// applyDesiredState sets the labels and the gateway class of the waypoint Gateway. The listeners are only set when
+// the Gateway is created, so that users can adjust them.
+func applyDesiredState(gateway *unstructured.Unstructured, ns *corev1.Namespace, trafficType string) {
+	labels := gateway.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[constants.KubernetesAppManagedByKey] = constants.KubernetesAppManagedByValue
+	labels[constants.IstioWaypointForLabel] = trafficType
+	if rev := revision.GetReferencedRevisionFromNamespace(ns.Labels); rev != "" {
+		labels[constants.IstioRevLabel] = rev
+	} else {
+		delete(labels, constants.IstioRevLabel)
+	}
+	gateway.SetLabels(labels)
+
+	_ = unstructured.SetNestedField(gateway.Object, waypointGatewayClassName, "spec", "gatewayClassName")
+}
+
+// getGatewayStatus returns the waypoint status based on the Programmed condition of the Gateway.
+func getGatewayStatus(gateway *unstructured.Unstructured) string {
+	conditions, _, _ := unstructured.NestedSlice(gateway.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]any)
+		if !ok || condition["type"] != "Programmed" {
+			continue
+		}
+		if condition["status"] == string(metav1.ConditionTrue) {
+			return waypointStatusReady
+		}
+		if message, _ := condition["message"].(string); message != "" {
+			return notReady(message)
+		}
+		break
+	}
+	return notReady("waiting for the Gateway to be programmed")
+}
+
+func notReady(message string) string {
+	return "NotReady: " + message
+}
+
+// pruneWaypoints deletes the waypoint Gateways in the namespace that were created by the operator, but are no longer desired.
+func (r *Reconciler) pruneWaypoints(ctx context.Context, namespace string, desired map[string]bool) error {
+	log := logf.FromContext(ctx)
+
+	gateways := &unstructured.UnstructuredList{}
+	gateways.SetGroupVersionKind(gatewayGVK.GroupVersion().WithKind(gatewayGVK.Kind + "List"))
+	if err := r.Client.List(ctx, gateways, client.InNamespace(namespace),
+		client.MatchingLabels{constants.KubernetesAppManagedByKey: constants.KubernetesAppManagedByValue}); err != nil {
+		return fmt.Errorf("failed to list Gateways: %w", err)
+	}
+
+	var errs errlist.Builder
+	for i := range gateways.Items {
+		gateway := &gateways.Items[i]
+		if desired[gateway.GetName()] || !isManagedWaypoint(gateway) {
+			continue
+		}
+		log.Info("Deleting waypoint Gateway", "Gateway", gateway.GetName())
+		if err := r.Client.Delete(ctx, gateway); client.IgnoreNotFound(err) != nil {
+			errs.Add(fmt.Errorf("failed to delete waypoint Gateway %s/%s: %w", namespace, gateway.GetName(), err))
+		}
+	}
+	return errs.Error()
+}
+
+// updateStatusAnnotation sets the waypoint status annotation on the given Namespace or ServiceAccount,
+// or removes it if status is empty.
+func (r *Reconciler) updateStatusAnnotation(ctx context.Context, obj client.Object, status string) error {
+	current, found := obj.GetAnnotations()[constants.WaypointStatusAnnotationKey]
+	if current == status && (found || status == "") {
+		return nil
+	}
+
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	annotations := obj.GetAnnotations()
+	if status == "" {
+		delete(annotations, constants.WaypointStatusAnnotationKey)
+	} else {
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[constants.WaypointStatusAnnotationKey] = status
+	}
+	obj.SetAnnotations(annotations)
+	if err := r.Client.Patch(ctx, obj, patch); err != nil {
+		return fmt.Errorf("failed to update waypoint status of %s: %w", obj.GetName(), err)
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager. The controller can only watch Gateways once the
+// Gateway API CRDs are installed in the cluster. If they aren't installed yet, the controller is set up as soon as
+// they are, which the operator checks every crdPollInterval.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	logger := mgr.GetLogger().WithName("ctrlr").WithName("waypoint")
+
+	installed, err := gatewayAPIInstalled(mgr.GetRESTMapper())
+	if err != nil {
+		return err
+	} else if installed {
+		return r.setupController(mgr, logger)
+	}
+
+	logger.Info("Gateway API CRDs not found; the waypoint controller will be started once they are installed")
+	return mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		err := wait.PollUntilContextCancel(ctx, crdPollInterval, false, func(ctx context.Context) (bool, error) {
+			installed, err := gatewayAPIInstalled(mgr.GetRESTMapper())
+			if err != nil {
+				logger.Error(err, "failed to check for Gateway API CRDs")
+			}
+			return installed, nil
+		})
+		if err != nil {
+			// the manager is shutting down
+			return nil
+		}
+		logger.Info("Gateway API CRDs found; starting the waypoint controller")
+		return r.setupController(mgr, logger)
+	}))
+}
+
+// gatewayAPIInstalled returns true if the Gateway CRD is installed in the cluster
+func gatewayAPIInstalled(mapper meta.RESTMapper) (bool, error) {
+	if _, err := mapper.RESTMapping(gatewayGVK.GroupKind(), gatewayGVK.Version); err != nil {
+		if meta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check for Gateway API CRDs: %w", err)
+	}
+	return true, nil
+}
+
+func (r *Reconciler) setupController(mgr ctrl.Manager, logger logr.Logger) error {
+	// mainObjectHandler handles the Namespace watch events
+	mainObjectHandler := wrapEventHandler(logger, &handler.EnqueueRequestForObject{})
+
+	// namespaceHandler enqueues the Namespace of the ServiceAccount or Gateway that changed
+	namespaceHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(mapToNamespace))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(controller.Options{
+			LogConstructor: func(req *reconcile.Request) logr.Logger {
+				log := logger
+				if req != nil {
+					log = log.WithValues("Namespace", req.Name)
+				}
+				return log
+			},
+		}).
+		// we use the Watches function instead of For(), so that we can wrap the handler so that events that cause the object to be enqueued are logged
+		Watches(&corev1.Namespace{}, mainObjectHandler, builder.WithPredicates(namespacePredicate())).
+		Named("waypoint").
+		Watches(&corev1.ServiceAccount{}, namespaceHandler, builder.WithPredicates(predicate.NewPredicateFuncs(isWaypointOwner))).
+		Watches(newGateway(), namespaceHandler, builder.WithPredicates(predicate.NewPredicateFuncs(isManagedWaypoint))).
+		Complete(reconciler.NewStandardReconciler[*corev1.Namespace](r.Client, r.Reconcile))
+}
+
+func newGateway() *unstructured.Unstructured {
+	gateway := &unstructured.Unstructured{}
+	gateway.SetGroupVersionKind(gatewayGVK)
+	return gateway
+}
+
+// isWaypointOwner returns true if the object requests a waypoint or still has the waypoint status annotation,
+// which means that its waypoint may need to be cleaned up.
+func isWaypointOwner(obj client.Object) bool {
+	_, labeled := obj.GetLabels()[constants.WaypointLabel]
+	_, annotated := obj.GetAnnotations()[constants.WaypointStatusAnnotationKey]
+	return labeled || annotated
+}
+
+// namespacePredicate filters out events for Namespaces that neither have nor had a waypoint. Since the waypoints of
+// ServiceAccounts are labeled with the revision of their Namespace, changes to the revision labels of any Namespace
+// are also let through.
+func namespacePredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return isWaypointOwner(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return isWaypointOwner(e.ObjectOld) || isWaypointOwner(e.ObjectNew) ||
+				revision.GetReferencedRevisionFromNamespace(e.ObjectOld.GetLabels()) !=
+					revision.GetReferencedRevisionFromNamespace(e.ObjectNew.GetLabels())
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return isWaypointOwner(e.Object)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return isWaypointOwner(e.Object)
+		},
+	}
+}
+
+// isManagedWaypoint returns true if the Gateway was created by this controller.
+func isManagedWaypoint(obj client.Object) bool {
+	if obj.GetLabels()[constants.KubernetesAppManagedByKey] != constants.KubernetesAppManagedByValue {
+		return false
+	}
+	ownerRef := metav1.GetControllerOf(obj)
+	return ownerRef != nil && ownerRef.APIVersion == corev1.SchemeGroupVersion.String() &&
+		(ownerRef.Kind == "Namespace" || ownerRef.Kind == "ServiceAccount")
+}
+
+func mapToNamespace(_ context.Context, obj client.Object) []reconcile.Request {
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: obj.GetNamespace()}}}
+}
+
+func wrapEventHandler(logger logr.Logger, handler handler.EventHandler) handler.EventHandler {
+	return enqueuelogger.WrapIfNecessary("Namespace", logger, handler)
+}
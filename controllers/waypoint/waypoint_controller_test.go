@@ -0,0 +1,294 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package waypoint
+
+import (
+	"context"
+	"testing"
+
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+var ctx = context.Background()
+
+const nsName = "bookinfo"
+
+func newFakeClient(objs ...client.Object) client.Client {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Namespace"), meta.RESTScopeRoot)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ServiceAccount"), meta.RESTScopeNamespace)
+	mapper.Add(gatewayGVK, meta.RESTScopeNamespace)
+	return fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithRESTMapper(mapper).
+		WithObjects(objs...).
+		Build()
+}
+
+func newNamespace(labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   nsName,
+			UID:    "ns-uid",
+			Labels: labels,
+		},
+	}
+}
+
+func getGateway(g *WithT, cl client.Client, name string) *unstructured.Unstructured {
+	gateway := newGateway()
+	g.Expect(cl.Get(ctx, types.NamespacedName{Namespace: nsName, Name: name}, gateway)).To(Succeed())
+	return gateway
+}
+
+func reconcileNamespace(g *WithT, cl client.Client) *corev1.Namespace {
+	ns := &corev1.Namespace{}
+	g.Expect(cl.Get(ctx, types.NamespacedName{Name: nsName}, ns)).To(Succeed())
+	_, err := NewReconciler(cl, scheme.Scheme).Reconcile(ctx, ns)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cl.Get(ctx, types.NamespacedName{Name: nsName}, ns)).To(Succeed())
+	return ns
+}
+
+func TestReconcile(t *testing.T) {
+	t.Run("creates waypoint for labeled namespace", func(t *testing.T) {
+		g := NewWithT(t)
+		cl := newFakeClient(newNamespace(map[string]string{
+			constants.WaypointLabel: "",
+			constants.IstioRevLabel: "canary",
+		}))
+
+		ns := reconcileNamespace(g, cl)
+
+		gateway := getGateway(g, cl, namespaceWaypointName)
+		g.Expect(gateway.GetLabels()).To(HaveKeyWithValue(constants.IstioWaypointForLabel, "service"))
+		g.Expect(gateway.GetLabels()).To(HaveKeyWithValue(constants.IstioRevLabel, "canary"))
+		g.Expect(metav1.IsControlledBy(gateway, ns)).To(BeTrue())
+		className, _, _ := unstructured.NestedString(gateway.Object, "spec", "gatewayClassName")
+		g.Expect(className).To(Equal(waypointGatewayClassName))
+		listeners, _, _ := unstructured.NestedSlice(gateway.Object, "spec", "listeners")
+		g.Expect(listeners).To(HaveLen(1))
+		g.Expect(ns.Annotations).To(HaveKeyWithValue(constants.WaypointStatusAnnotationKey,
+			"NotReady: waiting for the Gateway to be programmed"))
+	})
+
+	t.Run("creates waypoint for labeled service account", func(t *testing.T) {
+		g := NewWithT(t)
+		sa := &corev1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "reviews",
+				Namespace: nsName,
+				UID:       "sa-uid",
+				Labels:    map[string]string{constants.WaypointLabel: "workload"},
+			},
+		}
+		cl := newFakeClient(newNamespace(nil), sa)
+
+		ns := reconcileNamespace(g, cl)
+
+		gateway := getGateway(g, cl, "reviews-waypoint")
+		g.Expect(gateway.GetLabels()).To(HaveKeyWithValue(constants.IstioWaypointForLabel, "workload"))
+		g.Expect(gateway.GetLabels()).NotTo(HaveKey(constants.IstioRevLabel))
+		g.Expect(metav1.IsControlledBy(gateway, sa)).To(BeTrue())
+		g.Expect(ns.Annotations).NotTo(HaveKey(constants.WaypointStatusAnnotationKey))
+
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(sa), sa)).To(Succeed())
+		g.Expect(sa.Annotations).To(HaveKey(constants.WaypointStatusAnnotationKey))
+	})
+
+	t.Run("reports ready when gateway is programmed", func(t *testing.T) {
+		g := NewWithT(t)
+		cl := newFakeClient(newNamespace(map[string]string{constants.WaypointLabel: "all"}))
+		reconcileNamespace(g, cl)
+
+		gateway := getGateway(g, cl, namespaceWaypointName)
+		g.Expect(unstructured.SetNestedSlice(gateway.Object, []any{
+			map[string]any{"type": "Programmed", "status": "True"},
+		}, "status", "conditions")).To(Succeed())
+		g.Expect(cl.Update(ctx, gateway)).To(Succeed())
+
+		ns := reconcileNamespace(g, cl)
+		g.Expect(ns.Annotations).To(HaveKeyWithValue(constants.WaypointStatusAnnotationKey, waypointStatusReady))
+	})
+
+	t.Run("updates waypoint when namespace revision changes", func(t *testing.T) {
+		g := NewWithT(t)
+		cl := newFakeClient(newNamespace(map[string]string{constants.WaypointLabel: "service", constants.IstioRevLabel: "old"}))
+		ns := reconcileNamespace(g, cl)
+
+		ns.Labels[constants.IstioRevLabel] = "new"
+		ns.Labels[constants.WaypointLabel] = "all"
+		g.Expect(cl.Update(ctx, ns)).To(Succeed())
+		reconcileNamespace(g, cl)
+
+		gateway := getGateway(g, cl, namespaceWaypointName)
+		g.Expect(gateway.GetLabels()).To(HaveKeyWithValue(constants.IstioRevLabel, "new"))
+		g.Expect(gateway.GetLabels()).To(HaveKeyWithValue(constants.IstioWaypointForLabel, "all"))
+	})
+
+	t.Run("deletes waypoint when label is removed", func(t *testing.T) {
+		g := NewWithT(t)
+		cl := newFakeClient(newNamespace(map[string]string{constants.WaypointLabel: "service"}))
+		ns := reconcileNamespace(g, cl)
+
+		delete(ns.Labels, constants.WaypointLabel)
+		g.Expect(cl.Update(ctx, ns)).To(Succeed())
+		ns = reconcileNamespace(g, cl)
+
+		err := cl.Get(ctx, types.NamespacedName{Namespace: nsName, Name: namespaceWaypointName}, newGateway())
+		g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		g.Expect(ns.Annotations).NotTo(HaveKey(constants.WaypointStatusAnnotationKey))
+	})
+
+	t.Run("does not touch gateways it doesn't manage", func(t *testing.T) {
+		g := NewWithT(t)
+		gateway := newGateway()
+		gateway.SetName(namespaceWaypointName)
+		gateway.SetNamespace(nsName)
+		gateway.SetLabels(map[string]string{constants.IstioWaypointForLabel: "workload"})
+		cl := newFakeClient(newNamespace(map[string]string{constants.WaypointLabel: "service"}), gateway)
+
+		ns := reconcileNamespace(g, cl)
+		g.Expect(ns.Annotations).To(HaveKeyWithValue(constants.WaypointStatusAnnotationKey,
+			`NotReady: Gateway "waypoint" already exists and is not managed by the operator`))
+		g.Expect(getGateway(g, cl, namespaceWaypointName).GetLabels()).To(HaveKeyWithValue(constants.IstioWaypointForLabel, "workload"))
+
+		delete(ns.Labels, constants.WaypointLabel)
+		g.Expect(cl.Update(ctx, ns)).To(Succeed())
+		reconcileNamespace(g, cl)
+		getGateway(g, cl, namespaceWaypointName)
+	})
+
+	t.Run("rejects invalid traffic type", func(t *testing.T) {
+		g := NewWithT(t)
+		cl := newFakeClient(newNamespace(map[string]string{constants.WaypointLabel: "everything"}))
+
+		ns := reconcileNamespace(g, cl)
+		g.Expect(ns.Annotations[constants.WaypointStatusAnnotationKey]).To(ContainSubstring(`invalid value "everything"`))
+		err := cl.Get(ctx, types.NamespacedName{Namespace: nsName, Name: namespaceWaypointName}, newGateway())
+		g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+}
+
+func TestGetGatewayStatus(t *testing.T) {
+	testCases := []struct {
+		name       string
+		conditions []any
+		expected   string
+	}{
+		{
+			name:     "no conditions",
+			expected: "NotReady: waiting for the Gateway to be programmed",
+		},
+		{
+			name:       "programmed",
+			conditions: []any{map[string]any{"type": "Accepted", "status": "True"}, map[string]any{"type": "Programmed", "status": "True"}},
+			expected:   "Ready",
+		},
+		{
+			name:       "not programmed",
+			conditions: []any{map[string]any{"type": "Programmed", "status": "False", "message": "deployment not ready"}},
+			expected:   "NotReady: deployment not ready",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			gateway := newGateway()
+			if tc.conditions != nil {
+				g.Expect(unstructured.SetNestedSlice(gateway.Object, tc.conditions, "status", "conditions")).To(Succeed())
+			}
+			g.Expect(getGatewayStatus(gateway)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestNamespacePredicate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		old      *corev1.Namespace
+		new      *corev1.Namespace
+		expected bool
+	}{
+		{
+			name:     "unrelated namespace",
+			old:      newNamespace(map[string]string{"foo": "bar"}),
+			new:      newNamespace(map[string]string{"foo": "baz"}),
+			expected: false,
+		},
+		{
+			name:     "waypoint label added",
+			old:      newNamespace(nil),
+			new:      newNamespace(map[string]string{constants.WaypointLabel: "service"}),
+			expected: true,
+		},
+		{
+			name: "waypoint label removed, status annotation still present",
+			old:  newNamespace(map[string]string{constants.WaypointLabel: "service"}),
+			new: func() *corev1.Namespace {
+				ns := newNamespace(nil)
+				ns.Annotations = map[string]string{constants.WaypointStatusAnnotationKey: waypointStatusReady}
+				return ns
+			}(),
+			expected: true,
+		},
+		{
+			name:     "revision label changed",
+			old:      newNamespace(map[string]string{constants.IstioRevLabel: "default"}),
+			new:      newNamespace(map[string]string{constants.IstioRevLabel: "canary"}),
+			expected: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			p := namespacePredicate()
+			g.Expect(p.Update(event.UpdateEvent{ObjectOld: tc.old, ObjectNew: tc.new})).To(Equal(tc.expected))
+		})
+	}
+
+	t.Run("create", func(t *testing.T) {
+		g := NewWithT(t)
+		p := namespacePredicate()
+		g.Expect(p.Create(event.CreateEvent{Object: newNamespace(nil)})).To(BeFalse())
+		g.Expect(p.Create(event.CreateEvent{Object: newNamespace(map[string]string{constants.WaypointLabel: ""})})).To(BeTrue())
+	})
+}
+
+func TestGatewayAPIInstalled(t *testing.T) {
+	g := NewWithT(t)
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Namespace"), meta.RESTScopeRoot)
+	installed, err := gatewayAPIInstalled(mapper)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(installed).To(BeFalse())
+
+	mapper.Add(gatewayGVK, meta.RESTScopeNamespace)
+	installed, err = gatewayAPIInstalled(mapper)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(installed).To(BeTrue())
+}
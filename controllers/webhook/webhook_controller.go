@@ -22,7 +22,12 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -32,6 +37,8 @@ import (
 	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
 	"github.com/istio-ecosystem/sail-operator/pkg/revision"
 	admissionv1 "k8s.io/api/admissionregistration/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
@@ -47,6 +54,9 @@ import (
 const (
 	defaultPeriodSeconds  = 3 // matches the period in the istiod chart
 	defaultTimeoutSeconds = 5 // matches the timeout in the istiod chart
+
+	// maxProbeBackoff is the maximum delay between probes when the remote istiod keeps failing them
+	maxProbeBackoff = time.Minute
 )
 
 // overrides the default dial context; only used in unit tests
@@ -56,65 +66,257 @@ var customDialContext func(ctx context.Context, network, addr string) (net.Conn,
 type Reconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
-	probe  func(context.Context, *admissionv1.MutatingWebhookConfiguration) (bool, error)
+	probe  func(context.Context, *admissionv1.MutatingWebhookConfiguration, admissionv1.WebhookClientConfig) (bool, error)
+
+	// failures holds the number of consecutive failed probes of each MutatingWebhookConfiguration
+	failures     map[string]int
+	failuresLock sync.Mutex
 }
 
 func NewReconciler(client client.Client, scheme *runtime.Scheme) *Reconciler {
 	return &Reconciler{
-		Client: client,
-		Scheme: scheme,
-		probe:  doProbe,
+		Client:   client,
+		Scheme:   scheme,
+		probe:    doProbe,
+		failures: map[string]int{},
 	}
 }
 
 // +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=mutatingwebhookconfigurations,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=sailoperator.io,resources=istiorevisions/status,verbs=get;update;patch
 
-// Reconcile is part of the main kubernetes reconciliation loop which aims to
-// move the current state of the cluster closer to the desired state.
-//
-// For more details, check Reconcile and its Result here:
-// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.14.1/pkg/reconcile
+// Reconcile probes the remote istiod through each webhook in the MutatingWebhookConfiguration and
+// stores the results in the status of the IstioRevision that owns the configuration. A revision can own
+// several configurations (e.g. the sidecar injector and a revision tag), so the results are stored under
+// the configuration's name. While the probes fail, the delay between them grows exponentially. This
+// controller is the only writer of the revision's status.remoteIstiod field; it clears the field when
+// the revision no longer uses a remote control plane.
 func (r *Reconciler) Reconcile(ctx context.Context, webhook *admissionv1.MutatingWebhookConfiguration) (ctrl.Result, error) {
 	log := logf.FromContext(ctx)
 
-	isReady, err := r.probe(ctx, webhook)
-	reason := ""
+	rev, err := r.getOwnerRevision(ctx, webhook)
 	if err != nil {
-		log.V(3).Error(err, "Probe failed")
-		reason = err.Error()
+		return ctrl.Result{}, err
+	} else if rev == nil {
+		log.V(2).Info("MutatingWebhookConfiguration is not owned by an IstioRevision. Skipping probe")
+		r.forgetFailures(webhook.Name)
+		return ctrl.Result{}, nil
+	} else if !revision.IsUsingRemoteControlPlane(rev) {
+		log.V(2).Info("IstioRevision doesn't use a remote control plane. Skipping probe")
+		r.forgetFailures(webhook.Name)
+		return ctrl.Result{}, r.patchRemoteIstiodStatus(ctx, rev, nil)
 	}
 
-	if webhook.Annotations == nil {
-		webhook.Annotations = make(map[string]string)
+	results := r.probeWebhooks(ctx, webhook, rev.Status.RemoteIstiod.GetWebhookConfiguration(webhook.Name))
+	if err := r.patchRemoteIstiodStatus(ctx, rev, withWebhookConfiguration(rev.Status.RemoteIstiod, results)); err != nil {
+		return ctrl.Result{}, err
 	}
-	webhook.Annotations[constants.WebhookReadinessProbeStatusAnnotationKey] = strconv.FormatBool(isReady)
-	webhook.Annotations[constants.WebhookReadinessProbeStatusReasonAnnotationKey] = reason
+	return ctrl.Result{RequeueAfter: r.nextProbeDelay(webhook, results.IsReady())}, nil
+}
 
-	err = r.Client.Update(ctx, webhook)
-	if err != nil {
-		return ctrl.Result{}, err
+// reconcileRequest invokes the StandardReconciler, which skips MutatingWebhookConfigurations that no longer exist.
+// Before it does, it discards what the controller knows about deleted configurations.
+func (r *Reconciler) reconcileRequest(standardReconciler reconcile.Reconciler) reconcile.Func {
+	return func(ctx context.Context, req reconcile.Request) (ctrl.Result, error) {
+		err := r.Client.Get(ctx, req.NamespacedName, &admissionv1.MutatingWebhookConfiguration{})
+		if apierrors.IsNotFound(err) {
+			r.forgetFailures(req.Name)
+			if err := r.clearDeletedWebhookResults(ctx, req.Name); err != nil {
+				return ctrl.Result{}, err
+			}
+		} else if err != nil {
+			return ctrl.Result{}, err
+		}
+		return standardReconciler.Reconcile(ctx, req)
+	}
+}
+
+// clearDeletedWebhookResults removes the probe results of a deleted MutatingWebhookConfiguration from the status of
+// the IstioRevisions.
+func (r *Reconciler) clearDeletedWebhookResults(ctx context.Context, webhookName string) error {
+	revs := v1.IstioRevisionList{}
+	if err := r.Client.List(ctx, &revs); err != nil {
+		return fmt.Errorf("failed to list IstioRevisions: %w", err)
+	}
+	for i := range revs.Items {
+		rev := &revs.Items[i]
+		if rev.Status.RemoteIstiod.GetWebhookConfiguration(webhookName) != nil {
+			if err := r.patchRemoteIstiodStatus(ctx, rev, withoutWebhookConfiguration(rev.Status.RemoteIstiod, webhookName)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// patchRemoteIstiodStatus replaces the revision's status.remoteIstiod. Since each configuration is reconciled
+// separately, the patch is rejected if the revision has changed since it was read, so that the results of
+// another configuration aren't lost; the reconcile is then retried with the current revision.
+func (r *Reconciler) patchRemoteIstiodStatus(ctx context.Context, rev *v1.IstioRevision, status *v1.RemoteIstiodStatus) error {
+	if reflect.DeepEqual(rev.Status.RemoteIstiod, status) {
+		return nil
+	}
+	patch := client.MergeFromWithOptions(rev.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	rev.Status.RemoteIstiod = status
+	if err := r.Client.Status().Patch(ctx, rev, patch); err != nil {
+		return fmt.Errorf("failed to patch status of IstioRevision %s: %w", rev.Name, err)
+	}
+	return nil
+}
+
+// withWebhookConfiguration returns a copy of the status in which the results of the given configuration
+// replace its previous results. Configurations are kept sorted by name.
+func withWebhookConfiguration(status *v1.RemoteIstiodStatus, results v1.WebhookConfigurationProbeStatus) *v1.RemoteIstiodStatus {
+	newStatus := withoutWebhookConfiguration(status, results.Name)
+	if newStatus == nil {
+		newStatus = &v1.RemoteIstiodStatus{}
+	}
+	newStatus.WebhookConfigurations = append(newStatus.WebhookConfigurations, results)
+	slices.SortFunc(newStatus.WebhookConfigurations, func(a, b v1.WebhookConfigurationProbeStatus) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return newStatus
+}
+
+// withoutWebhookConfiguration returns a copy of the status without the results of the given configuration,
+// or nil if no other configuration has been probed.
+func withoutWebhookConfiguration(status *v1.RemoteIstiodStatus, webhookName string) *v1.RemoteIstiodStatus {
+	var configurations []v1.WebhookConfigurationProbeStatus
+	if status != nil {
+		for _, configuration := range status.WebhookConfigurations {
+			if configuration.Name != webhookName {
+				configurations = append(configurations, *configuration.DeepCopy())
+			}
+		}
+	}
+	if len(configurations) == 0 {
+		return nil
 	}
-	return ctrl.Result{RequeueAfter: getPeriod(webhook)}, nil
+	return &v1.RemoteIstiodStatus{WebhookConfigurations: configurations}
 }
 
-func doProbe(ctx context.Context, webhook *admissionv1.MutatingWebhookConfiguration) (bool, error) {
+// probeWebhooks probes the remote istiod through each webhook in the configuration. Webhooks that point
+// to the same readiness endpoint are only probed once.
+func (r *Reconciler) probeWebhooks(
+	ctx context.Context, webhook *admissionv1.MutatingWebhookConfiguration, prevResults *v1.WebhookConfigurationProbeStatus,
+) v1.WebhookConfigurationProbeStatus {
 	log := logf.FromContext(ctx)
-	if len(webhook.Webhooks) == 0 {
-		return false, errors.New("mutatingwebhookconfiguration contains no webhooks")
+
+	type probeResult struct {
+		ready   bool
+		message string
 	}
-	clientConfig := webhook.Webhooks[0].ClientConfig
-	if clientConfig.Service == nil {
-		return false, errors.New("missing webhooks[].clientConfig.service")
+	probedURLs := map[string]probeResult{}
+
+	now := metav1.NewTime(time.Now().Truncate(time.Second))
+	results := v1.WebhookConfigurationProbeStatus{Name: webhook.Name}
+	for _, wh := range webhook.Webhooks {
+		result := v1.WebhookProbeResult{Name: wh.Name}
+		probeURL, err := getReadinessProbeURL(wh.ClientConfig)
+		if err != nil {
+			result.Message = err.Error()
+		} else {
+			result.URL = probeURL
+			probed, found := probedURLs[probeURL]
+			if !found {
+				ready, err := r.probe(ctx, webhook, wh.ClientConfig)
+				switch {
+				case err != nil:
+					log.V(3).Error(err, "Probe failed", "webhook", wh.Name)
+					probed.message = err.Error()
+				case !ready:
+					probed.message = "readiness probe returned an unsuccessful response"
+				default:
+					probed.ready = true
+				}
+				probedURLs[probeURL] = probed
+			}
+			result.Ready = probed.ready
+			result.Message = probed.message
+		}
+
+		result.LastTransitionTime = now
+		if prevResult := findWebhookProbeResult(prevResults, wh.Name); prevResult != nil && prevResult.Ready == result.Ready {
+			result.LastTransitionTime = prevResult.LastTransitionTime
+		}
+		results.Webhooks = append(results.Webhooks, result)
 	}
+	return results
+}
 
-	if len(clientConfig.CABundle) == 0 {
-		return false, errors.New("webhooks[].clientConfig.caBundle hasn't been set; check if the remote istiod can access this cluster")
+func findWebhookProbeResult(results *v1.WebhookConfigurationProbeStatus, name string) *v1.WebhookProbeResult {
+	if results == nil {
+		return nil
+	}
+	for i := range results.Webhooks {
+		if results.Webhooks[i].Name == name {
+			return &results.Webhooks[i]
+		}
+	}
+	return nil
+}
+
+// nextProbeDelay returns the delay before the next probe. The delay is the probe period while the remote
+// istiod is ready; when it isn't, the delay doubles with each consecutive failure, up to maxProbeBackoff.
+func (r *Reconciler) nextProbeDelay(webhook *admissionv1.MutatingWebhookConfiguration, ready bool) time.Duration {
+	r.failuresLock.Lock()
+	defer r.failuresLock.Unlock()
+
+	period := getPeriod(webhook)
+	if ready {
+		delete(r.failures, webhook.Name)
+		return period
+	}
+
+	r.failures[webhook.Name]++
+	delay := period
+	for i := 1; i < r.failures[webhook.Name] && delay < maxProbeBackoff; i++ {
+		delay *= 2
+	}
+	return max(min(delay, maxProbeBackoff), period)
+}
+
+func (r *Reconciler) forgetFailures(webhookName string) {
+	r.failuresLock.Lock()
+	defer r.failuresLock.Unlock()
+	delete(r.failures, webhookName)
+}
+
+func (r *Reconciler) getOwnerRevision(ctx context.Context, webhook *admissionv1.MutatingWebhookConfiguration) (*v1.IstioRevision, error) {
+	for _, ownerRef := range webhook.OwnerReferences {
+		if ownerRef.APIVersion == v1.GroupVersion.String() && ownerRef.Kind == v1.IstioRevisionKind {
+			rev := &v1.IstioRevision{}
+			if err := r.Client.Get(ctx, client.ObjectKey{Name: ownerRef.Name}, rev); err != nil {
+				if apierrors.IsNotFound(err) {
+					return nil, nil
+				}
+				return nil, fmt.Errorf("failed to get IstioRevision %s: %w", ownerRef.Name, err)
+			}
+			return rev, nil
+		}
 	}
-	caCertPool := x509.NewCertPool()
-	if ok := caCertPool.AppendCertsFromPEM(clientConfig.CABundle); !ok {
-		return false, errors.New("failed to append CA bundle to cert pool")
+	return nil, nil
+}
+
+func doProbe(ctx context.Context, webhook *admissionv1.MutatingWebhookConfiguration, clientConfig admissionv1.WebhookClientConfig) (bool, error) {
+	log := logf.FromContext(ctx)
+
+	probeURL, err := getReadinessProbeURL(clientConfig)
+	if err != nil {
+		return false, err
 	}
 
+	var caCertPool *x509.CertPool
+	if len(clientConfig.CABundle) > 0 {
+		caCertPool = x509.NewCertPool()
+		if ok := caCertPool.AppendCertsFromPEM(clientConfig.CABundle); !ok {
+			return false, errors.New("failed to append CA bundle to cert pool")
+		}
+	} else if clientConfig.Service != nil {
+		return false, errors.New("webhooks[].clientConfig.caBundle hasn't been set; check if the remote istiod can access this cluster")
+	}
+	// when a URL webhook has no CA bundle, the remote istiod's certificate is verified using the system trust store
+
 	httpClient := http.Client{
 		Timeout: getTimeout(webhook),
 		Transport: &http.Transport{
@@ -126,12 +328,7 @@ func doProbe(ctx context.Context, webhook *admissionv1.MutatingWebhookConfigurat
 		},
 	}
 
-	url, err := getReadinessProbeURL(clientConfig)
-	if err != nil {
-		return false, err
-	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, probeURL, nil)
 	if err != nil {
 		return false, err
 	}
@@ -142,6 +339,7 @@ func doProbe(ctx context.Context, webhook *admissionv1.MutatingWebhookConfigurat
 		log.V(3).Info("Probe failed", "error", err)
 		return false, err
 	}
+	defer resp.Body.Close()
 	log.V(3).Info("Probe response", "response", resp.StatusCode)
 
 	return resp.StatusCode == http.StatusOK, nil
@@ -150,7 +348,16 @@ func doProbe(ctx context.Context, webhook *admissionv1.MutatingWebhookConfigurat
 func getReadinessProbeURL(config admissionv1.WebhookClientConfig) (string, error) {
 	switch {
 	case config.URL != nil:
-		return "", errors.New("only webhooks pointing to a Service are supported")
+		// the webhook URL points to the injection endpoint of the remote istiod (e.g. https://istiod.example.com:15017/inject),
+		// whereas the readiness endpoint is served on the same host and port
+		webhookURL, err := url.Parse(*config.URL)
+		if err != nil {
+			return "", fmt.Errorf("invalid webhooks[].clientConfig.url: %w", err)
+		}
+		if webhookURL.Scheme != "https" || webhookURL.Host == "" {
+			return "", fmt.Errorf("invalid webhooks[].clientConfig.url %q: must be an absolute https URL", *config.URL)
+		}
+		return (&url.URL{Scheme: webhookURL.Scheme, Host: webhookURL.Host, Path: "/ready"}).String(), nil
 
 	case config.Service != nil:
 		svc := config.Service
@@ -172,6 +379,14 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	// objectHandler handles the MutatingWebhookConfiguration watch events
 	objectHandler := wrapEventHandler(logger, &handler.EnqueueRequestForObject{})
 
+	// revisionHandler enqueues the MutatingWebhookConfigurations owned by an IstioRevision whenever the
+	// revision's mode changes, because the mode reported in the revision's status determines whether the
+	// webhook is probed, and the mode is only known after the webhook has been created. When the revision
+	// no longer uses a remote control plane, the configurations whose probe results are in its status are
+	// enqueued too, so that the results are cleared. Other changes are ignored, so that storing the probe
+	// results in the revision's status doesn't trigger another probe.
+	revisionHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapRevisionToReconcileRequests))
+
 	return ctrl.NewControllerManagedBy(mgr).
 		WithOptions(controller.Options{
 			LogConstructor: func(req *reconcile.Request) logr.Logger {
@@ -187,7 +402,40 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 		// +lint-watches:ignore: IstioRevision (not found in charts, but this is the main resource watched by this controller)
 		Watches(&admissionv1.MutatingWebhookConfiguration{}, objectHandler, builder.WithPredicates(ownedByRemoteIstioRevisionPredicate(mgr.GetClient()))).
 		Named("mutatingwebhookconfiguration").
-		Complete(reconciler.NewStandardReconciler[*admissionv1.MutatingWebhookConfiguration](r.Client, r.Reconcile))
+
+		// +lint-watches:ignore: IstioRevision (not found in charts, but must be watched so that webhooks are probed once the revision's mode is known)
+		Watches(&v1.IstioRevision{}, revisionHandler, builder.WithPredicates(modeChangedPredicate())).
+		Complete(r.reconcileRequest(reconciler.NewStandardReconciler[*admissionv1.MutatingWebhookConfiguration](r.Client, r.Reconcile)))
+}
+
+func (r *Reconciler) mapRevisionToReconcileRequests(ctx context.Context, obj client.Object) []reconcile.Request {
+	rev, ok := obj.(*v1.IstioRevision)
+	if !ok {
+		return nil
+	}
+
+	var requests []reconcile.Request
+	if rev.Status.RemoteIstiod != nil {
+		for _, configuration := range rev.Status.RemoteIstiod.WebhookConfigurations {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKey{Name: configuration.Name}})
+		}
+	}
+
+	webhooks := admissionv1.MutatingWebhookConfigurationList{}
+	if err := r.Client.List(ctx, &webhooks); err != nil {
+		logf.FromContext(ctx).Error(err, "failed to list MutatingWebhookConfigurations")
+		return requests
+	}
+
+	for _, webhook := range webhooks.Items {
+		for _, ownerRef := range webhook.OwnerReferences {
+			if ownerRef.APIVersion == v1.GroupVersion.String() && ownerRef.Kind == v1.IstioRevisionKind && ownerRef.Name == rev.Name {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&webhook)})
+				break
+			}
+		}
+	}
+	return requests
 }
 
 func ownedByRemoteIstioRevisionPredicate(cl client.Client) predicate.Predicate {
@@ -199,7 +447,8 @@ func ownedByRemoteIstioRevisionPredicate(cl client.Client) predicate.Predicate {
 			return IsOwnedByRevisionWithRemoteControlPlane(cl, e.ObjectNew)
 		},
 		DeleteFunc: func(e event.DeleteEvent) bool {
-			return IsOwnedByRevisionWithRemoteControlPlane(cl, e.Object)
+			// the owner may have been deleted already, so the revision's mode isn't checked
+			return isOwnedByRevision(e.Object)
 		},
 		GenericFunc: func(e event.GenericEvent) bool {
 			return IsOwnedByRevisionWithRemoteControlPlane(cl, e.Object)
@@ -207,6 +456,25 @@ func ownedByRemoteIstioRevisionPredicate(cl client.Client) predicate.Predicate {
 	}
 }
 
+func modeChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldRev, oldOk := e.ObjectOld.(*v1.IstioRevision)
+			newRev, newOk := e.ObjectNew.(*v1.IstioRevision)
+			return oldOk && newOk && oldRev.Status.Mode != newRev.Status.Mode
+		},
+	}
+}
+
+func isOwnedByRevision(obj client.Object) bool {
+	for _, ownerRef := range obj.GetOwnerReferences() {
+		if ownerRef.APIVersion == v1.GroupVersion.String() && ownerRef.Kind == v1.IstioRevisionKind {
+			return true
+		}
+	}
+	return false
+}
+
 func IsOwnedByRevisionWithRemoteControlPlane(cl client.Client, obj client.Object) bool {
 	for _, ownerRef := range obj.GetOwnerReferences() {
 		if ownerRef.APIVersion == v1.GroupVersion.String() && ownerRef.Kind == v1.IstioRevisionKind {
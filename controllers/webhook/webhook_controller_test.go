@@ -24,7 +24,6 @@ import (
 	"crypto/x509/pkix"
 	"encoding/pem"
 	"errors"
-	"fmt"
 	"math/big"
 	"net"
 	"net/http"
@@ -35,6 +34,7 @@ import (
 	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
 	"github.com/istio-ecosystem/sail-operator/pkg/constants"
 	"github.com/istio-ecosystem/sail-operator/pkg/kube"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
 	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
 	. "github.com/onsi/gomega"
 	admissionv1 "k8s.io/api/admissionregistration/v1"
@@ -43,6 +43,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"istio.io/istio/pkg/ptr"
 )
@@ -50,52 +51,121 @@ import (
 var ctx = context.Background()
 
 func TestReconcile(t *testing.T) {
+	const (
+		namespaceWebhook = "rev.namespace.sidecar-injector.istio.io"
+		objectWebhook    = "rev.object.sidecar-injector.istio.io"
+	)
+	probeErr := errors.New("some error")
+
 	tests := []struct {
 		name         string
 		setup        func(configuration *admissionv1.MutatingWebhookConfiguration)
-		probeFunc    func(context.Context, *admissionv1.MutatingWebhookConfiguration) (bool, error)
+		probeFunc    func(context.Context, *admissionv1.MutatingWebhookConfiguration, admissionv1.WebhookClientConfig) (bool, error)
 		interceptors interceptor.Funcs
 		expectResult ctrl.Result
-		expectErr    error
-		expectValue  string
+		expectErr    bool
+		expectStatus *v1.WebhookConfigurationProbeStatus
 	}{
 		{
 			name: "ready",
-			probeFunc: func(context.Context, *admissionv1.MutatingWebhookConfiguration) (bool, error) {
+			probeFunc: func(context.Context, *admissionv1.MutatingWebhookConfiguration, admissionv1.WebhookClientConfig) (bool, error) {
 				return true, nil
 			},
 			expectResult: ctrl.Result{RequeueAfter: defaultPeriodSeconds * time.Second},
-			expectValue:  "true",
+			expectStatus: &v1.WebhookConfigurationProbeStatus{
+				Name: "istio-sidecar-injector",
+				Webhooks: []v1.WebhookProbeResult{
+					{Name: namespaceWebhook, URL: "https://istiod.istio-system.svc:443/ready", Ready: true},
+					{Name: objectWebhook, URL: "https://istiod.istio-system.svc:443/ready", Ready: true},
+				},
+			},
 		},
 		{
 			name: "not ready",
-			probeFunc: func(context.Context, *admissionv1.MutatingWebhookConfiguration) (bool, error) {
+			probeFunc: func(context.Context, *admissionv1.MutatingWebhookConfiguration, admissionv1.WebhookClientConfig) (bool, error) {
 				return false, nil
 			},
 			expectResult: ctrl.Result{RequeueAfter: defaultPeriodSeconds * time.Second},
-			expectValue:  "false",
+			expectStatus: &v1.WebhookConfigurationProbeStatus{
+				Name: "istio-sidecar-injector",
+				Webhooks: []v1.WebhookProbeResult{
+					{
+						Name:    namespaceWebhook,
+						URL:     "https://istiod.istio-system.svc:443/ready",
+						Message: "readiness probe returned an unsuccessful response",
+					},
+					{
+						Name:    objectWebhook,
+						URL:     "https://istiod.istio-system.svc:443/ready",
+						Message: "readiness probe returned an unsuccessful response",
+					},
+				},
+			},
 		},
 		{
 			name: "probe error",
-			probeFunc: func(context.Context, *admissionv1.MutatingWebhookConfiguration) (bool, error) {
-				return false, fmt.Errorf("some error")
+			probeFunc: func(context.Context, *admissionv1.MutatingWebhookConfiguration, admissionv1.WebhookClientConfig) (bool, error) {
+				return false, probeErr
 			},
 			expectResult: ctrl.Result{RequeueAfter: defaultPeriodSeconds * time.Second},
-			expectValue:  "false",
+			expectStatus: &v1.WebhookConfigurationProbeStatus{
+				Name: "istio-sidecar-injector",
+				Webhooks: []v1.WebhookProbeResult{
+					{Name: namespaceWebhook, URL: "https://istiod.istio-system.svc:443/ready", Message: "some error"},
+					{Name: objectWebhook, URL: "https://istiod.istio-system.svc:443/ready", Message: "some error"},
+				},
+			},
+		},
+		{
+			name: "one of the webhooks fails",
+			setup: func(webhook *admissionv1.MutatingWebhookConfiguration) {
+				webhook.Webhooks[1].ClientConfig.Service.Port = ptr.Of(int32(15017))
+			},
+			probeFunc: func(_ context.Context, _ *admissionv1.MutatingWebhookConfiguration, clientConfig admissionv1.WebhookClientConfig) (bool, error) {
+				return clientConfig.Service.Port == nil, nil
+			},
+			expectResult: ctrl.Result{RequeueAfter: defaultPeriodSeconds * time.Second},
+			expectStatus: &v1.WebhookConfigurationProbeStatus{
+				Name: "istio-sidecar-injector",
+				Webhooks: []v1.WebhookProbeResult{
+					{Name: namespaceWebhook, URL: "https://istiod.istio-system.svc:443/ready", Ready: true},
+					{
+						Name:    objectWebhook,
+						URL:     "https://istiod.istio-system.svc:15017/ready",
+						Message: "readiness probe returned an unsuccessful response",
+					},
+				},
+			},
 		},
 		{
-			name: "update error",
-			probeFunc: func(context.Context, *admissionv1.MutatingWebhookConfiguration) (bool, error) {
+			name: "invalid client config",
+			setup: func(webhook *admissionv1.MutatingWebhookConfiguration) {
+				webhook.Webhooks[1].ClientConfig = admissionv1.WebhookClientConfig{}
+			},
+			probeFunc: func(context.Context, *admissionv1.MutatingWebhookConfiguration, admissionv1.WebhookClientConfig) (bool, error) {
+				return true, nil
+			},
+			expectResult: ctrl.Result{RequeueAfter: defaultPeriodSeconds * time.Second},
+			expectStatus: &v1.WebhookConfigurationProbeStatus{
+				Name: "istio-sidecar-injector",
+				Webhooks: []v1.WebhookProbeResult{
+					{Name: namespaceWebhook, URL: "https://istiod.istio-system.svc:443/ready", Ready: true},
+					{Name: objectWebhook, Message: "no URL or Service specified in WebhookClientConfig"},
+				},
+			},
+		},
+		{
+			name: "status patch error",
+			probeFunc: func(context.Context, *admissionv1.MutatingWebhookConfiguration, admissionv1.WebhookClientConfig) (bool, error) {
 				return true, nil
 			},
 			interceptors: interceptor.Funcs{
-				Update: func(ctx context.Context, cl client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				SubResourcePatch: func(_ context.Context, _ client.Client, _ string, _ client.Object, _ client.Patch, _ ...client.SubResourcePatchOption) error {
 					return errors.New("some error")
 				},
 			},
 			expectResult: ctrl.Result{},
-			expectErr:    errors.New("some error"),
-			expectValue:  "",
+			expectErr:    true,
 		},
 		{
 			name: "honors period annotation",
@@ -104,48 +174,320 @@ func TestReconcile(t *testing.T) {
 					constants.WebhookReadinessProbePeriodSecondsAnnotationKey: "123",
 				}
 			},
-			probeFunc: func(context.Context, *admissionv1.MutatingWebhookConfiguration) (bool, error) {
+			probeFunc: func(context.Context, *admissionv1.MutatingWebhookConfiguration, admissionv1.WebhookClientConfig) (bool, error) {
 				return true, nil
 			},
 			expectResult: ctrl.Result{RequeueAfter: 123 * time.Second},
-			expectValue:  "true",
+			expectStatus: &v1.WebhookConfigurationProbeStatus{
+				Name: "istio-sidecar-injector",
+				Webhooks: []v1.WebhookProbeResult{
+					{Name: namespaceWebhook, URL: "https://istiod.istio-system.svc:443/ready", Ready: true},
+					{Name: objectWebhook, URL: "https://istiod.istio-system.svc:443/ready", Ready: true},
+				},
+			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			g := NewWithT(t)
 
-			webhook := &admissionv1.MutatingWebhookConfiguration{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: "istio-sidecar-injector",
-				},
-			}
+			rev := newRemoteRevision()
+			webhook := newWebhookConfiguration(rev, namespaceWebhook, objectWebhook)
 			if tt.setup != nil {
 				tt.setup(webhook)
 			}
 
 			cl := newFakeClientBuilder().
-				WithObjects(webhook).
+				WithObjects(rev, webhook).
+				WithStatusSubresource(&v1.IstioRevision{}).
 				WithInterceptorFuncs(tt.interceptors).
 				Build()
 			r := NewReconciler(cl, scheme.Scheme)
-			r.probe = tt.probeFunc
+
+			probedURLs := map[string]int{}
+			r.probe = func(ctx context.Context, webhook *admissionv1.MutatingWebhookConfiguration, clientConfig admissionv1.WebhookClientConfig) (bool, error) {
+				probeURL, err := getReadinessProbeURL(clientConfig)
+				g.Expect(err).ToNot(HaveOccurred())
+				probedURLs[probeURL]++
+				return tt.probeFunc(ctx, webhook, clientConfig)
+			}
 
 			result, err := r.Reconcile(ctx, webhook)
 
 			g.Expect(result).To(Equal(tt.expectResult))
-			if tt.expectErr != nil {
-				g.Expect(err).To(Equal(tt.expectErr))
-			} else {
-				g.Expect(err).ToNot(HaveOccurred())
+			if tt.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+
+			for probeURL, count := range probedURLs {
+				g.Expect(count).To(Equal(1), "readiness endpoint %s was probed more than once", probeURL)
 			}
 
-			g.Expect(cl.Get(ctx, kube.Key("istio-sidecar-injector"), webhook)).To(Succeed())
-			g.Expect(webhook.Annotations[constants.WebhookReadinessProbeStatusAnnotationKey]).To(Equal(tt.expectValue), "Unexpected annotation value")
+			g.Expect(cl.Get(ctx, kube.Key(rev.Name), rev)).To(Succeed())
+			g.Expect(rev.Status.RemoteIstiod).ToNot(BeNil())
+			g.Expect(rev.Status.RemoteIstiod.WebhookConfigurations).To(HaveLen(1))
+			results := &rev.Status.RemoteIstiod.WebhookConfigurations[0]
+			for i := range results.Webhooks {
+				g.Expect(results.Webhooks[i].LastTransitionTime.IsZero()).To(BeFalse())
+				results.Webhooks[i].LastTransitionTime = metav1.Time{}
+			}
+			g.Expect(results).To(Equal(tt.expectStatus))
 		})
 	}
 }
 
+func TestReconcileWithoutOwner(t *testing.T) {
+	g := NewWithT(t)
+
+	webhook := &admissionv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "istio-sidecar-injector",
+		},
+	}
+	cl := newFakeClientBuilder().WithObjects(webhook).Build()
+	r := NewReconciler(cl, scheme.Scheme)
+	r.probe = func(context.Context, *admissionv1.MutatingWebhookConfiguration, admissionv1.WebhookClientConfig) (bool, error) {
+		g.Fail("probe should not be called")
+		return false, nil
+	}
+
+	result, err := r.Reconcile(ctx, webhook)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result).To(Equal(ctrl.Result{}))
+}
+
+func TestReconcileRevisionWithoutRemoteControlPlane(t *testing.T) {
+	g := NewWithT(t)
+
+	rev := newRemoteRevision()
+	rev.Status.Mode = v1.IstioRevisionModeLocal
+	rev.Status.RemoteIstiod = &v1.RemoteIstiodStatus{
+		WebhookConfigurations: []v1.WebhookConfigurationProbeStatus{
+			{
+				Name:     "istio-sidecar-injector",
+				Webhooks: []v1.WebhookProbeResult{{Name: "rev.namespace.sidecar-injector.istio.io"}},
+			},
+		},
+	}
+	webhook := newWebhookConfiguration(rev, "rev.namespace.sidecar-injector.istio.io")
+	cl := newFakeClientBuilder().
+		WithObjects(rev, webhook).
+		WithStatusSubresource(&v1.IstioRevision{}).
+		Build()
+	r := NewReconciler(cl, scheme.Scheme)
+	r.failures[webhook.Name] = 3
+	r.probe = func(context.Context, *admissionv1.MutatingWebhookConfiguration, admissionv1.WebhookClientConfig) (bool, error) {
+		g.Fail("probe should not be called")
+		return false, nil
+	}
+
+	result, err := r.Reconcile(ctx, webhook)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result).To(Equal(ctrl.Result{}), "expected probing to stop")
+	g.Expect(r.failures).ToNot(HaveKey(webhook.Name))
+
+	g.Expect(cl.Get(ctx, kube.Key(rev.Name), rev)).To(Succeed())
+	g.Expect(rev.Status.RemoteIstiod).To(BeNil())
+}
+
+func TestReconcileDeletedWebhook(t *testing.T) {
+	g := NewWithT(t)
+
+	rev := newRemoteRevision()
+	rev.Status.RemoteIstiod = &v1.RemoteIstiodStatus{
+		WebhookConfigurations: []v1.WebhookConfigurationProbeStatus{
+			{
+				Name:     "istio-sidecar-injector",
+				Webhooks: []v1.WebhookProbeResult{{Name: "rev.namespace.sidecar-injector.istio.io"}},
+			},
+		},
+	}
+	otherRev := newRemoteRevision()
+	otherRev.Name = "other"
+	otherRev.Status.RemoteIstiod = &v1.RemoteIstiodStatus{
+		WebhookConfigurations: []v1.WebhookConfigurationProbeStatus{
+			{
+				Name:     "istio-sidecar-injector-other",
+				Webhooks: []v1.WebhookProbeResult{{Name: "rev.namespace.sidecar-injector.istio.io", Ready: true}},
+			},
+		},
+	}
+	cl := newFakeClientBuilder().
+		WithObjects(rev, otherRev).
+		WithStatusSubresource(&v1.IstioRevision{}).
+		Build()
+	r := NewReconciler(cl, scheme.Scheme)
+	r.failures["istio-sidecar-injector"] = 3
+	r.failures["istio-sidecar-injector-other"] = 1
+
+	reconcileRequest := r.reconcileRequest(reconciler.NewStandardReconciler[*admissionv1.MutatingWebhookConfiguration](cl, r.Reconcile))
+	_, err := reconcileRequest(ctx, reconcile.Request{NamespacedName: kube.Key("istio-sidecar-injector")})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(r.failures).To(Equal(map[string]int{"istio-sidecar-injector-other": 1}))
+
+	g.Expect(cl.Get(ctx, kube.Key(rev.Name), rev)).To(Succeed())
+	g.Expect(rev.Status.RemoteIstiod).To(BeNil())
+	g.Expect(cl.Get(ctx, kube.Key(otherRev.Name), otherRev)).To(Succeed())
+	g.Expect(otherRev.Status.RemoteIstiod).ToNot(BeNil())
+}
+
+func TestReconcileMultipleWebhookConfigurations(t *testing.T) {
+	g := NewWithT(t)
+
+	rev := newRemoteRevision()
+	injector := newWebhookConfiguration(rev, "rev.namespace.sidecar-injector.istio.io")
+	tag := newWebhookConfiguration(rev, "rev.namespace.sidecar-injector.istio.io")
+	tag.Name = "istio-revision-tag-default"
+	tag.Webhooks[0].ClientConfig.Service.Port = ptr.Of(int32(15017))
+
+	cl := newFakeClientBuilder().
+		WithObjects(rev, injector, tag).
+		WithStatusSubresource(&v1.IstioRevision{}).
+		Build()
+	r := NewReconciler(cl, scheme.Scheme)
+	r.probe = func(_ context.Context, _ *admissionv1.MutatingWebhookConfiguration, clientConfig admissionv1.WebhookClientConfig) (bool, error) {
+		return clientConfig.Service.Port == nil, nil
+	}
+
+	getResults := func() map[string]bool {
+		g.Expect(cl.Get(ctx, kube.Key(rev.Name), rev)).To(Succeed())
+		results := map[string]bool{}
+		for _, configuration := range rev.Status.RemoteIstiod.WebhookConfigurations {
+			results[configuration.Name] = configuration.IsReady()
+		}
+		return results
+	}
+
+	// reconciling one configuration must not overwrite the results of the other
+	for range 2 {
+		for _, webhook := range []*admissionv1.MutatingWebhookConfiguration{injector, tag} {
+			_, err := r.Reconcile(ctx, webhook)
+			g.Expect(err).ToNot(HaveOccurred())
+		}
+		g.Expect(getResults()).To(Equal(map[string]bool{"istio-sidecar-injector": true, "istio-revision-tag-default": false}))
+	}
+
+	// deleting one configuration only removes its results
+	g.Expect(cl.Delete(ctx, tag)).To(Succeed())
+	reconcileRequest := r.reconcileRequest(reconciler.NewStandardReconciler[*admissionv1.MutatingWebhookConfiguration](cl, r.Reconcile))
+	_, err := reconcileRequest(ctx, reconcile.Request{NamespacedName: kube.Key(tag.Name)})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(getResults()).To(Equal(map[string]bool{"istio-sidecar-injector": true}))
+}
+
+func TestProbeBackoff(t *testing.T) {
+	g := NewWithT(t)
+
+	rev := newRemoteRevision()
+	webhook := newWebhookConfiguration(rev, "rev.namespace.sidecar-injector.istio.io")
+	cl := newFakeClientBuilder().
+		WithObjects(rev, webhook).
+		WithStatusSubresource(&v1.IstioRevision{}).
+		Build()
+	r := NewReconciler(cl, scheme.Scheme)
+
+	ready := false
+	r.probe = func(context.Context, *admissionv1.MutatingWebhookConfiguration, admissionv1.WebhookClientConfig) (bool, error) {
+		return ready, nil
+	}
+
+	reconcile := func() time.Duration {
+		result, err := r.Reconcile(ctx, webhook)
+		g.Expect(err).ToNot(HaveOccurred())
+		return result.RequeueAfter
+	}
+
+	// the delay doubles with each consecutive failure, up to maxProbeBackoff
+	for _, expected := range []time.Duration{3 * time.Second, 6 * time.Second, 12 * time.Second, 24 * time.Second, 48 * time.Second, time.Minute, time.Minute} {
+		g.Expect(reconcile()).To(Equal(expected))
+	}
+
+	g.Expect(cl.Get(ctx, kube.Key(rev.Name), rev)).To(Succeed())
+	transitionTime := rev.Status.RemoteIstiod.WebhookConfigurations[0].Webhooks[0].LastTransitionTime
+	g.Expect(transitionTime.IsZero()).To(BeFalse())
+
+	// a successful probe resets the backoff
+	ready = true
+	g.Expect(reconcile()).To(Equal(defaultPeriodSeconds * time.Second))
+
+	ready = false
+	g.Expect(reconcile()).To(Equal(defaultPeriodSeconds * time.Second))
+	g.Expect(reconcile()).To(Equal(2 * defaultPeriodSeconds * time.Second))
+}
+
+func TestProbeResultPreservesTransitionTime(t *testing.T) {
+	g := NewWithT(t)
+
+	transitionTime := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	rev := newRemoteRevision()
+	prevResults := &v1.WebhookConfigurationProbeStatus{
+		Name: "istio-sidecar-injector",
+		Webhooks: []v1.WebhookProbeResult{
+			{
+				Name:               "rev.namespace.sidecar-injector.istio.io",
+				URL:                "https://istiod.istio-system.svc:443/ready",
+				Ready:              true,
+				LastTransitionTime: transitionTime,
+			},
+		},
+	}
+	webhook := newWebhookConfiguration(rev, "rev.namespace.sidecar-injector.istio.io")
+
+	r := NewReconciler(newFakeClientBuilder().Build(), scheme.Scheme)
+
+	ready := true
+	r.probe = func(context.Context, *admissionv1.MutatingWebhookConfiguration, admissionv1.WebhookClientConfig) (bool, error) {
+		return ready, nil
+	}
+
+	results := r.probeWebhooks(ctx, webhook, prevResults)
+	g.Expect(&results).To(Equal(prevResults))
+
+	ready = false
+	results = r.probeWebhooks(ctx, webhook, prevResults)
+	g.Expect(results.Webhooks[0].Ready).To(BeFalse())
+	g.Expect(results.Webhooks[0].LastTransitionTime.After(transitionTime.Time)).To(BeTrue())
+}
+
+func newRemoteRevision() *v1.IstioRevision {
+	return &v1.IstioRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+			UID:  "some-uid",
+		},
+		Status: v1.IstioRevisionStatus{
+			Mode: v1.IstioRevisionModeRemote,
+		},
+	}
+}
+
+func newWebhookConfiguration(rev *v1.IstioRevision, names ...string) *admissionv1.MutatingWebhookConfiguration {
+	webhook := &admissionv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "istio-sidecar-injector",
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: v1.GroupVersion.String(),
+					Kind:       v1.IstioRevisionKind,
+					Name:       rev.Name,
+					UID:        rev.UID,
+				},
+			},
+		},
+	}
+	for _, name := range names {
+		webhook.Webhooks = append(webhook.Webhooks, admissionv1.MutatingWebhook{
+			Name: name,
+			ClientConfig: admissionv1.WebhookClientConfig{
+				Service: &admissionv1.ServiceReference{Name: "istiod", Namespace: "istio-system"},
+			},
+		})
+	}
+	return webhook
+}
+
 func TestDoProbe(t *testing.T) {
 	svc := admissionv1.ServiceReference{Name: "istiod", Namespace: "istio-system"}
 	host := svc.Name + "." + svc.Namespace + ".svc"
@@ -179,22 +521,14 @@ func TestDoProbe(t *testing.T) {
 		expectedError  string
 	}{
 		{
-			name: "No webhooks",
-			webhook: &admissionv1.MutatingWebhookConfiguration{
-				Webhooks: []admissionv1.MutatingWebhook{},
-			},
-			expectedResult: false,
-			expectedError:  "mutatingwebhookconfiguration contains no webhooks",
-		},
-		{
-			name: "No service in client config",
+			name: "No URL or service in client config",
 			webhook: &admissionv1.MutatingWebhookConfiguration{
 				Webhooks: []admissionv1.MutatingWebhook{
 					{ClientConfig: admissionv1.WebhookClientConfig{Service: nil}},
 				},
 			},
 			expectedResult: false,
-			expectedError:  "missing webhooks[].clientConfig.service",
+			expectedError:  "no URL or Service specified in WebhookClientConfig",
 		},
 		{
 			name: "Missing CA bundle",
@@ -258,6 +592,37 @@ func TestDoProbe(t *testing.T) {
 			expectedResult: true,
 			expectedError:  "",
 		},
+		{
+			name: "Successful HTTP response from URL webhook",
+			webhook: &admissionv1.MutatingWebhookConfiguration{
+				Webhooks: []admissionv1.MutatingWebhook{
+					{
+						ClientConfig: admissionv1.WebhookClientConfig{
+							URL:      ptr.Of("https://" + host + ":15017/inject/cluster/remote/net/network1"),
+							CABundle: certPEM,
+						},
+					},
+				},
+			},
+			httpStatus:     http.StatusOK,
+			expectedResult: true,
+			expectedError:  "",
+		},
+		{
+			name: "URL webhook without CA bundle uses system roots",
+			webhook: &admissionv1.MutatingWebhookConfiguration{
+				Webhooks: []admissionv1.MutatingWebhook{
+					{
+						ClientConfig: admissionv1.WebhookClientConfig{
+							URL: ptr.Of("https://" + host + ":15017/inject"),
+						},
+					},
+				},
+			},
+			httpStatus:     http.StatusOK,
+			expectedResult: false,
+			expectedError:  "certificate signed by unknown authority",
+		},
 		{
 			name: "Context timeout",
 			webhook: &admissionv1.MutatingWebhookConfiguration{
@@ -344,7 +709,7 @@ func TestDoProbe(t *testing.T) {
 			defer server.Close()
 
 			customDialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
-				if addr == host+":443" {
+				if addr == host+":443" || addr == host+":15017" {
 					return net.Dial(network, server.Listener.Addr().String())
 				}
 				return net.Dial(network, addr)
@@ -363,7 +728,7 @@ func TestDoProbe(t *testing.T) {
 			}
 
 			startTime := time.Now()
-			result, err := doProbe(probeCtx, tt.webhook)
+			result, err := doProbe(probeCtx, tt.webhook, tt.webhook.Webhooks[0].ClientConfig)
 			stopTime := time.Now()
 
 			if tt.maxDuration > 0 {
@@ -431,7 +796,7 @@ func TestIsOwnedByRevisionWithRemoteControlPlane(t *testing.T) {
 			expected: false,
 		},
 		{
-			name: "IstioRevision not using remote profile",
+			name: "IstioRevision not using remote control plane",
 			ownerRefs: []metav1.OwnerReference{
 				{
 					APIVersion: v1.GroupVersion.String(),
@@ -444,13 +809,15 @@ func TestIsOwnedByRevisionWithRemoteControlPlane(t *testing.T) {
 					ObjectMeta: metav1.ObjectMeta{
 						Name: "revision1",
 					},
-					Spec: v1.IstioRevisionSpec{},
+					Status: v1.IstioRevisionStatus{
+						Mode: v1.IstioRevisionModeLocal,
+					},
 				},
 			},
 			expected: false,
 		},
 		{
-			name: "IstioRevision uses remote profile",
+			name: "IstioRevision uses remote control plane",
 			ownerRefs: []metav1.OwnerReference{
 				{
 					APIVersion: v1.GroupVersion.String(),
@@ -463,10 +830,8 @@ func TestIsOwnedByRevisionWithRemoteControlPlane(t *testing.T) {
 					ObjectMeta: metav1.ObjectMeta{
 						Name: "revision1",
 					},
-					Spec: v1.IstioRevisionSpec{
-						Values: &v1.Values{
-							Profile: ptr.Of("remote"),
-						},
+					Status: v1.IstioRevisionStatus{
+						Mode: v1.IstioRevisionModeRemote,
 					},
 				},
 			},
@@ -505,7 +870,28 @@ func TestGetReadinessProbeURL(t *testing.T) {
 		{
 			name: "URL",
 			config: admissionv1.WebhookClientConfig{
-				URL: ptr.Of("https://some.url"),
+				URL: ptr.Of("https://some.url:15017/inject/cluster/remote/net/network1"),
+			},
+			expectURL: "https://some.url:15017/ready",
+		},
+		{
+			name: "URL without port",
+			config: admissionv1.WebhookClientConfig{
+				URL: ptr.Of("https://some.url/inject"),
+			},
+			expectURL: "https://some.url/ready",
+		},
+		{
+			name: "non-https URL",
+			config: admissionv1.WebhookClientConfig{
+				URL: ptr.Of("http://some.url:15017/inject"),
+			},
+			expectErr: true,
+		},
+		{
+			name: "relative URL",
+			config: admissionv1.WebhookClientConfig{
+				URL: ptr.Of("/inject"),
 			},
 			expectErr: true,
 		},
@@ -18,22 +18,26 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path"
 	"reflect"
 
 	"github.com/go-logr/logr"
 	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
-	"github.com/istio-ecosystem/sail-operator/api/v1alpha1"
 	"github.com/istio-ecosystem/sail-operator/pkg/config"
 	"github.com/istio-ecosystem/sail-operator/pkg/constants"
 	"github.com/istio-ecosystem/sail-operator/pkg/enqueuelogger"
 	"github.com/istio-ecosystem/sail-operator/pkg/errlist"
 	"github.com/istio-ecosystem/sail-operator/pkg/helm"
+	"github.com/istio-ecosystem/sail-operator/pkg/imageverification"
 	"github.com/istio-ecosystem/sail-operator/pkg/istiovalues"
 	"github.com/istio-ecosystem/sail-operator/pkg/kube"
+	"github.com/istio-ecosystem/sail-operator/pkg/namespace"
 	"github.com/istio-ecosystem/sail-operator/pkg/predicate"
 	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	"github.com/istio-ecosystem/sail-operator/pkg/revision"
 	"github.com/istio-ecosystem/sail-operator/pkg/validation"
+	"helm.sh/helm/v3/pkg/release"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -41,6 +45,9 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -58,6 +65,12 @@ type Reconciler struct {
 	Config       config.ReconcilerConfig
 	Scheme       *runtime.Scheme
 	ChartManager *helm.ChartManager
+	// imageVerifier is shared by all reconciliations, so that its caches outlive a single reconciliation
+	imageVerifier *imageverification.Verifier
+	// EventRecorder is set up by SetupWithManager
+	EventRecorder record.EventRecorder
+	// APIReader is set up by SetupWithManager and lists the workloads in the namespace before it's deleted
+	APIReader client.Reader
 }
 
 const (
@@ -66,10 +79,11 @@ const (
 
 func NewReconciler(cfg config.ReconcilerConfig, client client.Client, scheme *runtime.Scheme, chartManager *helm.ChartManager) *Reconciler {
 	return &Reconciler{
-		Config:       cfg,
-		Client:       client,
-		Scheme:       scheme,
-		ChartManager: chartManager,
+		Config:        cfg,
+		Client:        client,
+		Scheme:        scheme,
+		ChartManager:  chartManager,
+		imageVerifier: imageverification.NewVerifier(clock.RealClock{}),
 	}
 }
 
@@ -87,48 +101,172 @@ func NewReconciler(cfg config.ReconcilerConfig, client client.Client, scheme *ru
 //
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.14.1/pkg/reconcile
-func (r *Reconciler) Reconcile(ctx context.Context, ztunnel *v1alpha1.ZTunnel) (ctrl.Result, error) {
+func (r *Reconciler) Reconcile(ctx context.Context, ztunnel *v1.ZTunnel) (ctrl.Result, error) {
 	log := logf.FromContext(ctx)
 
-	reconcileErr := r.doReconcile(ctx, ztunnel)
+	result, reconcileErr := r.doReconcile(ctx, ztunnel)
 
 	log.Info("Reconciliation done. Updating status.")
-	statusErr := r.updateStatus(ctx, ztunnel, reconcileErr)
+	statusErr := r.updateStatus(ctx, ztunnel, result, reconcileErr)
 
 	return ctrl.Result{}, errors.Join(reconcileErr, statusErr)
 }
 
-func (r *Reconciler) Finalize(ctx context.Context, ztunnel *v1alpha1.ZTunnel) error {
-	return r.uninstallHelmChart(ctx, ztunnel)
+// Finalize uninstalls ztunnel, or orphans it if spec.deletionPolicy is Orphan.
+func (r *Reconciler) Finalize(ctx context.Context, ztunnel *v1.ZTunnel) error {
+	if ztunnel.Spec.DeletionPolicy == v1.DeletionPolicyOrphan {
+		if err := r.ChartManager.OrphanRelease(ctx, ztunnelChart, ztunnel.Spec.Namespace, ztunnel.UID); err != nil {
+			return fmt.Errorf("failed to orphan Helm chart %q: %w", ztunnelChart, err)
+		}
+		return nil
+	}
+	if err := r.uninstallHelmChart(ctx, ztunnel); err != nil {
+		return err
+	}
+	workloads, err := namespace.Delete(ctx, r.Client, r.APIReader, ztunnel.Spec.Namespace, ztunnel.Spec.CreateNamespace, namespace.Owner(v1.ZTunnelKind, ztunnel.Name))
+	namespace.RecordNotDeleted(r.EventRecorder, ztunnel, ztunnel.Spec.Namespace, workloads)
+	return err
 }
 
-func (r *Reconciler) doReconcile(ctx context.Context, ztunnel *v1alpha1.ZTunnel) error {
+// reconcileResult describes the outcome of doReconcile, so that the status reports what was actually installed.
+type reconcileResult struct {
+	// version is the Istio version whose chart was installed, or an empty string if no chart was installed
+	version string
+	// istioRevision is the name of the IstioRevision that the installed ztunnel connects to, if any
+	istioRevision string
+	// pending describes what the upgrade to the version of the Istio referenced in spec.versionFrom waits for
+	pending string
+}
+
+func (r *Reconciler) doReconcile(ctx context.Context, ztunnel *v1.ZTunnel) (reconcileResult, error) {
 	log := logf.FromContext(ctx)
+	if err := r.reconcileNamespace(ctx, ztunnel); err != nil {
+		return reconcileResult{}, err
+	}
 	if err := r.validate(ctx, ztunnel); err != nil {
-		return err
+		return reconcileResult{}, err
+	}
+	if err := r.adoptHelmRelease(ctx, ztunnel); err != nil {
+		return reconcileResult{}, err
 	}
 
-	log.Info("Installing ztunnel Helm chart")
-	return r.installHelmChart(ctx, ztunnel)
+	version, pending, err := r.determineVersion(ctx, ztunnel)
+	if err != nil {
+		return reconcileResult{}, err
+	}
+	if version == "" {
+		log.Info("Waiting for control plane before installing ztunnel Helm chart", "reason", pending)
+		return reconcileResult{pending: pending}, nil
+	}
+	if err := r.validateVersionSkew(ctx, ztunnel, version); err != nil {
+		return reconcileResult{pending: pending}, err
+	}
+	targetRevision, err := r.getTargetRevision(ctx, ztunnel)
+	if err != nil {
+		return reconcileResult{pending: pending}, err
+	}
+
+	log.Info("Installing ztunnel Helm chart", "version", version)
+	if err := r.installHelmChart(ctx, ztunnel, version, targetRevision); err != nil {
+		return reconcileResult{pending: pending}, err
+	}
+	result := reconcileResult{version: version, pending: pending}
+	if targetRevision != nil {
+		result.istioRevision = targetRevision.Name
+	}
+	return result, nil
 }
 
-func (r *Reconciler) validate(ctx context.Context, ztunnel *v1alpha1.ZTunnel) error {
-	if ztunnel.Spec.Version == "" {
+// reconcileNamespace creates the target namespace if spec.createNamespace is set.
+func (r *Reconciler) reconcileNamespace(ctx context.Context, ztunnel *v1.ZTunnel) error {
+	if ztunnel.Spec.CreateNamespace == nil || ztunnel.Spec.Namespace == "" {
+		return nil
+	}
+	return namespace.Reconcile(ctx, r.Client, ztunnel.Spec.Namespace, ztunnel.Spec.CreateNamespace, namespace.Owner(v1.ZTunnelKind, ztunnel.Name))
+}
+
+func (r *Reconciler) validate(ctx context.Context, ztunnel *v1.ZTunnel) error {
+	if ztunnel.Spec.Version == "" && ztunnel.Spec.VersionFrom == nil {
 		return reconciler.NewValidationError("spec.version not set")
 	}
 	if ztunnel.Spec.Namespace == "" {
 		return reconciler.NewValidationError("spec.namespace not set")
 	}
-	if err := validation.ValidateTargetNamespace(ctx, r.Client, ztunnel.Spec.Namespace); err != nil {
+	if err := validation.ValidateTargetNamespace(ctx, r.Client, r.Config.OperatorConfig.Get(), ztunnel.Spec.Namespace); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *Reconciler) installHelmChart(ctx context.Context, ztunnel *v1alpha1.ZTunnel) error {
+// determineVersion returns the Istio version to install. If spec.versionFrom is set, the version of the referenced
+// Istio is returned once its control plane has been upgraded. Until then, the previously installed version is returned
+// (or an empty string if ZTunnel hasn't been installed yet), along with a message describing what the upgrade waits for.
+func (r *Reconciler) determineVersion(ctx context.Context, ztunnel *v1.ZTunnel) (version string, pending string, err error) {
+	if ztunnel.Spec.VersionFrom == nil {
+		return ztunnel.Spec.Version, "", nil
+	}
+	version, pending, err = revision.GetControlPlaneVersion(ctx, r.Client, ztunnel.Spec.VersionFrom.Istio)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to determine version of Istio %q: %w", ztunnel.Spec.VersionFrom.Istio, err)
+	}
+	if pending != "" {
+		return ztunnel.Status.Version, pending, nil
+	}
+	return version, "", nil
+}
+
+// validateVersionSkew refuses to install or upgrade to a version that isn't supported by all in-use IstioRevisions,
+// if spec.versionSkewPolicy is Block. Reconciling the installed version is always allowed.
+func (r *Reconciler) validateVersionSkew(ctx context.Context, ztunnel *v1.ZTunnel, version string) error {
+	if ztunnel.Spec.VersionSkewPolicy != v1.VersionSkewPolicyBlock || version == ztunnel.Status.Version {
+		return nil
+	}
+	revisions, err := revision.ListUnsupportedSkew(ctx, r.Client, version)
+	if err != nil {
+		return fmt.Errorf("failed to list IstioRevisions: %w", err)
+	}
+	if len(revisions) > 0 {
+		return revision.NewUnsupportedVersionSkewError(revision.DescribeUnsupportedSkew(v1.ZTunnelKind, version, revisions))
+	}
+	return nil
+}
+
+// getTargetRevision returns the IstioRevision that ztunnel is bound to through spec.targetRef, or nil if
+// spec.targetRef isn't set. If the targetRef points to an Istio, its active revision is returned.
+func (r *Reconciler) getTargetRevision(ctx context.Context, ztunnel *v1.ZTunnel) (*v1.IstioRevision, error) {
+	if ztunnel.Spec.TargetRef == nil {
+		return nil, nil
+	}
+
+	revisionName := ztunnel.Spec.TargetRef.Name
+	if ztunnel.Spec.TargetRef.Kind == v1.IstioKind {
+		istio := v1.Istio{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: ztunnel.Spec.TargetRef.Name}, &istio); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, NewTargetNotFoundError(fmt.Sprintf("referenced Istio %q does not exist", ztunnel.Spec.TargetRef.Name))
+			}
+			return nil, fmt.Errorf("failed to get referenced Istio: %w", err)
+		}
+		if istio.Status.ActiveRevisionName == "" {
+			return nil, NewTargetNotFoundError(fmt.Sprintf("referenced Istio %q has no active revision", istio.Name))
+		}
+		revisionName = istio.Status.ActiveRevisionName
+	}
+
+	rev := v1.IstioRevision{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: revisionName}, &rev); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, NewTargetNotFoundError(fmt.Sprintf("referenced IstioRevision %q does not exist", revisionName))
+		}
+		return nil, fmt.Errorf("failed to get referenced IstioRevision: %w", err)
+	}
+	return &rev, nil
+}
+
+func (r *Reconciler) installHelmChart(ctx context.Context, ztunnel *v1.ZTunnel, version string, targetRevision *v1.IstioRevision) error {
 	ownerReference := metav1.OwnerReference{
-		APIVersion:         v1alpha1.GroupVersion.String(),
-		Kind:               v1alpha1.ZTunnelKind,
+		APIVersion:         v1.GroupVersion.String(),
+		Kind:               v1.ZTunnelKind,
 		Name:               ztunnel.Name,
 		UID:                ztunnel.UID,
 		Controller:         ptr.Of(true),
@@ -136,11 +274,12 @@ func (r *Reconciler) installHelmChart(ctx context.Context, ztunnel *v1alpha1.ZTu
 	}
 
 	// get userValues from ztunnel.spec.values
-	userValues := ztunnel.Spec.Values
+	userValues := ztunnel.Spec.Values.DeepCopy()
+
+	operatorConfig := r.Config.OperatorConfig.Get()
 
 	// apply image digests from configuration, if not already set by user
-	// TODO: Revisit once we support ImageOverrides for ztunnel
-	// userValues = applyImageDigests(ztunnel, userValues, config.Config)
+	userValues = applyImageDigests(version, userValues, operatorConfig)
 
 	if userValues == nil {
 		userValues = &v1.ZTunnelValues{}
@@ -150,35 +289,84 @@ func (r *Reconciler) installHelmChart(ctx context.Context, ztunnel *v1alpha1.ZTu
 		userValues.ZTunnel = &v1.ZTunnelConfig{}
 	}
 
+	// point ztunnel to the istiod of the revision referenced in spec.targetRef, if not already set by user
+	if targetRevision != nil {
+		applyTargetRevision(userValues.ZTunnel, targetRevision)
+	}
+
 	// apply userValues on top of defaultValues from profiles
 	mergedHelmValues, err := istiovalues.ApplyProfilesAndPlatform(
-		r.Config.ResourceDirectory, ztunnel.Spec.Version, r.Config.Platform, r.Config.DefaultProfile, ztunnel.Spec.Profile, helm.FromValues(userValues))
+		r.Config.ResourceDirectory, version, r.Config.ActivePlatform(), r.Config.ActiveDefaultProfile(), ztunnel.Spec.Profile, helm.FromValues(userValues))
 	if err != nil {
 		return fmt.Errorf("failed to apply profile: %w", err)
 	}
 
-	// Apply any user Overrides configured as part of values.ztunnel
-	// This step was not required for the IstioCNI resource because the Helm templates[*] automatically override values.cni
-	// [*]https://github.com/istio/istio/blob/0200fd0d4c3963a72f36987c2e8c2887df172abf/manifests/charts/istio-cni/templates/zzy_descope_legacy.yaml#L3
-	// However, ztunnel charts do not have such a file, hence we are manually applying the mergeOperation here.
-	finalHelmValues, err := istiovalues.ApplyUserValues(helm.FromValues(mergedHelmValues), helm.FromValues(userValues.ZTunnel))
-	if err != nil {
-		return fmt.Errorf("failed to apply user overrides: %w", err)
-	}
-
-	_, err = r.ChartManager.UpgradeOrInstallChart(ctx, r.getChartDir(ztunnel), finalHelmValues, ztunnel.Spec.Namespace, ztunnelChart, ownerReference)
+	_, err = r.ChartManager.UpgradeOrInstallChart(ctx, r.getChartDir(version), mergedHelmValues, ztunnel.Spec.Namespace, ztunnelChart, ownerReference,
+		helm.NewRegistryRewritePostRenderer(operatorConfig.RegistryRewrites),
+		helm.NewImageVerificationPostRenderer(ctx, r.imageVerifier, operatorConfig.ImageVerification))
 	if err != nil {
 		return fmt.Errorf("failed to install/update Helm chart %q: %w", ztunnelChart, err)
 	}
 	return nil
 }
 
-func (r *Reconciler) getChartDir(ztunnel *v1alpha1.ZTunnel) string {
-	return path.Join(r.Config.ResourceDirectory, ztunnel.Spec.Version, "charts", ztunnelChart)
+func (r *Reconciler) getChartDir(version string) string {
+	return path.Join(r.Config.ResourceDirectory, version, "charts", ztunnelChart)
 }
 
-func applyImageDigests(ztunnel *v1alpha1.ZTunnel, values *v1.ZTunnelValues, config config.OperatorConfig) *v1.ZTunnelValues {
-	imageDigests, digestsDefined := config.ImageDigests[ztunnel.Spec.Version]
+// adoptHelmRelease takes over the ztunnel release named in the sailoperator.io/adopt-helm-release annotation.
+// The version and values of the release are copied into the spec before the release is renamed to ztunnel.
+func (r *Reconciler) adoptHelmRelease(ctx context.Context, ztunnel *v1.ZTunnel) error {
+	return r.ChartManager.AdoptRelease(ctx, r.Client, r.Config.OperatorConfig.Get(), ztunnel, ztunnel.Spec.Namespace, ztunnelChart, func(rel *release.Release) error {
+		spec, err := ztunnelSpecFromRelease(ztunnel.Spec, rel)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(r.getChartDir(spec.Version)); err != nil {
+			return reconciler.NewValidationError(fmt.Sprintf("Helm release %q uses Istio version %s, which is not supported", rel.Name, spec.Version))
+		}
+
+		patch := client.MergeFrom(ztunnel.DeepCopy())
+		ztunnel.Spec = spec
+		if err := r.Client.Patch(ctx, ztunnel, patch); err != nil {
+			return fmt.Errorf("failed to copy the values of Helm release %q into the ZTunnel spec: %w", rel.Name, err)
+		}
+		return nil
+	})
+}
+
+// ztunnelSpecFromRelease returns the spec with the version of the release and, if the spec doesn't contain values
+// yet, the values the release was installed with. The ztunnel chart reads its configuration from the top level of
+// the values, whereas the ZTunnel resource nests it under values.ztunnel, so everything except the global values is
+// moved there.
+func ztunnelSpecFromRelease(spec v1.ZTunnelSpec, rel *release.Release) (v1.ZTunnelSpec, error) {
+	version := helm.ReleaseVersion(rel)
+	if version == "" {
+		return spec, reconciler.NewValidationError(fmt.Sprintf("cannot determine Istio version of Helm release %q", rel.Name))
+	}
+	spec.Version = version
+
+	if spec.Values == nil && len(rel.Config) > 0 {
+		ztunnelConfig := map[string]any{}
+		helmValues := helm.Values{"ztunnel": ztunnelConfig}
+		for key, value := range rel.Config {
+			if key == "global" {
+				helmValues[key] = value
+			} else {
+				ztunnelConfig[key] = value
+			}
+		}
+		values, err := helm.ToValues(helmValues, &v1.ZTunnelValues{})
+		if err != nil {
+			return spec, fmt.Errorf("failed to convert values of Helm release %q: %w", rel.Name, err)
+		}
+		spec.Values = values
+	}
+	return spec, nil
+}
+
+func applyImageDigests(version string, values *v1.ZTunnelValues, config config.OperatorConfig) *v1.ZTunnelValues {
+	imageDigests, digestsDefined := config.ImageDigests[version]
 	// if we don't have default image digests defined for this version, it's a no-op
 	if !digestsDefined {
 		return values
@@ -198,7 +386,31 @@ func applyImageDigests(ztunnel *v1alpha1.ZTunnel, values *v1.ZTunnelValues, conf
 	return values
 }
 
-func (r *Reconciler) uninstallHelmChart(ctx context.Context, ztunnel *v1alpha1.ZTunnel) error {
+// applyTargetRevision sets the revision and the addresses of the istiod of the given IstioRevision, unless they
+// have been configured by the user.
+func applyTargetRevision(values *v1.ZTunnelConfig, rev *v1.IstioRevision) {
+	revisionName := ""
+	if rev.Spec.Values != nil && rev.Spec.Values.Revision != nil {
+		revisionName = *rev.Spec.Values.Revision
+	}
+	istiodName := "istiod"
+	if revisionName != "" {
+		istiodName += "-" + revisionName
+	}
+	istiodAddress := fmt.Sprintf("%s.%s.svc:15012", istiodName, rev.Spec.Namespace)
+
+	if values.Revision == nil {
+		values.Revision = &revisionName
+	}
+	if values.XdsAddress == nil {
+		values.XdsAddress = &istiodAddress
+	}
+	if values.CaAddress == nil {
+		values.CaAddress = &istiodAddress
+	}
+}
+
+func (r *Reconciler) uninstallHelmChart(ctx context.Context, ztunnel *v1.ZTunnel) error {
 	_, err := r.ChartManager.UninstallChart(ctx, ztunnelChart, ztunnel.Spec.Namespace)
 	if err != nil {
 		return fmt.Errorf("failed to uninstall Helm chart %q: %w", ztunnelChart, err)
@@ -209,16 +421,21 @@ func (r *Reconciler) uninstallHelmChart(ctx context.Context, ztunnel *v1alpha1.Z
 // SetupWithManager sets up the controller with the Manager.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	logger := mgr.GetLogger().WithName("ctrlr").WithName("ztunnel")
+	r.EventRecorder = mgr.GetEventRecorderFor("sail-operator")
+	r.APIReader = mgr.GetAPIReader()
 
 	// mainObjectHandler handles the ZTunnel watch events
 	mainObjectHandler := wrapEventHandler(logger, &handler.EnqueueRequestForObject{})
 
 	// ownedResourceHandler handles resources that are owned by the ZTunnel CR
 	ownedResourceHandler := wrapEventHandler(logger,
-		handler.EnqueueRequestForOwner(r.Scheme, r.RESTMapper(), &v1alpha1.ZTunnel{}, handler.OnlyControllerOwner()))
+		handler.EnqueueRequestForOwner(r.Scheme, r.RESTMapper(), &v1.ZTunnel{}, handler.OnlyControllerOwner()))
 
 	namespaceHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapNamespaceToReconcileRequest))
 
+	// controlPlaneHandler handles Istio and IstioRevision events, which affect the version skew and the tracked version
+	controlPlaneHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapControlPlaneToReconcileRequest))
+
 	return ctrl.NewControllerManagedBy(mgr).
 		WithOptions(controller.Options{
 			LogConstructor: func(req *reconcile.Request) logr.Logger {
@@ -231,7 +448,7 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 		}).
 
 		// we use the Watches function instead of For(), so that we can wrap the handler so that events that cause the object to be enqueued are logged
-		Watches(&v1alpha1.ZTunnel{}, mainObjectHandler).Named("ztunnel").
+		Watches(&v1.ZTunnel{}, mainObjectHandler).Named("ztunnel").
 
 		// namespaced resources
 		Watches(&corev1.ConfigMap{}, ownedResourceHandler).
@@ -246,29 +463,49 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 		// cluster-scoped resources
 		// +lint-watches:ignore: Namespace (not present in charts, but must be watched to reconcile ZTunnel when its namespace is created)
 		Watches(&corev1.Namespace{}, namespaceHandler).
+		// +lint-watches:ignore: Istio (not present in charts, but must be watched to track the control plane version)
+		Watches(&v1.Istio{}, controlPlaneHandler).
+		// +lint-watches:ignore: IstioRevision (not present in charts, but must be watched to validate the version skew)
+		Watches(&v1.IstioRevision{}, controlPlaneHandler).
 		Watches(&rbacv1.ClusterRole{}, ownedResourceHandler).
 		Watches(&rbacv1.ClusterRoleBinding{}, ownedResourceHandler).
-		Complete(reconciler.NewStandardReconcilerWithFinalizer[*v1alpha1.ZTunnel](r.Client, r.Reconcile, r.Finalize, constants.FinalizerName))
+
+		// changes of the image digests or of other operator configuration affect the rendered values
+		WatchesRawSource(r.Config.OperatorConfig.WatchSource(r.mapOperatorConfigChangeToReconcileRequest)).
+		Complete(reconciler.NewStandardReconcilerWithFinalizer[*v1.ZTunnel](r.Client, r.Reconcile, r.Finalize, constants.FinalizerName))
 }
 
-func (r *Reconciler) determineStatus(ctx context.Context, ztunnel *v1alpha1.ZTunnel, reconcileErr error) (v1alpha1.ZTunnelStatus, error) {
+func (r *Reconciler) determineStatus(ctx context.Context, ztunnel *v1.ZTunnel, result reconcileResult, reconcileErr error) (v1.ZTunnelStatus, error) {
 	var errs errlist.Builder
-	reconciledCondition := r.determineReconciledCondition(reconcileErr)
+	reconciledCondition := r.determineReconciledCondition(reconcileErr, result.pending)
 	readyCondition, err := r.determineReadyCondition(ctx, ztunnel)
 	errs.Add(err)
 
+	daemonSetStatus, daemonSetErr := r.determineDaemonSetStatus(ctx, ztunnel)
+	errs.Add(daemonSetErr)
+
 	status := *ztunnel.Status.DeepCopy()
 	status.ObservedGeneration = ztunnel.Generation
+	if reconcileErr == nil && result.version != "" {
+		status.Version = result.version
+		status.IstioRevision = result.istioRevision
+	}
 	status.SetCondition(reconciledCondition)
 	status.SetCondition(readyCondition)
+	if daemonSetErr == nil {
+		status.DaemonSet = daemonSetStatus
+	}
+	versionSkewCondition, err := r.determineVersionSkewCondition(ctx, status.Version)
+	errs.Add(err)
+	status.SetCondition(versionSkewCondition)
 	status.State = deriveState(reconciledCondition, readyCondition)
 	return status, errs.Error()
 }
 
-func (r *Reconciler) updateStatus(ctx context.Context, ztunnel *v1alpha1.ZTunnel, reconcileErr error) error {
+func (r *Reconciler) updateStatus(ctx context.Context, ztunnel *v1.ZTunnel, result reconcileResult, reconcileErr error) error {
 	var errs errlist.Builder
 
-	status, err := r.determineStatus(ctx, ztunnel, reconcileErr)
+	status, err := r.determineStatus(ctx, ztunnel, result, reconcileErr)
 	if err != nil {
 		errs.Add(fmt.Errorf("failed to determine status: %w", err))
 	}
@@ -281,58 +518,139 @@ func (r *Reconciler) updateStatus(ctx context.Context, ztunnel *v1alpha1.ZTunnel
 	return errs.Error()
 }
 
-func deriveState(reconciledCondition, readyCondition v1alpha1.ZTunnelCondition) v1alpha1.ZTunnelConditionReason {
+func deriveState(reconciledCondition, readyCondition v1.ZTunnelCondition) v1.ZTunnelConditionReason {
 	if reconciledCondition.Status != metav1.ConditionTrue {
 		return reconciledCondition.Reason
 	} else if readyCondition.Status != metav1.ConditionTrue {
 		return readyCondition.Reason
 	}
-	return v1alpha1.ZTunnelReasonHealthy
+	return v1.ZTunnelReasonHealthy
 }
 
-func (r *Reconciler) determineReconciledCondition(err error) v1alpha1.ZTunnelCondition {
-	c := v1alpha1.ZTunnelCondition{Type: v1alpha1.ZTunnelConditionReconciled}
+func (r *Reconciler) determineReconciledCondition(err error, pending string) v1.ZTunnelCondition {
+	c := v1.ZTunnelCondition{Type: v1.ZTunnelConditionReconciled}
 
-	if err == nil {
+	if err == nil && pending == "" {
 		c.Status = metav1.ConditionTrue
+	} else if err == nil {
+		c.Status = metav1.ConditionFalse
+		c.Reason = v1.ZTunnelReasonWaitingForControlPlane
+		c.Message = pending
+	} else if revision.IsUnsupportedVersionSkewError(err) {
+		c.Status = metav1.ConditionFalse
+		c.Reason = v1.ZTunnelReasonUnsupportedVersionSkew
+		c.Message = err.Error()
+	} else if IsTargetNotFoundError(err) {
+		c.Status = metav1.ConditionFalse
+		c.Reason = v1.ZTunnelReasonTargetNotFound
+		c.Message = err.Error()
+	} else if imageverification.IsVerificationError(err) {
+		c.Status = metav1.ConditionFalse
+		c.Reason = v1.ZTunnelReasonImageVerificationFailed
+		c.Message = err.Error()
 	} else {
 		c.Status = metav1.ConditionFalse
-		c.Reason = v1alpha1.ZTunnelReasonReconcileError
+		c.Reason = v1.ZTunnelReasonReconcileError
 		c.Message = fmt.Sprintf("error reconciling resource: %v", err)
 	}
 	return c
 }
 
-func (r *Reconciler) determineReadyCondition(ctx context.Context, ztunnel *v1alpha1.ZTunnel) (v1alpha1.ZTunnelCondition, error) {
-	c := v1alpha1.ZTunnelCondition{
-		Type:   v1alpha1.ZTunnelConditionReady,
+// determineVersionSkewCondition checks whether the installed version is supported by all in-use IstioRevisions.
+func (r *Reconciler) determineVersionSkewCondition(ctx context.Context, version string) (v1.ZTunnelCondition, error) {
+	c := v1.ZTunnelCondition{Type: v1.ZTunnelConditionSupportedVersionSkew}
+	if version == "" {
+		c.Status = metav1.ConditionUnknown
+		c.Message = "ZTunnel has not been installed yet"
+		return c, nil
+	}
+
+	revisions, err := revision.ListUnsupportedSkew(ctx, r.Client, version)
+	if err != nil {
+		c.Status = metav1.ConditionUnknown
+		c.Message = fmt.Sprintf("failed to check version skew: %v", err)
+		return c, fmt.Errorf("failed to list IstioRevisions: %w", err)
+	}
+	if len(revisions) > 0 {
+		c.Status = metav1.ConditionFalse
+		c.Reason = v1.ZTunnelReasonUnsupportedVersionSkew
+		c.Message = revision.DescribeUnsupportedSkew(v1.ZTunnelKind, version, revisions)
+	} else {
+		c.Status = metav1.ConditionTrue
+	}
+	return c, nil
+}
+
+func (r *Reconciler) determineReadyCondition(ctx context.Context, ztunnel *v1.ZTunnel) (v1.ZTunnelCondition, error) {
+	c := v1.ZTunnelCondition{
+		Type:   v1.ZTunnelConditionReady,
 		Status: metav1.ConditionFalse,
 	}
 
 	ds := appsv1.DaemonSet{}
 	if err := r.Client.Get(ctx, r.getDaemonSetKey(ztunnel), &ds); err == nil {
 		if ds.Status.CurrentNumberScheduled == 0 {
-			c.Reason = v1alpha1.ZTunnelDaemonSetNotReady
+			c.Reason = v1.ZTunnelDaemonSetNotReady
 			c.Message = "no ztunnel pods are currently scheduled"
+		} else if ds.Status.ObservedGeneration < ds.Generation || ds.Status.UpdatedNumberScheduled < ds.Status.DesiredNumberScheduled {
+			c.Reason = v1.ZTunnelReasonUpgrading
+			c.Message = fmt.Sprintf("%d of %d ztunnel pods have been updated",
+				ds.Status.UpdatedNumberScheduled, ds.Status.DesiredNumberScheduled)
 		} else if ds.Status.NumberReady < ds.Status.CurrentNumberScheduled {
-			c.Reason = v1alpha1.ZTunnelDaemonSetNotReady
+			c.Reason = v1.ZTunnelDaemonSetNotReady
 			c.Message = "not all ztunnel pods are ready"
 		} else {
 			c.Status = metav1.ConditionTrue
 		}
 	} else if apierrors.IsNotFound(err) {
-		c.Reason = v1alpha1.ZTunnelDaemonSetNotReady
+		c.Reason = v1.ZTunnelDaemonSetNotReady
 		c.Message = "ztunnel DaemonSet not found"
 	} else {
 		c.Status = metav1.ConditionUnknown
-		c.Reason = v1alpha1.ZTunnelReasonReadinessCheckFailed
+		c.Reason = v1.ZTunnelReasonReadinessCheckFailed
 		c.Message = fmt.Sprintf("failed to get readiness: %v", err)
 		return c, fmt.Errorf("get failed: %w", err)
 	}
 	return c, nil
 }
 
-func (r *Reconciler) getDaemonSetKey(ztunnel *v1alpha1.ZTunnel) client.ObjectKey {
+// determineDaemonSetStatus reports the rollout of the ztunnel DaemonSet and the versions of the running
+// ztunnel pods. It returns nil if the DaemonSet doesn't exist.
+func (r *Reconciler) determineDaemonSetStatus(ctx context.Context, ztunnel *v1.ZTunnel) (*v1.ZTunnelDaemonSetStatus, error) {
+	ds := appsv1.DaemonSet{}
+	if err := r.Client.Get(ctx, r.getDaemonSetKey(ztunnel), &ds); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get DaemonSet: %w", err)
+	}
+
+	status := &v1.ZTunnelDaemonSetStatus{
+		DesiredNumberScheduled: ds.Status.DesiredNumberScheduled,
+		UpdatedNumberScheduled: ds.Status.UpdatedNumberScheduled,
+		NumberReady:            ds.Status.NumberReady,
+		NumberUnavailable:      ds.Status.NumberUnavailable,
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(ds.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DaemonSet selector: %w", err)
+	}
+	podList := corev1.PodList{}
+	if err := r.Client.List(ctx, &podList, client.InNamespace(ds.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("failed to list ztunnel pods: %w", err)
+	}
+	versions := sets.New[string]()
+	for _, pod := range podList.Items {
+		if pod.DeletionTimestamp == nil && pod.Labels[constants.KubernetesAppVersionKey] != "" {
+			versions.Insert(pod.Labels[constants.KubernetesAppVersionKey])
+		}
+	}
+	status.Versions = sets.List(versions)
+	return status, nil
+}
+
+func (r *Reconciler) getDaemonSetKey(ztunnel *v1.ZTunnel) client.ObjectKey {
 	return client.ObjectKey{
 		Namespace: ztunnel.Spec.Namespace,
 		Name:      "ztunnel",
@@ -343,7 +661,7 @@ func (r *Reconciler) mapNamespaceToReconcileRequest(ctx context.Context, ns clie
 	log := logf.FromContext(ctx)
 
 	// Check if any ZTunnel references this namespace in .spec.namespace
-	ztunnelList := v1alpha1.ZTunnelList{}
+	ztunnelList := v1.ZTunnelList{}
 	if err := r.Client.List(ctx, &ztunnelList); err != nil {
 		log.Error(err, "failed to list ZTunnels")
 		return nil
@@ -358,6 +676,67 @@ func (r *Reconciler) mapNamespaceToReconcileRequest(ctx context.Context, ns clie
 	return requests
 }
 
+// mapOperatorConfigChangeToReconcileRequest enqueues the ZTunnels affected by a change of the operator configuration.
+// When only image digests changed, these are the ZTunnels whose installed version uses one of the changed digests.
+func (r *Reconciler) mapOperatorConfigChangeToReconcileRequest(ctx context.Context, change config.Change) []reconcile.Request {
+	log := logf.FromContext(ctx)
+
+	ztunnelList := v1.ZTunnelList{}
+	if err := r.Client.List(ctx, &ztunnelList); err != nil {
+		log.Error(err, "failed to list ZTunnels")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, ztunnel := range ztunnelList.Items {
+		if change.Affects(ztunnel.Spec.Version, ztunnel.Status.Version) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: ztunnel.Name}})
+		}
+	}
+	return requests
+}
+
+// mapControlPlaneToReconcileRequest enqueues all ZTunnels, since any change to an Istio or IstioRevision
+// may affect the version skew or the version tracked through spec.versionFrom.
+func (r *Reconciler) mapControlPlaneToReconcileRequest(ctx context.Context, _ client.Object) []reconcile.Request {
+	log := logf.FromContext(ctx)
+
+	ztunnelList := v1.ZTunnelList{}
+	if err := r.Client.List(ctx, &ztunnelList); err != nil {
+		log.Error(err, "failed to list ZTunnels")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(ztunnelList.Items))
+	for _, ztunnel := range ztunnelList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: ztunnel.Name}})
+	}
+	return requests
+}
+
+// TargetNotFoundError is returned when the Istio or IstioRevision referenced in spec.targetRef doesn't exist.
+// It wraps a validation error, so the reconciliation is only retried when an Istio or IstioRevision changes.
+type TargetNotFoundError struct {
+	err error
+}
+
+func NewTargetNotFoundError(message string) error {
+	return &TargetNotFoundError{err: reconciler.NewValidationError(message)}
+}
+
+func (e TargetNotFoundError) Error() string {
+	return e.err.Error()
+}
+
+func (e TargetNotFoundError) Unwrap() error {
+	return e.err
+}
+
+func IsTargetNotFoundError(err error) bool {
+	e := &TargetNotFoundError{}
+	return errors.As(err, &e)
+}
+
 func wrapEventHandler(logger logr.Logger, handler handler.EventHandler) handler.EventHandler {
-	return enqueuelogger.WrapIfNecessary(v1alpha1.ZTunnelKind, logger, handler)
+	return enqueuelogger.WrapIfNecessary(v1.ZTunnelKind, logger, handler)
 }
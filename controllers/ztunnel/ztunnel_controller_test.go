@@ -17,16 +17,24 @@ package ztunnel
 import (
 	"context"
 	"fmt"
+	"path"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
-	"github.com/istio-ecosystem/sail-operator/api/v1alpha1"
 	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/helm"
+	"github.com/istio-ecosystem/sail-operator/pkg/imageverification"
 	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
+	"github.com/istio-ecosystem/sail-operator/pkg/test/project"
 	"github.com/istio-ecosystem/sail-operator/pkg/test/util/supportedversion"
 	. "github.com/onsi/gomega"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"helm.sh/helm/v3/pkg/release"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -52,17 +60,17 @@ func TestValidate(t *testing.T) {
 
 	testCases := []struct {
 		name      string
-		ztunnel   *v1alpha1.ZTunnel
+		ztunnel   *v1.ZTunnel
 		objects   []client.Object
 		expectErr string
 	}{
 		{
 			name: "success",
-			ztunnel: &v1alpha1.ZTunnel{
+			ztunnel: &v1.ZTunnel{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "default",
 				},
-				Spec: v1alpha1.ZTunnelSpec{
+				Spec: v1.ZTunnelSpec{
 					Version:   supportedversion.Default,
 					Namespace: ztunnelNamespace,
 				},
@@ -72,24 +80,38 @@ func TestValidate(t *testing.T) {
 		},
 		{
 			name: "no version",
-			ztunnel: &v1alpha1.ZTunnel{
+			ztunnel: &v1.ZTunnel{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "default",
 				},
-				Spec: v1alpha1.ZTunnelSpec{
+				Spec: v1.ZTunnelSpec{
 					Namespace: ztunnelNamespace,
 				},
 			},
 			objects:   []client.Object{ns},
 			expectErr: "spec.version not set",
 		},
+		{
+			name: "no version, but tracking Istio version",
+			ztunnel: &v1.ZTunnel{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "default",
+				},
+				Spec: v1.ZTunnelSpec{
+					Namespace:   ztunnelNamespace,
+					VersionFrom: &v1.VersionSource{Istio: "default"},
+				},
+			},
+			objects:   []client.Object{ns},
+			expectErr: "",
+		},
 		{
 			name: "no namespace",
-			ztunnel: &v1alpha1.ZTunnel{
+			ztunnel: &v1.ZTunnel{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "default",
 				},
-				Spec: v1alpha1.ZTunnelSpec{
+				Spec: v1.ZTunnelSpec{
 					Version: supportedversion.Default,
 				},
 			},
@@ -98,11 +120,11 @@ func TestValidate(t *testing.T) {
 		},
 		{
 			name: "namespace not found",
-			ztunnel: &v1alpha1.ZTunnel{
+			ztunnel: &v1.ZTunnel{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "default",
 				},
-				Spec: v1alpha1.ZTunnelSpec{
+				Spec: v1.ZTunnelSpec{
 					Version:   supportedversion.Default,
 					Namespace: ztunnelNamespace,
 				},
@@ -112,11 +134,11 @@ func TestValidate(t *testing.T) {
 		},
 		{
 			name: "namespace is being deleted",
-			ztunnel: &v1alpha1.ZTunnel{
+			ztunnel: &v1.ZTunnel{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "default",
 				},
-				Spec: v1alpha1.ZTunnelSpec{
+				Spec: v1.ZTunnelSpec{
 					Version:   supportedversion.Default,
 					Namespace: ztunnelNamespace,
 				},
@@ -155,39 +177,39 @@ func TestValidate(t *testing.T) {
 func TestDeriveState(t *testing.T) {
 	testCases := []struct {
 		name                string
-		reconciledCondition v1alpha1.ZTunnelCondition
-		readyCondition      v1alpha1.ZTunnelCondition
-		expectedState       v1alpha1.ZTunnelConditionReason
+		reconciledCondition v1.ZTunnelCondition
+		readyCondition      v1.ZTunnelCondition
+		expectedState       v1.ZTunnelConditionReason
 	}{
 		{
 			name:                "healthy",
-			reconciledCondition: newCondition(v1alpha1.ZTunnelConditionReconciled, metav1.ConditionTrue, ""),
-			readyCondition:      newCondition(v1alpha1.ZTunnelConditionReady, metav1.ConditionTrue, ""),
-			expectedState:       v1alpha1.ZTunnelReasonHealthy,
+			reconciledCondition: newCondition(v1.ZTunnelConditionReconciled, metav1.ConditionTrue, ""),
+			readyCondition:      newCondition(v1.ZTunnelConditionReady, metav1.ConditionTrue, ""),
+			expectedState:       v1.ZTunnelReasonHealthy,
 		},
 		{
 			name:                "not reconciled",
-			reconciledCondition: newCondition(v1alpha1.ZTunnelConditionReconciled, metav1.ConditionFalse, v1alpha1.ZTunnelReasonReconcileError),
-			readyCondition:      newCondition(v1alpha1.ZTunnelConditionReady, metav1.ConditionTrue, ""),
-			expectedState:       v1alpha1.ZTunnelReasonReconcileError,
+			reconciledCondition: newCondition(v1.ZTunnelConditionReconciled, metav1.ConditionFalse, v1.ZTunnelReasonReconcileError),
+			readyCondition:      newCondition(v1.ZTunnelConditionReady, metav1.ConditionTrue, ""),
+			expectedState:       v1.ZTunnelReasonReconcileError,
 		},
 		{
 			name:                "not ready",
-			reconciledCondition: newCondition(v1alpha1.ZTunnelConditionReconciled, metav1.ConditionTrue, ""),
-			readyCondition:      newCondition(v1alpha1.ZTunnelConditionReady, metav1.ConditionFalse, v1alpha1.ZTunnelDaemonSetNotReady),
-			expectedState:       v1alpha1.ZTunnelDaemonSetNotReady,
+			reconciledCondition: newCondition(v1.ZTunnelConditionReconciled, metav1.ConditionTrue, ""),
+			readyCondition:      newCondition(v1.ZTunnelConditionReady, metav1.ConditionFalse, v1.ZTunnelDaemonSetNotReady),
+			expectedState:       v1.ZTunnelDaemonSetNotReady,
 		},
 		{
 			name:                "readiness unknown",
-			reconciledCondition: newCondition(v1alpha1.ZTunnelConditionReconciled, metav1.ConditionTrue, ""),
-			readyCondition:      newCondition(v1alpha1.ZTunnelConditionReady, metav1.ConditionUnknown, v1alpha1.ZTunnelReasonReadinessCheckFailed),
-			expectedState:       v1alpha1.ZTunnelReasonReadinessCheckFailed,
+			reconciledCondition: newCondition(v1.ZTunnelConditionReconciled, metav1.ConditionTrue, ""),
+			readyCondition:      newCondition(v1.ZTunnelConditionReady, metav1.ConditionUnknown, v1.ZTunnelReasonReadinessCheckFailed),
+			expectedState:       v1.ZTunnelReasonReadinessCheckFailed,
 		},
 		{
 			name:                "not reconciled nor ready",
-			reconciledCondition: newCondition(v1alpha1.ZTunnelConditionReconciled, metav1.ConditionFalse, v1alpha1.ZTunnelReasonReconcileError),
-			readyCondition:      newCondition(v1alpha1.ZTunnelConditionReady, metav1.ConditionFalse, v1alpha1.ZTunnelDaemonSetNotReady),
-			expectedState:       v1alpha1.ZTunnelReasonReconcileError, // reconcile reason takes precedence over ready reason
+			reconciledCondition: newCondition(v1.ZTunnelConditionReconciled, metav1.ConditionFalse, v1.ZTunnelReasonReconcileError),
+			readyCondition:      newCondition(v1.ZTunnelConditionReady, metav1.ConditionFalse, v1.ZTunnelDaemonSetNotReady),
+			expectedState:       v1.ZTunnelReasonReconcileError, // reconcile reason takes precedence over ready reason
 		},
 	}
 
@@ -200,8 +222,8 @@ func TestDeriveState(t *testing.T) {
 	}
 }
 
-func newCondition(condType v1alpha1.ZTunnelConditionType, status metav1.ConditionStatus, reason v1alpha1.ZTunnelConditionReason) v1alpha1.ZTunnelCondition {
-	return v1alpha1.ZTunnelCondition{
+func newCondition(condType v1.ZTunnelConditionType, status metav1.ConditionStatus, reason v1.ZTunnelConditionReason) v1.ZTunnelCondition {
+	return v1.ZTunnelCondition{
 		Type:   condType,
 		Status: status,
 		Reason: reason,
@@ -215,7 +237,7 @@ func TestDetermineReadyCondition(t *testing.T) {
 		name          string
 		clientObjects []client.Object
 		interceptors  interceptor.Funcs
-		expected      v1alpha1.ZTunnelCondition
+		expected      v1.ZTunnelCondition
 		expectErr     bool
 	}{
 		{
@@ -232,8 +254,8 @@ func TestDetermineReadyCondition(t *testing.T) {
 					},
 				},
 			},
-			expected: v1alpha1.ZTunnelCondition{
-				Type:   v1alpha1.ZTunnelConditionReady,
+			expected: v1.ZTunnelCondition{
+				Type:   v1.ZTunnelConditionReady,
 				Status: metav1.ConditionTrue,
 			},
 		},
@@ -251,10 +273,10 @@ func TestDetermineReadyCondition(t *testing.T) {
 					},
 				},
 			},
-			expected: v1alpha1.ZTunnelCondition{
-				Type:    v1alpha1.ZTunnelConditionReady,
+			expected: v1.ZTunnelCondition{
+				Type:    v1.ZTunnelConditionReady,
 				Status:  metav1.ConditionFalse,
-				Reason:  v1alpha1.ZTunnelDaemonSetNotReady,
+				Reason:  v1.ZTunnelDaemonSetNotReady,
 				Message: "not all ztunnel pods are ready",
 			},
 		},
@@ -272,20 +294,43 @@ func TestDetermineReadyCondition(t *testing.T) {
 					},
 				},
 			},
-			expected: v1alpha1.ZTunnelCondition{
-				Type:    v1alpha1.ZTunnelConditionReady,
+			expected: v1.ZTunnelCondition{
+				Type:    v1.ZTunnelConditionReady,
 				Status:  metav1.ConditionFalse,
-				Reason:  v1alpha1.ZTunnelDaemonSetNotReady,
+				Reason:  v1.ZTunnelDaemonSetNotReady,
 				Message: "no ztunnel pods are currently scheduled",
 			},
 		},
+		{
+			name: "ZTunnel upgrading",
+			clientObjects: []client.Object{
+				&appsv1.DaemonSet{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "ztunnel",
+						Namespace: ztunnelNamespace,
+					},
+					Status: appsv1.DaemonSetStatus{
+						CurrentNumberScheduled: 3,
+						DesiredNumberScheduled: 3,
+						UpdatedNumberScheduled: 1,
+						NumberReady:            3,
+					},
+				},
+			},
+			expected: v1.ZTunnelCondition{
+				Type:    v1.ZTunnelConditionReady,
+				Status:  metav1.ConditionFalse,
+				Reason:  v1.ZTunnelReasonUpgrading,
+				Message: "1 of 3 ztunnel pods have been updated",
+			},
+		},
 		{
 			name:          "ZTunnel daemonSet not found",
 			clientObjects: []client.Object{},
-			expected: v1alpha1.ZTunnelCondition{
-				Type:    v1alpha1.ZTunnelConditionReady,
+			expected: v1.ZTunnelCondition{
+				Type:    v1.ZTunnelConditionReady,
 				Status:  metav1.ConditionFalse,
-				Reason:  v1alpha1.ZTunnelDaemonSetNotReady,
+				Reason:  v1.ZTunnelDaemonSetNotReady,
 				Message: "ztunnel DaemonSet not found",
 			},
 		},
@@ -297,10 +342,10 @@ func TestDetermineReadyCondition(t *testing.T) {
 					return fmt.Errorf("simulated error")
 				},
 			},
-			expected: v1alpha1.ZTunnelCondition{
-				Type:    v1alpha1.ZTunnelConditionReady,
+			expected: v1.ZTunnelCondition{
+				Type:    v1.ZTunnelConditionReady,
 				Status:  metav1.ConditionUnknown,
-				Reason:  v1alpha1.ZTunnelReasonReadinessCheckFailed,
+				Reason:  v1.ZTunnelReasonReadinessCheckFailed,
 				Message: "failed to get readiness: simulated error",
 			},
 			expectErr: true,
@@ -315,11 +360,11 @@ func TestDetermineReadyCondition(t *testing.T) {
 
 			r := NewReconciler(cfg, cl, scheme.Scheme, nil)
 
-			ztunnel := &v1alpha1.ZTunnel{
+			ztunnel := &v1.ZTunnel{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "ztunnel",
 				},
-				Spec: v1alpha1.ZTunnelSpec{
+				Spec: v1.ZTunnelSpec{
 					Namespace: ztunnelNamespace,
 				},
 			}
@@ -344,7 +389,7 @@ func TestApplyImageDigests(t *testing.T) {
 	testCases := []struct {
 		name         string
 		config       config.OperatorConfig
-		input        *v1alpha1.ZTunnel
+		input        *v1.ZTunnel
 		expectValues *v1.ZTunnelValues
 	}{
 		{
@@ -352,8 +397,8 @@ func TestApplyImageDigests(t *testing.T) {
 			config: config.OperatorConfig{
 				ImageDigests: map[string]config.IstioImageConfig{},
 			},
-			input: &v1alpha1.ZTunnel{
-				Spec: v1alpha1.ZTunnelSpec{
+			input: &v1.ZTunnel{
+				Spec: v1.ZTunnelSpec{
 					Version: "v1.24.0",
 					Values: &v1.ZTunnelValues{
 						ZTunnel: &v1.ZTunnelConfig{
@@ -377,8 +422,8 @@ func TestApplyImageDigests(t *testing.T) {
 					},
 				},
 			},
-			input: &v1alpha1.ZTunnel{
-				Spec: v1alpha1.ZTunnelSpec{
+			input: &v1.ZTunnel{
+				Spec: v1.ZTunnelSpec{
 					Version: "v1.24.0",
 					Values:  &v1.ZTunnelValues{},
 				},
@@ -398,8 +443,8 @@ func TestApplyImageDigests(t *testing.T) {
 					},
 				},
 			},
-			input: &v1alpha1.ZTunnel{
-				Spec: v1alpha1.ZTunnelSpec{
+			input: &v1.ZTunnel{
+				Spec: v1.ZTunnelSpec{
 					Version: "v1.24.0",
 					Values: &v1.ZTunnelValues{
 						ZTunnel: &v1.ZTunnelConfig{
@@ -423,8 +468,8 @@ func TestApplyImageDigests(t *testing.T) {
 					},
 				},
 			},
-			input: &v1alpha1.ZTunnel{
-				Spec: v1alpha1.ZTunnelSpec{
+			input: &v1.ZTunnel{
+				Spec: v1.ZTunnelSpec{
 					Version: "v1.24.0",
 					Values: &v1.ZTunnelValues{
 						ZTunnel: &v1.ZTunnelConfig{
@@ -450,8 +495,8 @@ func TestApplyImageDigests(t *testing.T) {
 					},
 				},
 			},
-			input: &v1alpha1.ZTunnel{
-				Spec: v1alpha1.ZTunnelSpec{
+			input: &v1.ZTunnel{
+				Spec: v1.ZTunnelSpec{
 					Version: "v1.24.1",
 					Values: &v1.ZTunnelValues{
 						ZTunnel: &v1.ZTunnelConfig{
@@ -471,7 +516,7 @@ func TestApplyImageDigests(t *testing.T) {
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result := applyImageDigests(tc.input, tc.input.Spec.Values, tc.config)
+			result := applyImageDigests(tc.input.Spec.Version, tc.input.Spec.Values, tc.config)
 			if diff := cmp.Diff(tc.expectValues, result); diff != "" {
 				t.Errorf("unexpected merge result; diff (-expected, +actual):\n%v", diff)
 			}
@@ -483,16 +528,38 @@ func TestDetermineStatus(t *testing.T) {
 	cfg := newReconcilerTestConfig(t)
 
 	tests := []struct {
-		name         string
-		reconcileErr error
+		name           string
+		result         reconcileResult
+		reconcileErr   error
+		expectVersion  string
+		expectRevision string
 	}{
 		{
-			name:         "no error",
-			reconcileErr: nil,
+			name:           "no error",
+			result:         reconcileResult{version: "1.24.2", istioRevision: "default"},
+			expectVersion:  "1.24.2",
+			expectRevision: "default",
+		},
+		{
+			// the status reports the installed version, even if spec.version or the version of the Istio referenced
+			// in spec.versionFrom has changed since the chart was installed
+			name:           "installed version differs from spec",
+			result:         reconcileResult{version: "1.24.1", istioRevision: "default"},
+			expectVersion:  "1.24.1",
+			expectRevision: "default",
 		},
 		{
-			name:         "reconcile error",
-			reconcileErr: fmt.Errorf("some reconcile error"),
+			name:           "waiting for control plane",
+			result:         reconcileResult{pending: "waiting for the control plane to be upgraded"},
+			expectVersion:  "1.23.4",
+			expectRevision: "previous",
+		},
+		{
+			name:           "reconcile error",
+			result:         reconcileResult{pending: "waiting for the control plane to be upgraded"},
+			reconcileErr:   fmt.Errorf("some reconcile error"),
+			expectVersion:  "1.23.4",
+			expectRevision: "previous",
 		},
 	}
 
@@ -504,34 +571,397 @@ func TestDetermineStatus(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			g := NewWithT(t)
 
-			ztunnel := &v1alpha1.ZTunnel{
+			ztunnel := &v1.ZTunnel{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:       "ztunnel",
 					Generation: 123,
 				},
+				Spec:   v1.ZTunnelSpec{Version: "1.24.2"},
+				Status: v1.ZTunnelStatus{Version: "1.23.4", IstioRevision: "previous"},
 			}
 
-			status, err := r.determineStatus(ctx, ztunnel, tt.reconcileErr)
+			status, err := r.determineStatus(ctx, ztunnel, tt.result, tt.reconcileErr)
 			g.Expect(err).ToNot(HaveOccurred())
 
 			g.Expect(status.ObservedGeneration).To(Equal(ztunnel.Generation))
 
-			reconciledCondition := r.determineReconciledCondition(tt.reconcileErr)
+			g.Expect(status.Version).To(Equal(tt.expectVersion))
+			g.Expect(status.IstioRevision).To(Equal(tt.expectRevision))
+
+			reconciledCondition := r.determineReconciledCondition(tt.reconcileErr, tt.result.pending)
 			readyCondition, err := r.determineReadyCondition(ctx, ztunnel)
 			g.Expect(err).ToNot(HaveOccurred())
 
 			g.Expect(status.State).To(Equal(deriveState(reconciledCondition, readyCondition)))
-			g.Expect(normalize(status.GetCondition(v1alpha1.ZTunnelConditionReconciled))).To(Equal(normalize(reconciledCondition)))
-			g.Expect(normalize(status.GetCondition(v1alpha1.ZTunnelConditionReady))).To(Equal(normalize(readyCondition)))
+			g.Expect(normalize(status.GetCondition(v1.ZTunnelConditionReconciled))).To(Equal(normalize(reconciledCondition)))
+			g.Expect(normalize(status.GetCondition(v1.ZTunnelConditionReady))).To(Equal(normalize(readyCondition)))
 		})
 	}
 }
 
-func normalize(condition v1alpha1.ZTunnelCondition) v1alpha1.ZTunnelCondition {
+func newRevision(name, version string, inUse, ready metav1.ConditionStatus) *v1.IstioRevision {
+	return &v1.IstioRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       v1.IstioRevisionSpec{Version: version},
+		Status: v1.IstioRevisionStatus{
+			Conditions: []v1.IstioRevisionCondition{
+				{Type: v1.IstioRevisionConditionInUse, Status: inUse},
+				{Type: v1.IstioRevisionConditionReady, Status: ready},
+			},
+		},
+	}
+}
+
+func TestDetermineVersion(t *testing.T) {
+	istio := &v1.Istio{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec:       v1.IstioSpec{Version: "v1.24.2"},
+		Status:     v1.IstioStatus{ActiveRevisionName: "default"},
+	}
+
+	testCases := []struct {
+		name             string
+		spec             v1.ZTunnelSpec
+		installedVersion string
+		objects          []client.Object
+		expectedVersion  string
+		expectPending    bool
+	}{
+		{
+			name:            "spec.version",
+			spec:            v1.ZTunnelSpec{Version: "v1.24.1"},
+			expectedVersion: "v1.24.1",
+		},
+		{
+			name:            "tracks version of upgraded control plane",
+			spec:            v1.ZTunnelSpec{Version: "v1.24.1", VersionFrom: &v1.VersionSource{Istio: "default"}},
+			objects:         []client.Object{istio, newRevision("default", "v1.24.2", metav1.ConditionTrue, metav1.ConditionTrue)},
+			expectedVersion: "v1.24.2",
+		},
+		{
+			name:             "keeps installed version while control plane is upgrading",
+			spec:             v1.ZTunnelSpec{VersionFrom: &v1.VersionSource{Istio: "default"}},
+			installedVersion: "v1.24.1",
+			objects:          []client.Object{istio, newRevision("default", "v1.24.1", metav1.ConditionTrue, metav1.ConditionTrue)},
+			expectedVersion:  "v1.24.1",
+			expectPending:    true,
+		},
+		{
+			name:          "nothing to install while control plane isn't ready",
+			spec:          v1.ZTunnelSpec{VersionFrom: &v1.VersionSource{Istio: "default"}},
+			objects:       []client.Object{istio, newRevision("default", "v1.24.2", metav1.ConditionTrue, metav1.ConditionFalse)},
+			expectPending: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(tc.objects...).Build()
+			r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme, nil)
+
+			ztunnel := &v1.ZTunnel{
+				ObjectMeta: metav1.ObjectMeta{Name: "default"},
+				Spec:       tc.spec,
+				Status:     v1.ZTunnelStatus{Version: tc.installedVersion},
+			}
+			version, pending, err := r.determineVersion(context.TODO(), ztunnel)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(version).To(Equal(tc.expectedVersion))
+			g.Expect(pending != "").To(Equal(tc.expectPending))
+
+			reconciledCondition := r.determineReconciledCondition(nil, pending)
+			if tc.expectPending {
+				g.Expect(reconciledCondition.Status).To(Equal(metav1.ConditionFalse))
+				g.Expect(reconciledCondition.Reason).To(Equal(v1.ZTunnelReasonWaitingForControlPlane))
+			} else {
+				g.Expect(reconciledCondition.Status).To(Equal(metav1.ConditionTrue))
+			}
+		})
+	}
+}
+
+func TestVersionSkew(t *testing.T) {
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+		newRevision("old", "v1.22.8", metav1.ConditionTrue, metav1.ConditionTrue),
+		newRevision("new", "v1.23.4", metav1.ConditionTrue, metav1.ConditionTrue),
+		newRevision("unused", "v1.21.6", metav1.ConditionFalse, metav1.ConditionTrue),
+	).Build()
+
+	testCases := []struct {
+		name              string
+		policy            v1.VersionSkewPolicy
+		version           string
+		installedVersion  string
+		expectBlocked     bool
+		expectedSkewState metav1.ConditionStatus
+	}{
+		{
+			name:              "supported skew",
+			policy:            v1.VersionSkewPolicyBlock,
+			version:           "v1.23.3",
+			installedVersion:  "v1.23.3",
+			expectedSkewState: metav1.ConditionTrue,
+		},
+		{
+			name:              "unsupported skew with Warn policy",
+			policy:            v1.VersionSkewPolicyWarn,
+			version:           "v1.24.2",
+			installedVersion:  "v1.24.2",
+			expectedSkewState: metav1.ConditionFalse,
+		},
+		{
+			name:              "upgrade to unsupported skew with Block policy",
+			policy:            v1.VersionSkewPolicyBlock,
+			version:           "v1.24.2",
+			installedVersion:  "v1.23.3",
+			expectBlocked:     true,
+			expectedSkewState: metav1.ConditionTrue,
+		},
+		{
+			name:              "installed version with unsupported skew and Block policy",
+			policy:            v1.VersionSkewPolicyBlock,
+			version:           "v1.24.2",
+			installedVersion:  "v1.24.2",
+			expectedSkewState: metav1.ConditionFalse,
+		},
+		{
+			name:              "not yet installed",
+			policy:            v1.VersionSkewPolicyBlock,
+			version:           "v1.23.3",
+			expectedSkewState: metav1.ConditionUnknown,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme, nil)
+
+			ztunnel := &v1.ZTunnel{
+				ObjectMeta: metav1.ObjectMeta{Name: "default"},
+				Spec:       v1.ZTunnelSpec{Version: tc.version, VersionSkewPolicy: tc.policy},
+				Status:     v1.ZTunnelStatus{Version: tc.installedVersion},
+			}
+			err := r.validateVersionSkew(context.TODO(), ztunnel, tc.version)
+			if tc.expectBlocked {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("IstioRevision old with version v1.22.8"))
+				g.Expect(err.Error()).ToNot(ContainSubstring("IstioRevision new"))
+
+				reconciledCondition := r.determineReconciledCondition(err, "")
+				g.Expect(reconciledCondition.Status).To(Equal(metav1.ConditionFalse))
+				g.Expect(reconciledCondition.Reason).To(Equal(v1.ZTunnelReasonUnsupportedVersionSkew))
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+
+			skewCondition, err := r.determineVersionSkewCondition(context.TODO(), tc.installedVersion)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(skewCondition.Status).To(Equal(tc.expectedSkewState))
+			if tc.expectedSkewState == metav1.ConditionFalse {
+				g.Expect(skewCondition.Reason).To(Equal(v1.ZTunnelReasonUnsupportedVersionSkew))
+			}
+		})
+	}
+}
+
+func TestGetTargetRevision(t *testing.T) {
+	istio := &v1.Istio{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Status:     v1.IstioStatus{ActiveRevisionName: "default-v1-24-2"},
+	}
+	rev := &v1.IstioRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "default-v1-24-2"},
+		Spec: v1.IstioRevisionSpec{
+			Namespace: "istio-system",
+			Values:    &v1.Values{Revision: ptr.Of("default-v1-24-2")},
+		},
+	}
+
+	testCases := []struct {
+		name             string
+		targetRef        *v1.ZTunnelTargetReference
+		objects          []client.Object
+		expectedRevision string
+		expectNotFound   bool
+	}{
+		{
+			name: "no targetRef",
+		},
+		{
+			name:             "Istio",
+			targetRef:        &v1.ZTunnelTargetReference{Kind: v1.IstioKind, Name: "default"},
+			objects:          []client.Object{istio, rev},
+			expectedRevision: "default-v1-24-2",
+		},
+		{
+			name:             "IstioRevision",
+			targetRef:        &v1.ZTunnelTargetReference{Kind: v1.IstioRevisionKind, Name: "default-v1-24-2"},
+			objects:          []client.Object{rev},
+			expectedRevision: "default-v1-24-2",
+		},
+		{
+			name:           "Istio not found",
+			targetRef:      &v1.ZTunnelTargetReference{Kind: v1.IstioKind, Name: "default"},
+			expectNotFound: true,
+		},
+		{
+			name:           "active revision of Istio not found",
+			targetRef:      &v1.ZTunnelTargetReference{Kind: v1.IstioKind, Name: "default"},
+			objects:        []client.Object{istio},
+			expectNotFound: true,
+		},
+		{
+			name:           "IstioRevision not found",
+			targetRef:      &v1.ZTunnelTargetReference{Kind: v1.IstioRevisionKind, Name: "default-v1-24-2"},
+			expectNotFound: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(tc.objects...).Build()
+			r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme, nil)
+
+			ztunnel := &v1.ZTunnel{
+				ObjectMeta: metav1.ObjectMeta{Name: "default"},
+				Spec:       v1.ZTunnelSpec{TargetRef: tc.targetRef},
+			}
+			result, err := r.getTargetRevision(context.TODO(), ztunnel)
+			if tc.expectNotFound {
+				g.Expect(IsTargetNotFoundError(err)).To(BeTrue())
+				g.Expect(r.determineReconciledCondition(err, "").Reason).To(Equal(v1.ZTunnelReasonTargetNotFound))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			if tc.expectedRevision == "" {
+				g.Expect(result).To(BeNil())
+			} else {
+				g.Expect(result.Name).To(Equal(tc.expectedRevision))
+			}
+		})
+	}
+}
+
+func TestApplyTargetRevision(t *testing.T) {
+	testCases := []struct {
+		name     string
+		values   *v1.ZTunnelConfig
+		rev      *v1.IstioRevision
+		expected *v1.ZTunnelConfig
+	}{
+		{
+			name:   "default revision",
+			values: &v1.ZTunnelConfig{},
+			rev: &v1.IstioRevision{
+				Spec: v1.IstioRevisionSpec{Namespace: "istio-system"},
+			},
+			expected: &v1.ZTunnelConfig{
+				Revision:   ptr.Of(""),
+				XdsAddress: ptr.Of("istiod.istio-system.svc:15012"),
+				CaAddress:  ptr.Of("istiod.istio-system.svc:15012"),
+			},
+		},
+		{
+			name:   "named revision",
+			values: &v1.ZTunnelConfig{},
+			rev: &v1.IstioRevision{
+				Spec: v1.IstioRevisionSpec{Namespace: "istio-system", Values: &v1.Values{Revision: ptr.Of("canary")}},
+			},
+			expected: &v1.ZTunnelConfig{
+				Revision:   ptr.Of("canary"),
+				XdsAddress: ptr.Of("istiod-canary.istio-system.svc:15012"),
+				CaAddress:  ptr.Of("istiod-canary.istio-system.svc:15012"),
+			},
+		},
+		{
+			name:   "user-configured values are preserved",
+			values: &v1.ZTunnelConfig{CaAddress: ptr.Of("my-ca:15012")},
+			rev: &v1.IstioRevision{
+				Spec: v1.IstioRevisionSpec{Namespace: "istio-system", Values: &v1.Values{Revision: ptr.Of("canary")}},
+			},
+			expected: &v1.ZTunnelConfig{
+				Revision:   ptr.Of("canary"),
+				XdsAddress: ptr.Of("istiod-canary.istio-system.svc:15012"),
+				CaAddress:  ptr.Of("my-ca:15012"),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			applyTargetRevision(tc.values, tc.rev)
+			g.Expect(tc.values).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestDetermineDaemonSetStatus(t *testing.T) {
+	g := NewWithT(t)
+
+	newPod := func(name, version string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: ztunnelNamespace,
+				Labels:    map[string]string{"app": "ztunnel", "app.kubernetes.io/version": version},
+			},
+		}
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "ztunnel", Namespace: ztunnelNamespace},
+			Spec: appsv1.DaemonSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "ztunnel"}},
+			},
+			Status: appsv1.DaemonSetStatus{
+				DesiredNumberScheduled: 3,
+				UpdatedNumberScheduled: 2,
+				NumberReady:            3,
+			},
+		},
+		newPod("ztunnel-a", "1.24.1"),
+		newPod("ztunnel-b", "1.24.2"),
+		newPod("ztunnel-c", "1.24.2"),
+	).Build()
+	r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme, nil)
+
+	ztunnel := &v1.ZTunnel{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec:       v1.ZTunnelSpec{Namespace: ztunnelNamespace},
+	}
+	status, err := r.determineDaemonSetStatus(context.TODO(), ztunnel)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(status).To(Equal(&v1.ZTunnelDaemonSetStatus{
+		DesiredNumberScheduled: 3,
+		UpdatedNumberScheduled: 2,
+		NumberReady:            3,
+		Versions:               []string{"1.24.1", "1.24.2"},
+	}))
+
+	// no status is reported when the DaemonSet doesn't exist
+	ztunnel.Spec.Namespace = "other"
+	status, err = r.determineDaemonSetStatus(context.TODO(), ztunnel)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(status).To(BeNil())
+}
+
+func normalize(condition v1.ZTunnelCondition) v1.ZTunnelCondition {
 	condition.LastTransitionTime = metav1.Time{}
 	return condition
 }
 
+func TestDetermineReconciledConditionImageVerificationFailed(t *testing.T) {
+	g := NewWithT(t)
+	r := NewReconciler(newReconcilerTestConfig(t), fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(), scheme.Scheme, nil)
+
+	image := "gcr.io/istio-release/ztunnel:1.24.2"
+	err := fmt.Errorf("failed to install/update Helm chart: %w", imageverification.NewVerificationError(image, "digest sha256:1234 is not allowed"))
+	c := r.determineReconciledCondition(err, "")
+	g.Expect(c.Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(c.Reason).To(Equal(v1.ZTunnelReasonImageVerificationFailed))
+	g.Expect(c.Message).To(ContainSubstring(image))
+}
+
 func newReconcilerTestConfig(t *testing.T) config.ReconcilerConfig {
 	return config.ReconcilerConfig{
 		ResourceDirectory: t.TempDir(),
@@ -539,3 +969,70 @@ func newReconcilerTestConfig(t *testing.T) config.ReconcilerConfig {
 		DefaultProfile:    "",
 	}
 }
+
+func TestZTunnelSpecFromRelease(t *testing.T) {
+	rel := &release.Release{
+		Name:  "ztunnel",
+		Chart: &chart.Chart{Metadata: &chart.Metadata{Name: "ztunnel", Version: "1.24.1"}},
+		Config: map[string]any{
+			"hub":    "quay.io/custom",
+			"global": map[string]any{"platform": "k3d"},
+		},
+	}
+
+	t.Run("moves values under ztunnel", func(t *testing.T) {
+		g := NewWithT(t)
+		spec, err := ztunnelSpecFromRelease(v1.ZTunnelSpec{Version: "v1.24.2", Namespace: ztunnelNamespace}, rel)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(spec.Version).To(Equal("v1.24.1"))
+		g.Expect(spec.Namespace).To(Equal(ztunnelNamespace))
+		g.Expect(spec.Values).To(Equal(&v1.ZTunnelValues{
+			ZTunnel: &v1.ZTunnelConfig{Hub: ptr.Of("quay.io/custom")},
+			Global:  &v1.ZTunnelGlobalConfig{Platform: ptr.Of("k3d")},
+		}))
+	})
+
+	t.Run("keeps values in spec", func(t *testing.T) {
+		g := NewWithT(t)
+		values := &v1.ZTunnelValues{ZTunnel: &v1.ZTunnelConfig{Tag: ptr.Of("1.24.1")}}
+		spec, err := ztunnelSpecFromRelease(v1.ZTunnelSpec{Version: "v1.24.2", Values: values}, rel)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(spec.Version).To(Equal("v1.24.1"))
+		g.Expect(spec.Values).To(Equal(values))
+	})
+
+	t.Run("no chart version", func(t *testing.T) {
+		g := NewWithT(t)
+		_, err := ztunnelSpecFromRelease(v1.ZTunnelSpec{}, &release.Release{Name: "ztunnel"})
+		g.Expect(err).To(HaveOccurred())
+	})
+}
+
+// TestChartAppliesZTunnelValues verifies that the ztunnel chart of every supported version reads the values nested
+// under values.ztunnel, which is where the ZTunnel resource puts them.
+func TestChartAppliesZTunnelValues(t *testing.T) {
+	values := &v1.ZTunnelValues{
+		ZTunnel: &v1.ZTunnelConfig{
+			PodLabels: map[string]string{"custom-label": "custom-value"},
+			LogLevel:  ptr.Of("debug"),
+		},
+	}
+
+	for _, version := range supportedversion.List {
+		t.Run(version.Name, func(t *testing.T) {
+			g := NewWithT(t)
+			chrt, err := loader.Load(path.Join(project.RootDir, "resources", version.Name, "charts", ztunnelChart))
+			g.Expect(err).ToNot(HaveOccurred())
+
+			renderValues, err := chartutil.ToRenderValues(chrt, helm.FromValues(values),
+				chartutil.ReleaseOptions{Name: ztunnelChart, Namespace: ztunnelNamespace}, nil)
+			g.Expect(err).ToNot(HaveOccurred())
+			manifests, err := engine.Render(chrt, renderValues)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			daemonSet := manifests[ztunnelChart+"/templates/daemonset.yaml"]
+			g.Expect(daemonSet).To(ContainSubstring("custom-label: custom-value"))
+			g.Expect(daemonSet).To(ContainSubstring("debug"))
+		})
+	}
+}
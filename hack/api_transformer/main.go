@@ -53,8 +53,13 @@ type Config struct {
 }
 
 type InputFile struct {
-	Module          string           `yaml:"module"`
-	Path            string           `yaml:"path"`
+	Module string `yaml:"module"`
+	Path   string `yaml:"path"`
+	// Chart is the directory of a Helm chart whose values.yaml is converted into the type TypeName (with the
+	// doc comment Comments) before the transformations are applied. Module and Path are ignored if Chart is set.
+	Chart           string           `yaml:"chart"`
+	TypeName        string           `yaml:"typeName"`
+	Comments        []string         `yaml:"comments"`
 	Transformations *Transformations `yaml:"transformations"`
 }
 
@@ -88,6 +93,7 @@ var config *Config
 type FileTransformer struct {
 	FileSet         *token.FileSet
 	InputFile       string
+	Source          []byte // if nil, the source is read from InputFile
 	Transformations *Transformations
 	Package         string
 }
@@ -106,10 +112,19 @@ func main() {
 	for _, inputFile := range config.InputFiles {
 		fileTransformer := FileTransformer{
 			FileSet:         fset,
-			InputFile:       getFilePath(inputFile.Module, inputFile.Path),
 			Transformations: merge(inputFile.Transformations, config.GlobalTransformations),
 			Package:         config.Package,
 		}
+		if inputFile.Chart != "" {
+			fileTransformer.InputFile = filepath.Join(inputFile.Chart, "values.yaml")
+			source, err := fileTransformer.chartValuesToGo(inputFile.TypeName, inputFile.Comments)
+			if err != nil {
+				panic(err)
+			}
+			fileTransformer.Source = source
+		} else {
+			fileTransformer.InputFile = getFilePath(inputFile.Module, inputFile.Path)
+		}
 		file, err := fileTransformer.processFile()
 		if err != nil {
 			panic(err)
@@ -194,7 +209,11 @@ func goFmt(fset *token.FileSet, file *ast.File) string {
 
 func (t *FileTransformer) processFile() (*ast.File, error) {
 	// Parse the Go source file
-	file, err := parser.ParseFile(t.FileSet, t.InputFile, nil, parser.ParseComments)
+	var src any
+	if t.Source != nil {
+		src = t.Source
+	}
+	file, err := parser.ParseFile(t.FileSet, t.InputFile, src, parser.ParseComments)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing file: %w", err)
 	}
@@ -287,7 +306,7 @@ func (t *FileTransformer) processFile() (*ast.File, error) {
 					typeSpec.Name.Name = newName
 				}
 
-				if ct, found := t.getCopyTransform(structName); found {
+				for _, ct := range t.getCopyTransforms(structName) {
 					structCopy := &ast.StructType{
 						Fields: &ast.FieldList{},
 					}
@@ -329,6 +348,117 @@ func (t *FileTransformer) processFile() (*ast.File, error) {
 	return file, nil
 }
 
+// chartValuesToGo converts the default values in the chart's values.yaml into Go source code declaring the struct
+// typeName. Nested objects become nested structs, unless the field's type is set in replaceFieldTypes. The type of
+// all other fields is derived from their default value, and the YAML comments become the fields' doc comments.
+func (t *FileTransformer) chartValuesToGo(typeName string, comments []string) ([]byte, error) {
+	data, err := os.ReadFile(t.InputFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading chart values: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing chart values: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("chart values in %s are not an object", t.InputFile)
+	}
+	values := doc.Content[0]
+	// charts that support profiles keep their defaults under _internal_defaults_do_not_set
+	if defaults := getMappingValue(values, "_internal_defaults_do_not_set"); defaults != nil {
+		values = defaults
+	}
+
+	var buf strings.Builder
+	buf.WriteString("package " + t.Package + "\n")
+	if err := t.writeValuesStruct(&buf, typeName, comments, values); err != nil {
+		return nil, err
+	}
+	return []byte(buf.String()), nil
+}
+
+func (t *FileTransformer) writeValuesStruct(buf *strings.Builder, typeName string, comments []string, values *yaml.Node) error {
+	type nestedStruct struct {
+		name   string
+		values *yaml.Node
+	}
+	var nestedStructs []nestedStruct
+
+	buf.WriteString("\n")
+	for _, c := range comments {
+		buf.WriteString(c + "\n")
+	}
+	buf.WriteString("type " + typeName + " struct {\n")
+	for i := 0; i+1 < len(values.Content); i += 2 {
+		key, value := values.Content[i], values.Content[i+1]
+		if !token.IsIdentifier(key.Value) {
+			return fmt.Errorf("cannot derive field name from key %s.%s; set its type in replaceFieldTypes", typeName, key.Value)
+		}
+		fieldName := strings.ToUpper(key.Value[:1]) + key.Value[1:]
+		fieldType := getMapValue(typeName, fieldName, t.Transformations.ReplaceFieldTypes)
+		if fieldType == "" {
+			fieldType = getValueType(value)
+		}
+		if fieldType == "[]" {
+			return fmt.Errorf("cannot derive the type of list %s.%s; set its type in replaceFieldTypes", typeName, key.Value)
+		}
+		if fieldType == "" {
+			fieldType = "*" + typeName + fieldName
+			nestedStructs = append(nestedStructs, nestedStruct{name: typeName + fieldName, values: value})
+		}
+		for _, line := range strings.Split(key.HeadComment, "\n") {
+			if line != "" {
+				buf.WriteString("\t//" + strings.TrimPrefix(line, "#") + "\n")
+			}
+		}
+		buf.WriteString(fmt.Sprintf("\t%s %s `json:\"%s,omitempty\"`\n", fieldName, fieldType, key.Value))
+	}
+	buf.WriteString("}\n")
+
+	for _, nested := range nestedStructs {
+		if err := t.writeValuesStruct(buf, nested.name, nil, nested.values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getValueType returns the Go type of the given default value, or an empty string if the value is an object with
+// fields, which needs a struct of its own. Lists of such objects are returned as "[]".
+func getValueType(value *yaml.Node) string {
+	switch value.Kind {
+	case yaml.MappingNode:
+		if len(value.Content) == 0 {
+			return "map[string]string"
+		}
+		return ""
+	case yaml.SequenceNode:
+		if len(value.Content) == 0 {
+			return "[]string"
+		}
+		return "[]" + getValueType(value.Content[0])
+	case yaml.ScalarNode:
+		switch value.Tag {
+		case "!!bool":
+			return "bool"
+		case "!!int":
+			return "int64"
+		case "!!float":
+			return "float64"
+		}
+	}
+	return "string"
+}
+
+func getMappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
 // getFilePath finds the file path of the given module and file in the go.mod cache.
 func getFilePath(module, file string) string {
 	goModPath := "go.mod"
@@ -820,13 +950,14 @@ func matches(parent string, child string, list []string) bool {
 	return false
 }
 
-func (t *FileTransformer) getCopyTransform(typeName string) (CopyTransform, bool) {
+func (t *FileTransformer) getCopyTransforms(typeName string) []CopyTransform {
+	var transforms []CopyTransform
 	for _, ct := range t.Transformations.CopyTypes {
 		if ct.From == typeName {
-			return ct, true
+			transforms = append(transforms, ct)
 		}
 	}
-	return CopyTransform{}, false
+	return transforms
 }
 
 func (t *FileTransformer) getTypeRename(typeName string) string {
@@ -96,6 +96,14 @@ function patchIstioCharts() {
 
   # remove CRDs from istiod-remote chart, since they are installed by OLM, not by the operator
   rm -f "${CHARTS_DIR}/istiod-remote/templates/crd-all.gen.yaml"
+
+  # copy values.ztunnel to the top level, like the istio-cni chart does with values.cni, so that the ZTunnel
+  # resource can pass its values under the ztunnel key
+  cat > "${CHARTS_DIR}/ztunnel/templates/zzy_descope_legacy.yaml" <<'EOF'
+{{/* Copy anything under `.ztunnel` to `.`, to avoid the need to specify a redundant prefix.
+Due to the file naming, this always happens after zzz_profile.yaml */}}
+{{- $_ := mustMergeOverwrite $.Values (index $.Values "ztunnel") }}
+EOF
 }
 
 function convertIstioProfiles() {
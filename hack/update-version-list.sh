@@ -45,7 +45,7 @@ function updateVersionsInIstioTypeComment() {
       -e "/\+sail:version/,/Version string/ s/(\/\/ \+kubebuilder:default=)(.*)/\1$defaultVersion/g" \
       -e "/\+sail:version/,/Version string/ s/(\/\/ \Must be one of:)(.*)/\1 $ztunnelversions./g" \
       -e "s/(\+kubebuilder:default=.*version: \")[^\"]*\"/\1$defaultVersion\"/g" \
-      api/v1alpha1/ztunnel_types.go
+      api/v1/ztunnel_types.go api/v1alpha1/ztunnel_types.go
 }
 
 function updateVersionsInCSVDescription() {
@@ -15,15 +15,109 @@
 package config
 
 import (
+	"slices"
 	"strings"
 
 	"github.com/magiconair/properties"
 )
 
-var Config = OperatorConfig{}
+const (
+	// FeatureHelmReleaseAdoption allows Istio, IstioCNI and ZTunnel resources to adopt existing Helm releases
+	// through the sailoperator.io/adopt-helm-release annotation. Enabled by default.
+	FeatureHelmReleaseAdoption = "HelmReleaseAdoption"
+)
+
+// defaultFeatureGates lists all known feature gates and whether they're enabled when not configured
+var defaultFeatureGates = map[string]bool{
+	FeatureHelmReleaseAdoption: true,
+}
 
+// OperatorConfig is the operator-wide configuration. The image digests are read from the properties file, which is
+// reloaded when it changes; the SailOperatorConfig resource can override them and set the remaining fields.
 type OperatorConfig struct {
 	ImageDigests map[string]IstioImageConfig `properties:"images"`
+
+	// DefaultProfile overrides the profile that is applied before the user-selected profile, if set
+	DefaultProfile string `properties:"-"`
+
+	// Platform overrides the platform detected at startup, if set
+	Platform Platform `properties:"-"`
+
+	// ProbeDefaults are applied to the sidecar proxy values of every Istio that doesn't set them
+	ProbeDefaults ProbeDefaults `properties:"-"`
+
+	// WatchedNamespaces are the namespaces that components may be installed into; empty means all namespaces
+	WatchedNamespaces []string `properties:"-"`
+
+	// FeatureGates enables or disables the features listed in defaultFeatureGates
+	FeatureGates map[string]bool `properties:"-"`
+
+	// RegistryRewrites are applied in order to every image in the rendered charts
+	RegistryRewrites []RegistryRewrite `properties:"-"`
+
+	// ImageVerification, if set, restricts the images that the operator deploys
+	ImageVerification *ImageVerificationPolicy `properties:"-"`
+}
+
+// ImageVerificationPolicy defines the images that the operator may deploy. An image is allowed if its digest is
+// listed in AllowedDigests or if it has a cosign signature that can be verified with PublicKey.
+type ImageVerificationPolicy struct {
+	AllowedDigests []string
+	// PublicKey is the PEM-encoded public key of the cosign signatures
+	PublicKey string
+}
+
+// RegistryRewrite replaces the Source prefix of an image with the Mirror prefix.
+type RegistryRewrite struct {
+	Source string
+	Mirror string
+}
+
+// RewriteImage applies the first rule whose source matches the repository of the image. A source matches if it
+// equals the repository or is followed by a slash in it, so docker.io/istio matches docker.io/istio/proxyv2 but
+// not docker.io/istio-testing/proxyv2. The tag or digest of the image is preserved. RewriteImage returns the image
+// unchanged if no rule matches.
+func RewriteImage(rules []RegistryRewrite, image string) string {
+	for _, rule := range rules {
+		source := strings.TrimSuffix(rule.Source, "/")
+		if source == "" {
+			continue
+		}
+		rest, found := strings.CutPrefix(image, source)
+		if !found {
+			continue
+		}
+		if rest == "" || rest[0] == '/' || rest[0] == '@' || rest[0] == ':' && !strings.Contains(rest, "/") {
+			return strings.TrimSuffix(rule.Mirror, "/") + rest
+		}
+	}
+	return image
+}
+
+// ProbeDefaults holds the default readiness probe settings of the sidecar proxies.
+type ProbeDefaults struct {
+	ReadinessInitialDelaySeconds *uint32
+	ReadinessPeriodSeconds       *uint32
+	ReadinessFailureThreshold    *uint32
+}
+
+// IsFeatureEnabled returns whether the feature gate with the given name is enabled.
+func (c OperatorConfig) IsFeatureEnabled(name string) bool {
+	if enabled, found := c.FeatureGates[name]; found {
+		return enabled
+	}
+	return defaultFeatureGates[name]
+}
+
+// IsNamespaceWatched returns whether components may be installed into the given namespace.
+func (c OperatorConfig) IsNamespaceWatched(namespace string) bool {
+	return len(c.WatchedNamespaces) == 0 || slices.Contains(c.WatchedNamespaces, namespace)
+}
+
+// IsKnownFeatureGate returns whether the operator supports a feature gate with the given name.
+func IsKnownFeatureGate(name string) bool {
+	_, found := defaultFeatureGates[name]
+	return found
 }
 
 type IstioImageConfig struct {
@@ -37,27 +131,59 @@ type ReconcilerConfig struct {
 	ResourceDirectory string
 	Platform          Platform
 	DefaultProfile    string
+	OperatorConfig    *Provider
+}
+
+// ActivePlatform returns the platform set in the operator configuration or, if none is set, the detected platform.
+func (c ReconcilerConfig) ActivePlatform() Platform {
+	if platform := c.OperatorConfig.Get().Platform; platform != PlatformUndefined {
+		return platform
+	}
+	return c.Platform
+}
+
+// ActiveDefaultProfile returns the default profile set in the operator configuration. If none is set, it returns
+// the default profile for the platform set in the operator configuration or, failing that, the startup default.
+func (c ReconcilerConfig) ActiveDefaultProfile() string {
+	cfg := c.OperatorConfig.Get()
+	if cfg.DefaultProfile != "" {
+		return cfg.DefaultProfile
+	}
+	if cfg.Platform != PlatformUndefined {
+		return DefaultProfileFor(cfg.Platform)
+	}
+	return c.DefaultProfile
+}
+
+// DefaultProfileFor returns the profile that is applied on the given platform before the user-selected profile.
+func DefaultProfileFor(platform Platform) string {
+	if platform == PlatformOpenShift {
+		return "openshift"
+	}
+	return "default"
 }
 
-func Read(configFile string) error {
+// Read reads the image digests from the properties file.
+func Read(configFile string) (OperatorConfig, error) {
 	p, err := properties.LoadFile(configFile, properties.UTF8)
 	if err != nil {
-		return err
+		return OperatorConfig{}, err
 	}
 	// remove quotes
 	for _, key := range p.Keys() {
 		val, _ := p.Get(key)
 		_, _, _ = p.Set(key, strings.Trim(val, `"`))
 	}
-	err = p.Decode(&Config)
+	cfg := OperatorConfig{}
+	err = p.Decode(&cfg)
 	if err != nil {
-		return err
+		return OperatorConfig{}, err
 	}
 	// replace "_" in versions with "." (e.g. v1_20_0 => v1.20.0)
-	newImageDigests := make(map[string]IstioImageConfig, len(Config.ImageDigests))
-	for k, v := range Config.ImageDigests {
+	newImageDigests := make(map[string]IstioImageConfig, len(cfg.ImageDigests))
+	for k, v := range cfg.ImageDigests {
 		newImageDigests[strings.Replace(k, "_", ".", -1)] = v
 	}
-	Config.ImageDigests = newImageDigests
-	return nil
+	cfg.ImageDigests = newImageDigests
+	return cfg, nil
 }
@@ -106,7 +106,7 @@ images.v1_20_0.ztunnel=ztunnel-test
 			if err != nil {
 				t.Fatal(err)
 			}
-			err = Read(file.Name())
+			cfg, err := Read(file.Name())
 			if !tc.success {
 				if err != nil {
 					return
@@ -115,9 +115,37 @@ images.v1_20_0.ztunnel=ztunnel-test
 			} else if err != nil {
 				t.Fatal("expected no error but got:", err)
 			}
-			if diff := cmp.Diff(Config, tc.expectedConfig); diff != "" {
+			if diff := cmp.Diff(cfg, tc.expectedConfig); diff != "" {
 				t.Fatal("config did not match expectation:\n\n", diff)
 			}
 		})
 	}
 }
+
+func TestRewriteImage(t *testing.T) {
+	rules := []RegistryRewrite{
+		{Source: "docker.io/istio", Mirror: "mirror.example.com/istio"},
+		{Source: "gcr.io/", Mirror: "mirror.example.com:5000/gcr/"},
+		{Source: "quay.io/sail-dev/proxyv2", Mirror: "mirror.example.com/proxyv2"},
+	}
+	testCases := []struct {
+		image    string
+		expected string
+	}{
+		{image: "docker.io/istio/proxyv2:1.24.2", expected: "mirror.example.com/istio/proxyv2:1.24.2"},
+		{image: "docker.io/istio", expected: "mirror.example.com/istio"},
+		{image: "docker.io/istio-testing/proxyv2:latest", expected: "docker.io/istio-testing/proxyv2:latest"},
+		{image: "gcr.io/istio-release/pilot@sha256:abc", expected: "mirror.example.com:5000/gcr/istio-release/pilot@sha256:abc"},
+		{image: "quay.io/sail-dev/proxyv2:1.24", expected: "mirror.example.com/proxyv2:1.24"},
+		{image: "quay.io/sail-dev/proxyv2@sha256:abc", expected: "mirror.example.com/proxyv2@sha256:abc"},
+		{image: "quay.io/sail-dev/proxyv2-debug:1.24", expected: "quay.io/sail-dev/proxyv2-debug:1.24"},
+		{image: "proxyv2", expected: "proxyv2"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.image, func(t *testing.T) {
+			if actual := RewriteImage(rules, tc.image); actual != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
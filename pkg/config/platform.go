@@ -15,10 +15,18 @@
 package config
 
 import (
+	"context"
 	"fmt"
+	"slices"
+	"strings"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
 
@@ -28,31 +36,203 @@ const (
 	PlatformUndefined  Platform = ""
 	PlatformOpenShift  Platform = "openshift"
 	PlatformKubernetes Platform = "kubernetes"
+	PlatformGKE        Platform = "gke"
+	PlatformEKS        Platform = "eks"
+	PlatformK3d        Platform = "k3d"
+	PlatformK3s        Platform = "k3s"
+	PlatformMinikube   Platform = "minikube"
+	PlatformMicroK8s   Platform = "microk8s"
+	PlatformKind       Platform = "kind"
 )
 
+// knownPlatforms lists the platforms that can be detected or set explicitly
+var knownPlatforms = []Platform{
+	PlatformKubernetes, PlatformOpenShift, PlatformGKE, PlatformEKS,
+	PlatformK3d, PlatformK3s, PlatformMinikube, PlatformMicroK8s, PlatformKind,
+}
+
 const (
 	openshiftKind            = "OpenShiftAPIServer"
 	openshiftResourceGroup   = "operator.openshift.io"
 	openshiftResourceVersion = "v1"
+
+	// detectionNodeLimit is the maximum number of nodes whose labels are inspected during detection
+	detectionNodeLimit = 10
 )
 
-func DetectPlatform(cfg *rest.Config) (Platform, error) {
-	dc, err := discovery.NewDiscoveryClientForConfig(cfg)
+// KnownPlatforms returns the platforms that the operator supports.
+func KnownPlatforms() []Platform {
+	return slices.Clone(knownPlatforms)
+}
+
+// ParsePlatform returns the Platform with the given name or an error if the operator doesn't support it.
+func ParsePlatform(name string) (Platform, error) {
+	platform := Platform(strings.ToLower(name))
+	if !slices.Contains(knownPlatforms, platform) {
+		return PlatformUndefined, fmt.Errorf("unsupported platform %q; must be one of %v", name, knownPlatforms)
+	}
+	return platform, nil
+}
+
+// ClusterInfo holds the information about the cluster that the PlatformDetectors inspect.
+type ClusterInfo struct {
+	// ServerVersion is the version reported by the API server
+	ServerVersion *version.Info
+	// APIGroups holds the names of the API groups served by the cluster
+	APIGroups sets.Set[string]
+	// Nodes holds some of the cluster's nodes. It is empty if the operator isn't allowed to list nodes.
+	Nodes []corev1.Node
+	// Discovery can be used to look up the resources of an API group
+	Discovery discovery.DiscoveryInterface
+}
+
+// PlatformDetector detects whether the cluster runs on a specific platform.
+type PlatformDetector struct {
+	Platform Platform
+	Detect   func(cluster ClusterInfo) (bool, error)
+}
+
+// DefaultPlatformDetectors are the detectors used by DetectPlatform, in the order they are run. Detectors for
+// distributions that are built on top of another come before the detector of the underlying distribution, e.g.
+// k3d runs k3s in containers.
+var DefaultPlatformDetectors = []PlatformDetector{
+	{Platform: PlatformOpenShift, Detect: isOpenShift},
+	{Platform: PlatformK3d, Detect: isK3d},
+	{Platform: PlatformK3s, Detect: isK3s},
+	{Platform: PlatformMinikube, Detect: isMinikube},
+	{Platform: PlatformMicroK8s, Detect: isMicroK8s},
+	{Platform: PlatformKind, Detect: isKind},
+	{Platform: PlatformGKE, Detect: isGKE},
+	{Platform: PlatformEKS, Detect: isEKS},
+}
+
+// DetectPlatform detects the platform of the cluster using the DefaultPlatformDetectors.
+func DetectPlatform(ctx context.Context, cfg *rest.Config) (Platform, error) {
+	clientset, err := kubernetes.NewForConfig(cfg)
 	if err != nil {
-		return "", fmt.Errorf("failed to create discoveryClient: %w", err)
+		return "", fmt.Errorf("failed to create clientset: %w", err)
+	}
+	cluster, err := GetClusterInfo(ctx, clientset)
+	if err != nil {
+		return "", err
+	}
+	return DetectPlatformWith(cluster, DefaultPlatformDetectors)
+}
+
+// GetClusterInfo gathers the information inspected by the PlatformDetectors.
+func GetClusterInfo(ctx context.Context, clientset kubernetes.Interface) (ClusterInfo, error) {
+	dc := clientset.Discovery()
+	serverVersion, err := dc.ServerVersion()
+	if err != nil {
+		return ClusterInfo{}, fmt.Errorf("failed to get server version: %w", err)
+	}
+	groups, err := dc.ServerGroups()
+	if err != nil {
+		return ClusterInfo{}, fmt.Errorf("failed to get API groups: %w", err)
+	}
+	apiGroups := sets.New[string]()
+	for _, group := range groups.Groups {
+		apiGroups.Insert(group.Name)
+	}
+
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{Limit: detectionNodeLimit})
+	if err != nil && !errors.IsForbidden(err) {
+		return ClusterInfo{}, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	cluster := ClusterInfo{ServerVersion: serverVersion, APIGroups: apiGroups, Discovery: dc}
+	if nodes != nil {
+		cluster.Nodes = nodes.Items
+	}
+	return cluster, nil
+}
+
+// DetectPlatformWith returns the platform of the first detector that matches the cluster or PlatformKubernetes if
+// none matches.
+func DetectPlatformWith(cluster ClusterInfo, detectors []PlatformDetector) (Platform, error) {
+	for _, detector := range detectors {
+		detected, err := detector.Detect(cluster)
+		if err != nil {
+			return "", fmt.Errorf("failed to detect platform %s: %w", detector.Platform, err)
+		}
+		if detected {
+			return detector.Platform, nil
+		}
 	}
+	return PlatformKubernetes, nil
+}
 
-	resources, err := dc.ServerResourcesForGroupVersion(openshiftResourceGroup + "/" + openshiftResourceVersion)
+func isOpenShift(cluster ClusterInfo) (bool, error) {
+	if !cluster.APIGroups.Has(openshiftResourceGroup) {
+		return false, nil
+	}
+	resources, err := cluster.Discovery.ServerResourcesForGroupVersion(openshiftResourceGroup + "/" + openshiftResourceVersion)
 	if errors.IsNotFound(err) {
-		return PlatformKubernetes, nil
+		return false, nil
 	} else if err != nil {
-		return "", err
+		return false, err
 	}
 
 	for _, apiResource := range resources.APIResources {
 		if apiResource.Kind == openshiftKind {
-			return PlatformOpenShift, nil
+			return true, nil
 		}
 	}
-	return PlatformKubernetes, nil
+	return false, nil
+}
+
+func isK3d(cluster ClusterInfo) (bool, error) {
+	k3s, err := isK3s(cluster)
+	if err != nil || !k3s {
+		return false, err
+	}
+	return anyNode(cluster, func(node corev1.Node) bool {
+		return strings.HasPrefix(node.Name, "k3d-")
+	}), nil
+}
+
+func isK3s(cluster ClusterInfo) (bool, error) {
+	return serverVersionContains(cluster, "+k3s") || anyNode(cluster, func(node corev1.Node) bool {
+		return node.Labels[corev1.LabelInstanceTypeStable] == "k3s"
+	}), nil
+}
+
+func isMinikube(cluster ClusterInfo) (bool, error) {
+	return anyNodeHasLabel(cluster, "minikube.k8s.io/name"), nil
+}
+
+func isMicroK8s(cluster ClusterInfo) (bool, error) {
+	return anyNodeHasLabel(cluster, "microk8s.io/cluster"), nil
+}
+
+func isKind(cluster ClusterInfo) (bool, error) {
+	return anyNode(cluster, func(node corev1.Node) bool {
+		return strings.HasPrefix(node.Spec.ProviderID, "kind://")
+	}), nil
+}
+
+func isGKE(cluster ClusterInfo) (bool, error) {
+	return serverVersionContains(cluster, "-gke.") ||
+		anyNodeHasLabel(cluster, "cloud.google.com/gke-nodepool") ||
+		cluster.APIGroups.Has("networking.gke.io"), nil
+}
+
+func isEKS(cluster ClusterInfo) (bool, error) {
+	return serverVersionContains(cluster, "-eks-") ||
+		anyNodeHasLabel(cluster, "eks.amazonaws.com/nodegroup") ||
+		cluster.APIGroups.Has("vpcresources.k8s.aws"), nil
+}
+
+func serverVersionContains(cluster ClusterInfo, s string) bool {
+	return cluster.ServerVersion != nil && strings.Contains(cluster.ServerVersion.GitVersion, s)
+}
+
+func anyNode(cluster ClusterInfo, predicate func(node corev1.Node) bool) bool {
+	return slices.ContainsFunc(cluster.Nodes, predicate)
+}
+
+func anyNodeHasLabel(cluster ClusterInfo, label string) bool {
+	return anyNode(cluster, func(node corev1.Node) bool {
+		_, found := node.Labels[label]
+		return found
+	})
 }
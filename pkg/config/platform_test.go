@@ -0,0 +1,195 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func node(name string, labels map[string]string, providerID string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Spec:       corev1.NodeSpec{ProviderID: providerID},
+	}
+}
+
+func TestDetectPlatform(t *testing.T) {
+	openshiftResources := &metav1.APIResourceList{
+		GroupVersion: openshiftResourceGroup + "/" + openshiftResourceVersion,
+		APIResources: []metav1.APIResource{{Name: "openshiftapiservers", Kind: openshiftKind}},
+	}
+
+	testCases := []struct {
+		name          string
+		gitVersion    string
+		resources     []*metav1.APIResourceList
+		nodes         []runtime.Object
+		nodesDenied   bool
+		expectedValue Platform
+	}{
+		{
+			name:          "vanilla kubernetes",
+			gitVersion:    "v1.31.0",
+			nodes:         []runtime.Object{node("worker", map[string]string{"kubernetes.io/os": "linux"}, "")},
+			expectedValue: PlatformKubernetes,
+		},
+		{
+			name:          "openshift",
+			gitVersion:    "v1.31.0",
+			resources:     []*metav1.APIResourceList{openshiftResources},
+			expectedValue: PlatformOpenShift,
+		},
+		{
+			name:       "operator.openshift.io group without OpenShiftAPIServer",
+			gitVersion: "v1.31.0",
+			resources: []*metav1.APIResourceList{{
+				GroupVersion: openshiftResourceGroup + "/" + openshiftResourceVersion,
+				APIResources: []metav1.APIResource{{Name: "others", Kind: "Other"}},
+			}},
+			expectedValue: PlatformKubernetes,
+		},
+		{
+			name:          "gke by server version",
+			gitVersion:    "v1.30.5-gke.1014001",
+			expectedValue: PlatformGKE,
+		},
+		{
+			name:          "gke by node label",
+			gitVersion:    "v1.30.5",
+			nodes:         []runtime.Object{node("gke-node", map[string]string{"cloud.google.com/gke-nodepool": "default-pool"}, "")},
+			expectedValue: PlatformGKE,
+		},
+		{
+			name:          "eks by server version",
+			gitVersion:    "v1.30.4-eks-a737599",
+			expectedValue: PlatformEKS,
+		},
+		{
+			name:       "eks by API group",
+			gitVersion: "v1.30.4",
+			resources: []*metav1.APIResourceList{{
+				GroupVersion: "vpcresources.k8s.aws/v1beta1",
+				APIResources: []metav1.APIResource{{Name: "securitygrouppolicies", Kind: "SecurityGroupPolicy"}},
+			}},
+			expectedValue: PlatformEKS,
+		},
+		{
+			name:          "k3s",
+			gitVersion:    "v1.31.4+k3s1",
+			nodes:         []runtime.Object{node("server", map[string]string{corev1.LabelInstanceTypeStable: "k3s"}, "")},
+			expectedValue: PlatformK3s,
+		},
+		{
+			name:          "k3d",
+			gitVersion:    "v1.31.4+k3s1",
+			nodes:         []runtime.Object{node("k3d-test-server-0", map[string]string{corev1.LabelInstanceTypeStable: "k3s"}, "")},
+			expectedValue: PlatformK3d,
+		},
+		{
+			name:          "minikube",
+			gitVersion:    "v1.31.0",
+			nodes:         []runtime.Object{node("minikube", map[string]string{"minikube.k8s.io/name": "minikube"}, "")},
+			expectedValue: PlatformMinikube,
+		},
+		{
+			name:          "microk8s",
+			gitVersion:    "v1.31.0",
+			nodes:         []runtime.Object{node("microk8s", map[string]string{"microk8s.io/cluster": "true"}, "")},
+			expectedValue: PlatformMicroK8s,
+		},
+		{
+			name:          "kind",
+			gitVersion:    "v1.31.0",
+			nodes:         []runtime.Object{node("kind-control-plane", nil, "kind://docker/kind/kind-control-plane")},
+			expectedValue: PlatformKind,
+		},
+		{
+			name:          "nodes can't be listed",
+			gitVersion:    "v1.31.4+k3s1",
+			nodesDenied:   true,
+			expectedValue: PlatformK3s,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			clientset := fake.NewSimpleClientset(tc.nodes...)
+			dc := clientset.Discovery().(*fakediscovery.FakeDiscovery)
+			dc.Resources = tc.resources
+			dc.FakedServerVersion = &version.Info{GitVersion: tc.gitVersion}
+			if tc.nodesDenied {
+				clientset.PrependReactor("list", "nodes", func(k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "nodes"}, "", fmt.Errorf("denied"))
+				})
+			}
+
+			cluster, err := GetClusterInfo(context.Background(), clientset)
+			g.Expect(err).NotTo(HaveOccurred())
+			platform, err := DetectPlatformWith(cluster, DefaultPlatformDetectors)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(platform).To(Equal(tc.expectedValue))
+		})
+	}
+}
+
+func TestDetectPlatformWithCustomDetector(t *testing.T) {
+	g := NewWithT(t)
+	custom := PlatformDetector{
+		Platform: "custom",
+		Detect: func(cluster ClusterInfo) (bool, error) {
+			return cluster.ServerVersion.GitVersion == "v1.31.0-custom", nil
+		},
+	}
+	cluster := ClusterInfo{ServerVersion: &version.Info{GitVersion: "v1.31.0-custom"}}
+
+	platform, err := DetectPlatformWith(cluster, append([]PlatformDetector{custom}, DefaultPlatformDetectors...))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(platform).To(Equal(Platform("custom")))
+
+	failing := PlatformDetector{Platform: "failing", Detect: func(ClusterInfo) (bool, error) {
+		return false, fmt.Errorf("boom")
+	}}
+	_, err = DetectPlatformWith(cluster, []PlatformDetector{failing})
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestParsePlatform(t *testing.T) {
+	g := NewWithT(t)
+	for _, platform := range KnownPlatforms() {
+		parsed, err := ParsePlatform(string(platform))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(parsed).To(Equal(platform))
+	}
+
+	parsed, err := ParsePlatform("MicroK8s")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(parsed).To(Equal(PlatformMicroK8s))
+
+	_, err = ParsePlatform("aks")
+	g.Expect(err).To(HaveOccurred())
+}
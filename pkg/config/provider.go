@@ -0,0 +1,203 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+	"sync"
+
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// Provider holds the operator configuration and notifies the controllers when it changes. The configuration
+// consists of two layers: the configuration read from the properties file, which is replaced whenever the file
+// changes, and the overrides from the SailOperatorConfig resource. A nil Provider returns an empty configuration.
+type Provider struct {
+	lock        sync.RWMutex
+	file        OperatorConfig
+	overrides   OperatorConfig
+	current     OperatorConfig
+	subscribers []chan event.TypedGenericEvent[Change]
+}
+
+// NewProvider returns a Provider whose configuration is the given configuration read from the properties file.
+func NewProvider(fileConfig OperatorConfig) *Provider {
+	return &Provider{file: fileConfig, current: fileConfig}
+}
+
+// Change describes a change of the operator configuration.
+type Change struct {
+	Old OperatorConfig
+	New OperatorConfig
+}
+
+// ImageDigestsChanged returns whether the images of the given Istio version changed.
+func (c Change) ImageDigestsChanged(version string) bool {
+	return c.Old.ImageDigests[version] != c.New.ImageDigests[version]
+}
+
+// ChangedVersions returns the sorted list of Istio versions whose images changed.
+func (c Change) ChangedVersions() []string {
+	var versions []string
+	for version := range c.Old.ImageDigests {
+		if c.ImageDigestsChanged(version) {
+			versions = append(versions, version)
+		}
+	}
+	for version := range c.New.ImageDigests {
+		if _, found := c.Old.ImageDigests[version]; !found {
+			versions = append(versions, version)
+		}
+	}
+	slices.Sort(versions)
+	return versions
+}
+
+// OnlyImageDigestsChanged returns whether the change is limited to image digests, in which case only the
+// resources using one of the changed versions need to be reconciled again.
+func (c Change) OnlyImageDigestsChanged() bool {
+	oldConfig, newConfig := c.Old, c.New
+	oldConfig.ImageDigests, newConfig.ImageDigests = nil, nil
+	return reflect.DeepEqual(oldConfig, newConfig)
+}
+
+// Affects returns whether a resource that installs the given Istio versions must be reconciled again.
+func (c Change) Affects(versions ...string) bool {
+	if !c.OnlyImageDigestsChanged() {
+		return true
+	}
+	for _, version := range versions {
+		if version != "" && c.ImageDigestsChanged(version) {
+			return true
+		}
+	}
+	return false
+}
+
+// Get returns the current operator configuration. The returned value must not be modified.
+func (p *Provider) Get() OperatorConfig {
+	if p == nil {
+		return OperatorConfig{}
+	}
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.current
+}
+
+// FileConfig returns the configuration read from the properties file, without the overrides.
+func (p *Provider) FileConfig() OperatorConfig {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.file
+}
+
+// SetFileConfig replaces the configuration read from the properties file.
+func (p *Provider) SetFileConfig(cfg OperatorConfig) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.file = cfg
+	p.update()
+}
+
+// SetOverrides replaces the overrides applied on top of the configuration read from the properties file. An empty
+// OperatorConfig removes all overrides.
+func (p *Provider) SetOverrides(overrides OperatorConfig) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.overrides = overrides
+	p.update()
+}
+
+// update recomputes the current configuration and notifies the subscribers if it changed. The caller must hold
+// the write lock.
+func (p *Provider) update() {
+	previous := p.current
+	p.current = Merge(p.file, p.overrides)
+	if reflect.DeepEqual(previous, p.current) {
+		return
+	}
+	for _, ch := range p.subscribers {
+		// if the subscriber hasn't received the previous change yet, replace it with one spanning both changes
+		old := previous
+		select {
+		case pending := <-ch:
+			old = pending.Object.Old
+		default:
+		}
+		ch <- event.TypedGenericEvent[Change]{Object: Change{Old: old, New: p.current}}
+	}
+}
+
+// WatchSource returns a watch source that passes each configuration change to mapFunc and enqueues the returned
+// requests. Changes that occur before the source is started or while the requests are processed are merged, so
+// the source never blocks the Provider.
+func (p *Provider) WatchSource(mapFunc handler.TypedMapFunc[Change, reconcile.Request]) source.Source {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	ch := make(chan event.TypedGenericEvent[Change], 1)
+	p.subscribers = append(p.subscribers, ch)
+	return source.Channel(ch, handler.TypedEnqueueRequestsFromMapFunc(mapFunc))
+}
+
+// Merge returns the base configuration with the fields set in overrides applied to it. The images of a version
+// in overrides replace the images of the same version in base, and feature gates are overridden one by one.
+func Merge(base, overrides OperatorConfig) OperatorConfig {
+	cfg := base
+
+	if len(overrides.ImageDigests) > 0 {
+		cfg.ImageDigests = maps.Clone(base.ImageDigests)
+		if cfg.ImageDigests == nil {
+			cfg.ImageDigests = map[string]IstioImageConfig{}
+		}
+		maps.Copy(cfg.ImageDigests, overrides.ImageDigests)
+	}
+	if overrides.DefaultProfile != "" {
+		cfg.DefaultProfile = overrides.DefaultProfile
+	}
+	if overrides.Platform != PlatformUndefined {
+		cfg.Platform = overrides.Platform
+	}
+	if overrides.ProbeDefaults.ReadinessInitialDelaySeconds != nil {
+		cfg.ProbeDefaults.ReadinessInitialDelaySeconds = overrides.ProbeDefaults.ReadinessInitialDelaySeconds
+	}
+	if overrides.ProbeDefaults.ReadinessPeriodSeconds != nil {
+		cfg.ProbeDefaults.ReadinessPeriodSeconds = overrides.ProbeDefaults.ReadinessPeriodSeconds
+	}
+	if overrides.ProbeDefaults.ReadinessFailureThreshold != nil {
+		cfg.ProbeDefaults.ReadinessFailureThreshold = overrides.ProbeDefaults.ReadinessFailureThreshold
+	}
+	if len(overrides.WatchedNamespaces) > 0 {
+		cfg.WatchedNamespaces = overrides.WatchedNamespaces
+	}
+	if len(overrides.RegistryRewrites) > 0 {
+		cfg.RegistryRewrites = overrides.RegistryRewrites
+	}
+	if overrides.ImageVerification != nil {
+		cfg.ImageVerification = overrides.ImageVerification
+	}
+	if len(overrides.FeatureGates) > 0 {
+		cfg.FeatureGates = maps.Clone(base.FeatureGates)
+		if cfg.FeatureGates == nil {
+			cfg.FeatureGates = map[string]bool{}
+		}
+		maps.Copy(cfg.FeatureGates, overrides.FeatureGates)
+	}
+	return cfg
+}
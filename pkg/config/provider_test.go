@@ -0,0 +1,148 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"istio.io/istio/pkg/ptr"
+)
+
+var (
+	images1242 = IstioImageConfig{IstiodImage: "istiod-1.24.2", ProxyImage: "proxy-1.24.2"}
+	images1243 = IstioImageConfig{IstiodImage: "istiod-1.24.3", ProxyImage: "proxy-1.24.3"}
+)
+
+func TestMerge(t *testing.T) {
+	g := NewWithT(t)
+	base := OperatorConfig{
+		ImageDigests:  map[string]IstioImageConfig{"v1.24.2": images1242, "v1.24.3": images1243},
+		ProbeDefaults: ProbeDefaults{ReadinessPeriodSeconds: ptr.Of(uint32(2))},
+		FeatureGates:  map[string]bool{"A": true},
+	}
+
+	g.Expect(Merge(base, OperatorConfig{})).To(Equal(base))
+
+	merged := Merge(base, OperatorConfig{
+		ImageDigests:      map[string]IstioImageConfig{"v1.24.2": {IstiodImage: "mirror/istiod"}},
+		DefaultProfile:    "ambient",
+		Platform:          PlatformOpenShift,
+		ProbeDefaults:     ProbeDefaults{ReadinessFailureThreshold: ptr.Of(uint32(4))},
+		WatchedNamespaces: []string{"istio-system"},
+		FeatureGates:      map[string]bool{"B": false},
+	})
+	g.Expect(merged).To(Equal(OperatorConfig{
+		ImageDigests:      map[string]IstioImageConfig{"v1.24.2": {IstiodImage: "mirror/istiod"}, "v1.24.3": images1243},
+		DefaultProfile:    "ambient",
+		Platform:          PlatformOpenShift,
+		ProbeDefaults:     ProbeDefaults{ReadinessPeriodSeconds: ptr.Of(uint32(2)), ReadinessFailureThreshold: ptr.Of(uint32(4))},
+		WatchedNamespaces: []string{"istio-system"},
+		FeatureGates:      map[string]bool{"A": true, "B": false},
+	}))
+
+	// the base configuration must not be modified
+	g.Expect(base.ImageDigests["v1.24.2"]).To(Equal(images1242))
+	g.Expect(base.FeatureGates).To(HaveLen(1))
+}
+
+func TestChange(t *testing.T) {
+	old := OperatorConfig{ImageDigests: map[string]IstioImageConfig{"v1.24.2": images1242, "v1.24.3": images1243}}
+
+	t.Run("image digests", func(t *testing.T) {
+		g := NewWithT(t)
+		change := Change{Old: old, New: OperatorConfig{ImageDigests: map[string]IstioImageConfig{
+			"v1.24.2": {IstiodImage: "istiod-1.24.2-rebuilt", ProxyImage: "proxy-1.24.2"},
+			"v1.24.3": images1243,
+			"v1.25.0": images1243,
+		}}}
+		g.Expect(change.OnlyImageDigestsChanged()).To(BeTrue())
+		g.Expect(change.ChangedVersions()).To(Equal([]string{"v1.24.2", "v1.25.0"}))
+		g.Expect(change.Affects("v1.24.2")).To(BeTrue())
+		g.Expect(change.Affects("", "v1.24.3")).To(BeFalse())
+		g.Expect(change.Affects()).To(BeFalse())
+	})
+
+	t.Run("removed version", func(t *testing.T) {
+		g := NewWithT(t)
+		change := Change{Old: old, New: OperatorConfig{ImageDigests: map[string]IstioImageConfig{"v1.24.3": images1243}}}
+		g.Expect(change.ChangedVersions()).To(Equal([]string{"v1.24.2"}))
+	})
+
+	t.Run("other fields", func(t *testing.T) {
+		g := NewWithT(t)
+		change := Change{Old: old, New: OperatorConfig{ImageDigests: old.ImageDigests, WatchedNamespaces: []string{"istio-system"}}}
+		g.Expect(change.OnlyImageDigestsChanged()).To(BeFalse())
+		g.Expect(change.ChangedVersions()).To(BeEmpty())
+		g.Expect(change.Affects()).To(BeTrue())
+	})
+}
+
+func TestProvider(t *testing.T) {
+	g := NewWithT(t)
+	fileConfig := OperatorConfig{ImageDigests: map[string]IstioImageConfig{"v1.24.2": images1242}}
+	provider := NewProvider(fileConfig)
+	g.Expect(provider.Get()).To(Equal(fileConfig))
+
+	var changes []Change
+	src := provider.WatchSource(func(_ context.Context, change Change) []reconcile.Request {
+		changes = append(changes, change)
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: change.New.DefaultProfile}}}
+	})
+
+	// changes made before the source is started are merged into one
+	provider.SetOverrides(OperatorConfig{DefaultProfile: "first"})
+	provider.SetOverrides(OperatorConfig{DefaultProfile: "second"})
+	provider.SetOverrides(OperatorConfig{DefaultProfile: "second"})
+	g.Expect(provider.Get().DefaultProfile).To(Equal("second"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	queue := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer queue.ShutDown()
+	g.Expect(src.Start(ctx, queue)).To(Succeed())
+
+	req, _ := queue.Get()
+	g.Expect(req.Name).To(Equal("second"))
+	g.Expect(changes).To(HaveLen(1))
+	g.Expect(changes[0].Old).To(Equal(fileConfig))
+	queue.Done(req)
+
+	// a new file configuration keeps the overrides
+	provider.SetFileConfig(OperatorConfig{ImageDigests: map[string]IstioImageConfig{"v1.24.2": images1243}})
+	req, _ = queue.Get()
+	g.Expect(req.Name).To(Equal("second"))
+	g.Expect(changes).To(HaveLen(2))
+	g.Expect(changes[1].ChangedVersions()).To(Equal([]string{"v1.24.2"}))
+	g.Expect(changes[1].OnlyImageDigestsChanged()).To(BeTrue())
+	queue.Done(req)
+
+	// nothing is enqueued if the configuration doesn't change
+	provider.SetFileConfig(OperatorConfig{ImageDigests: map[string]IstioImageConfig{"v1.24.2": images1243}})
+	g.Consistently(queue.Len, 100*time.Millisecond).Should(BeZero())
+	g.Expect(changes).To(HaveLen(2))
+}
+
+func TestNilProvider(t *testing.T) {
+	g := NewWithT(t)
+	var provider *Provider
+	g.Expect(provider.Get()).To(Equal(OperatorConfig{}))
+}
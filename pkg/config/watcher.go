@@ -0,0 +1,99 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+
+	"github.com/fsnotify/fsnotify"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// FileWatcher reloads the properties file whenever it changes and passes the new configuration to the Provider.
+// It watches the directory containing the file rather than the file itself, because the kubelet updates a mounted
+// ConfigMap by replacing a symlink in that directory, which doesn't generate events for the file.
+type FileWatcher struct {
+	path     string
+	provider *Provider
+}
+
+var _ manager.LeaderElectionRunnable = &FileWatcher{}
+
+// NewFileWatcher creates a FileWatcher for the properties file at path.
+func NewFileWatcher(path string, provider *Provider) *FileWatcher {
+	return &FileWatcher{path: path, provider: provider}
+}
+
+// Start watches the file until the context is cancelled.
+func (w *FileWatcher) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx).WithName("config-watcher").WithValues("file", w.path)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create watcher for %s: %w", w.path, err)
+	}
+	defer watcher.Close()
+	if err := watcher.Add(filepath.Dir(w.path)); err != nil {
+		return fmt.Errorf("failed to watch %s: %w", w.path, err)
+	}
+
+	// the file may have changed between the initial read and the start of the watch
+	w.reload(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			w.reload(ctx)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Error(err, "error watching config file")
+		}
+	}
+}
+
+// reload reads the file and updates the Provider if the configuration in the file changed. If the file can't be
+// read or parsed, the previous configuration is kept.
+func (w *FileWatcher) reload(ctx context.Context) {
+	log := logf.FromContext(ctx).WithName("config-watcher").WithValues("file", w.path)
+
+	cfg, err := Read(w.path)
+	if err != nil {
+		log.Error(err, "failed to reload config file; keeping previous configuration")
+		return
+	}
+	previous := w.provider.FileConfig()
+	if reflect.DeepEqual(previous, cfg) {
+		return
+	}
+	log.Info("Config file changed", "changedVersions", Change{Old: previous, New: cfg}.ChangedVersions())
+	w.provider.SetFileConfig(cfg)
+}
+
+// NeedLeaderElection returns false, so that replicas that aren't the leader also keep their configuration up to
+// date and can take over with the current images.
+func (w *FileWatcher) NeedLeaderElection() bool {
+	return false
+}
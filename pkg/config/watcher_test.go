@@ -0,0 +1,77 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestFileWatcher(t *testing.T) {
+	g := NewWithT(t)
+
+	// simulate a mounted ConfigMap, where the file is a symlink into a data directory that is replaced on update
+	dir := t.TempDir()
+	writeConfigMapData := func(name, contents string) {
+		dataDir := filepath.Join(dir, name)
+		g.Expect(os.Mkdir(dataDir, 0o755)).To(Succeed())
+		g.Expect(os.WriteFile(filepath.Join(dataDir, "config.properties"), []byte(contents), 0o644)).To(Succeed())
+		g.Expect(os.Symlink(name, filepath.Join(dir, "..data_tmp"))).To(Succeed())
+		g.Expect(os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data"))).To(Succeed())
+	}
+	writeConfigMapData("..v1", `
+images.v1_24_2.istiod=istiod-test
+images.v1_24_2.proxy=proxy-test
+images.v1_24_2.cni=cni-test
+images.v1_24_2.ztunnel=ztunnel-test
+`)
+	configFile := filepath.Join(dir, "config.properties")
+	g.Expect(os.Symlink(filepath.Join("..data", "config.properties"), configFile)).To(Succeed())
+
+	fileConfig, err := Read(configFile)
+	g.Expect(err).NotTo(HaveOccurred())
+	provider := NewProvider(fileConfig)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error)
+	go func() {
+		done <- NewFileWatcher(configFile, provider).Start(ctx)
+	}()
+
+	writeConfigMapData("..v2", `
+images.v1_24_2.istiod=istiod-rebuilt
+images.v1_24_2.proxy=proxy-test
+images.v1_24_2.cni=cni-test
+images.v1_24_2.ztunnel=ztunnel-test
+`)
+	g.Eventually(func() string {
+		return provider.Get().ImageDigests["v1.24.2"].IstiodImage
+	}, 5*time.Second, 10*time.Millisecond).Should(Equal("istiod-rebuilt"))
+
+	// an invalid file doesn't replace the configuration
+	writeConfigMapData("..v3", "images.v1_24_2.istiod=istiod-invalid\n")
+	g.Consistently(func() string {
+		return provider.Get().ImageDigests["v1.24.2"].IstiodImage
+	}, 200*time.Millisecond, 10*time.Millisecond).Should(Equal("istiod-rebuilt"))
+
+	cancel()
+	g.Eventually(done).Should(Receive(BeNil()))
+}
@@ -33,6 +33,18 @@ const (
 	// IgnoreNamespaceKey indicates that sidecar injection should be disabled for the namespace
 	IgnoreNamespaceKey = MetadataNamespace + "/ignore-namespace"
 
+	// NamespaceCreatedForKey is the annotation the operator sets on the namespaces it creates. Its value identifies
+	// the resource (<kind>/<name>) the namespace was created for.
+	NamespaceCreatedForKey = MetadataNamespace + "/created-for"
+
+	// ForceDeleteKey is an annotation on an Istio, IstioRevision or IstioCNI that allows the operator to uninstall
+	// the resource when it's deleted, even though workloads still use it
+	ForceDeleteKey = MetadataNamespace + "/force-delete"
+
+	// AdoptHelmReleaseKey is an annotation on a new Istio, IstioCNI or ZTunnel that names an existing Helm release
+	// in the target namespace, which the operator takes over instead of installing the component anew
+	AdoptHelmReleaseKey = MetadataNamespace + "/adopt-helm-release"
+
 	// GenerationKey represents the generation to which the resource was last reconciled
 	GenerationKey = MetadataNamespace + "/generation"
 
@@ -61,14 +73,6 @@ const (
 	// KubernetesAppManagedByValue is the KubernetesAppManagedByKey label value the operator sets on all objects it creates
 	KubernetesAppManagedByValue = "sail-operator"
 
-	// WebhookReadinessProbeStatusAnnotationKey is an annotation on the istio-sidecar-injection MutatingWebhookConfiguration that
-	// reports whether the remote control plane is ready or not
-	WebhookReadinessProbeStatusAnnotationKey = MetadataNamespace + "/readinessProbe.status"
-
-	// WebhookReadinessProbeStatusReasonAnnotationKey is an annotation on the istio-sidecar-injection MutatingWebhookConfiguration that
-	// reports why the remote control plane is not ready
-	WebhookReadinessProbeStatusReasonAnnotationKey = MetadataNamespace + "/readinessProbe.reason"
-
 	// WebhookReadinessProbePeriodSecondsAnnotationKey is an annotation on the istio-sidecar-injection MutatingWebhookConfiguration that
 	// specifies the period for the readiness probe
 	WebhookReadinessProbePeriodSecondsAnnotationKey = MetadataNamespace + "/readinessProbe.periodSeconds"
@@ -86,9 +90,68 @@ const (
 	// IstioRevLabel is the label that is used to configure injection for non-default IstioRevisions
 	IstioRevLabel = "istio.io/rev"
 
+	// IstioDataplaneModeLabel is the label that adds the workloads in a namespace to the ambient mesh
+	IstioDataplaneModeLabel = "istio.io/dataplane-mode"
+
+	// IstioDataplaneModeAmbient is the value of IstioDataplaneModeLabel that enables ambient mode
+	IstioDataplaneModeAmbient = "ambient"
+
+	// IstioAmbientRedirectionAnnotation is the annotation that the CNI plugin sets on pods whose traffic it redirects
+	// to ztunnel. Its value is "enabled" for pods that are part of the ambient mesh
+	IstioAmbientRedirectionAnnotation = "ambient.istio.io/redirection"
+
 	// IstioSidecarInjectLabel is the label that is used to configure injection for specific workloads
 	IstioSidecarInjectLabel = "sidecar.istio.io/inject"
 
+	// IstioMultiClusterSecretLabel is the label that istiod uses to discover remote secrets
+	IstioMultiClusterSecretLabel = "istio/multiCluster"
+
+	// IstioClusterAnnotation is the annotation that identifies the cluster a remote secret belongs to
+	IstioClusterAnnotation = "networking.istio.io/cluster"
+
+	// RemoteClusterSecretLabel is the label that the operator sets on the remote secrets it creates and on the
+	// kubeconfig secrets referenced by RemoteClusters. The operator only caches and watches secrets with this label.
+	RemoteClusterSecretLabel = MetadataNamespace + "/remote-cluster-secret"
+
+	// RemoteClusterTokenExpirationAnnotationKey is an annotation on remote secrets created by the operator
+	// that records when the ServiceAccount token in the secret expires
+	RemoteClusterTokenExpirationAnnotationKey = MetadataNamespace + "/token-expiration-time"
+
+	// RemoteClusterServiceAccountAnnotationKey is an annotation on remote secrets created by the operator
+	// that records the ServiceAccount (in namespace/name format) for which the token in the secret was issued
+	RemoteClusterServiceAccountAnnotationKey = MetadataNamespace + "/token-service-account"
+
+	// IstioRevisionTagApprovedRevisionAnnotationKey is an annotation on an IstioRevisionTag that approves moving the tag
+	// to the IstioRevision specified in the annotation value, when the tag's promotion policy requires approval
+	IstioRevisionTagApprovedRevisionAnnotationKey = MetadataNamespace + "/approved-revision"
+
+	// IstioRevisionTagAdoptWebhookAnnotationKey is an annotation on an IstioRevisionTag that allows the operator to take
+	// ownership of an existing revision tag MutatingWebhookConfiguration that wasn't created by the operator (e.g. by istioctl)
+	IstioRevisionTagAdoptWebhookAnnotationKey = MetadataNamespace + "/adopt-webhook"
+
+	// CanaryBucketLabel is the label that the operator sets on the namespaces that reference an IstioRevisionTag
+	// while the tag has a canary revision. Its value is the bucket, derived from the namespace name, that determines
+	// whether the namespace's pods are injected by the tag's primary or canary revision.
+	CanaryBucketLabel = MetadataNamespace + "/canary-bucket"
+
+	// IstioTagLabel is the label that identifies the revision tag a MutatingWebhookConfiguration belongs to
+	IstioTagLabel = "istio.io/tag"
+
+	// WaypointLabel is the label on a Namespace or ServiceAccount that tells the operator to create a waypoint proxy
+	// for it. The value specifies the type of traffic the waypoint handles (service, workload or all)
+	WaypointLabel = MetadataNamespace + "/waypoint"
+
+	// WaypointStatusAnnotationKey is an annotation that the operator sets on a Namespace or ServiceAccount
+	// labeled with WaypointLabel to report whether the waypoint proxy created for it is ready
+	WaypointStatusAnnotationKey = MetadataNamespace + "/waypoint-status"
+
+	// IstioWaypointForLabel is the label that specifies the type of traffic a waypoint Gateway handles
+	IstioWaypointForLabel = "istio.io/waypoint-for"
+
 	// IstiodChartName is the name of the chart that installs istiod
 	IstiodChartName = "istiod"
+
+	// BaseChartName is the name of the chart that installs the Istio CRDs and cluster-wide resources when Istio is
+	// installed with Helm. The operator doesn't install it, because it ships the CRDs itself.
+	BaseChartName = "base"
 )
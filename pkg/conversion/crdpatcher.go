@@ -0,0 +1,148 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conversion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"istio.io/istio/pkg/ptr"
+)
+
+const (
+	// WebhookPath is the path on which controller-runtime serves the conversion webhook
+	WebhookPath = "/convert"
+
+	// webhookServicePort is the port of the Service that fronts the operator's webhook server
+	webhookServicePort = 443
+
+	// caBundleFile is the file in the certificate directory that holds the CA which signed the serving certificate
+	caBundleFile = "ca.crt"
+
+	// resyncPeriod is how often the conversion configuration is re-applied, so that it's restored when the CRD is
+	// replaced (e.g. by `kubectl apply` of the manifests in the chart's crds directory)
+	resyncPeriod = 5 * time.Minute
+)
+
+// CRDPatcher points the conversion of the given CRDs at the operator's webhook Service. OLM does this for bundle
+// installs; CRDPatcher does it when the operator is installed from the Helm chart, whose CRDs can't be templated.
+type CRDPatcher struct {
+	client  client.Client
+	crds    []string
+	service types.NamespacedName
+	certDir string
+}
+
+var _ manager.LeaderElectionRunnable = &CRDPatcher{}
+
+// NewCRDPatcher creates a CRDPatcher that configures the named CRDs to use the webhook served behind the given
+// Service, with the CA bundle read from the ca.crt file in certDir.
+func NewCRDPatcher(cl client.Client, service types.NamespacedName, certDir string, crds ...string) *CRDPatcher {
+	return &CRDPatcher{client: cl, crds: crds, service: service, certDir: certDir}
+}
+
+// ParseService parses a Service reference in the form namespace/name.
+func ParseService(ref string) (types.NamespacedName, error) {
+	namespace, name, found := strings.Cut(ref, "/")
+	if !found || namespace == "" || name == "" {
+		return types.NamespacedName{}, fmt.Errorf("invalid service reference %q: must be in the form namespace/name", ref)
+	}
+	return types.NamespacedName{Namespace: namespace, Name: name}, nil
+}
+
+// +kubebuilder:rbac:groups="apiextensions.k8s.io",resources=customresourcedefinitions,resourceNames=ztunnels.sailoperator.io,verbs=patch
+
+// Start patches the CRDs and re-applies the patch periodically until the context is cancelled.
+func (p *CRDPatcher) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx).WithName("crd-conversion")
+
+	ticker := time.NewTicker(resyncPeriod)
+	defer ticker.Stop()
+	for {
+		if err := p.patch(ctx); err != nil {
+			log.Error(err, "failed to configure CRD conversion webhook")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// NeedLeaderElection returns true, since only the leader needs to keep the CRDs up to date.
+func (p *CRDPatcher) NeedLeaderElection() bool {
+	return true
+}
+
+func (p *CRDPatcher) patch(ctx context.Context) error {
+	caBundle, err := os.ReadFile(filepath.Join(p.certDir, caBundleFile))
+	if err != nil {
+		return fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+	data, err := conversionPatch(p.service, caBundle)
+	if err != nil {
+		return err
+	}
+	for _, name := range p.crds {
+		crd := &unstructured.Unstructured{}
+		crd.SetGroupVersionKind(apiextensionsv1.SchemeGroupVersion.WithKind("CustomResourceDefinition"))
+		crd.SetName(name)
+		if err := p.client.Patch(ctx, crd, client.RawPatch(types.MergePatchType, data)); err != nil {
+			return fmt.Errorf("failed to patch CustomResourceDefinition %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// conversionPatch returns a merge patch that sets the Webhook conversion strategy on a CRD.
+func conversionPatch(service types.NamespacedName, caBundle []byte) ([]byte, error) {
+	patch := map[string]any{
+		"spec": map[string]any{
+			"conversion": apiextensionsv1.CustomResourceConversion{
+				Strategy: apiextensionsv1.WebhookConverter,
+				Webhook: &apiextensionsv1.WebhookConversion{
+					ClientConfig: &apiextensionsv1.WebhookClientConfig{
+						Service: &apiextensionsv1.ServiceReference{
+							Namespace: service.Namespace,
+							Name:      service.Name,
+							Path:      ptr.Of(WebhookPath),
+							Port:      ptr.Of(int32(webhookServicePort)),
+						},
+						CABundle: caBundle,
+					},
+					ConversionReviewVersions: []string{"v1"},
+				},
+			},
+		},
+	}
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal conversion patch: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,113 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conversion
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"istio.io/istio/pkg/ptr"
+)
+
+func TestParseService(t *testing.T) {
+	tests := []struct {
+		ref       string
+		expected  types.NamespacedName
+		expectErr bool
+	}{
+		{ref: "sail-operator/sail-operator-webhook-service", expected: types.NamespacedName{Namespace: "sail-operator", Name: "sail-operator-webhook-service"}},
+		{ref: "sail-operator-webhook-service", expectErr: true},
+		{ref: "/sail-operator-webhook-service", expectErr: true},
+		{ref: "sail-operator/", expectErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			g := NewWithT(t)
+			service, err := ParseService(tt.ref)
+			if tt.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(service).To(Equal(tt.expected))
+		})
+	}
+}
+
+func TestPatch(t *testing.T) {
+	g := NewWithT(t)
+
+	certDir := t.TempDir()
+	g.Expect(os.WriteFile(filepath.Join(certDir, "ca.crt"), []byte("test-ca"), 0o644)).To(Succeed())
+
+	patched := map[string]apiextensionsv1.CustomResourceConversion{}
+	cl := fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+		Patch: func(_ context.Context, _ client.WithWatch, obj client.Object, patch client.Patch, _ ...client.PatchOption) error {
+			g.Expect(patch.Type()).To(Equal(types.MergePatchType))
+			data, err := patch.Data(obj)
+			g.Expect(err).NotTo(HaveOccurred())
+			crd := apiextensionsv1.CustomResourceDefinition{}
+			g.Expect(json.Unmarshal(data, &crd)).To(Succeed())
+			patched[obj.GetName()] = *crd.Spec.Conversion
+			return nil
+		},
+	}).Build()
+
+	service := types.NamespacedName{Namespace: "sail-operator", Name: "sail-operator-webhook-service"}
+	p := NewCRDPatcher(cl, service, certDir, "ztunnels.sailoperator.io")
+	g.Expect(p.patch(context.Background())).To(Succeed())
+
+	g.Expect(patched).To(Equal(map[string]apiextensionsv1.CustomResourceConversion{
+		"ztunnels.sailoperator.io": {
+			Strategy: apiextensionsv1.WebhookConverter,
+			Webhook: &apiextensionsv1.WebhookConversion{
+				ClientConfig: &apiextensionsv1.WebhookClientConfig{
+					Service: &apiextensionsv1.ServiceReference{
+						Namespace: "sail-operator",
+						Name:      "sail-operator-webhook-service",
+						Path:      ptr.Of("/convert"),
+						Port:      ptr.Of(int32(443)),
+					},
+					CABundle: []byte("test-ca"),
+				},
+				ConversionReviewVersions: []string{"v1"},
+			},
+		},
+	}))
+}
+
+func TestPatchWithoutCABundle(t *testing.T) {
+	g := NewWithT(t)
+
+	cl := fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+		Patch: func(_ context.Context, _ client.WithWatch, _ client.Object, _ client.Patch, _ ...client.PatchOption) error {
+			t.Fatal("the CRD must not be patched without a CA bundle")
+			return nil
+		},
+	}).Build()
+
+	p := NewCRDPatcher(cl, types.NamespacedName{Namespace: "ns", Name: "svc"}, t.TempDir(), "ztunnels.sailoperator.io")
+	g.Expect(p.patch(context.Background())).To(MatchError(ContainSubstring("failed to read CA bundle")))
+}
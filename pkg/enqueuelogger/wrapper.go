@@ -17,6 +17,7 @@ package enqueuelogger
 import (
 	"context"
 	"reflect"
+	"sync/atomic"
 
 	"github.com/go-logr/logr"
 	"k8s.io/client-go/util/workqueue"
@@ -26,7 +27,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
-var LogEnqueueEvents = false
+// LogEnqueueEvents controls whether the handlers returned by WrapIfNecessary log the items they enqueue. It can be
+// changed while the operator is running.
+var LogEnqueueEvents atomic.Bool
 
 // EnqueueEventLogger is a handler.EventHandler that wraps another handler.EventHandler and logs enqueued items (i.e.
 // if the wrapped handler enqueues items from the event that is being handled, the EnqueueEventLogger logs them).
@@ -59,6 +62,9 @@ func (h *EnqueueEventLogger) Generic(ctx context.Context, e event.TypedGenericEv
 func (h *EnqueueEventLogger) wrapQueue(
 	q workqueue.TypedRateLimitingInterface[reconcile.Request], eventType string, obj client.Object,
 ) workqueue.TypedRateLimitingInterface[reconcile.Request] {
+	if !LogEnqueueEvents.Load() {
+		return q
+	}
 	return &AdditionNotifierQueue{
 		delegate: q,
 		onAdd: func(request reconcile.Request) {
@@ -93,15 +99,14 @@ func determineKind(obj client.Object) string {
 	return kind
 }
 
+// WrapIfNecessary wraps the handler in an EnqueueEventLogger. Since LogEnqueueEvents can be enabled at runtime, the
+// handler is always wrapped, but the wrapper only logs while LogEnqueueEvents is set.
 func WrapIfNecessary(kind string, logger logr.Logger, handler handler.EventHandler) handler.EventHandler {
-	if LogEnqueueEvents {
-		return &EnqueueEventLogger{
-			kind:     kind,
-			logger:   logger,
-			delegate: handler,
-		}
+	return &EnqueueEventLogger{
+		kind:     kind,
+		logger:   logger,
+		delegate: handler,
 	}
-	return handler
 }
 
 type EventSummary struct {
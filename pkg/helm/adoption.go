@@ -0,0 +1,161 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	"helm.sh/helm/v3/pkg/release"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// ManagedByHelmValue is the value of the app.kubernetes.io/managed-by label that Helm requires on resources it adopts
+	ManagedByHelmValue = "Helm"
+
+	// AnnotationReleaseName is the annotation that Helm uses to record the release a resource belongs to
+	AnnotationReleaseName = "meta.helm.sh/release-name"
+
+	// AnnotationReleaseNamespace is the annotation that Helm uses to record the namespace of the release a resource belongs to
+	AnnotationReleaseNamespace = "meta.helm.sh/release-namespace"
+)
+
+// SetReleaseOwnership adds the labels and annotations to obj that mark it as belonging to the specified Helm release.
+// When the release is subsequently installed or upgraded, Helm adopts the existing object and updates it in place
+// instead of failing because the object already exists.
+func SetReleaseOwnership(obj metav1.Object, releaseName, releaseNamespace string) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[constants.KubernetesAppManagedByKey] = ManagedByHelmValue
+	obj.SetLabels(labels)
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[AnnotationReleaseName] = releaseName
+	annotations[AnnotationReleaseNamespace] = releaseNamespace
+	obj.SetAnnotations(annotations)
+}
+
+// HasReleaseOwnership returns true if obj carries the labels and annotations that mark it as belonging to the
+// specified Helm release, e.g. because the release was orphaned. Helm adopts such objects when the release is
+// installed again.
+func HasReleaseOwnership(obj metav1.Object, releaseName, releaseNamespace string) bool {
+	return obj.GetLabels()[constants.KubernetesAppManagedByKey] == ManagedByHelmValue &&
+		obj.GetAnnotations()[AnnotationReleaseName] == releaseName &&
+		obj.GetAnnotations()[AnnotationReleaseNamespace] == releaseNamespace
+}
+
+// RemoveReleaseOwnership removes the labels and annotations from obj that mark it as belonging to the specified
+// Helm release and returns true if obj was changed. The app.kubernetes.io/managed-by label is set to the operator,
+// so that Helm no longer considers the object part of the release.
+func RemoveReleaseOwnership(obj metav1.Object, releaseName, releaseNamespace string) bool {
+	if !HasReleaseOwnership(obj, releaseName, releaseNamespace) {
+		return false
+	}
+	labels := obj.GetLabels()
+	labels[constants.KubernetesAppManagedByKey] = constants.KubernetesAppManagedByValue
+	obj.SetLabels(labels)
+
+	annotations := obj.GetAnnotations()
+	delete(annotations, AnnotationReleaseName)
+	delete(annotations, AnnotationReleaseNamespace)
+	obj.SetAnnotations(annotations)
+	return true
+}
+
+// ReleaseVersion returns the Istio version of the chart that the release was installed from, in the format used in
+// the spec.version field of the operator's resources (e.g. v1.24.2), or "" if the release has no chart metadata.
+func ReleaseVersion(rel *release.Release) string {
+	if rel.Chart == nil || rel.Chart.Metadata == nil || rel.Chart.Metadata.Version == "" {
+		return ""
+	}
+	return "v" + strings.TrimPrefix(rel.Chart.Metadata.Version, "v")
+}
+
+// AdoptRelease takes over the existing release named in the sailoperator.io/adopt-helm-release annotation of obj
+// as the release targetReleaseName in the same namespace, provided that the operator configuration enables the
+// HelmReleaseAdoption feature gate. The adopt function is called first, so that the caller
+// can copy the version and values of the release into the spec of obj. The release is then renamed to
+// targetReleaseName, if its name differs, and the annotation is removed from obj to mark the adoption as complete.
+// The resources of the release are left untouched apart from their Helm metadata, so that the subsequent upgrade
+// of the target release only adds the owner references.
+func (h *ChartManager) AdoptRelease(
+	ctx context.Context, cl client.Client, operatorConfig config.OperatorConfig, obj client.Object, namespace, targetReleaseName string,
+	adopt func(rel *release.Release) error,
+) error {
+	releaseName := obj.GetAnnotations()[constants.AdoptHelmReleaseKey]
+	if releaseName == "" {
+		return nil
+	}
+	if !operatorConfig.IsFeatureEnabled(config.FeatureHelmReleaseAdoption) {
+		return reconciler.NewValidationError(fmt.Sprintf("cannot adopt Helm release %q, because the %s feature gate is disabled",
+			releaseName, config.FeatureHelmReleaseAdoption))
+	}
+
+	rel, err := h.GetRelease(ctx, releaseName, namespace)
+	if err != nil {
+		return err
+	}
+	if releaseName != targetReleaseName {
+		target, err := h.GetRelease(ctx, targetReleaseName, namespace)
+		if err != nil {
+			return err
+		}
+		if target != nil {
+			if rel != nil {
+				return reconciler.NewValidationError(fmt.Sprintf("cannot adopt Helm release %q, because release %q already exists in namespace %q",
+					releaseName, targetReleaseName, namespace))
+			}
+			// the release was renamed in a previous reconciliation, but the annotation wasn't removed
+			return removeAdoptionAnnotation(ctx, cl, obj)
+		}
+	}
+	if rel == nil {
+		return reconciler.NewValidationError(fmt.Sprintf("Helm release %q to adopt not found in namespace %q", releaseName, namespace))
+	}
+
+	if err := adopt(rel); err != nil {
+		return err
+	}
+	if releaseName != targetReleaseName {
+		if err := h.RenameRelease(ctx, releaseName, targetReleaseName, namespace); err != nil {
+			return err
+		}
+	}
+	logf.FromContext(ctx).Info("Adopted Helm release", "release", releaseName, "targetRelease", targetReleaseName)
+	return removeAdoptionAnnotation(ctx, cl, obj)
+}
+
+func removeAdoptionAnnotation(ctx context.Context, cl client.Client, obj client.Object) error {
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	annotations := obj.GetAnnotations()
+	delete(annotations, constants.AdoptHelmReleaseKey)
+	obj.SetAnnotations(annotations)
+	if err := cl.Patch(ctx, obj, patch); err != nil {
+		return fmt.Errorf("failed to remove annotation %s: %w", constants.AdoptHelmReleaseKey, err)
+	}
+	return nil
+}
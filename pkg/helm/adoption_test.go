@@ -0,0 +1,165 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetReleaseOwnership(t *testing.T) {
+	obj := &metav1.ObjectMeta{
+		Name:   "istio-revision-tag-prod",
+		Labels: map[string]string{"istio.io/tag": "prod"},
+	}
+
+	SetReleaseOwnership(obj, "prod-revisiontags", "istio-system")
+
+	expectedLabels := map[string]string{
+		"istio.io/tag":                 "prod",
+		"app.kubernetes.io/managed-by": "Helm",
+	}
+	expectedAnnotations := map[string]string{
+		"meta.helm.sh/release-name":      "prod-revisiontags",
+		"meta.helm.sh/release-namespace": "istio-system",
+	}
+	if diff := cmp.Diff(expectedLabels, obj.Labels); diff != "" {
+		t.Errorf("unexpected labels (-expected +actual):\n%s", diff)
+	}
+	if diff := cmp.Diff(expectedAnnotations, obj.Annotations); diff != "" {
+		t.Errorf("unexpected annotations (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestHasReleaseOwnership(t *testing.T) {
+	obj := &metav1.ObjectMeta{Name: "istio-revision-tag-prod"}
+	if HasReleaseOwnership(obj, "prod-revisiontags", "istio-system") {
+		t.Errorf("expected object without Helm metadata not to belong to the release")
+	}
+
+	SetReleaseOwnership(obj, "prod-revisiontags", "istio-system")
+	if !HasReleaseOwnership(obj, "prod-revisiontags", "istio-system") {
+		t.Errorf("expected object to belong to the release")
+	}
+	if HasReleaseOwnership(obj, "prod-revisiontags", "other-namespace") {
+		t.Errorf("expected object not to belong to a release in another namespace")
+	}
+}
+
+func TestRemoveReleaseOwnership(t *testing.T) {
+	obj := &metav1.ObjectMeta{
+		Name:   "istio-reader-service-account",
+		Labels: map[string]string{"app": "istio-reader"},
+	}
+	if RemoveReleaseOwnership(obj, "istio-base", "istio-system") {
+		t.Errorf("expected object without Helm metadata not to be changed")
+	}
+
+	SetReleaseOwnership(obj, "istio-base", "istio-system")
+	if RemoveReleaseOwnership(obj, "istio-base", "other-namespace") {
+		t.Errorf("expected object of a release in another namespace not to be changed")
+	}
+	if !RemoveReleaseOwnership(obj, "istio-base", "istio-system") {
+		t.Errorf("expected object to be changed")
+	}
+
+	expectedLabels := map[string]string{
+		"app":                          "istio-reader",
+		"app.kubernetes.io/managed-by": "sail-operator",
+	}
+	if diff := cmp.Diff(expectedLabels, obj.Labels); diff != "" {
+		t.Errorf("unexpected labels (-expected +actual):\n%s", diff)
+	}
+	if len(obj.Annotations) != 0 {
+		t.Errorf("expected Helm annotations to be removed, got %v", obj.Annotations)
+	}
+}
+
+func TestDetachReleases(t *testing.T) {
+	cfg := &action.Configuration{
+		Releases:   storage.Init(driver.NewMemory()),
+		KubeClient: &kubefake.PrintingKubeClient{},
+	}
+	newRelease := func(name, chartName string, version int, status release.Status) *release.Release {
+		return &release.Release{
+			Name:      name,
+			Namespace: "istio-system",
+			Version:   version,
+			Info:      &release.Info{Status: status},
+			Chart:     &chart.Chart{Metadata: &chart.Metadata{Name: chartName, Version: "1.24.2"}},
+		}
+	}
+	for _, rel := range []*release.Release{
+		newRelease("istio-base", "base", 1, release.StatusSuperseded),
+		newRelease("istio-base", "base", 2, release.StatusDeployed),
+		newRelease("istiod", "istiod", 1, release.StatusDeployed),
+	} {
+		if err := cfg.Releases.Create(rel); err != nil {
+			t.Fatalf("failed to create release: %v", err)
+		}
+	}
+
+	detached, err := detachReleases(context.Background(), cfg, "base")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"istio-base"}, detached); diff != "" {
+		t.Errorf("unexpected detached releases (-expected +actual):\n%s", diff)
+	}
+
+	if history, _ := cfg.Releases.History("istio-base"); len(history) != 0 {
+		t.Errorf("expected all records of release istio-base to be deleted, got %d records", len(history))
+	}
+	if _, err := cfg.Releases.Deployed("istiod"); err != nil {
+		t.Errorf("expected release istiod to be kept: %v", err)
+	}
+
+	detached, err = detachReleases(context.Background(), cfg, "base")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(detached) != 0 {
+		t.Errorf("expected no releases to be detached again, got %v", detached)
+	}
+}
+
+func TestReleaseVersion(t *testing.T) {
+	testCases := []struct {
+		name     string
+		chart    *chart.Chart
+		expected string
+	}{
+		{name: "no chart", expected: ""},
+		{name: "no version", chart: &chart.Chart{Metadata: &chart.Metadata{Name: "istiod"}}, expected: ""},
+		{name: "plain version", chart: &chart.Chart{Metadata: &chart.Metadata{Name: "istiod", Version: "1.24.2"}}, expected: "v1.24.2"},
+		{name: "prefixed version", chart: &chart.Chart{Metadata: &chart.Metadata{Name: "istiod", Version: "v1.23.4"}}, expected: "v1.23.4"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := ReleaseVersion(&release.Release{Name: "istiod", Chart: tc.chart}); actual != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -15,17 +15,25 @@
 package helm
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 
 	"helm.sh/helm/v3/pkg/action"
 	chartLoader "helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/postrender"
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/storage/driver"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -60,10 +68,11 @@ func (h *ChartManager) newActionConfig(ctx context.Context, namespace string) (*
 	return actionConfig, err
 }
 
-// UpgradeOrInstallChart upgrades a chart in cluster or installs it new if it does not already exist
+// UpgradeOrInstallChart upgrades a chart in cluster or installs it new if it does not already exist.
+// The rendered manifests are passed through the specified postRenderers after the owner reference has been added.
 func (h *ChartManager) UpgradeOrInstallChart(
 	ctx context.Context, chartDir string, values Values,
-	namespace, releaseName string, ownerReference metav1.OwnerReference,
+	namespace, releaseName string, ownerReference metav1.OwnerReference, postRenderers ...postrender.PostRenderer,
 ) (*release.Release, error) {
 	log := logf.FromContext(ctx)
 
@@ -106,11 +115,13 @@ func (h *ChartManager) UpgradeOrInstallChart(
 		return nil, fmt.Errorf("unexpected helm release status %s", rel.Info.Status)
 	}
 
+	postRenderer := NewChainedPostRenderer(append([]postrender.PostRenderer{NewOwnerReferencePostRenderer(ownerReference, "")}, postRenderers...)...)
+
 	if releaseExists {
 		log.V(2).Info("Performing helm upgrade", "chartName", chart.Name())
 
 		updateAction := action.NewUpgrade(cfg)
-		updateAction.PostRenderer = NewOwnerReferencePostRenderer(ownerReference, "")
+		updateAction.PostRenderer = postRenderer
 		updateAction.MaxHistory = 1
 		updateAction.SkipCRDs = true
 
@@ -122,7 +133,7 @@ func (h *ChartManager) UpgradeOrInstallChart(
 		log.V(2).Info("Performing helm install", "chartName", chart.Name())
 
 		installAction := action.NewInstall(cfg)
-		installAction.PostRenderer = NewOwnerReferencePostRenderer(ownerReference, "")
+		installAction.PostRenderer = postRenderer
 		installAction.Namespace = namespace
 		installAction.ReleaseName = releaseName
 		installAction.SkipCRDs = true
@@ -152,6 +163,200 @@ func (h *ChartManager) UninstallChart(ctx context.Context, releaseName, namespac
 	return action.NewUninstall(cfg).Run(releaseName)
 }
 
+// OrphanRelease deletes the records of a release without deleting the resources it installed, and removes the
+// owner references to the owner with the specified UID from those resources, so that they're not garbage-collected
+// when the owner is deleted. The resources keep the labels and annotations through which Helm tracks them, so a
+// subsequent install of a release with the same name and namespace adopts them.
+func (h *ChartManager) OrphanRelease(ctx context.Context, releaseName, namespace string, ownerUID types.UID) error {
+	log := logf.FromContext(ctx)
+
+	cfg, err := h.newActionConfig(ctx, namespace)
+	if err != nil {
+		return err
+	}
+
+	rel, err := getRelease(cfg, releaseName)
+	if err != nil || rel == nil {
+		return err
+	}
+
+	err = updateReleaseResources(ctx, cfg, rel, func(obj metav1.Object) bool {
+		return removeOwnerReference(obj, ownerUID)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove owner references from resources of helm release %s: %w", releaseName, err)
+	}
+
+	if err := deleteReleaseHistory(cfg, releaseName); err != nil {
+		return err
+	}
+	log.Info("Orphaned helm release", "release", releaseName)
+	return nil
+}
+
+// GetRelease returns the current version of the release with the given name, or nil if the release doesn't exist.
+func (h *ChartManager) GetRelease(ctx context.Context, releaseName, namespace string) (*release.Release, error) {
+	cfg, err := h.newActionConfig(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return getRelease(cfg, releaseName)
+}
+
+// RenameRelease re-associates the resources of a deployed release with a release of a different name in the same
+// namespace. The resources are only relabeled, so the workloads keep running. The history of the old release is
+// replaced by a single record of the new release that contains the old release's chart, values and manifest, so
+// that the next upgrade of the new release updates the resources in place instead of re-creating them.
+func (h *ChartManager) RenameRelease(ctx context.Context, releaseName, newReleaseName, namespace string) error {
+	log := logf.FromContext(ctx)
+
+	cfg, err := h.newActionConfig(ctx, namespace)
+	if err != nil {
+		return err
+	}
+
+	rel, err := getRelease(cfg, releaseName)
+	if err != nil {
+		return err
+	} else if rel == nil {
+		return fmt.Errorf("helm release %s not found in namespace %s", releaseName, namespace)
+	} else if rel.Info.Status != release.StatusDeployed {
+		return fmt.Errorf("cannot rename helm release %s with status %s", releaseName, rel.Info.Status)
+	}
+
+	if existing, err := getRelease(cfg, newReleaseName); err != nil {
+		return err
+	} else if existing != nil {
+		return fmt.Errorf("cannot rename helm release %s to %s, because a release with that name already exists", releaseName, newReleaseName)
+	}
+
+	err = updateReleaseResources(ctx, cfg, rel, func(obj metav1.Object) bool {
+		if HasReleaseOwnership(obj, newReleaseName, namespace) {
+			return false
+		}
+		SetReleaseOwnership(obj, newReleaseName, namespace)
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("failed to re-associate resources of helm release %s with release %s: %w", releaseName, newReleaseName, err)
+	}
+
+	info := *rel.Info
+	info.Description = fmt.Sprintf("Renamed from release %s", releaseName)
+	renamed := *rel
+	renamed.Name = newReleaseName
+	renamed.Version = 1
+	renamed.Info = &info
+	if err := cfg.Releases.Create(&renamed); err != nil {
+		return fmt.Errorf("failed to create record of helm release %s: %w", newReleaseName, err)
+	}
+
+	if err := deleteReleaseHistory(cfg, releaseName); err != nil {
+		return err
+	}
+	log.Info("Renamed helm release", "release", releaseName, "newRelease", newReleaseName)
+	return nil
+}
+
+// DetachReleases deletes the records of the deployed releases of the specified chart in the namespace without
+// deleting the resources they installed, and removes the Helm metadata from those resources, so that neither Helm
+// nor the operator deletes them. Unlike OrphanRelease, the resources can't be adopted by installing the release
+// again. It returns the names of the detached releases.
+func (h *ChartManager) DetachReleases(ctx context.Context, chartName, namespace string) ([]string, error) {
+	cfg, err := h.newActionConfig(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return detachReleases(ctx, cfg, chartName)
+}
+
+func detachReleases(ctx context.Context, cfg *action.Configuration, chartName string) ([]string, error) {
+	log := logf.FromContext(ctx)
+
+	releases, err := cfg.Releases.ListDeployed()
+	if err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+		return nil, fmt.Errorf("failed to list helm releases: %w", err)
+	}
+
+	var detached []string
+	for _, rel := range releases {
+		if rel.Chart == nil || rel.Chart.Metadata == nil || rel.Chart.Metadata.Name != chartName {
+			continue
+		}
+		err := updateReleaseResources(ctx, cfg, rel, func(obj metav1.Object) bool {
+			return RemoveReleaseOwnership(obj, rel.Name, rel.Namespace)
+		})
+		if err != nil {
+			return detached, fmt.Errorf("failed to remove helm metadata from resources of helm release %s: %w", rel.Name, err)
+		}
+		if err := deleteReleaseHistory(cfg, rel.Name); err != nil {
+			return detached, err
+		}
+		log.Info("Detached helm release", "release", rel.Name, "chart", chartName)
+		detached = append(detached, rel.Name)
+	}
+	return detached, nil
+}
+
+// updateReleaseResources applies the update function to the metadata of each resource in the release's manifest
+// and patches the labels, annotations and owner references of the resources that the function reports as changed.
+// Resources that no longer exist are skipped.
+func updateReleaseResources(ctx context.Context, cfg *action.Configuration, rel *release.Release, update func(obj metav1.Object) bool) error {
+	log := logf.FromContext(ctx)
+
+	resources, err := cfg.KubeClient.Build(bytes.NewBufferString(rel.Manifest), false)
+	if err != nil {
+		return fmt.Errorf("failed to parse manifest of helm release %s: %w", rel.Name, err)
+	}
+	return resources.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+		helper := resource.NewHelper(info.Client, info.Mapping)
+		obj, err := helper.Get(info.Namespace, info.Name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+		if !update(accessor) {
+			return nil
+		}
+		patch, err := json.Marshal(map[string]any{
+			"metadata": map[string]any{
+				"labels":          accessor.GetLabels(),
+				"annotations":     accessor.GetAnnotations(),
+				"ownerReferences": accessor.GetOwnerReferences(),
+			},
+		})
+		if err != nil {
+			return err
+		}
+		log.V(2).Info("Updating metadata", "kind", info.Mapping.GroupVersionKind.Kind, "name", info.Name, "namespace", info.Namespace)
+		_, err = helper.Patch(info.Namespace, info.Name, types.MergePatchType, patch, nil)
+		return client.IgnoreNotFound(err)
+	})
+}
+
+// deleteReleaseHistory deletes all records of the release, but not the resources it installed.
+func deleteReleaseHistory(cfg *action.Configuration, releaseName string) error {
+	history, err := cfg.Releases.History(releaseName)
+	if err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+		return fmt.Errorf("failed to get history of helm release %s: %w", releaseName, err)
+	}
+	for _, r := range history {
+		if _, err := cfg.Releases.Delete(r.Name, r.Version); err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+			return fmt.Errorf("failed to delete record of helm release %s: %w", releaseName, err)
+		}
+	}
+	return nil
+}
+
 func getRelease(cfg *action.Configuration, releaseName string) (*release.Release, error) {
 	getAction := action.NewGet(cfg)
 	rel, err := getAction.Run(releaseName)
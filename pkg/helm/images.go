@@ -0,0 +1,130 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// sidecarInjectorConfigMapPrefix is the name prefix of the ConfigMaps that hold the values istiod uses when it
+// injects sidecars and gateways
+const sidecarInjectorConfigMapPrefix = "istio-sidecar-injector"
+
+// mapContainerImages replaces the image of each container in the manifest with the value returned by fn, wherever
+// the pod spec is nested (Deployment, DaemonSet, Job, CronJob, etc.)
+func mapContainerImages(node any, fn func(image string) string) {
+	switch node := node.(type) {
+	case map[string]any:
+		for key, value := range node {
+			if key == "containers" || key == "initContainers" || key == "ephemeralContainers" {
+				if containers, ok := value.([]any); ok {
+					for _, container := range containers {
+						if container, ok := container.(map[string]any); ok {
+							if image, ok := container["image"].(string); ok {
+								container["image"] = fn(image)
+							}
+						}
+					}
+				}
+			}
+			mapContainerImages(value, fn)
+		}
+	case []any:
+		for _, item := range node {
+			mapContainerImages(item, fn)
+		}
+	}
+}
+
+// decodeInjectorValues returns the values stored in the manifest if it is a sidecar injector ConfigMap. The
+// values aren't part of any container in the manifests, but istiod uses them for the proxy and init containers
+// it injects into workloads and gateways.
+func decodeInjectorValues(manifest map[string]any) (values map[string]any, found bool, err error) {
+	if manifest["kind"] != "ConfigMap" {
+		return nil, false, nil
+	}
+	name, _, _ := unstructured.NestedString(manifest, "metadata", "name")
+	if !strings.HasPrefix(name, sidecarInjectorConfigMapPrefix) {
+		return nil, false, nil
+	}
+	valuesJSON, found, _ := unstructured.NestedString(manifest, "data", "values")
+	if !found {
+		return nil, false, nil
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(valuesJSON))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return nil, false, fmt.Errorf("failed to parse values in ConfigMap %s: %w", name, err)
+	}
+	return values, true, nil
+}
+
+// encodeInjectorValues stores the values in the sidecar injector ConfigMap in the manifest, formatted like the
+// values rendered by the chart
+func encodeInjectorValues(manifest map[string]any, values map[string]any) error {
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(values); err != nil {
+		return fmt.Errorf("failed to encode sidecar injector values: %w", err)
+	}
+	return unstructured.SetNestedField(manifest, strings.TrimSuffix(buf.String(), "\n"), "data", "values")
+}
+
+// injectedProxyImage returns the proxy image that istiod injects according to the sidecar injector values, in the
+// same way as the injection template: global.proxy.image is used as is if it contains a slash, otherwise the image
+// is composed of global.hub, global.proxy.image, global.tag and global.variant.
+func injectedProxyImage(values map[string]any) string {
+	image := stringValue(values, "global", "proxy", "image")
+	if image == "" || strings.Contains(image, "/") {
+		return image
+	}
+	hub := stringValue(values, "global", "hub")
+	if hub == "" {
+		return ""
+	}
+	image = hub + "/" + image
+	if tag := stringValue(values, "global", "tag"); tag != "" {
+		image += ":" + tag
+		if variant := stringValue(values, "global", "variant"); variant != "" {
+			image += "-" + variant
+		}
+	}
+	return image
+}
+
+// stringValue returns the value at the given path as a string, including numeric values such as tags. It returns
+// an empty string if the path doesn't exist.
+func stringValue(values map[string]any, fields ...string) string {
+	value, found, _ := unstructured.NestedFieldNoCopy(values, fields...)
+	if !found || value == nil {
+		return ""
+	}
+	switch value := value.(type) {
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	default:
+		return fmt.Sprint(value)
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"slices"
+
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/imageverification"
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/postrender"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// NewImageVerificationPostRenderer creates a Helm PostRenderer that verifies the images in the rendered manifests
+// against the specified policy using the verifier and fails if any image is rejected, which prevents the chart from
+// being installed or upgraded. The verified images are pinned to their digests in the returned manifests, so that a
+// tag that is moved after the verification doesn't change what is deployed. If the policy is nil, the manifests
+// aren't checked. Since a PostRenderer receives no context, the context used for the registry requests is passed here.
+func NewImageVerificationPostRenderer(
+	ctx context.Context, verifier *imageverification.Verifier, policy *config.ImageVerificationPolicy,
+) postrender.PostRenderer {
+	return ImageVerificationPostRenderer{ctx: ctx, verifier: verifier, policy: policy}
+}
+
+type ImageVerificationPostRenderer struct {
+	ctx      context.Context
+	verifier *imageverification.Verifier
+	policy   *config.ImageVerificationPolicy
+}
+
+var _ postrender.PostRenderer = ImageVerificationPostRenderer{}
+
+func (pr ImageVerificationPostRenderer) Run(renderedManifests *bytes.Buffer) (modifiedManifests *bytes.Buffer, err error) {
+	if pr.policy == nil {
+		return renderedManifests, nil
+	}
+
+	policy, err := imageverification.NewPolicy(*pr.policy)
+	if err != nil {
+		return nil, err
+	}
+	images, err := collectImages(renderedManifests.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	pinned := make(map[string]string, len(images))
+	for _, image := range images {
+		if pinned[image], err = pr.verifier.Verify(pr.ctx, policy, image); err != nil {
+			return nil, err
+		}
+	}
+	return pinImages(renderedManifests, pinned)
+}
+
+// pinImages replaces the images in the manifests with the pinned images, including the proxy image in the values
+// of the sidecar injector ConfigMap
+func pinImages(manifests *bytes.Buffer, pinned map[string]string) (*bytes.Buffer, error) {
+	pin := func(image string) string {
+		if pinnedImage, found := pinned[image]; found {
+			return pinnedImage
+		}
+		return image
+	}
+
+	modifiedManifests := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(modifiedManifests)
+	encoder.SetIndent(2)
+	decoder := yaml.NewDecoder(manifests)
+	for {
+		manifest := map[string]any{}
+
+		if err := decoder.Decode(&manifest); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+
+		if manifest == nil {
+			continue
+		}
+
+		mapContainerImages(manifest, pin)
+		if err := pinInjectedProxyImage(manifest, pin); err != nil {
+			return nil, err
+		}
+
+		if err := encoder.Encode(manifest); err != nil {
+			return nil, err
+		}
+	}
+	return modifiedManifests, nil
+}
+
+// pinInjectedProxyImage replaces the proxy image in the values of the sidecar injector ConfigMap. Since istiod uses
+// global.proxy.image as is when it contains a slash, the pinned image also replaces an image that is composed of
+// global.hub, global.tag and global.variant.
+func pinInjectedProxyImage(manifest map[string]any, pin func(image string) string) error {
+	values, found, err := decodeInjectorValues(manifest)
+	if err != nil || !found {
+		return err
+	}
+	image := injectedProxyImage(values)
+	if image == "" || pin(image) == image {
+		return nil
+	}
+	if err := unstructured.SetNestedField(values, pin(image), "global", "proxy", "image"); err != nil {
+		return err
+	}
+	return encodeInjectorValues(manifest, values)
+}
+
+// collectImages returns the sorted list of container images in the manifests, including the proxy image that
+// istiod injects
+func collectImages(manifests []byte) ([]string, error) {
+	var images []string
+	addImage := func(image string) string {
+		if image != "" && !slices.Contains(images, image) {
+			images = append(images, image)
+		}
+		return image
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(manifests))
+	for {
+		manifest := map[string]any{}
+
+		if err := decoder.Decode(&manifest); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+
+		if manifest == nil {
+			continue
+		}
+
+		mapContainerImages(manifest, addImage)
+		values, found, err := decodeInjectorValues(manifest)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			addImage(injectedProxyImage(values))
+		}
+	}
+	slices.Sort(images)
+	return images, nil
+}
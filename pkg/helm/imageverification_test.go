@@ -0,0 +1,251 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/imageverification"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	"k8s.io/utils/clock"
+)
+
+const (
+	pilotDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	proxyDigest = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+)
+
+var verificationManifests = `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: istiod
+spec:
+  template:
+    spec:
+      containers:
+      - name: discovery
+        image: gcr.io/istio-release/pilot@` + pilotDigest + `
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: istio-sidecar-injector
+data:
+  values: |-
+    {
+      "global": {
+        "hub": "gcr.io/istio-release",
+        "proxy": {
+          "image": "gcr.io/istio-release/proxyv2@` + proxyDigest + `"
+        }
+      }
+    }
+`
+
+func TestCollectImages(t *testing.T) {
+	images, err := collectImages([]byte(verificationManifests))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"gcr.io/istio-release/pilot@" + pilotDigest, "gcr.io/istio-release/proxyv2@" + proxyDigest}
+	if diff := cmp.Diff(expected, images); diff != "" {
+		t.Errorf("unexpected images (-expected +actual):\n%v", diff)
+	}
+}
+
+func TestInjectedProxyImage(t *testing.T) {
+	testCases := []struct {
+		name     string
+		values   map[string]any
+		expected string
+	}{
+		{
+			name:     "full image",
+			values:   map[string]any{"global": map[string]any{"hub": "docker.io/istio", "proxy": map[string]any{"image": "quay.io/istio/proxyv2:1.24.2"}}},
+			expected: "quay.io/istio/proxyv2:1.24.2",
+		},
+		{
+			name:     "hub and tag",
+			values:   map[string]any{"global": map[string]any{"hub": "docker.io/istio", "tag": "1.24.2", "proxy": map[string]any{"image": "proxyv2"}}},
+			expected: "docker.io/istio/proxyv2:1.24.2",
+		},
+		{
+			name: "variant",
+			values: map[string]any{"global": map[string]any{
+				"hub": "docker.io/istio", "tag": "1.24.2", "variant": "distroless", "proxy": map[string]any{"image": "proxyv2"},
+			}},
+			expected: "docker.io/istio/proxyv2:1.24.2-distroless",
+		},
+		{
+			name:     "no hub",
+			values:   map[string]any{"global": map[string]any{"proxy": map[string]any{"image": "proxyv2"}}},
+			expected: "",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := injectedProxyImage(tc.values); actual != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+// pushImage pushes a random image to the registry and returns its digest
+func pushImage(t *testing.T, image string) string {
+	img, err := random.Image(256, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return digest.String()
+}
+
+func TestImageVerificationPostRenderer(t *testing.T) {
+	ctx := context.Background()
+	verifier := imageverification.NewVerifier(clock.RealClock{})
+
+	t.Run("no policy", func(t *testing.T) {
+		input := bytes.NewBufferString(verificationManifests)
+		actual, err := NewImageVerificationPostRenderer(ctx, verifier, nil).Run(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != input {
+			t.Errorf("expected the rendered manifests to be returned unchanged")
+		}
+	})
+
+	t.Run("all images allowed", func(t *testing.T) {
+		policy := &config.ImageVerificationPolicy{AllowedDigests: []string{pilotDigest, proxyDigest}}
+		actual, err := NewImageVerificationPostRenderer(ctx, verifier, policy).Run(bytes.NewBufferString(verificationManifests))
+		if err != nil {
+			t.Fatal(err)
+		}
+		images, err := collectImages(actual.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []string{"gcr.io/istio-release/pilot@" + pilotDigest, "gcr.io/istio-release/proxyv2@" + proxyDigest}
+		if diff := cmp.Diff(expected, images); diff != "" {
+			t.Errorf("unexpected images (-expected +actual):\n%v", diff)
+		}
+	})
+
+	t.Run("tagged images pinned to digests", func(t *testing.T) {
+		server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+		t.Cleanup(server.Close)
+		hub := strings.TrimPrefix(server.URL, "http://") + "/istio"
+		pilot := pushImage(t, hub+"/pilot:1.24.2")
+		proxy := pushImage(t, hub+"/proxyv2:1.24.2")
+
+		input := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: istiod
+spec:
+  template:
+    spec:
+      containers:
+      - name: discovery
+        image: ` + hub + `/pilot:1.24.2
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: istio-sidecar-injector
+data:
+  values: |-
+    {
+      "global": {
+        "hub": "` + hub + `",
+        "proxy": {
+          "image": "proxyv2"
+        },
+        "tag": "1.24.2"
+      }
+    }
+`
+		expected := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: istiod
+spec:
+  template:
+    spec:
+      containers:
+        - image: ` + hub + `/pilot@` + pilot + `
+          name: discovery
+---
+apiVersion: v1
+data:
+  values: |-
+    {
+      "global": {
+        "hub": "` + hub + `",
+        "proxy": {
+          "image": "` + hub + `/proxyv2@` + proxy + `"
+        },
+        "tag": "1.24.2"
+      }
+    }
+kind: ConfigMap
+metadata:
+  name: istio-sidecar-injector
+`
+		policy := &config.ImageVerificationPolicy{AllowedDigests: []string{pilot, proxy}}
+		actual, err := NewImageVerificationPostRenderer(ctx, verifier, policy).Run(bytes.NewBufferString(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(expected, actual.String()); diff != "" {
+			t.Errorf("unexpected manifests (-expected +actual):\n%v", diff)
+		}
+	})
+
+	t.Run("injected proxy image not allowed", func(t *testing.T) {
+		policy := &config.ImageVerificationPolicy{AllowedDigests: []string{pilotDigest}}
+		_, err := NewImageVerificationPostRenderer(ctx, verifier, policy).Run(bytes.NewBufferString(verificationManifests))
+		if !reconciler.IsValidationError(err) || !imageverification.IsVerificationError(err) {
+			t.Fatalf("expected verification error, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "gcr.io/istio-release/proxyv2@"+proxyDigest) {
+			t.Errorf("expected error to name the rejected image, got %v", err)
+		}
+	})
+}
@@ -24,6 +24,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 const (
@@ -111,3 +112,20 @@ func (pr OwnerReferencePostRenderer) addOwnerReference(manifest map[string]any)
 	}
 	return manifest, nil
 }
+
+// removeOwnerReference removes the owner references to the owner with the specified UID from obj. It returns
+// whether any owner reference was removed.
+func removeOwnerReference(obj metav1.Object, ownerUID types.UID) bool {
+	refs := obj.GetOwnerReferences()
+	remaining := make([]metav1.OwnerReference, 0, len(refs))
+	for _, ref := range refs {
+		if ref.UID != ownerUID {
+			remaining = append(remaining, ref)
+		}
+	}
+	if len(remaining) == len(refs) {
+		return false
+	}
+	obj.SetOwnerReferences(remaining)
+	return true
+}
@@ -90,3 +90,20 @@ spec:
 		t.Errorf("ownerReference wasn't added properly; diff (-expected, +actual):\n%v", diff)
 	}
 }
+
+func TestRemoveOwnerReference(t *testing.T) {
+	sailRef := metav1.OwnerReference{APIVersion: "sailoperator.io/v1", Kind: "IstioRevision", Name: "default", UID: "123"}
+	otherRef := metav1.OwnerReference{APIVersion: "v1", Kind: "ConfigMap", Name: "other", UID: "456"}
+
+	obj := &metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{otherRef, sailRef}}
+	if !removeOwnerReference(obj, sailRef.UID) {
+		t.Errorf("expected owner reference to be removed")
+	}
+	if diff := cmp.Diff([]metav1.OwnerReference{otherRef}, obj.OwnerReferences); diff != "" {
+		t.Errorf("unexpected owner references (-expected +actual):\n%v", diff)
+	}
+
+	if removeOwnerReference(obj, sailRef.UID) {
+		t.Errorf("expected no owner reference to be removed")
+	}
+}
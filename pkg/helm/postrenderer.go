@@ -0,0 +1,43 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"bytes"
+
+	"helm.sh/helm/v3/pkg/postrender"
+)
+
+// NewChainedPostRenderer creates a Helm PostRenderer that passes the rendered
+// manifests through each of the specified PostRenderers in order
+func NewChainedPostRenderer(postRenderers ...postrender.PostRenderer) postrender.PostRenderer {
+	return ChainedPostRenderer{postRenderers: postRenderers}
+}
+
+type ChainedPostRenderer struct {
+	postRenderers []postrender.PostRenderer
+}
+
+var _ postrender.PostRenderer = ChainedPostRenderer{}
+
+func (pr ChainedPostRenderer) Run(renderedManifests *bytes.Buffer) (modifiedManifests *bytes.Buffer, err error) {
+	modifiedManifests = renderedManifests
+	for _, postRenderer := range pr.postRenderers {
+		if modifiedManifests, err = postRenderer.Run(modifiedManifests); err != nil {
+			return nil, err
+		}
+	}
+	return modifiedManifests, nil
+}
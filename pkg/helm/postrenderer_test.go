@@ -0,0 +1,75 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type funcPostRenderer func(*bytes.Buffer) (*bytes.Buffer, error)
+
+func (f funcPostRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	return f(renderedManifests)
+}
+
+func appendingPostRenderer(suffix string) funcPostRenderer {
+	return func(in *bytes.Buffer) (*bytes.Buffer, error) {
+		return bytes.NewBufferString(in.String() + suffix), nil
+	}
+}
+
+func TestChainedPostRenderer(t *testing.T) {
+	t.Run("runs post renderers in order", func(t *testing.T) {
+		postRenderer := NewChainedPostRenderer(appendingPostRenderer("a"), appendingPostRenderer("b"))
+		actual, err := postRenderer.Run(bytes.NewBufferString("-"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diff := cmp.Diff("-ab", actual.String()); diff != "" {
+			t.Errorf("unexpected output (-expected +actual):\n%s", diff)
+		}
+	})
+
+	t.Run("returns the input when empty", func(t *testing.T) {
+		actual, err := NewChainedPostRenderer().Run(bytes.NewBufferString("input"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diff := cmp.Diff("input", actual.String()); diff != "" {
+			t.Errorf("unexpected output (-expected +actual):\n%s", diff)
+		}
+	})
+
+	t.Run("stops at first error", func(t *testing.T) {
+		called := false
+		failing := funcPostRenderer(func(*bytes.Buffer) (*bytes.Buffer, error) {
+			return nil, errors.New("simulated error")
+		})
+		next := funcPostRenderer(func(in *bytes.Buffer) (*bytes.Buffer, error) {
+			called = true
+			return in, nil
+		})
+		if _, err := NewChainedPostRenderer(failing, next).Run(bytes.NewBufferString("input")); err == nil {
+			t.Fatal("expected error")
+		}
+		if called {
+			t.Error("expected post renderer after the failing one not to be called")
+		}
+	})
+}
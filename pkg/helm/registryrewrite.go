@@ -0,0 +1,106 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/postrender"
+)
+
+// NewRegistryRewritePostRenderer creates a Helm PostRenderer that rewrites the images in the rendered manifests
+// according to the specified rules
+func NewRegistryRewritePostRenderer(rules []config.RegistryRewrite) postrender.PostRenderer {
+	return RegistryRewritePostRenderer{rules: rules}
+}
+
+type RegistryRewritePostRenderer struct {
+	rules []config.RegistryRewrite
+}
+
+var _ postrender.PostRenderer = RegistryRewritePostRenderer{}
+
+func (pr RegistryRewritePostRenderer) Run(renderedManifests *bytes.Buffer) (modifiedManifests *bytes.Buffer, err error) {
+	if len(pr.rules) == 0 {
+		return renderedManifests, nil
+	}
+
+	modifiedManifests = &bytes.Buffer{}
+	encoder := yaml.NewEncoder(modifiedManifests)
+	encoder.SetIndent(2)
+	decoder := yaml.NewDecoder(renderedManifests)
+	for {
+		manifest := map[string]any{}
+
+		if err := decoder.Decode(&manifest); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+
+		if manifest == nil {
+			continue
+		}
+
+		mapContainerImages(manifest, func(image string) string {
+			return config.RewriteImage(pr.rules, image)
+		})
+		if err := pr.rewriteInjectorValues(manifest); err != nil {
+			return nil, err
+		}
+
+		if err := encoder.Encode(manifest); err != nil {
+			return nil, err
+		}
+	}
+	return modifiedManifests, nil
+}
+
+// rewriteInjectorValues rewrites the hub and image fields in the values of the sidecar injector ConfigMap
+func (pr RegistryRewritePostRenderer) rewriteInjectorValues(manifest map[string]any) error {
+	values, found, err := decodeInjectorValues(manifest)
+	if err != nil || !found || !pr.rewriteHubsAndImages(values) {
+		return err
+	}
+	return encodeInjectorValues(manifest, values)
+}
+
+// rewriteHubsAndImages rewrites all string fields named hub or image in the values. It returns whether any field
+// was changed.
+func (pr RegistryRewritePostRenderer) rewriteHubsAndImages(node any) bool {
+	changed := false
+	switch node := node.(type) {
+	case map[string]any:
+		for key, value := range node {
+			if str, ok := value.(string); ok && (key == "hub" || key == "image") {
+				if rewritten := config.RewriteImage(pr.rules, str); rewritten != str {
+					node[key] = rewritten
+					changed = true
+				}
+				continue
+			}
+			changed = pr.rewriteHubsAndImages(value) || changed
+		}
+	case []any:
+		for _, item := range node {
+			changed = pr.rewriteHubsAndImages(item) || changed
+		}
+	}
+	return changed
+}
@@ -0,0 +1,140 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+)
+
+func TestRegistryRewritePostRenderer(t *testing.T) {
+	postRenderer := NewRegistryRewritePostRenderer([]config.RegistryRewrite{
+		{Source: "gcr.io/istio-release", Mirror: "mirror.example.com:5000/istio"},
+		{Source: "docker.io/istio", Mirror: "mirror.example.com/dockerhub/istio"},
+	})
+
+	input := `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: istiod
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: docker.io/istio/proxyv2:1.24.2
+      containers:
+      - name: discovery
+        image: gcr.io/istio-release/pilot@sha256:abc123
+      - name: other
+        image: quay.io/other/image:latest
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: istio-sidecar-injector
+data:
+  config: |-
+    image: "{{ .Values.global.hub }}/{{ .Values.global.proxy.image }}"
+  values: |-
+    {
+      "global": {
+        "hub": "docker.io/istio",
+        "proxy": {
+          "image": "gcr.io/istio-release/proxyv2@sha256:def456",
+          "readinessPeriodSeconds": 15
+        },
+        "proxy_init": {
+          "image": "proxyv2"
+        }
+      }
+    }
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unrelated
+data:
+  values: '{"hub": "docker.io/istio"}'
+`
+
+	expected := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: istiod
+spec:
+  template:
+    spec:
+      containers:
+        - image: mirror.example.com:5000/istio/pilot@sha256:abc123
+          name: discovery
+        - image: quay.io/other/image:latest
+          name: other
+      initContainers:
+        - image: mirror.example.com/dockerhub/istio/proxyv2:1.24.2
+          name: init
+---
+apiVersion: v1
+data:
+  config: 'image: "{{ .Values.global.hub }}/{{ .Values.global.proxy.image }}"'
+  values: |-
+    {
+      "global": {
+        "hub": "mirror.example.com/dockerhub/istio",
+        "proxy": {
+          "image": "mirror.example.com:5000/istio/proxyv2@sha256:def456",
+          "readinessPeriodSeconds": 15
+        },
+        "proxy_init": {
+          "image": "proxyv2"
+        }
+      }
+    }
+kind: ConfigMap
+metadata:
+  name: istio-sidecar-injector
+---
+apiVersion: v1
+data:
+  values: '{"hub": "docker.io/istio"}'
+kind: ConfigMap
+metadata:
+  name: unrelated
+`
+
+	actual, err := postRenderer.Run(bytes.NewBufferString(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(expected, actual.String()); diff != "" {
+		t.Errorf("images weren't rewritten properly; diff (-expected, +actual):\n%v", diff)
+	}
+}
+
+func TestRegistryRewritePostRendererWithoutRules(t *testing.T) {
+	input := bytes.NewBufferString("kind: Pod\nspec:\n  containers:\n  - image: docker.io/istio/proxyv2:1.24.2\n")
+	actual, err := NewRegistryRewritePostRenderer(nil).Run(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual != input {
+		t.Errorf("expected the rendered manifests to be returned unchanged")
+	}
+}
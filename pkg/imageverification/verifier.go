@@ -0,0 +1,315 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imageverification
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	"k8s.io/utils/clock"
+	"k8s.io/utils/lru"
+)
+
+const (
+	// SignatureAnnotation is the annotation of a layer in a cosign signature image that holds the base64-encoded
+	// signature of the layer's payload
+	SignatureAnnotation = "dev.cosignproject.cosign/signature"
+
+	// SignatureMediaType is the media type of the payload layers in a cosign signature image
+	SignatureMediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+)
+
+// resolveTTL is how long the digest that a tag resolves to is cached. A tag can be moved to another image, so the
+// digest is looked up again after this time, but not on every reconciliation.
+const resolveTTL = 5 * time.Minute
+
+// maxCacheEntries is the maximum number of entries in each of the Verifier's caches. The least recently used
+// entries are evicted when the limit is reached.
+const maxCacheEntries = 1000
+
+// errNoValidSignature is returned when the signature image of an image contains no signature that can be verified
+var errNoValidSignature = errors.New("no valid signature")
+
+type resolvedDigest struct {
+	digest  string
+	expires time.Time
+}
+
+// Verifier checks images against ImageVerificationPolicies. It caches the digests that tags resolve to and the
+// digests whose signatures were verified, so it is meant to be created once and kept for the lifetime of the
+// reconciler that uses it. It is safe for concurrent use.
+type Verifier struct {
+	clock         clock.PassiveClock
+	remoteOptions []remote.Option
+
+	// resolvedDigests caches the digests that tags resolve to, keyed by the fully-qualified tag. The entries expire
+	// after resolveTTL.
+	resolvedDigests *lru.Cache
+
+	// verifiedSignatures caches the digests whose signatures were verified, keyed by the key ID and the digest. The
+	// digest of an image can't change, so the result doesn't have to be verified again on every reconciliation.
+	verifiedSignatures *lru.Cache
+}
+
+// NewVerifier creates a Verifier that uses the clock to expire the cached digests of tags.
+func NewVerifier(c clock.PassiveClock) *Verifier {
+	return &Verifier{
+		clock: c,
+		// the default keychain reads the credentials from the operator's Docker config file, if any, and falls
+		// back to anonymous access
+		remoteOptions:      []remote.Option{remote.WithAuthFromKeychain(authn.DefaultKeychain)},
+		resolvedDigests:    lru.New(maxCacheEntries),
+		verifiedSignatures: lru.New(maxCacheEntries),
+	}
+}
+
+// Policy is an ImageVerificationPolicy whose public key has been parsed.
+type Policy struct {
+	allowedDigests map[string]struct{}
+	publicKey      crypto.PublicKey
+	keyID          string
+}
+
+// NewPolicy parses the policy. It returns an error if the policy's public key is invalid.
+func NewPolicy(policy config.ImageVerificationPolicy) (*Policy, error) {
+	p := &Policy{
+		allowedDigests: make(map[string]struct{}, len(policy.AllowedDigests)),
+	}
+	for _, digest := range policy.AllowedDigests {
+		p.allowedDigests[digest] = struct{}{}
+	}
+	if policy.PublicKey != "" {
+		key, err := ParsePublicKey(policy.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		p.publicKey = key
+		keyHash := sha256.Sum256([]byte(policy.PublicKey))
+		p.keyID = hex.EncodeToString(keyHash[:])
+	}
+	return p, nil
+}
+
+// ParsePublicKey parses a PEM-encoded ECDSA, RSA or Ed25519 public key, as generated by cosign generate-key-pair.
+func ParsePublicKey(publicKey string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKey))
+	if block == nil {
+		return nil, fmt.Errorf("public key is not PEM-encoded")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	switch key.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", key)
+	}
+}
+
+// Verify resolves the image to its digest and checks whether the policy allows it. It returns the image pinned to
+// the verified digest, so that the image that is deployed is the one that was verified, even if its tag is moved
+// later. If the image is rejected by the policy, Verify returns a VerificationError naming the image. Other errors,
+// e.g. when the registry can't be reached, are returned as is, so that the reconciliation is retried.
+func (v *Verifier) Verify(ctx context.Context, policy *Policy, image string) (string, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return "", NewVerificationError(image, fmt.Sprintf("invalid reference: %v", err))
+	}
+	digest, err := v.resolve(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve digest of image %s: %w", image, err)
+	}
+	if _, found := policy.allowedDigests[digest.DigestStr()]; !found {
+		if policy.publicKey == nil {
+			return "", NewVerificationError(image, fmt.Sprintf("digest %s is not allowed", digest.DigestStr()))
+		}
+		if err := v.verifySignature(ctx, policy, digest); err != nil {
+			if errors.Is(err, errNoValidSignature) {
+				return "", NewVerificationError(image, fmt.Sprintf("digest %s has no valid signature", digest.DigestStr()))
+			}
+			return "", fmt.Errorf("failed to verify signature of image %s: %w", image, err)
+		}
+	}
+	return pin(image, ref, digest), nil
+}
+
+// pin returns the image referenced by the digest. The repository is kept as written in the image rather than in
+// its normalized form (e.g. istio/pilot instead of index.docker.io/istio/pilot).
+func pin(image string, ref name.Reference, digest name.Digest) string {
+	tag, ok := ref.(name.Tag)
+	if !ok {
+		return image
+	}
+	return strings.TrimSuffix(image, ":"+tag.TagStr()) + "@" + digest.DigestStr()
+}
+
+// resolve returns the digest of the image. Images that are referenced by digest are not looked up in the registry,
+// and the digests of tags are cached for resolveTTL.
+func (v *Verifier) resolve(ctx context.Context, ref name.Reference) (name.Digest, error) {
+	if digest, ok := ref.(name.Digest); ok {
+		return digest, nil
+	}
+	cacheKey := ref.Name()
+	if cached, found := v.resolvedDigests.Get(cacheKey); found {
+		if cached := cached.(resolvedDigest); v.clock.Now().Before(cached.expires) {
+			return ref.Context().Digest(cached.digest), nil
+		}
+		v.resolvedDigests.Remove(cacheKey)
+	}
+
+	desc, err := remote.Head(ref, v.options(ctx)...)
+	if err != nil {
+		return name.Digest{}, err
+	}
+	v.resolvedDigests.Add(cacheKey, resolvedDigest{digest: desc.Digest.String(), expires: v.clock.Now().Add(resolveTTL)})
+	return ref.Context().Digest(desc.Digest.String()), nil
+}
+
+// verifySignature verifies the cosign signature of the image. Cosign stores the signatures of an image in a
+// separate image in the same repository, tagged with the image's digest and the .sig suffix. Each layer of that
+// image is a payload naming the signed digest, with the signature of the payload in an annotation.
+func (v *Verifier) verifySignature(ctx context.Context, policy *Policy, digest name.Digest) error {
+	cacheKey := policy.keyID + "/" + digest.String()
+	if _, found := v.verifiedSignatures.Get(cacheKey); found {
+		return nil
+	}
+
+	sigTag := digest.Context().Tag(strings.Replace(digest.DigestStr(), ":", "-", 1) + ".sig")
+	sigImage, err := remote.Image(sigTag, v.options(ctx)...)
+	if err != nil {
+		var transportErr *transport.Error
+		if errors.As(err, &transportErr) && transportErr.StatusCode == http.StatusNotFound {
+			return errNoValidSignature
+		}
+		return err
+	}
+	manifest, err := sigImage.Manifest()
+	if err != nil {
+		return err
+	}
+
+	for _, desc := range manifest.Layers {
+		signature, err := base64.StdEncoding.DecodeString(desc.Annotations[SignatureAnnotation])
+		if err != nil || len(signature) == 0 {
+			continue
+		}
+		layer, err := sigImage.LayerByDigest(desc.Digest)
+		if err != nil {
+			return err
+		}
+		payload, err := readLayer(layer.Compressed)
+		if err != nil {
+			return err
+		}
+		if verifyPayload(policy.publicKey, payload, signature) && signedDigest(payload) == digest.DigestStr() {
+			v.verifiedSignatures.Add(cacheKey, struct{}{})
+			return nil
+		}
+	}
+	return errNoValidSignature
+}
+
+func (v *Verifier) options(ctx context.Context) []remote.Option {
+	return append([]remote.Option{remote.WithContext(ctx)}, v.remoteOptions...)
+}
+
+func readLayer(open func() (io.ReadCloser, error)) ([]byte, error) {
+	rc, err := open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// verifyPayload returns whether the signature of the payload is valid for the key.
+func verifyPayload(publicKey crypto.PublicKey, payload, signature []byte) bool {
+	hash := sha256.Sum256(payload)
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(key, hash[:], signature)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], signature) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, payload, signature)
+	}
+	return false
+}
+
+// signedDigest returns the image digest named in a cosign payload.
+func signedDigest(payload []byte) string {
+	var simpleSigning struct {
+		Critical struct {
+			Image struct {
+				DockerManifestDigest string `json:"docker-manifest-digest"`
+			} `json:"image"`
+		} `json:"critical"`
+	}
+	if err := json.Unmarshal(payload, &simpleSigning); err != nil {
+		return ""
+	}
+	return simpleSigning.Critical.Image.DockerManifestDigest
+}
+
+// VerificationError is returned when an image is rejected by the ImageVerificationPolicy. It wraps a validation
+// error, so the reconciliation isn't retried until the resource or the operator configuration changes.
+type VerificationError struct {
+	// Image is the rejected image, as it appears in the rendered manifests.
+	Image string
+	err   error
+}
+
+func NewVerificationError(image, reason string) error {
+	return &VerificationError{
+		Image: image,
+		err:   reconciler.NewValidationError(fmt.Sprintf("image %s failed verification: %s", image, reason)),
+	}
+}
+
+func (e VerificationError) Error() string {
+	return e.err.Error()
+}
+
+func (e VerificationError) Unwrap() error {
+	return e.err
+}
+
+func IsVerificationError(err error) bool {
+	e := &VerificationError{}
+	return errors.As(err, &e)
+}
@@ -0,0 +1,305 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imageverification
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/clock"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/lru"
+)
+
+// testRegistry is a local OCI registry that stands in for the registries the images are pulled from
+type testRegistry struct {
+	t    *testing.T
+	host string
+}
+
+func newTestRegistry(t *testing.T) *testRegistry {
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(server.Close)
+	return &testRegistry{t: t, host: strings.TrimPrefix(server.URL, "http://")}
+}
+
+// push pushes a random image to the repository and returns its reference and digest
+func (r *testRegistry) push(repository string) (string, v1.Hash) {
+	img, err := random.Image(256, 1)
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	image := fmt.Sprintf("%s/%s:1.24.2", r.host, repository)
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	if err := remote.Write(ref, img); err != nil {
+		r.t.Fatal(err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	return image, digest
+}
+
+// sign pushes a cosign signature of the image with the given digest, like cosign sign does
+func (r *testRegistry) sign(repository string, digest v1.Hash, key *ecdsa.PrivateKey) {
+	payload := []byte(fmt.Sprintf(
+		`{"critical":{"identity":{"docker-reference":"%s/%s"},"image":{"docker-manifest-digest":"%s"},"type":"cosign container image signature"},"optional":null}`,
+		r.host, repository, digest))
+	hash := sha256.Sum256(payload)
+	signature, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+	if err != nil {
+		r.t.Fatal(err)
+	}
+
+	sigImage, err := mutate.Append(mutate.MediaType(empty.Image, types.OCIManifestSchema1), mutate.Addendum{
+		Layer:       static.NewLayer(payload, SignatureMediaType),
+		Annotations: map[string]string{SignatureAnnotation: base64.StdEncoding.EncodeToString(signature)},
+	})
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	ref, err := name.NewTag(fmt.Sprintf("%s/%s:%s-%s.sig", r.host, repository, digest.Algorithm, digest.Hex))
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	if err := remote.Write(ref, sigImage); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+func generateKey(t *testing.T) (*ecdsa.PrivateKey, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func TestVerify(t *testing.T) {
+	reg := newTestRegistry(t)
+	key, publicKey := generateKey(t)
+	otherKey, _ := generateKey(t)
+
+	signedImage, signedDigest := reg.push("istio/pilot")
+	reg.sign("istio/pilot", signedDigest, key)
+	unsignedImage, unsignedDigest := reg.push("istio/proxyv2")
+	otherKeyImage, otherKeyDigest := reg.push("istio/install-cni")
+	reg.sign("istio/install-cni", otherKeyDigest, otherKey)
+	signedByDigest := fmt.Sprintf("%s/istio/pilot@%s", reg.host, signedDigest)
+	unsignedByDigest := fmt.Sprintf("%s/istio/proxyv2@%s", reg.host, unsignedDigest)
+
+	testCases := []struct {
+		name                 string
+		policy               config.ImageVerificationPolicy
+		image                string
+		expectedImage        string
+		expectValidationErr  bool
+		expectOtherErr       bool
+		expectErrContainsRef bool
+	}{
+		{
+			name:          "allowed digest",
+			policy:        config.ImageVerificationPolicy{AllowedDigests: []string{unsignedDigest.String()}},
+			image:         unsignedImage,
+			expectedImage: unsignedByDigest,
+		},
+		{
+			name:                 "digest not allowed",
+			policy:               config.ImageVerificationPolicy{AllowedDigests: []string{signedDigest.String()}},
+			image:                unsignedImage,
+			expectValidationErr:  true,
+			expectErrContainsRef: true,
+		},
+		{
+			name:          "valid signature",
+			policy:        config.ImageVerificationPolicy{PublicKey: publicKey},
+			image:         signedImage,
+			expectedImage: signedByDigest,
+		},
+		{
+			name:          "valid signature of image referenced by digest",
+			policy:        config.ImageVerificationPolicy{PublicKey: publicKey},
+			image:         signedByDigest,
+			expectedImage: signedByDigest,
+		},
+		{
+			name:                 "no signature",
+			policy:               config.ImageVerificationPolicy{PublicKey: publicKey},
+			image:                unsignedImage,
+			expectValidationErr:  true,
+			expectErrContainsRef: true,
+		},
+		{
+			name:                 "signed with other key",
+			policy:               config.ImageVerificationPolicy{PublicKey: publicKey},
+			image:                otherKeyImage,
+			expectValidationErr:  true,
+			expectErrContainsRef: true,
+		},
+		{
+			name:          "unsigned but allowed digest",
+			policy:        config.ImageVerificationPolicy{PublicKey: publicKey, AllowedDigests: []string{unsignedDigest.String()}},
+			image:         unsignedImage,
+			expectedImage: unsignedByDigest,
+		},
+		{
+			name:           "image not found",
+			policy:         config.ImageVerificationPolicy{PublicKey: publicKey},
+			image:          reg.host + "/istio/missing:1.24.2",
+			expectOtherErr: true,
+		},
+		{
+			name:                "invalid reference",
+			policy:              config.ImageVerificationPolicy{PublicKey: publicKey},
+			image:               "Invalid Image",
+			expectValidationErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			policy, err := NewPolicy(tc.policy)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			pinned, err := NewVerifier(clock.RealClock{}).Verify(context.Background(), policy, tc.image)
+			switch {
+			case tc.expectValidationErr:
+				g.Expect(reconciler.IsValidationError(err)).To(BeTrue(), "expected validation error, got %v", err)
+				g.Expect(IsVerificationError(err)).To(BeTrue(), "expected verification error, got %v", err)
+			case tc.expectOtherErr:
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(reconciler.IsValidationError(err)).To(BeFalse())
+				g.Expect(IsVerificationError(err)).To(BeFalse())
+			default:
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(pinned).To(Equal(tc.expectedImage))
+			}
+			if tc.expectErrContainsRef {
+				g.Expect(err.Error()).To(ContainSubstring(tc.image))
+			}
+		})
+	}
+}
+
+func TestResolveCache(t *testing.T) {
+	g := NewWithT(t)
+	reg := newTestRegistry(t)
+	image, digest := reg.push("istio/pilot")
+	policy, err := NewPolicy(config.ImageVerificationPolicy{AllowedDigests: []string{digest.String()}})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	fakeClock := clocktesting.NewFakePassiveClock(time.Now())
+	verifier := NewVerifier(fakeClock)
+
+	pinned, err := verifier.Verify(context.Background(), policy, image)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pinned).To(HaveSuffix("@" + digest.String()))
+
+	// the tag is moved to another image, but the cached digest is used until it expires
+	_, movedDigest := reg.push("istio/pilot")
+	pinned, err = verifier.Verify(context.Background(), policy, image)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pinned).To(HaveSuffix("@" + digest.String()))
+
+	fakeClock.SetTime(fakeClock.Now().Add(resolveTTL))
+	_, err = verifier.Verify(context.Background(), policy, image)
+	g.Expect(IsVerificationError(err)).To(BeTrue(), "expected verification error, got %v", err)
+	g.Expect(err.Error()).To(ContainSubstring(movedDigest.String()))
+}
+
+func TestResolveCacheEviction(t *testing.T) {
+	g := NewWithT(t)
+	reg := newTestRegistry(t)
+	image, digest := reg.push("istio/pilot")
+	otherImage, otherDigest := reg.push("istio/proxyv2")
+	policy, err := NewPolicy(config.ImageVerificationPolicy{AllowedDigests: []string{digest.String(), otherDigest.String()}})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	verifier := NewVerifier(clocktesting.NewFakePassiveClock(time.Now()))
+	verifier.resolvedDigests = lru.New(1)
+
+	_, err = verifier.Verify(context.Background(), policy, image)
+	g.Expect(err).NotTo(HaveOccurred())
+	_, err = verifier.Verify(context.Background(), policy, otherImage)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(verifier.resolvedDigests.Len()).To(Equal(1))
+
+	// the digest of the first tag was evicted, so the moved tag is resolved again before the TTL expires
+	_, movedDigest := reg.push("istio/pilot")
+	_, err = verifier.Verify(context.Background(), policy, image)
+	g.Expect(IsVerificationError(err)).To(BeTrue(), "expected verification error, got %v", err)
+	g.Expect(err.Error()).To(ContainSubstring(movedDigest.String()))
+}
+
+func TestParsePublicKey(t *testing.T) {
+	g := NewWithT(t)
+	_, publicKey := generateKey(t)
+
+	key, err := ParsePublicKey(publicKey)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(key).To(BeAssignableToTypeOf(&ecdsa.PublicKey{}))
+
+	_, err = ParsePublicKey("not a key")
+	g.Expect(err).To(HaveOccurred())
+
+	_, err = NewPolicy(config.ImageVerificationPolicy{PublicKey: "not a key"})
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestVerifyPayload(t *testing.T) {
+	g := NewWithT(t)
+	key, _ := generateKey(t)
+	payload := []byte("payload")
+	hash := sha256.Sum256(payload)
+	signature, err := key.Sign(rand.Reader, hash[:], crypto.SHA256)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(verifyPayload(key.Public(), payload, signature)).To(BeTrue())
+	g.Expect(verifyPayload(key.Public(), []byte("other payload"), signature)).To(BeFalse())
+}
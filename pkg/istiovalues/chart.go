@@ -0,0 +1,106 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package istiovalues
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/istio-ecosystem/sail-operator/pkg/helm"
+	"gopkg.in/yaml.v3"
+)
+
+// internalDefaultsKey is the key under which the charts store their default values,
+// so that the zzz_profile.yaml template can insert the profile values between the
+// default values and the user-provided values.
+const internalDefaultsKey = "_internal_defaults_do_not_set"
+
+// EvaluateChartValues returns the values that the chart in chartDir sees when it's
+// rendered with the given user values. It emulates the chart's zzz_profile.yaml
+// template, which merges the following, with each overriding the previous:
+//   - the default values in the chart's values.yaml
+//   - the built-in profile selected by the profile value
+//   - the built-in profile selected by the compatibilityVersion value
+//   - the built-in profile selected by the platform value
+//   - the user values
+func EvaluateChartValues(chartDir string, userValues helm.Values) (helm.Values, error) {
+	values, err := readYAMLFile(path.Join(chartDir, "values.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	if defaults, ok := values[internalDefaultsKey].(map[string]any); ok {
+		values = defaults
+	}
+
+	if profile := getStringValue(userValues, "profile", "global.profile"); profile != "" {
+		if values, err = mergeChartProfile(chartDir, values, "profile-"+profile+".yaml"); err != nil {
+			return nil, err
+		}
+	}
+	if compatibilityVersion := getStringValue(userValues, "compatibilityVersion"); compatibilityVersion != "" {
+		if values, err = mergeChartProfile(chartDir, values, "profile-compatibility-version-"+compatibilityVersion+".yaml"); err != nil {
+			return nil, err
+		}
+	}
+	if platform := getStringValue(userValues, "platform", "global.platform"); platform != "" {
+		if values, err = mergeChartProfile(chartDir, values, "profile-platform-"+platform+".yaml"); err != nil {
+			return nil, err
+		}
+	}
+
+	return mergeOverwrite(values, helm.FromValues(userValues)), nil
+}
+
+func mergeChartProfile(chartDir string, values helm.Values, fileName string) (helm.Values, error) {
+	filesDir := path.Join(chartDir, "files")
+	file := path.Join(filesDir, fileName)
+	// prevent path traversal attacks
+	if path.Dir(file) != filesDir {
+		return nil, fmt.Errorf("invalid profile file name %s", fileName)
+	}
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		// the chart doesn't define the profile, so it doesn't change any values
+		return values, nil
+	}
+	profileValues, err := readYAMLFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return mergeOverwrite(values, profileValues), nil
+}
+
+// getStringValue returns the first non-empty string value found under the given keys
+func getStringValue(values helm.Values, keys ...string) string {
+	for _, key := range keys {
+		if val, found, err := values.GetString(key); found && err == nil && val != "" {
+			return val
+		}
+	}
+	return ""
+}
+
+func readYAMLFile(file string) (helm.Values, error) {
+	fileContents, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %v: %w", file, err)
+	}
+
+	var values map[string]any
+	if err = yaml.Unmarshal(fileContents, &values); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal YAML %s: %w", file, err)
+	}
+	return values, nil
+}
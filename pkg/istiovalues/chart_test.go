@@ -0,0 +1,142 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package istiovalues
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/istio-ecosystem/sail-operator/pkg/helm"
+)
+
+func TestEvaluateChartValues(t *testing.T) {
+	chartDir := t.TempDir()
+	filesDir := path.Join(chartDir, "files")
+	Must(t, os.MkdirAll(filesDir, 0o755))
+
+	Must(t, os.WriteFile(path.Join(chartDir, "values.yaml"), []byte(`
+_internal_defaults_do_not_set:
+  istiodRemote:
+    enabled: false
+  global:
+    externalIstiod: false
+    hub: from-defaults
+    tag: from-defaults
+    platform: ""`), 0o644))
+	Must(t, os.WriteFile(path.Join(filesDir, "profile-remote.yaml"), []byte(`
+istiodRemote:
+  enabled: true
+global:
+  tag: from-profile`), 0o644))
+	Must(t, os.WriteFile(path.Join(filesDir, "profile-platform-openshift.yaml"), []byte(`
+global:
+  hub: from-platform`), 0o644))
+
+	tests := []struct {
+		name         string
+		userValues   helm.Values
+		expectValues helm.Values
+		expectErr    bool
+	}{
+		{
+			name: "defaults only",
+			expectValues: helm.Values{
+				"istiodRemote": map[string]any{"enabled": false},
+				"global": map[string]any{
+					"externalIstiod": false,
+					"hub":            "from-defaults",
+					"tag":            "from-defaults",
+					"platform":       "",
+				},
+			},
+		},
+		{
+			name: "profile and platform",
+			userValues: helm.Values{
+				"profile": "remote",
+				"global":  map[string]any{"platform": "openshift"},
+			},
+			expectValues: helm.Values{
+				"profile":      "remote",
+				"istiodRemote": map[string]any{"enabled": true},
+				"global": map[string]any{
+					"externalIstiod": false,
+					"hub":            "from-platform",
+					"tag":            "from-profile",
+					"platform":       "openshift",
+				},
+			},
+		},
+		{
+			name: "user values override profile",
+			userValues: helm.Values{
+				"profile":      "remote",
+				"istiodRemote": map[string]any{"enabled": false},
+				"global":       map[string]any{"tag": "from-user"},
+			},
+			expectValues: helm.Values{
+				"profile":      "remote",
+				"istiodRemote": map[string]any{"enabled": false},
+				"global": map[string]any{
+					"externalIstiod": false,
+					"hub":            "from-defaults",
+					"tag":            "from-user",
+					"platform":       "",
+				},
+			},
+		},
+		{
+			name:       "profile not defined in chart",
+			userValues: helm.Values{"profile": "unknown"},
+			expectValues: helm.Values{
+				"profile":      "unknown",
+				"istiodRemote": map[string]any{"enabled": false},
+				"global": map[string]any{
+					"externalIstiod": false,
+					"hub":            "from-defaults",
+					"tag":            "from-defaults",
+					"platform":       "",
+				},
+			},
+		},
+		{
+			name:       "path traversal",
+			userValues: helm.Values{"profile": "/../../values"},
+			expectErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := EvaluateChartValues(chartDir, tt.userValues)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("EvaluateChartValues() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if err == nil {
+				if diff := cmp.Diff(tt.expectValues, actual); diff != "" {
+					t.Errorf("EvaluateChartValues() mismatch (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+
+	t.Run("missing chart", func(t *testing.T) {
+		if _, err := EvaluateChartValues(path.Join(chartDir, "does-not-exist"), nil); err == nil {
+			t.Errorf("expected error, but got none")
+		}
+	})
+}
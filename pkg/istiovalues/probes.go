@@ -0,0 +1,49 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package istiovalues
+
+import (
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+)
+
+// ApplyProbeDefaults applies the sidecar readiness probe defaults from the operator configuration, if not already set by user
+func ApplyProbeDefaults(values *v1.Values, defaults config.ProbeDefaults) *v1.Values {
+	if defaults == (config.ProbeDefaults{}) {
+		return values
+	}
+
+	if values == nil {
+		values = &v1.Values{}
+	}
+	if values.Global == nil {
+		values.Global = &v1.GlobalConfig{}
+	}
+	if values.Global.Proxy == nil {
+		values.Global.Proxy = &v1.ProxyConfig{}
+	}
+
+	proxy := values.Global.Proxy
+	if proxy.ReadinessInitialDelaySeconds == nil {
+		proxy.ReadinessInitialDelaySeconds = defaults.ReadinessInitialDelaySeconds
+	}
+	if proxy.ReadinessPeriodSeconds == nil {
+		proxy.ReadinessPeriodSeconds = defaults.ReadinessPeriodSeconds
+	}
+	if proxy.ReadinessFailureThreshold == nil {
+		proxy.ReadinessFailureThreshold = defaults.ReadinessFailureThreshold
+	}
+	return values
+}
@@ -0,0 +1,86 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package istiovalues
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+
+	"istio.io/istio/pkg/ptr"
+)
+
+func TestApplyProbeDefaults(t *testing.T) {
+	defaults := config.ProbeDefaults{
+		ReadinessInitialDelaySeconds: ptr.Of(uint32(5)),
+		ReadinessFailureThreshold:    ptr.Of(uint32(10)),
+	}
+
+	testCases := []struct {
+		name         string
+		defaults     config.ProbeDefaults
+		inputValues  *v1.Values
+		expectValues *v1.Values
+	}{
+		{
+			name:         "no-defaults",
+			inputValues:  nil,
+			expectValues: nil,
+		},
+		{
+			name:     "no-user-values",
+			defaults: defaults,
+			expectValues: &v1.Values{
+				Global: &v1.GlobalConfig{
+					Proxy: &v1.ProxyConfig{
+						ReadinessInitialDelaySeconds: ptr.Of(uint32(5)),
+						ReadinessFailureThreshold:    ptr.Of(uint32(10)),
+					},
+				},
+			},
+		},
+		{
+			name:     "user-values",
+			defaults: defaults,
+			inputValues: &v1.Values{
+				Global: &v1.GlobalConfig{
+					Proxy: &v1.ProxyConfig{
+						ReadinessInitialDelaySeconds: ptr.Of(uint32(1)),
+						ReadinessPeriodSeconds:       ptr.Of(uint32(2)),
+					},
+				},
+			},
+			expectValues: &v1.Values{
+				Global: &v1.GlobalConfig{
+					Proxy: &v1.ProxyConfig{
+						ReadinessInitialDelaySeconds: ptr.Of(uint32(1)),
+						ReadinessPeriodSeconds:       ptr.Of(uint32(2)),
+						ReadinessFailureThreshold:    ptr.Of(uint32(10)),
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := ApplyProbeDefaults(tc.inputValues, tc.defaults)
+			if diff := cmp.Diff(tc.expectValues, result); diff != "" {
+				t.Errorf("unexpected merge result; diff (-expected, +actual):\n%v", diff)
+			}
+		})
+	}
+}
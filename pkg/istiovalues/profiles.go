@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"path/filepath"
 
 	"github.com/istio-ecosystem/sail-operator/pkg/config"
 	"github.com/istio-ecosystem/sail-operator/pkg/helm"
@@ -38,7 +39,7 @@ func ApplyProfilesAndPlatform(
 	}
 	values := helm.Values(mergeOverwrite(defaultValues, userValues))
 
-	if platform != config.PlatformKubernetes && platform != config.PlatformUndefined {
+	if chartsSupportPlatform(resourceDir, version, platform) {
 		if err = values.SetIfAbsent("global.platform", string(platform)); err != nil {
 			return nil, fmt.Errorf("failed to set global.platform: %w", err)
 		}
@@ -46,10 +47,19 @@ func ApplyProfilesAndPlatform(
 	return values, nil
 }
 
-func ApplyUserValues(mergedValues, userValues helm.Values,
-) (helm.Values, error) {
-	values := helm.Values(mergeOverwrite(mergedValues, userValues))
-	return values, nil
+// chartsSupportPlatform returns whether global.platform may be set to the platform. The charts apply their
+// built-in profile for the platform in global.platform and fail to render if they don't have one, so the value
+// is only set if the charts of the version include a profile-platform-<name>.yaml file. On OpenShift, the value
+// is always set, because older charts check for it in their templates.
+func chartsSupportPlatform(resourceDir, version string, platform config.Platform) bool {
+	switch platform {
+	case config.PlatformUndefined, config.PlatformKubernetes:
+		return false
+	case config.PlatformOpenShift:
+		return true
+	}
+	matches, _ := filepath.Glob(path.Join(resourceDir, version, "charts", "*", "files", "profile-platform-"+string(platform)+".yaml"))
+	return len(matches) > 0
 }
 
 func resolve(defaultProfile, userProfile string) []string {
@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
 	"github.com/istio-ecosystem/sail-operator/pkg/helm"
 	. "github.com/onsi/gomega"
 )
@@ -261,3 +262,81 @@ func Must(t *testing.T, err error) {
 		t.Fatal(err)
 	}
 }
+
+func TestApplyProfilesAndPlatform(t *testing.T) {
+	const version = "my-version"
+	resourceDir := t.TempDir()
+	profilesDir := path.Join(resourceDir, version, "profiles")
+	chartFilesDir := path.Join(resourceDir, version, "charts", "cni", "files")
+	Must(t, os.MkdirAll(profilesDir, 0o755))
+	Must(t, os.MkdirAll(chartFilesDir, 0o755))
+
+	Must(t, os.WriteFile(path.Join(profilesDir, "default.yaml"), []byte(`
+spec:
+  values:
+    cni:
+      cniBinDir: /opt/cni/bin
+      logLevel: info`), 0o644))
+	Must(t, os.WriteFile(path.Join(chartFilesDir, "profile-platform-k3d.yaml"), []byte(`
+cni:
+  cniBinDir: /bin`), 0o644))
+
+	tests := []struct {
+		name         string
+		platform     config.Platform
+		userValues   helm.Values
+		expectValues helm.Values
+	}{
+		{
+			name:     "kubernetes",
+			platform: config.PlatformKubernetes,
+			expectValues: helm.Values{
+				"cni": map[string]any{"cniBinDir": "/opt/cni/bin", "logLevel": "info"},
+			},
+		},
+		{
+			name:     "openshift",
+			platform: config.PlatformOpenShift,
+			expectValues: helm.Values{
+				"cni":    map[string]any{"cniBinDir": "/opt/cni/bin", "logLevel": "info"},
+				"global": map[string]any{"platform": "openshift"},
+			},
+		},
+		{
+			name:     "platform with chart profile",
+			platform: config.PlatformK3d,
+			expectValues: helm.Values{
+				"cni":    map[string]any{"cniBinDir": "/opt/cni/bin", "logLevel": "info"},
+				"global": map[string]any{"platform": "k3d"},
+			},
+		},
+		{
+			name:       "user values override platform",
+			platform:   config.PlatformK3d,
+			userValues: helm.Values{"cni": map[string]any{"cniBinDir": "/custom/bin"}, "global": map[string]any{"platform": "k3s"}},
+			expectValues: helm.Values{
+				"cni":    map[string]any{"cniBinDir": "/custom/bin", "logLevel": "info"},
+				"global": map[string]any{"platform": "k3s"},
+			},
+		},
+		{
+			name:     "platform without profile",
+			platform: config.PlatformEKS,
+			expectValues: helm.Values{
+				"cni": map[string]any{"cniBinDir": "/opt/cni/bin", "logLevel": "info"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := ApplyProfilesAndPlatform(resourceDir, version, tt.platform, "", "", tt.userValues)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.expectValues, actual); diff != "" {
+				t.Errorf("unexpected values; diff (-expected, +actual):\n%v", diff)
+			}
+		})
+	}
+}
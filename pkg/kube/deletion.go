@@ -12,14 +12,15 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package revision
+package kube
 
-import v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+import (
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
 
-// IsUsingRemoteControlPlane returns true if the IstioRevision is configured to
-// connect to a remote rather than deploy a local control plane.
-func IsUsingRemoteControlPlane(rev *v1.IstioRevision) bool {
-	// TODO: we should use values.istiodRemote.enabled instead of the profile, but we can't get the final set of values because of new profiles implementation
-	values := rev.Spec.Values
-	return values != nil && values.Profile != nil && *values.Profile == "remote"
+// IsForceDeleteRequested returns true if the object is annotated with sailoperator.io/force-delete=true, which
+// tells the operator to finalize the object even if workloads still use it.
+func IsForceDeleteRequested(obj client.Object) bool {
+	return obj.GetAnnotations()[constants.ForceDeleteKey] == "true"
 }
@@ -0,0 +1,215 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package namespace
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// ReasonNotDeleted is the reason of the event that is emitted when an owned namespace is left in place
+	ReasonNotDeleted = "NamespaceNotDeleted"
+
+	// maxListedWorkloads is the maximum number of workloads listed in the NamespaceNotDeleted event
+	maxListedWorkloads = 5
+)
+
+// workloadKinds are the kinds of objects that prevent the deletion of a namespace. ReplicaSets, Jobs and Pods only
+// count when they aren't controlled by another object, since their controller (e.g. a Deployment or CronJob) is
+// listed instead.
+var workloadKinds = []struct {
+	gvk            schema.GroupVersionKind
+	standaloneOnly bool
+}{
+	{gvk: appsv1.SchemeGroupVersion.WithKind("Deployment")},
+	{gvk: appsv1.SchemeGroupVersion.WithKind("StatefulSet")},
+	{gvk: appsv1.SchemeGroupVersion.WithKind("DaemonSet")},
+	{gvk: appsv1.SchemeGroupVersion.WithKind("ReplicaSet"), standaloneOnly: true},
+	{gvk: batchv1.SchemeGroupVersion.WithKind("CronJob")},
+	{gvk: batchv1.SchemeGroupVersion.WithKind("Job"), standaloneOnly: true},
+	{gvk: corev1.SchemeGroupVersion.WithKind("Pod"), standaloneOnly: true},
+}
+
+// Owner returns the value of the sailoperator.io/created-for annotation for the given resource.
+func Owner(kind, name string) string {
+	return kind + "/" + name
+}
+
+// Reconcile creates the namespace if it doesn't exist and applies the labels and annotations specified in the
+// NamespaceCreation. A namespace created by this function is annotated with the given owner, so that it can later
+// be deleted by Delete.
+func Reconcile(ctx context.Context, cl client.Client, name string, spec *v1.NamespaceCreation, owner string) error {
+	log := logf.FromContext(ctx)
+
+	ns := &corev1.Namespace{}
+	if err := cl.Get(ctx, types.NamespacedName{Name: name}, ns); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get namespace %q: %w", name, err)
+		}
+		ns = &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Labels:      maps.Clone(spec.Labels),
+				Annotations: map[string]string{constants.NamespaceCreatedForKey: owner},
+			},
+		}
+		for k, v := range spec.Annotations {
+			ns.Annotations[k] = v
+		}
+		log.Info("Creating namespace", "Namespace", name)
+		if err := cl.Create(ctx, ns); err != nil {
+			return fmt.Errorf("failed to create namespace %q: %w", name, err)
+		}
+		return nil
+	}
+
+	if ns.DeletionTimestamp != nil {
+		// the caller's validation reports that the namespace is being deleted
+		return nil
+	}
+
+	patch := client.MergeFrom(ns.DeepCopy())
+	changed := false
+	apply := func(target *map[string]string, values map[string]string) {
+		for k, v := range values {
+			if existing, found := (*target)[k]; !found || existing != v {
+				if *target == nil {
+					*target = map[string]string{}
+				}
+				(*target)[k] = v
+				changed = true
+			}
+		}
+	}
+	apply(&ns.Labels, spec.Labels)
+	apply(&ns.Annotations, spec.Annotations)
+	if !changed {
+		return nil
+	}
+	log.Info("Updating labels and annotations of namespace", "Namespace", name)
+	if err := cl.Patch(ctx, ns, patch); err != nil {
+		return fmt.Errorf("failed to update namespace %q: %w", name, err)
+	}
+	return nil
+}
+
+// +kubebuilder:rbac:groups="apps",resources=statefulsets;replicasets,verbs=list
+// +kubebuilder:rbac:groups="batch",resources=jobs;cronjobs,verbs=list
+
+// Delete deletes the namespace if the NamespaceCreation marks it as owned, the namespace was created for the given
+// owner, and the namespace contains no workloads other than the ones installed by the operator. It returns the
+// workloads that prevented the deletion, if any. The workloads are listed with reader, which should read from the
+// API server (e.g. the manager's API reader), so that the operator doesn't cache all workloads in the cluster. If
+// reader is nil, which is the case when the reconciler wasn't set up with a manager, cl is used.
+func Delete(
+	ctx context.Context, cl client.Client, reader client.Reader, name string, spec *v1.NamespaceCreation, owner string,
+) ([]string, error) {
+	log := logf.FromContext(ctx)
+	if spec == nil || !spec.Owned {
+		return nil, nil
+	}
+
+	ns := &corev1.Namespace{}
+	if err := cl.Get(ctx, types.NamespacedName{Name: name}, ns); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	if ns.DeletionTimestamp != nil || ns.Annotations[constants.NamespaceCreatedForKey] != owner {
+		return nil, nil
+	}
+
+	if reader == nil {
+		reader = cl
+	}
+	workloads, err := listForeignWorkloads(ctx, reader, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(workloads) > 0 {
+		log.Info("Not deleting namespace, because it contains workloads that weren't installed by the operator",
+			"Namespace", name, "workloads", strings.Join(workloads, ", "))
+		return workloads, nil
+	}
+
+	log.Info("Deleting namespace", "Namespace", name)
+	if err := cl.Delete(ctx, ns); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	return nil, nil
+}
+
+// RecordNotDeleted emits a Warning event on the owner of the namespace, listing the workloads that prevented
+// Delete from deleting it. Unlike a condition, the event remains visible after the owner is gone. Nothing is
+// recorded if recorder is nil, which is the case when the reconciler wasn't set up with a manager.
+func RecordNotDeleted(recorder record.EventRecorder, owner runtime.Object, name string, workloads []string) {
+	if recorder == nil || len(workloads) == 0 {
+		return
+	}
+	listed := strings.Join(workloads, ", ")
+	if len(workloads) > maxListedWorkloads {
+		listed = fmt.Sprintf("%s and %d more", strings.Join(workloads[:maxListedWorkloads], ", "), len(workloads)-maxListedWorkloads)
+	}
+	recorder.Eventf(owner, corev1.EventTypeWarning, ReasonNotDeleted,
+		"namespace %s was not deleted, because it contains workloads that weren't installed by the operator: %s", name, listed)
+}
+
+// listForeignWorkloads returns the workloads of the kinds in workloadKinds in the namespace that aren't owned by a
+// resource in the sailoperator.io API group. Only the metadata of the workloads is read. Other objects, such as
+// custom resources that run workloads through their own controllers, aren't considered.
+func listForeignWorkloads(ctx context.Context, reader client.Reader, namespace string) ([]string, error) {
+	var workloads []string
+	for _, kind := range workloadKinds {
+		list := &metav1.PartialObjectMetadataList{}
+		list.SetGroupVersionKind(kind.gvk.GroupVersion().WithKind(kind.gvk.Kind + "List"))
+		if err := reader.List(ctx, list, client.InNamespace(namespace)); err != nil {
+			return nil, fmt.Errorf("failed to list %ss in namespace %q: %w", kind.gvk.Kind, namespace, err)
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			if kind.standaloneOnly && metav1.GetControllerOf(obj) != nil {
+				continue
+			}
+			if !isOwnedByOperator(obj) {
+				workloads = append(workloads, kind.gvk.Kind+"/"+obj.GetName())
+			}
+		}
+	}
+	return workloads, nil
+}
+
+func isOwnedByOperator(obj client.Object) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if gv, err := schema.ParseGroupVersion(ref.APIVersion); err == nil && gv.Group == v1.GroupVersion.Group {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,198 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package namespace
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"istio.io/istio/pkg/ptr"
+)
+
+var (
+	ctx   = context.Background()
+	owner = Owner(v1.IstioCNIKind, "default")
+	nsKey = types.NamespacedName{Name: "istio-cni"}
+)
+
+func newNamespace(annotations map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: nsKey.Name, Annotations: annotations}}
+}
+
+func TestReconcile(t *testing.T) {
+	spec := &v1.NamespaceCreation{
+		Labels:      map[string]string{"pod-security.kubernetes.io/enforce": "privileged"},
+		Annotations: map[string]string{"foo": "bar"},
+	}
+
+	t.Run("creates namespace", func(t *testing.T) {
+		g := NewWithT(t)
+		cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+
+		g.Expect(Reconcile(ctx, cl, nsKey.Name, spec, owner)).To(Succeed())
+
+		ns := &corev1.Namespace{}
+		g.Expect(cl.Get(ctx, nsKey, ns)).To(Succeed())
+		g.Expect(ns.Labels).To(Equal(spec.Labels))
+		g.Expect(ns.Annotations).To(Equal(map[string]string{"foo": "bar", constants.NamespaceCreatedForKey: owner}))
+	})
+
+	t.Run("updates existing namespace", func(t *testing.T) {
+		g := NewWithT(t)
+		existing := newNamespace(map[string]string{"foo": "old", "other": "value"})
+		existing.Labels = map[string]string{"pod-security.kubernetes.io/enforce": "restricted", "team": "a"}
+		cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(existing).Build()
+
+		g.Expect(Reconcile(ctx, cl, nsKey.Name, spec, owner)).To(Succeed())
+
+		ns := &corev1.Namespace{}
+		g.Expect(cl.Get(ctx, nsKey, ns)).To(Succeed())
+		g.Expect(ns.Labels).To(Equal(map[string]string{"pod-security.kubernetes.io/enforce": "privileged", "team": "a"}))
+		g.Expect(ns.Annotations).To(Equal(map[string]string{"foo": "bar", "other": "value"}))
+	})
+}
+
+func TestDelete(t *testing.T) {
+	owned := &v1.NamespaceCreation{Owned: true}
+	createdForOwner := map[string]string{constants.NamespaceCreatedForKey: owner}
+	operatorOwnerRef := metav1.OwnerReference{
+		APIVersion: v1.GroupVersion.String(),
+		Kind:       v1.IstioCNIKind,
+		Name:       "default",
+		Controller: ptr.Of(true),
+	}
+
+	testCases := []struct {
+		name              string
+		spec              *v1.NamespaceCreation
+		objects           []client.Object
+		expectDeleted     bool
+		expectedWorkloads []string
+	}{
+		{
+			name:          "not owned",
+			spec:          &v1.NamespaceCreation{},
+			objects:       []client.Object{newNamespace(createdForOwner)},
+			expectDeleted: false,
+		},
+		{
+			name:          "created for another resource",
+			spec:          owned,
+			objects:       []client.Object{newNamespace(map[string]string{constants.NamespaceCreatedForKey: "ZTunnel/default"})},
+			expectDeleted: false,
+		},
+		{
+			name:          "not created by operator",
+			spec:          owned,
+			objects:       []client.Object{newNamespace(nil)},
+			expectDeleted: false,
+		},
+		{
+			name: "contains only operator workloads",
+			spec: owned,
+			objects: []client.Object{
+				newNamespace(createdForOwner),
+				&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{
+					Name: "istio-cni-node", Namespace: nsKey.Name, OwnerReferences: []metav1.OwnerReference{operatorOwnerRef},
+				}},
+				&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+					Name: "istio-cni-node-abcde", Namespace: nsKey.Name,
+					OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "istio-cni-node", Controller: ptr.Of(true)}},
+				}},
+			},
+			expectDeleted: true,
+		},
+		{
+			name: "contains foreign workloads",
+			spec: owned,
+			objects: []client.Object{
+				newNamespace(createdForOwner),
+				&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: nsKey.Name}},
+				&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: nsKey.Name}},
+				&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "debug", Namespace: nsKey.Name}},
+			},
+			expectDeleted:     false,
+			expectedWorkloads: []string{"Deployment/app", "StatefulSet/db", "Pod/debug"},
+		},
+		{
+			name: "contains standalone ReplicaSets and Jobs",
+			spec: owned,
+			objects: []client.Object{
+				newNamespace(createdForOwner),
+				&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "standalone", Namespace: nsKey.Name}},
+				&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+					Name: "app-abcde", Namespace: nsKey.Name,
+					OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "app", Controller: ptr.Of(true)}},
+				}},
+				&batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: nsKey.Name}},
+				&batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+					Name: "backup-12345", Namespace: nsKey.Name,
+					OwnerReferences: []metav1.OwnerReference{{APIVersion: "batch/v1", Kind: "CronJob", Name: "backup", Controller: ptr.Of(true)}},
+				}},
+				&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "migrate", Namespace: nsKey.Name}},
+			},
+			expectDeleted:     false,
+			expectedWorkloads: []string{"ReplicaSet/standalone", "CronJob/backup", "Job/migrate"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(tc.objects...).Build()
+
+			workloads, err := Delete(ctx, cl, cl, nsKey.Name, tc.spec, owner)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(workloads).To(Equal(tc.expectedWorkloads))
+
+			err = cl.Get(ctx, nsKey, &corev1.Namespace{})
+			if tc.expectDeleted {
+				g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestRecordNotDeleted(t *testing.T) {
+	g := NewWithT(t)
+	cni := &v1.IstioCNI{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+	recorder := record.NewFakeRecorder(10)
+
+	RecordNotDeleted(recorder, cni, nsKey.Name, nil)
+	g.Expect(recorder.Events).To(BeEmpty())
+
+	RecordNotDeleted(recorder, cni, nsKey.Name,
+		[]string{"Deployment/a", "Deployment/b", "Deployment/c", "Deployment/d", "Deployment/e", "Deployment/f"})
+	g.Expect(recorder.Events).To(Receive(Equal("Warning NamespaceNotDeleted namespace istio-cni was not deleted, because it " +
+		"contains workloads that weren't installed by the operator: Deployment/a, Deployment/b, Deployment/c, Deployment/d, Deployment/e and 1 more")))
+
+	// reconcilers that weren't set up with a manager have no recorder
+	RecordNotDeleted(nil, cni, nsKey.Name, []string{"Deployment/a"})
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/istio-ecosystem/sail-operator/pkg/kube"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -26,6 +27,11 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+// blockedFinalizationRetryInterval is how often the finalization of an object is retried while it's blocked, e.g.
+// because workloads still use the object. Since the blocking condition can last for a long time, the retries aren't
+// subject to the exponential backoff of failed reconciliations.
+const blockedFinalizationRetryInterval = 30 * time.Second
+
 // ReconcileFunc is a function that reconciles an object.
 type ReconcileFunc[T client.Object] func(ctx context.Context, obj T) (ctrl.Result, error)
 
@@ -80,6 +86,10 @@ func (r *StandardReconciler[T]) Reconcile(ctx context.Context, req ctrl.Request)
 	if !obj.GetDeletionTimestamp().IsZero() {
 		if r.finalizationEnabled() && kube.HasFinalizer(obj, r.finalizer) {
 			if err := r.finalize(ctx, obj); err != nil {
+				if IsTransientError(err) {
+					log.Info("Finalization blocked. Retrying...", "reason", err, "retryAfter", blockedFinalizationRetryInterval)
+					return ctrl.Result{RequeueAfter: blockedFinalizationRetryInterval}, nil
+				}
 				return ctrl.Result{}, err
 			}
 			return kube.RemoveFinalizer(ctx, r.client, obj, r.finalizer)
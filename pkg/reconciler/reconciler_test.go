@@ -157,6 +157,30 @@ func TestReconcile(t *testing.T) {
 				g.Expect(obj.GetFinalizers()).To(ContainElement(testFinalizer))
 			},
 		},
+		{
+			name: "preserves finalizer and retries later when finalization returns TransientError",
+			objects: []client.Object{
+				&v1.Istio{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              key.Name,
+						DeletionTimestamp: testtime.OneMinuteAgo(),
+						Finalizers:        []string{testFinalizer},
+					},
+				},
+			},
+			setup: func(g *WithT, mock *mockReconciler) {
+				mock.finalizeError = NewTransientError("simulated transient error")
+			},
+			assert: func(g *WithT, cl client.Client, result ctrl.Result, err error, mock *mockReconciler) {
+				g.Expect(result).To(Equal(reconcile.Result{RequeueAfter: blockedFinalizationRetryInterval}))
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(mock.finalizeInvoked).To(BeTrue())
+
+				obj := &v1.Istio{}
+				g.Expect(cl.Get(ctx, key, obj)).To(Succeed())
+				g.Expect(obj.GetFinalizers()).To(ContainElement(testFinalizer))
+			},
+		},
 		{
 			name: "adds finalizer when resource doesn't have it",
 			objects: []client.Object{
@@ -0,0 +1,182 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package revision
+
+import (
+	"strings"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// systemNamespaces are the namespaces that are never injected when
+// sidecarInjectorWebhook.enableNamespacesByDefault is true. This list must
+// match the one in the "auto." webhook in the istiod and revisiontags charts.
+var systemNamespaces = []string{"kube-system", "kube-public", "kube-node-lease", "local-path-storage"}
+
+// InjectionPolicy determines which pods are injected by the sidecar injector
+// of a revision or revision tag. It evaluates the namespaceSelector and
+// objectSelector of the injection webhooks rendered by the chart, followed by
+// the neverInjectSelector, alwaysInjectSelector and autoInject policy that
+// istiod applies to the pods it receives from the webhook.
+type InjectionPolicy struct {
+	name                      string
+	enableNamespacesByDefault bool
+	autoInjectDisabled        bool
+	neverInjectSelectors      []labels.Selector
+	alwaysInjectSelectors     []labels.Selector
+	webhooks                  []admissionv1.MutatingWebhook
+}
+
+// NewInjectionPolicy creates the InjectionPolicy for the revision or revision
+// tag with the given name. The values are those of the IstioRevision that
+// provides the injector. The webhookConfig is the injection
+// MutatingWebhookConfiguration rendered for the revision or tag; if it's nil
+// (e.g. because the chart hasn't been installed yet), the policy falls back to
+// evaluating the istio-injection, istio.io/rev and sidecar.istio.io/inject
+// labels the same way the chart's webhooks do.
+func NewInjectionPolicy(name string, values *v1.Values, webhookConfig *admissionv1.MutatingWebhookConfiguration) *InjectionPolicy {
+	p := &InjectionPolicy{name: name}
+	if values != nil {
+		if sidecarInjector := values.SidecarInjectorWebhook; sidecarInjector != nil {
+			p.enableNamespacesByDefault = sidecarInjector.EnableNamespacesByDefault != nil && *sidecarInjector.EnableNamespacesByDefault
+			p.neverInjectSelectors = toSelectors(sidecarInjector.NeverInjectSelector)
+			p.alwaysInjectSelectors = toSelectors(sidecarInjector.AlwaysInjectSelector)
+		}
+		if values.Global != nil && values.Global.Proxy != nil && values.Global.Proxy.AutoInject != nil {
+			p.autoInjectDisabled = *values.Global.Proxy.AutoInject == "disabled"
+		}
+	}
+	if webhookConfig != nil {
+		p.webhooks = webhookConfig.Webhooks
+	}
+	return p
+}
+
+// InjectsPod returns true if a pod with the given labels and annotations,
+// created in the given namespace, would be injected by this revision or
+// revision tag.
+func (p *InjectionPolicy) InjectsPod(podLabels, podAnnotations map[string]string, ns *corev1.Namespace) bool {
+	return p.webhookSelectsPod(podLabels, ns) && p.injectorSelectsPod(podLabels, podAnnotations)
+}
+
+func (p *InjectionPolicy) webhookSelectsPod(podLabels map[string]string, ns *corev1.Namespace) bool {
+	nsLabels := namespaceLabels(ns)
+	if p.webhooks == nil {
+		return p.labelsSelectPod(podLabels, nsLabels)
+	}
+	for _, webhook := range p.webhooks {
+		if selectorMatches(webhook.NamespaceSelector, nsLabels) && selectorMatches(webhook.ObjectSelector, podLabels) {
+			return true
+		}
+	}
+	return false
+}
+
+// labelsSelectPod mirrors the webhooks in the istiod chart's mutatingwebhook.yaml and is used when the
+// MutatingWebhookConfiguration isn't available.
+func (p *InjectionPolicy) labelsSelectPod(podLabels, nsLabels map[string]string) bool {
+	if nsRevision := GetReferencedRevisionFromNamespace(nsLabels); nsRevision != "" {
+		return nsRevision == p.name && podLabels[constants.IstioSidecarInjectLabel] != "false"
+	}
+	if _, found := nsLabels[constants.IstioInjectionLabel]; found {
+		// e.g. istio-injection=disabled
+		return false
+	}
+	if podRevision := GetReferencedRevisionFromPod(podLabels); podRevision != "" {
+		return podRevision == p.name
+	}
+	_, injectLabelFound := podLabels[constants.IstioSidecarInjectLabel]
+	return p.name == v1.DefaultRevision && p.enableNamespacesByDefault && !injectLabelFound &&
+		!isSystemNamespace(nsLabels[corev1.LabelMetadataName])
+}
+
+// injectorSelectsPod mirrors the logic in istiod's injectRequired function, which decides whether a pod
+// that was sent to the injector by the webhook actually gets injected.
+func (p *InjectionPolicy) injectorSelectsPod(podLabels, podAnnotations map[string]string) bool {
+	// like istiod, we honor the sidecar.istio.io/inject annotation, but the label takes precedence
+	inject := podAnnotations[constants.IstioSidecarInjectLabel]
+	if value, found := podLabels[constants.IstioSidecarInjectLabel]; found {
+		inject = value
+	}
+	switch strings.ToLower(inject) {
+	case "y", "yes", "true", "on":
+		return true
+	case "":
+		// no explicit decision; use selectors and policy
+	default:
+		return false
+	}
+
+	podLabelSet := labels.Set(podLabels)
+	for _, selector := range p.neverInjectSelectors {
+		if !selector.Empty() && selector.Matches(podLabelSet) {
+			return false
+		}
+	}
+	for _, selector := range p.alwaysInjectSelectors {
+		if !selector.Empty() && selector.Matches(podLabelSet) {
+			return true
+		}
+	}
+	return !p.autoInjectDisabled
+}
+
+// namespaceLabels returns the namespace labels, including the kubernetes.io/metadata.name label, which
+// is set by the API server, but may be missing in objects that haven't been persisted yet.
+func namespaceLabels(ns *corev1.Namespace) map[string]string {
+	nsLabels := make(map[string]string, len(ns.Labels)+1)
+	for k, v := range ns.Labels {
+		nsLabels[k] = v
+	}
+	nsLabels[corev1.LabelMetadataName] = ns.Name
+	return nsLabels
+}
+
+func selectorMatches(labelSelector *metav1.LabelSelector, objectLabels map[string]string) bool {
+	if labelSelector == nil {
+		// a webhook without a selector matches all objects
+		return true
+	}
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(objectLabels))
+}
+
+func toSelectors(labelSelectors []metav1.LabelSelector) []labels.Selector {
+	var selectors []labels.Selector
+	for i := range labelSelectors {
+		// istiod ignores invalid selectors, so we do the same
+		if selector, err := metav1.LabelSelectorAsSelector(&labelSelectors[i]); err == nil {
+			selectors = append(selectors, selector)
+		}
+	}
+	return selectors
+}
+
+func isSystemNamespace(name string) bool {
+	for _, ns := range systemNamespaces {
+		if ns == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,248 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package revision
+
+import (
+	"testing"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/istio/pkg/ptr"
+)
+
+func TestInjectionPolicy(t *testing.T) {
+	enabledByDefault := &v1.Values{
+		SidecarInjectorWebhook: &v1.SidecarInjectorConfig{
+			EnableNamespacesByDefault: ptr.Of(true),
+		},
+	}
+
+	// autoWebhook is the "auto." webhook rendered by the istiod chart when enableNamespacesByDefault is true
+	autoWebhook := &admissionv1.MutatingWebhookConfiguration{
+		Webhooks: []admissionv1.MutatingWebhook{
+			{
+				Name: "auto.sidecar-injector.istio.io",
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "istio-injection", Operator: metav1.LabelSelectorOpDoesNotExist},
+						{Key: "istio.io/rev", Operator: metav1.LabelSelectorOpDoesNotExist},
+						{Key: "kubernetes.io/metadata.name", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"kube-system"}},
+					},
+				},
+				ObjectSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "sidecar.istio.io/inject", Operator: metav1.LabelSelectorOpDoesNotExist},
+						{Key: "istio.io/rev", Operator: metav1.LabelSelectorOpDoesNotExist},
+					},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		revision       string
+		values         *v1.Values
+		webhookConfig  *admissionv1.MutatingWebhookConfiguration
+		nsName         string
+		nsLabels       map[string]string
+		podLabels      map[string]string
+		podAnnotations map[string]string
+		expected       bool
+	}{
+		{
+			name:     "no labels",
+			revision: v1.DefaultRevision,
+			expected: false,
+		},
+		{
+			name:     "namespace references revision",
+			revision: "my-rev",
+			nsLabels: map[string]string{"istio.io/rev": "my-rev"},
+			expected: true,
+		},
+		{
+			name:      "namespace references revision, but pod opts out",
+			revision:  "my-rev",
+			nsLabels:  map[string]string{"istio.io/rev": "my-rev"},
+			podLabels: map[string]string{"sidecar.istio.io/inject": "false"},
+			expected:  false,
+		},
+		{
+			name:           "namespace references revision, but pod opts out with annotation",
+			revision:       "my-rev",
+			nsLabels:       map[string]string{"istio.io/rev": "my-rev"},
+			podAnnotations: map[string]string{"sidecar.istio.io/inject": "false"},
+			expected:       false,
+		},
+		{
+			name:           "namespace references revision, pod label overrides annotation",
+			revision:       "my-rev",
+			nsLabels:       map[string]string{"istio.io/rev": "my-rev"},
+			podLabels:      map[string]string{"sidecar.istio.io/inject": "true"},
+			podAnnotations: map[string]string{"sidecar.istio.io/inject": "false"},
+			expected:       true,
+		},
+		{
+			name:     "namespace references other revision",
+			revision: "my-rev",
+			nsLabels: map[string]string{"istio.io/rev": "other-rev"},
+			expected: false,
+		},
+		{
+			name:      "pod references revision",
+			revision:  "my-rev",
+			podLabels: map[string]string{"istio.io/rev": "my-rev"},
+			expected:  true,
+		},
+		{
+			name:      "pod references revision, but namespace has injection disabled",
+			revision:  "my-rev",
+			nsLabels:  map[string]string{"istio-injection": "disabled"},
+			podLabels: map[string]string{"istio.io/rev": "my-rev"},
+			expected:  false,
+		},
+		{
+			name:     "enableNamespacesByDefault, no labels",
+			revision: v1.DefaultRevision,
+			values:   enabledByDefault,
+			expected: true,
+		},
+		{
+			name:     "enableNamespacesByDefault, non-default revision",
+			revision: "my-rev",
+			values:   enabledByDefault,
+			expected: false,
+		},
+		{
+			name:     "enableNamespacesByDefault, system namespace",
+			revision: v1.DefaultRevision,
+			values:   enabledByDefault,
+			nsName:   "kube-system",
+			expected: false,
+		},
+		{
+			name:     "enableNamespacesByDefault, namespace with injection disabled",
+			revision: v1.DefaultRevision,
+			values:   enabledByDefault,
+			nsLabels: map[string]string{"istio-injection": "disabled"},
+			expected: false,
+		},
+		{
+			name:      "enableNamespacesByDefault, pod opts out",
+			revision:  v1.DefaultRevision,
+			values:    enabledByDefault,
+			podLabels: map[string]string{"sidecar.istio.io/inject": "false"},
+			expected:  false,
+		},
+		{
+			name:     "enableNamespacesByDefault, namespace references other revision",
+			revision: v1.DefaultRevision,
+			values:   enabledByDefault,
+			nsLabels: map[string]string{"istio.io/rev": "my-rev"},
+			expected: false,
+		},
+		{
+			name:     "enableNamespacesByDefault, autoInject disabled",
+			revision: v1.DefaultRevision,
+			values: &v1.Values{
+				Global: &v1.GlobalConfig{
+					Proxy: &v1.ProxyConfig{AutoInject: ptr.Of("disabled")},
+				},
+				SidecarInjectorWebhook: &v1.SidecarInjectorConfig{
+					EnableNamespacesByDefault: ptr.Of(true),
+				},
+			},
+			expected: false,
+		},
+		{
+			name:     "enableNamespacesByDefault, pod matches neverInjectSelector",
+			revision: v1.DefaultRevision,
+			values: &v1.Values{
+				SidecarInjectorWebhook: &v1.SidecarInjectorConfig{
+					EnableNamespacesByDefault: ptr.Of(true),
+					NeverInjectSelector: []metav1.LabelSelector{
+						{MatchLabels: map[string]string{"app": "batch"}},
+					},
+				},
+			},
+			podLabels: map[string]string{"app": "batch"},
+			expected:  false,
+		},
+		{
+			name:     "autoInject disabled, pod matches alwaysInjectSelector",
+			revision: "my-rev",
+			values: &v1.Values{
+				Global: &v1.GlobalConfig{
+					Proxy: &v1.ProxyConfig{AutoInject: ptr.Of("disabled")},
+				},
+				SidecarInjectorWebhook: &v1.SidecarInjectorConfig{
+					AlwaysInjectSelector: []metav1.LabelSelector{
+						{MatchLabels: map[string]string{"app": "frontend"}},
+					},
+				},
+			},
+			nsLabels:  map[string]string{"istio.io/rev": "my-rev"},
+			podLabels: map[string]string{"app": "frontend"},
+			expected:  true,
+		},
+		{
+			name:          "rendered webhook, no labels",
+			revision:      v1.DefaultRevision,
+			values:        enabledByDefault,
+			webhookConfig: autoWebhook,
+			expected:      true,
+		},
+		{
+			name:          "rendered webhook, system namespace",
+			revision:      v1.DefaultRevision,
+			values:        enabledByDefault,
+			webhookConfig: autoWebhook,
+			nsName:        "kube-system",
+			expected:      false,
+		},
+		{
+			name:          "rendered webhook, pod references revision",
+			revision:      v1.DefaultRevision,
+			values:        enabledByDefault,
+			webhookConfig: autoWebhook,
+			podLabels:     map[string]string{"istio.io/rev": "default"},
+			expected:      false, // not matched by the auto webhook, and there are no other webhooks in this configuration
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			nsName := tc.nsName
+			if nsName == "" {
+				nsName = "bookinfo"
+			}
+			ns := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   nsName,
+					Labels: tc.nsLabels,
+				},
+			}
+
+			policy := NewInjectionPolicy(tc.revision, tc.values, tc.webhookConfig)
+			if actual := policy.InjectsPod(tc.podLabels, tc.podAnnotations, ns); actual != tc.expected {
+				t.Errorf("expected InjectsPod to return %v, but got %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package revision
+
+import (
+	"fmt"
+	"path"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	"github.com/istio-ecosystem/sail-operator/pkg/helm"
+	"github.com/istio-ecosystem/sail-operator/pkg/istiovalues"
+)
+
+// ComputeMode evaluates the values of the IstioRevision against the istiod chart
+// (including the chart's built-in profiles) and derives the control plane mode
+// from the result.
+func ComputeMode(resourceDir string, rev *v1.IstioRevision) (v1.IstioRevisionMode, error) {
+	chartDir := path.Join(resourceDir, rev.Spec.Version, "charts", constants.IstiodChartName)
+	values, err := istiovalues.EvaluateChartValues(chartDir, helm.FromValues(rev.Spec.Values))
+	if err != nil {
+		return "", fmt.Errorf("failed to evaluate values: %w", err)
+	}
+	return DetermineMode(values), nil
+}
+
+// DetermineMode derives the control plane mode from the fully evaluated Helm values.
+func DetermineMode(values helm.Values) v1.IstioRevisionMode {
+	if remote, _, _ := values.GetBool("istiodRemote.enabled"); remote {
+		return v1.IstioRevisionModeRemote
+	}
+	if external, _, _ := values.GetBool("global.externalIstiod"); external {
+		return v1.IstioRevisionModeExternal
+	}
+	return v1.IstioRevisionModeLocal
+}
+
+// IsUsingRemoteControlPlane returns true if the IstioRevision is configured to
+// connect to a remote rather than deploy a local control plane.
+func IsUsingRemoteControlPlane(rev *v1.IstioRevision) bool {
+	return rev.Status.Mode == v1.IstioRevisionModeRemote
+}
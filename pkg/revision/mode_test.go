@@ -0,0 +1,87 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package revision
+
+import (
+	"path"
+	"testing"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/test/project"
+	"github.com/istio-ecosystem/sail-operator/pkg/test/util/supportedversion"
+
+	"istio.io/istio/pkg/ptr"
+)
+
+func TestComputeMode(t *testing.T) {
+	resourceDir := path.Join(project.RootDir, "resources")
+
+	tests := []struct {
+		name       string
+		values     *v1.Values
+		expectMode v1.IstioRevisionMode
+	}{
+		{
+			name:       "no values",
+			values:     nil,
+			expectMode: v1.IstioRevisionModeLocal,
+		},
+		{
+			name:       "remote profile",
+			values:     &v1.Values{Profile: ptr.Of("remote")},
+			expectMode: v1.IstioRevisionModeRemote,
+		},
+		{
+			name: "istiodRemote enabled without remote profile",
+			values: &v1.Values{
+				IstiodRemote: &v1.IstiodRemoteConfig{Enabled: ptr.Of(true)},
+			},
+			expectMode: v1.IstioRevisionModeRemote,
+		},
+		{
+			name: "remote profile, but istiodRemote disabled in values",
+			values: &v1.Values{
+				Profile:      ptr.Of("remote"),
+				IstiodRemote: &v1.IstiodRemoteConfig{Enabled: ptr.Of(false)},
+			},
+			expectMode: v1.IstioRevisionModeLocal,
+		},
+		{
+			name: "external istiod",
+			values: &v1.Values{
+				Global: &v1.GlobalConfig{ExternalIstiod: ptr.Of(true)},
+			},
+			expectMode: v1.IstioRevisionModeExternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rev := &v1.IstioRevision{
+				Spec: v1.IstioRevisionSpec{
+					Version: supportedversion.Default,
+					Values:  tt.values,
+				},
+			}
+			mode, err := ComputeMode(resourceDir, rev)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if mode != tt.expectMode {
+				t.Errorf("expected mode %s, but got %s", tt.expectMode, mode)
+			}
+		})
+	}
+}
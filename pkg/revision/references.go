@@ -17,8 +17,12 @@ package revision
 import (
 	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
 	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
+// GetReferencedRevisionFromNamespace returns the name of the revision that the
+// namespace explicitly references via the istio-injection or istio.io/rev label.
 func GetReferencedRevisionFromNamespace(labels map[string]string) string {
 	// istio-injection label takes precedence over istio.io/rev
 	if labels[constants.IstioInjectionLabel] == constants.IstioInjectionEnabledValue {
@@ -28,8 +32,8 @@ func GetReferencedRevisionFromNamespace(labels map[string]string) string {
 	if revision != "" {
 		return revision
 	}
-	// TODO: if .Values.sidecarInjectorWebhook.enableNamespacesByDefault is true, then all namespaces except system namespaces should use the "default" revision
-
+	// Note: if .Values.sidecarInjectorWebhook.enableNamespacesByDefault is true, pods in unlabeled namespaces are also
+	// injected by the "default" revision; use InjectionPolicy to determine whether a pod is actually injected.
 	return ""
 }
 
@@ -56,3 +60,28 @@ func GetInjectedRevisionFromPod(podAnnotations map[string]string) string {
 
 	return ""
 }
+
+// GetReferencedRevisionsByNamespace returns, for each namespace, the names of the revisions (or revision tags) that
+// are referenced by the namespace itself or by any of the pods in it. Namespaces that don't reference any revision
+// are not included in the result.
+func GetReferencedRevisionsByNamespace(namespaces []corev1.Namespace, pods []corev1.Pod) map[string]sets.Set[string] {
+	references := map[string]sets.Set[string]{}
+	add := func(namespace, revision string) {
+		if revision == "" {
+			return
+		}
+		if references[namespace] == nil {
+			references[namespace] = sets.New[string]()
+		}
+		references[namespace].Insert(revision)
+	}
+
+	for _, ns := range namespaces {
+		add(ns.Name, GetReferencedRevisionFromNamespace(ns.Labels))
+	}
+	for _, pod := range pods {
+		add(pod.Namespace, GetReferencedRevisionFromPod(pod.Labels))
+		add(pod.Namespace, GetInjectedRevisionFromPod(pod.Annotations))
+	}
+	return references
+}
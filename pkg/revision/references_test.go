@@ -0,0 +1,49 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package revision
+
+import (
+	"testing"
+
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestGetReferencedRevisionsByNamespace(t *testing.T) {
+	g := NewWithT(t)
+	namespaces := []corev1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "injection", Labels: map[string]string{constants.IstioInjectionLabel: "enabled"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "rev", Labels: map[string]string{constants.IstioRevLabel: "canary"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "unlabeled"}},
+	}
+	pods := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "labeled", Namespace: "unlabeled", Labels: map[string]string{constants.IstioRevLabel: "prod"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "injected", Namespace: "rev", Annotations: map[string]string{constants.IstioRevLabel: "old"}}},
+		{ObjectMeta: metav1.ObjectMeta{
+			Name:      "opted-out",
+			Namespace: "unlabeled",
+			Labels:    map[string]string{constants.IstioRevLabel: "ignored", constants.IstioSidecarInjectLabel: "false"},
+		}},
+	}
+
+	g.Expect(GetReferencedRevisionsByNamespace(namespaces, pods)).To(Equal(map[string]sets.Set[string]{
+		"injection": sets.New("default"),
+		"rev":       sets.New("canary", "old"),
+		"unlabeled": sets.New("prod"),
+	}))
+}
@@ -0,0 +1,127 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package revision
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	"github.com/istio-ecosystem/sail-operator/pkg/version"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ListUnsupportedSkew returns the in-use IstioRevisions whose version is too far apart from the given
+// data plane component version. Versions that aren't valid semantic versions (e.g. "latest") are ignored.
+func ListUnsupportedSkew(ctx context.Context, cl client.Client, componentVersion string) ([]v1.IstioRevision, error) {
+	cv, err := semver.NewVersion(componentVersion)
+	if err != nil {
+		return nil, nil
+	}
+
+	revList := v1.IstioRevisionList{}
+	if err := cl.List(ctx, &revList); err != nil {
+		return nil, fmt.Errorf("list failed: %w", err)
+	}
+
+	var revisions []v1.IstioRevision
+	for _, rev := range revList.Items {
+		if rev.Status.GetCondition(v1.IstioRevisionConditionInUse).Status != metav1.ConditionTrue {
+			continue
+		}
+		rv, err := semver.NewVersion(rev.Spec.Version)
+		if err != nil {
+			continue
+		}
+		if !version.IsSupportedSkew(cv, rv) {
+			revisions = append(revisions, rev)
+		}
+	}
+	return revisions, nil
+}
+
+// GetControlPlaneVersion returns the version of the given Istio once its active IstioRevision has been
+// upgraded to that version and is ready. Until then, it returns an empty version and a message
+// describing what the caller is waiting for.
+func GetControlPlaneVersion(ctx context.Context, cl client.Client, istioName string) (string, string, error) {
+	istio := v1.Istio{}
+	if err := cl.Get(ctx, types.NamespacedName{Name: istioName}, &istio); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", fmt.Sprintf("Istio %q not found", istioName), nil
+		}
+		return "", "", fmt.Errorf("get failed: %w", err)
+	}
+
+	if istio.Status.ActiveRevisionName == "" {
+		return "", fmt.Sprintf("Istio %q has no active revision", istioName), nil
+	}
+
+	rev := v1.IstioRevision{}
+	if err := cl.Get(ctx, types.NamespacedName{Name: istio.Status.ActiveRevisionName}, &rev); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", fmt.Sprintf("IstioRevision %q not found", istio.Status.ActiveRevisionName), nil
+		}
+		return "", "", fmt.Errorf("get failed: %w", err)
+	}
+
+	if rev.Spec.Version != istio.Spec.Version {
+		return "", fmt.Sprintf("waiting for IstioRevision %q to be upgraded to %s", rev.Name, istio.Spec.Version), nil
+	}
+	if rev.Status.ObservedGeneration < rev.Generation || rev.Status.GetCondition(v1.IstioRevisionConditionReady).Status != metav1.ConditionTrue {
+		return "", fmt.Sprintf("waiting for IstioRevision %q with version %s to become ready", rev.Name, rev.Spec.Version), nil
+	}
+	return istio.Spec.Version, "", nil
+}
+
+// DescribeUnsupportedSkew returns a human-readable message listing the IstioRevisions that don't support
+// the given version of a data plane component.
+func DescribeUnsupportedSkew(component, componentVersion string, revisions []v1.IstioRevision) string {
+	var messages []string
+	for _, rev := range revisions {
+		messages = append(messages, fmt.Sprintf("IstioRevision %s with version %s", rev.Name, rev.Spec.Version))
+	}
+	return fmt.Sprintf("%s version %s is not supported by in-use %s", component, componentVersion, strings.Join(messages, ", "))
+}
+
+// UnsupportedVersionSkewError is returned when a data plane component isn't installed or upgraded because
+// its version is too far apart from the version of an in-use IstioRevision. It wraps a validation error,
+// so the reconciliation isn't retried until the component or one of the revisions changes.
+type UnsupportedVersionSkewError struct {
+	err error
+}
+
+func NewUnsupportedVersionSkewError(message string) error {
+	return &UnsupportedVersionSkewError{err: reconciler.NewValidationError(message)}
+}
+
+func (e UnsupportedVersionSkewError) Error() string {
+	return e.err.Error()
+}
+
+func (e UnsupportedVersionSkewError) Unwrap() error {
+	return e.err
+}
+
+func IsUnsupportedVersionSkewError(err error) bool {
+	e := &UnsupportedVersionSkewError{}
+	return errors.As(err, &e)
+}
@@ -0,0 +1,131 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package revision
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newRevision(name, version string, inUse, ready metav1.ConditionStatus) *v1.IstioRevision {
+	return &v1.IstioRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       v1.IstioRevisionSpec{Version: version},
+		Status: v1.IstioRevisionStatus{
+			Conditions: []v1.IstioRevisionCondition{
+				{Type: v1.IstioRevisionConditionInUse, Status: inUse},
+				{Type: v1.IstioRevisionConditionReady, Status: ready},
+			},
+		},
+	}
+}
+
+func TestListUnsupportedSkew(t *testing.T) {
+	g := NewWithT(t)
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+		newRevision("same-minor", "v1.24.0", metav1.ConditionTrue, metav1.ConditionTrue),
+		newRevision("one-minor-older", "v1.23.4", metav1.ConditionTrue, metav1.ConditionTrue),
+		newRevision("two-minors-older", "v1.22.8", metav1.ConditionTrue, metav1.ConditionTrue),
+		newRevision("two-minors-older-unused", "v1.22.7", metav1.ConditionFalse, metav1.ConditionTrue),
+		newRevision("latest", "latest", metav1.ConditionTrue, metav1.ConditionTrue),
+	).Build()
+
+	revisions, err := ListUnsupportedSkew(context.TODO(), cl, "v1.24.2")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(revisions).To(HaveLen(1))
+	g.Expect(revisions[0].Name).To(Equal("two-minors-older"))
+	g.Expect(DescribeUnsupportedSkew(v1.IstioCNIKind, "v1.24.2", revisions)).
+		To(Equal("IstioCNI version v1.24.2 is not supported by in-use IstioRevision two-minors-older with version v1.22.8"))
+
+	// component versions that aren't semantic versions are never reported
+	revisions, err = ListUnsupportedSkew(context.TODO(), cl, "latest")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(revisions).To(BeEmpty())
+}
+
+func TestGetControlPlaneVersion(t *testing.T) {
+	istio := &v1.Istio{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec:       v1.IstioSpec{Version: "v1.24.2"},
+		Status:     v1.IstioStatus{ActiveRevisionName: "default"},
+	}
+
+	testCases := []struct {
+		name            string
+		objects         []client.Object
+		expectedVersion string
+		expectedPending string
+	}{
+		{
+			name:            "Istio not found",
+			expectedPending: `Istio "default" not found`,
+		},
+		{
+			name: "no active revision",
+			objects: []client.Object{&v1.Istio{
+				ObjectMeta: metav1.ObjectMeta{Name: "default"},
+				Spec:       v1.IstioSpec{Version: "v1.24.2"},
+			}},
+			expectedPending: `Istio "default" has no active revision`,
+		},
+		{
+			name:            "active revision not found",
+			objects:         []client.Object{istio},
+			expectedPending: `IstioRevision "default" not found`,
+		},
+		{
+			name:            "active revision not yet upgraded",
+			objects:         []client.Object{istio, newRevision("default", "v1.24.1", metav1.ConditionTrue, metav1.ConditionTrue)},
+			expectedPending: `waiting for IstioRevision "default" to be upgraded to v1.24.2`,
+		},
+		{
+			name:            "active revision not ready",
+			objects:         []client.Object{istio, newRevision("default", "v1.24.2", metav1.ConditionTrue, metav1.ConditionFalse)},
+			expectedPending: `waiting for IstioRevision "default" with version v1.24.2 to become ready`,
+		},
+		{
+			name:            "active revision upgraded and ready",
+			objects:         []client.Object{istio, newRevision("default", "v1.24.2", metav1.ConditionTrue, metav1.ConditionTrue)},
+			expectedVersion: "v1.24.2",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(tc.objects...).Build()
+
+			version, pending, err := GetControlPlaneVersion(context.TODO(), cl, "default")
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(version).To(Equal(tc.expectedVersion))
+			g.Expect(pending).To(Equal(tc.expectedPending))
+		})
+	}
+}
+
+func TestUnsupportedVersionSkewError(t *testing.T) {
+	g := NewWithT(t)
+	err := NewUnsupportedVersionSkewError("too far apart")
+	g.Expect(IsUnsupportedVersionSkewError(err)).To(BeTrue())
+	g.Expect(reconciler.IsValidationError(err)).To(BeTrue())
+	g.Expect(IsUnsupportedVersionSkewError(reconciler.NewValidationError("other"))).To(BeFalse())
+}
@@ -24,16 +24,17 @@ import (
 )
 
 // ComputeValues computes the Istio Helm values for an IstioRevision as follows:
-// - applies image digests from the operator configuration
+// - applies image digests and sidecar probe defaults from the operator configuration
 // - applies the user-provided values on top of the default values from the default and user-selected profiles
 // - applies overrides that are not configurable by the user
 func ComputeValues(
-	userValues *v1.Values, namespace string, version string,
+	operatorConfig config.OperatorConfig, userValues *v1.Values, namespace string, version string,
 	platform config.Platform, defaultProfile, userProfile string, resourceDir string,
 	activeRevisionName string,
 ) (*v1.Values, error) {
-	// apply image digests from configuration, if not already set by user
-	userValues = istiovalues.ApplyDigests(version, userValues, config.Config)
+	// apply image digests and probe defaults from configuration, if not already set by user
+	userValues = istiovalues.ApplyDigests(version, userValues, operatorConfig)
+	userValues = istiovalues.ApplyProbeDefaults(userValues, operatorConfig.ProbeDefaults)
 
 	// apply userValues on top of defaultValues from profiles
 	mergedHelmValues, err := istiovalues.ApplyProfilesAndPlatform(resourceDir, version, platform, defaultProfile, userProfile, helm.FromValues(userValues))
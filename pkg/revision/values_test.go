@@ -67,7 +67,7 @@ spec:
 		},
 	}
 
-	result, err := ComputeValues(values, namespace, version, config.PlatformOpenShift, "default", "my-profile", resourceDir, revisionName)
+	result, err := ComputeValues(config.OperatorConfig{}, values, namespace, version, config.PlatformOpenShift, "default", "my-profile", resourceDir, revisionName)
 	if err != nil {
 		t.Errorf("Expected no error, but got an error: %v", err)
 	}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 
 	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
 	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -26,8 +27,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// ValidateTargetNamespace checks if the target namespace exists and is not being deleted.
-func ValidateTargetNamespace(ctx context.Context, cl client.Client, namespace string) error {
+// ValidateTargetNamespace checks if the target namespace is watched by the operator, exists and is not being deleted.
+func ValidateTargetNamespace(ctx context.Context, cl client.Client, operatorConfig config.OperatorConfig, namespace string) error {
+	if !operatorConfig.IsNamespaceWatched(namespace) {
+		return reconciler.NewValidationError(fmt.Sprintf("namespace %q is not in the operator's watched namespaces", namespace))
+	}
 	ns := &corev1.Namespace{}
 	if err := cl.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
 		if apierrors.IsNotFound(err) {
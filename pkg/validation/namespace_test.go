@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
 	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
 	"github.com/istio-ecosystem/sail-operator/pkg/test/testtime"
 	. "github.com/onsi/gomega"
@@ -31,10 +32,11 @@ import (
 
 func TestValidateTargetNamespace(t *testing.T) {
 	testCases := []struct {
-		name         string
-		objects      []client.Object
-		interceptors interceptor.Funcs
-		expectErr    string
+		name              string
+		objects           []client.Object
+		interceptors      interceptor.Funcs
+		watchedNamespaces []string
+		expectErr         string
 	}{
 		{
 			name: "success",
@@ -65,6 +67,22 @@ func TestValidateTargetNamespace(t *testing.T) {
 			},
 			expectErr: `namespace "my-namespace" is being deleted`,
 		},
+		{
+			name: "namespace watched",
+			objects: []client.Object{
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "my-namespace"}},
+			},
+			watchedNamespaces: []string{"other-namespace", "my-namespace"},
+			expectErr:         "",
+		},
+		{
+			name: "namespace not watched",
+			objects: []client.Object{
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "my-namespace"}},
+			},
+			watchedNamespaces: []string{"other-namespace"},
+			expectErr:         `namespace "my-namespace" is not in the operator's watched namespaces`,
+		},
 		{
 			name: "get error",
 			interceptors: interceptor.Funcs{
@@ -84,7 +102,8 @@ func TestValidateTargetNamespace(t *testing.T) {
 				WithInterceptorFuncs(tc.interceptors).
 				Build()
 
-			err := ValidateTargetNamespace(context.TODO(), cl, "my-namespace")
+			operatorConfig := config.OperatorConfig{WatchedNamespaces: tc.watchedNamespaces}
+			err := ValidateTargetNamespace(context.TODO(), cl, operatorConfig, "my-namespace")
 			if tc.expectErr == "" {
 				g.Expect(err).ToNot(HaveOccurred())
 			} else {
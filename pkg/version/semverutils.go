@@ -14,7 +14,11 @@
 
 package version
 
-import "github.com/Masterminds/semver/v3"
+import (
+	"fmt"
+
+	"github.com/Masterminds/semver/v3"
+)
 
 // VersionConstraint returns a semver constraint for the given string or panics
 // if the string is not a valid semver constraint.
@@ -25,3 +29,31 @@ func Constraint(constraint string) semver.Constraints {
 	}
 	panic(err)
 }
+
+// MatchesConstraint returns true if the given version satisfies the given semver
+// constraint. It returns an error if either the version or the constraint is invalid.
+func MatchesConstraint(version string, constraint string) (bool, error) {
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return false, fmt.Errorf("invalid version constraint %q: %w", constraint, err)
+	}
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return false, fmt.Errorf("invalid version %q: %w", version, err)
+	}
+	return c.Check(v), nil
+}
+
+// MaxSupportedMinorVersionSkew is the maximum difference between the minor version of a data plane
+// component (IstioCNI, ZTunnel) and the minor version of an IstioRevision that is supported by Istio.
+const MaxSupportedMinorVersionSkew = 1
+
+// IsSupportedSkew returns true if the two versions have the same major version and their minor
+// versions are at most MaxSupportedMinorVersionSkew apart.
+func IsSupportedSkew(a, b *semver.Version) bool {
+	if a.Major() != b.Major() {
+		return false
+	}
+	diff := int64(a.Minor()) - int64(b.Minor())
+	return diff >= -MaxSupportedMinorVersionSkew && diff <= MaxSupportedMinorVersionSkew
+}
@@ -16,6 +16,8 @@ package version
 
 import (
 	"testing"
+
+	"github.com/Masterminds/semver/v3"
 )
 
 func TestConstraint(t *testing.T) {
@@ -32,3 +34,57 @@ func TestConstraint(t *testing.T) {
 		_ = Constraint("invalid_version")
 	})
 }
+
+func TestMatchesConstraint(t *testing.T) {
+	testCases := []struct {
+		name        string
+		version     string
+		constraint  string
+		expected    bool
+		expectError bool
+	}{
+		{name: "matching version", version: "v1.24.2", constraint: ">=1.24, <1.25", expected: true},
+		{name: "non-matching version", version: "v1.23.4", constraint: ">=1.24, <1.25", expected: false},
+		{name: "tilde constraint", version: "1.24.0", constraint: "~1.24", expected: true},
+		{name: "invalid version", version: "latest", constraint: ">=1.24", expectError: true},
+		{name: "invalid constraint", version: "v1.24.2", constraint: "invalid_version", expectError: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := MatchesConstraint(tc.version, tc.constraint)
+			if tc.expectError {
+				if err == nil {
+					t.Error("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if actual != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestIsSupportedSkew(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected bool
+	}{
+		{a: "1.24.2", b: "1.24.0", expected: true},
+		{a: "1.24.2", b: "1.23.4", expected: true},
+		{a: "1.23.4", b: "1.24.2", expected: true},
+		{a: "1.24.2", b: "1.22.8", expected: false},
+		{a: "1.22.8", b: "1.24.2", expected: false},
+		{a: "2.24.0", b: "1.24.0", expected: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.a+" vs "+tc.b, func(t *testing.T) {
+			if actual := IsSupportedSkew(semver.MustParse(tc.a), semver.MustParse(tc.b)); actual != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
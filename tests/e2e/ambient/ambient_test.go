@@ -170,7 +170,7 @@ spec:
 				When("the ZTunnel CR is created", func() {
 					BeforeAll(func() {
 						ztunnelYaml := `
-apiVersion: sailoperator.io/v1alpha1
+apiVersion: sailoperator.io/v1
 kind: ZTunnel
 metadata:
   name: default
@@ -22,12 +22,15 @@ import (
 	"time"
 
 	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
 	. "github.com/istio-ecosystem/sail-operator/pkg/test/util/ginkgo"
 	"github.com/istio-ecosystem/sail-operator/pkg/test/util/supportedversion"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -475,6 +478,9 @@ var _ = Describe("Istio resource", Ordered, func() {
 
 func deleteAllIstiosAndRevisions(ctx context.Context) {
 	Step("Deleting all Istio and IstioRevision resources")
+	// workloads left over by previous tests would otherwise block the deletion
+	forceDeleteAll(ctx, &v1.IstioList{})
+	forceDeleteAll(ctx, &v1.IstioRevisionList{})
 	Eventually(k8sClient.DeleteAllOf).WithArguments(ctx, &v1.Istio{}).Should(Succeed())
 	Eventually(func(g Gomega) {
 		list := &v1.IstioList{}
@@ -490,6 +496,22 @@ func deleteAllIstiosAndRevisions(ctx context.Context) {
 	}).Should(Succeed())
 }
 
+// forceDeleteAll annotates all objects in the list with sailoperator.io/force-delete=true
+func forceDeleteAll(ctx context.Context, list client.ObjectList) {
+	Expect(k8sClient.List(ctx, list)).To(Succeed())
+	Expect(meta.EachListItem(list, func(obj runtime.Object) error {
+		o := obj.(client.Object)
+		patch := client.MergeFrom(o.DeepCopyObject().(client.Object))
+		annotations := o.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[constants.ForceDeleteKey] = "true"
+		o.SetAnnotations(annotations)
+		return client.IgnoreNotFound(k8sClient.Patch(ctx, o, patch))
+	})).To(Succeed())
+}
+
 func generateContextName(withWorkloads bool) string {
 	if withWorkloads {
 		return "with workloads"
@@ -44,7 +44,7 @@ var _ = Describe("IstioCNI", Ordered, func() {
 	SetDefaultEventuallyPollingInterval(time.Second)
 	SetDefaultEventuallyTimeout(30 * time.Second)
 
-	enqueuelogger.LogEnqueueEvents = true
+	enqueuelogger.LogEnqueueEvents.Store(true)
 
 	ctx := context.Background()
 
@@ -53,7 +53,7 @@ var _ = Describe("IstioRevision resource", Ordered, func() {
 	SetDefaultEventuallyPollingInterval(time.Second)
 	SetDefaultEventuallyTimeout(30 * time.Second)
 
-	enqueuelogger.LogEnqueueEvents = true
+	enqueuelogger.LogEnqueueEvents.Store(true)
 
 	ctx := context.Background()
 
@@ -78,11 +78,12 @@ var _ = BeforeSuite(func() {
 		ResourceDirectory: path.Join(project.RootDir, "resources"),
 		Platform:          config.PlatformKubernetes,
 		DefaultProfile:    "",
+		OperatorConfig:    config.NewProvider(config.OperatorConfig{}),
 	}
 
 	cl := mgr.GetClient()
 	scheme := mgr.GetScheme()
-	Expect(istio.NewReconciler(cfg, cl, scheme).SetupWithManager(mgr)).To(Succeed())
+	Expect(istio.NewReconciler(cfg, cl, scheme, chartManager).SetupWithManager(mgr)).To(Succeed())
 	Expect(istiorevision.NewReconciler(cfg, cl, scheme, chartManager).SetupWithManager(mgr)).To(Succeed())
 	Expect(istiorevisiontag.NewReconciler(cfg, cl, scheme, chartManager).SetupWithManager(mgr)).To(Succeed())
 	Expect(istiocni.NewReconciler(cfg, cl, scheme, chartManager).SetupWithManager(mgr)).To(Succeed())